@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+// AdminConfig 本机管理通道：Linux 上为 Unix 套接字（仅运行账户可访问），Windows 上为命名管道（仅 SYSTEM 和管理员可访问），
+// 不需要开放 TCP 端口。每个连接发送一行命令：trigger、status、pause、resume、reload、holds、hold <目录>、release <目录>、confirm <目录>，返回一行 JSON
+type AdminConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Path    string `yaml:"path" mapstructure:"path"` // 默认为程序目录下的 cleanlog.sock，Windows 上为 \\.\pipe\cleanlog；多实例时附加服务名称
+}
+
+// adminListener 管理通道的监听，Unix 套接字和命名管道各自实现
+type adminListener interface {
+	Accept() (io.ReadWriteCloser, error)
+	Close() error
+}
+
+// adminReply 管理命令的返回内容
+type adminReply struct {
+	OK       bool          `json:"ok"`
+	Error    string        `json:"error,omitempty"`
+	Started  *bool         `json:"started,omitempty"`
+	Health   *health       `json:"health,omitempty"`
+	LastRun  *runResult    `json:"last_run,omitempty"`
+	Holds    []holdEntry   `json:"holds,omitempty"`
+	Canaries []canaryEntry `json:"canaries,omitempty"`
+	LogDest  string        `json:"log_destination,omitempty"` // status 时为服务日志实际写入的位置
+}
+
+// adminPath 返回管理通道的路径，未配置时按服务名称生成默认值
+func adminPath(cfg *AdminConfig, name string) string {
+	if cfg != nil && cfg.Path != "" {
+		return cleaner.ExpandPath(cfg.Path)
+	}
+	base := "cleanlog"
+	if name != defaultServiceName {
+		base += "-" + name
+	}
+	return defaultAdminPath(base)
+}
+
+// startAdmin 启动管理通道，监听失败只记录日志，不影响清理任务
+func (p *program) startAdmin() {
+	cfg := p.config.Admin
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+	path := adminPath(cfg, p.name)
+	ln, err := listenAdmin(path)
+	if err != nil {
+		p.logger.Printf(i18n.T("管理通道启动失败: %s"), err)
+		return
+	}
+	p.admin = ln
+	p.logger.Printf(i18n.T("管理通道监听 %s"), path)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				select {
+				case <-p.exit:
+					return
+				default:
+				}
+				p.logger.Printf(i18n.T("管理通道接受连接失败: %s"), err)
+				continue
+			}
+			go p.serveAdmin(conn)
+		}
+	}()
+}
+
+func (p *program) stopAdmin() {
+	if p.admin != nil {
+		p.admin.Close()
+	}
+}
+
+// serveAdmin 读取一行命令并返回结果
+func (p *program) serveAdmin(conn io.ReadWriteCloser) {
+	defer conn.Close()
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && line == "" {
+		return
+	}
+	reply := p.adminCommand(strings.TrimSpace(line))
+	json.NewEncoder(conn).Encode(reply)
+}
+
+func (p *program) adminCommand(cmd string) adminReply {
+	reply := adminReply{OK: true}
+	cmd, arg, _ := strings.Cut(cmd, " ")
+	switch cmd {
+	case "trigger":
+		run, ok := p.begin("")
+		if ok {
+			go run()
+		}
+		reply.Started = &ok
+	case "status":
+		h, _ := p.health()
+		reply.Health = &h
+		p.mu.Lock()
+		if n := len(p.history); n > 0 {
+			reply.LastRun = p.history[n-1]
+		}
+		p.mu.Unlock()
+		reply.Canaries = p.pendingCanaries()
+		reply.LogDest = p.logDest
+	case "pause", "resume":
+		p.setPaused(cmd == "pause")
+	case "reload":
+		if _, err := p.reload(); err != nil {
+			reply.OK = false
+			reply.Error = err.Error()
+		}
+	case "hold", "release":
+		if err := p.setHold(strings.TrimSpace(arg), "", cmd == "hold"); err != nil {
+			reply.OK = false
+			reply.Error = err.Error()
+			break
+		}
+		reply.Holds = p.holdList()
+	case "holds":
+		reply.Holds = p.holdList()
+	case "confirm":
+		if err := p.confirmCanary(strings.TrimSpace(arg)); err != nil {
+			reply.OK = false
+			reply.Error = err.Error()
+			break
+		}
+		reply.Canaries = p.pendingCanaries()
+	default:
+		reply.OK = false
+		reply.Error = i18n.Sprintf("未知命令 %q，可选 trigger、status、pause、resume、reload、holds、hold、release、confirm", cmd)
+	}
+	return reply
+}
+
+// runCtl 实现 ctl 子命令：连接本机管理通道发送命令并输出结果
+func runCtl(configFilePath, name string, args []string) error {
+	hold := len(args) > 0 && (args[0] == "hold" || args[0] == "release" || args[0] == "confirm")
+	if len(args) == 0 || (hold && len(args) < 2) || (!hold && len(args) != 1) {
+		return i18n.Errorf("用法: ctl trigger|status|pause|resume|reload|holds，或 ctl hold|release|confirm <目录>")
+	}
+	if hold {
+		// 服务的工作目录与当前目录不同，相对路径在这里转换
+		dir, err := filepath.Abs(strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+		args = []string{args[0], dir}
+	}
+	var cfg *AdminConfig
+	if err := readConfigKey(configFilePath, "admin", &cfg); err != nil {
+		return err
+	}
+	conn, err := dialAdmin(adminPath(cfg, name))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := fmt.Fprintln(conn, strings.Join(args, " ")); err != nil {
+		return err
+	}
+	_, err = io.Copy(os.Stdout, conn)
+	return err
+}
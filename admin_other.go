@@ -0,0 +1,61 @@
+//go:build !windows
+
+package main
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+func defaultAdminPath(base string) string {
+	return filepath.Join(getCurrentAbPathByExecutable(), base+".sock")
+}
+
+type unixAdminListener struct {
+	ln net.Listener
+}
+
+func (l unixAdminListener) Accept() (io.ReadWriteCloser, error) {
+	return l.ln.Accept()
+}
+
+func (l unixAdminListener) Close() error {
+	return l.ln.Close()
+}
+
+// privateUnixSocket listenUnix 创建的套接字只有运行服务的账户可以连接
+const privateUnixSocket = true
+
+// listenAdmin 监听 Unix 套接字，权限为 0600，只有运行服务的账户可以连接
+func listenAdmin(path string) (adminListener, error) {
+	ln, err := listenUnix(path)
+	if err != nil {
+		return nil, err
+	}
+	return unixAdminListener{ln}, nil
+}
+
+// listenUnix 监听权限为 0600 的 Unix 套接字。创建时临时设置 umask，
+// 套接字从创建起就是 0600，不会在 chmod 之前被其他账户连上
+func listenUnix(path string) (net.Listener, error) {
+	// 上次异常退出时残留的套接字文件会导致监听失败
+	os.Remove(path)
+	old := syscall.Umask(0o177)
+	ln, err := net.Listen("unix", path)
+	syscall.Umask(old)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+func dialAdmin(path string) (io.ReadWriteCloser, error) {
+	return net.Dial("unix", path)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// pipeSDDL 只允许 SYSTEM 和本机管理员访问管道
+const pipeSDDL = "D:P(A;;GA;;;SY)(A;;GA;;;BA)"
+
+func defaultAdminPath(base string) string {
+	return `\\.\pipe\` + base
+}
+
+type pipeListener struct {
+	path   string
+	sa     *windows.SecurityAttributes
+	closed atomic.Bool
+}
+
+// pipeConn 关闭前先刷新缓冲区，确保客户端读完返回内容
+type pipeConn struct {
+	*os.File
+	h windows.Handle
+}
+
+func (c pipeConn) Close() error {
+	windows.FlushFileBuffers(c.h)
+	return c.File.Close()
+}
+
+func listenAdmin(path string) (adminListener, error) {
+	sd, err := windows.SecurityDescriptorFromString(pipeSDDL)
+	if err != nil {
+		return nil, err
+	}
+	sa := &windows.SecurityAttributes{SecurityDescriptor: sd}
+	sa.Length = uint32(unsafe.Sizeof(*sa))
+	return &pipeListener{path: path, sa: sa}, nil
+}
+
+// Accept 为每个连接创建一个管道实例并等待客户端连接
+func (l *pipeListener) Accept() (io.ReadWriteCloser, error) {
+	name, err := windows.UTF16PtrFromString(l.path)
+	if err != nil {
+		return nil, err
+	}
+	h, err := windows.CreateNamedPipe(name,
+		windows.PIPE_ACCESS_DUPLEX,
+		windows.PIPE_TYPE_BYTE|windows.PIPE_READMODE_BYTE|windows.PIPE_WAIT|windows.PIPE_REJECT_REMOTE_CLIENTS,
+		windows.PIPE_UNLIMITED_INSTANCES, 4096, 4096, 0, l.sa)
+	if err != nil {
+		return nil, err
+	}
+	if err := windows.ConnectNamedPipe(h, nil); err != nil && err != windows.ERROR_PIPE_CONNECTED {
+		windows.CloseHandle(h)
+		return nil, err
+	}
+	if l.closed.Load() {
+		windows.CloseHandle(h)
+		return nil, os.ErrClosed
+	}
+	return pipeConn{File: os.NewFile(uintptr(h), l.path), h: h}, nil
+}
+
+// Close 连接一次管道，使阻塞在 ConnectNamedPipe 的 Accept 返回
+func (l *pipeListener) Close() error {
+	if l.closed.Swap(true) {
+		return nil
+	}
+	if c, err := dialAdmin(l.path); err == nil {
+		c.Close()
+	}
+	return nil
+}
+
+// dialAdmin 连接命名管道，所有实例都忙时稍后重试
+func dialAdmin(path string) (io.ReadWriteCloser, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; ; i++ {
+		h, err := windows.CreateFile(name, windows.GENERIC_READ|windows.GENERIC_WRITE, 0, nil, windows.OPEN_EXISTING, 0, 0)
+		if err == nil {
+			return os.NewFile(uintptr(h), path), nil
+		}
+		if err != windows.ERROR_PIPE_BUSY || i >= 20 {
+			return nil, err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// privateUnixSocket Windows 上的本地套接字沿用所在目录的 ACL，其他账户可能可以连接，需配置令牌
+const privateUnixSocket = false
+
+func listenUnix(path string) (net.Listener, error) {
+	// 上次异常退出时残留的套接字文件会导致监听失败
+	os.Remove(path)
+	return net.Listen("unix", path)
+}
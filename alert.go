@@ -0,0 +1,136 @@
+package main
+
+import (
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+	"strings"
+)
+
+// failureAlert alert_on_failures 通知的 details
+type failureAlert struct {
+	Failed      int            `json:"failed"`
+	Threshold   int            `json:"threshold"`
+	Directories map[string]int `json:"directories"` // 各目录的失败数，只列出有失败的目录
+	Categories  map[string]int `json:"categories"`  // 按原因分类的失败数，见 cleaner.ErrorCategories
+}
+
+// overThreshold 任务失败的文件数是否超过 alert_on_failures，未配置时总为 false。调用方需持有 p.mu
+func (p *program) overThreshold(result *runResult) bool {
+	limit := p.failureLimit(result)
+	return limit != nil && result != nil && result.Report.Failed > *limit
+}
+
+// failureLimit 返回任务适用的 alert_on_failures：策略组的任务优先使用该组的设置。调用方需持有 p.mu
+func (p *program) failureLimit(result *runResult) *int {
+	if result != nil {
+		if g := p.config.group(result.Group); g != nil && g.AlertOnFailures != nil {
+			return g.AlertOnFailures
+		}
+	}
+	return p.config.AlertOnFailures
+}
+
+// degraded 最近一次任务失败的文件数超过 alert_on_failures。调用方需持有 p.mu
+func (p *program) degraded() bool {
+	if n := len(p.history); n > 0 {
+		return p.overThreshold(p.history[n-1])
+	}
+	return false
+}
+
+// checkFailures 任务失败的文件数超过 alert_on_failures 时发送通知，偶尔有文件被占用不会触发
+func (p *program) checkFailures(result *runResult) {
+	p.mu.Lock()
+	over := p.overThreshold(result)
+	var limit int
+	if over {
+		limit = *p.failureLimit(result)
+	}
+	p.mu.Unlock()
+	if !over {
+		return
+	}
+	details := failureAlert{Failed: result.Report.Failed, Threshold: limit, Directories: map[string]int{}, Categories: result.Report.Errors}
+	for _, d := range result.Report.Directories {
+		if d.Failed > 0 {
+			details.Directories[d.Path] = d.Failed
+		}
+	}
+	p.notifyDetails("failures", i18n.T("清理任务失败的文件过多"),
+		i18n.Sprintf("本次任务有 %d 个文件处理失败，超过 alert_on_failures 设置的 %d；失败原因: %s", result.Report.Failed, limit, cleaner.DescribeErrors(result.Report.Errors)), details)
+}
+
+// byteCapAlert 达到 max_bytes_per_run 停止任务时通知的 details
+type byteCapAlert struct {
+	Capped      string           `json:"capped"`
+	FreedBytes  int64            `json:"freed_bytes"`
+	Directories map[string]int64 `json:"directories"` // 各目录本次释放的空间，只列出有释放的目录
+}
+
+// checkByteCap 任务因达到 max_bytes_per_run 停止时发送通知。通常说明保留期配置有误，需要人工确认后再放开上限
+func (p *program) checkByteCap(report cleaner.Report) {
+	if report.Capped == "" {
+		return
+	}
+	details := byteCapAlert{Capped: report.Capped, FreedBytes: report.FreedBytes, Directories: map[string]int64{}}
+	for _, d := range report.Directories {
+		if d.FreedBytes > 0 {
+			details.Directories[d.Path] += d.FreedBytes
+		}
+	}
+	p.notifyDetails("bytes_cap", i18n.T("清理任务达到 max_bytes_per_run 已停止"),
+		i18n.Sprintf("本次任务已释放 %s，%s，其余到期文件未处理，请检查保留期配置", cleaner.ByteSize(report.FreedBytes), report.Capped), details)
+}
+
+// runSummary run_success、run_failed 通知的 details
+// abortAlert 目录因失败过多停止处理时通知的 details 中的一项
+type abortAlert struct {
+	Path       string         `json:"path"`
+	Reason     string         `json:"reason"`
+	Failed     int            `json:"failed"`
+	Categories map[string]int `json:"categories,omitempty"`
+}
+
+// checkAborted 有目录因 stop_after_failures、stop_failure_percent 停止处理时发送通知，不受 alert_on_failures 限制
+func (p *program) checkAborted(report cleaner.Report) {
+	var details []abortAlert
+	var lines []string
+	for _, d := range report.Directories {
+		if d.Aborted == "" {
+			continue
+		}
+		details = append(details, abortAlert{Path: d.Path, Reason: d.Aborted, Failed: d.Failed, Categories: d.Errors})
+		lines = append(lines, i18n.Sprintf("%s（%s；失败原因: %s）", d.Path, d.Aborted, cleaner.DescribeErrors(d.Errors)))
+	}
+	if len(details) == 0 {
+		return
+	}
+	p.notifyDetails("dir_aborted", i18n.T("目录因处理失败过多已停止处理"),
+		i18n.Sprintf("以下目录中的文件大量处理失败，通常是权限或挂载问题，本次已停止处理其余文件: %s", strings.Join(lines, "; ")), details)
+}
+
+type runSummary struct {
+	Error      string         `json:"error,omitempty"`
+	Deleted    int            `json:"deleted"`
+	Failed     int            `json:"failed"`
+	FreedBytes int64          `json:"freed_bytes"`
+	Duration   float64        `json:"duration_seconds"`
+	Categories map[string]int `json:"categories,omitempty"`
+}
+
+// notifyRun 任务结束后向 on 中选择了 success 或 failure 的渠道发送结果：任务出错或有文件处理失败时为 run_failed，
+// 否则为 run_success。未配置这两类事件的渠道不会收到，也不记录日志，任务结果已在任务日志中
+func (p *program) notifyRun(result *runResult) {
+	r := result.Report
+	details := runSummary{Error: result.Error, Deleted: r.Deleted, Failed: r.Failed, FreedBytes: r.FreedBytes, Duration: result.Duration, Categories: r.Errors}
+	if result.Error == "" && r.Failed == 0 {
+		p.dispatch("run_success", i18n.T("清理任务完成"),
+			i18n.Sprintf("删除 %d 个文件，释放 %s，耗时 %.1f 秒", r.Deleted, cleaner.ByteSize(r.FreedBytes), result.Duration), details)
+		return
+	}
+	msg := i18n.Sprintf("删除 %d 个文件，释放 %s，失败 %d 个；失败原因: %s", r.Deleted, cleaner.ByteSize(r.FreedBytes), r.Failed, cleaner.DescribeErrors(r.Errors))
+	if result.Error != "" {
+		msg = i18n.Sprintf("任务出错: %s；", result.Error) + msg
+	}
+	p.dispatch("run_failed", i18n.T("清理任务失败"), msg, details)
+}
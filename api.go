@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+const defaultHealthGrace = 10 * time.Minute
+
+// APIConfig HTTP 接口配置
+type APIConfig struct {
+	Listen      string        `yaml:"listen" mapstructure:"listen"`             // 监听地址，如 127.0.0.1:8089
+	HealthGrace time.Duration `yaml:"health_grace" mapstructure:"health_grace"` // 超过预期的下次运行时间多久后 /healthz 返回失败，默认 10m
+	AuthConfig  `yaml:",inline" mapstructure:",squash"`
+}
+
+// health /healthz 的返回内容
+type health struct {
+	Status    string     `json:"status"`
+	Scheduler bool       `json:"scheduler"`
+	LastRun   *time.Time `json:"last_run,omitempty"`
+	NextRun   *time.Time `json:"next_run,omitempty"`
+	Running   bool       `json:"running"`
+	Paused    bool       `json:"paused"`
+	Degraded  bool       `json:"degraded"` // 最近一次任务失败的文件数超过 alert_on_failures
+	Health    string     `json:"health"`   // healthy、degraded 或 failing
+	Version   string     `json:"version"`
+}
+
+// runResult 一次任务的结果，由 /status 返回
+type runResult struct {
+	ID       string         `json:"id,omitempty"`    // 任务 ID，与该任务的日志、通知和指标中的 ID 相同
+	Group    string         `json:"group,omitempty"` // 策略组的定时任务所属的组
+	Start    time.Time      `json:"start"`
+	Duration float64        `json:"duration_seconds"`
+	Error    string         `json:"error,omitempty"`
+	Report   cleaner.Report `json:"report"`
+}
+
+// status /status 的返回内容
+type status struct {
+	Running        bool          `json:"running"`
+	Paused         bool          `json:"paused"`
+	Degraded       bool          `json:"degraded"`
+	Health         string        `json:"health"` // 按最近的任务结果：healthy、degraded（部分失败）或 failing（连续失败或定时未执行）
+	LastRun        *runResult    `json:"last_run,omitempty"`
+	Disks          []diskTrend   `json:"disks,omitempty"`    // disk_trend 开启时各磁盘的剩余空间趋势
+	Holds          []holdEntry   `json:"holds,omitempty"`    // 通过管理接口设置保留的目录
+	Canaries       []canaryEntry `json:"canaries,omitempty"` // 处于 canary 模式、尚未确认的目录
+	Groups         []groupStatus `json:"groups,omitempty"`   // 配置了策略组时各组的状态
+	LogDestination string        `json:"log_destination"`    // 服务日志实际写入的位置，日志文件无法创建时为 eventlog:<服务名称> 或 stderr
+}
+
+// startAPI 启动 HTTP 接口，监听失败只记录日志，不影响清理任务
+func (p *program) startAPI() {
+	p.mu.Lock()
+	cfg := p.config.API
+	p.mu.Unlock()
+	if (cfg == nil || cfg.Listen == "") && p.container {
+		p.startProbes()
+		return
+	}
+	if cfg == nil || cfg.Listen == "" {
+		return
+	}
+	token, tlsConfig, err := cfg.setup(cfg.Listen)
+	if err != nil {
+		p.logger.Printf(i18n.T("HTTP 接口启动失败: %s"), err)
+		return
+	}
+	// /healthz 和管理页面本身不需要令牌，页面中的请求由浏览器带上用户输入的令牌；修改类请求只接受本页面发起
+	auth := func(h http.HandlerFunc) http.Handler { return sameOrigin(requireToken(token, h)) }
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", p.handleHealth)
+	mux.Handle("/status", auth(p.handleStatus))
+	mux.Handle("/metrics", auth(p.handleMetrics))
+	mux.Handle("/history", auth(p.handleHistory))
+	mux.Handle("/run", auth(p.handleRun))
+	mux.Handle("/pause", auth(p.handlePause(true)))
+	mux.Handle("/resume", auth(p.handlePause(false)))
+	mux.Handle("/reload", auth(p.handleReload))
+	mux.Handle("/hold", auth(p.handleHold))
+	mux.Handle("/confirm", auth(p.handleConfirm))
+	mux.Handle("/", dashboardHandler())
+	p.api = &http.Server{Addr: cfg.Listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second, TLSConfig: tlsConfig}
+	go func() {
+		p.logger.Printf(i18n.T("HTTP 接口监听 %s"), cfg.Listen)
+		serve := p.api.ListenAndServe
+		if tlsConfig != nil {
+			serve = func() error { return p.api.ListenAndServeTLS("", "") }
+		}
+		if err := serve(); err != nil && err != http.ErrServerClosed {
+			p.logger.Printf(i18n.T("HTTP 接口启动失败: %s"), err)
+		}
+	}()
+}
+
+func (p *program) stopAPI() {
+	if p.api == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	p.api.Shutdown(ctx)
+}
+
+// health 调度器在运行且最近一次任务在预期时间内开始时为健康。服务启动后还未执行过任务时（catch_up: false、
+// start_delay），到第一次定时任务的时间加上 health_grace 之前同样视为健康，避免探针在第一次清理前反复重启服务
+func (p *program) health() (health, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	grace := defaultHealthGrace
+	if p.config.API != nil && p.config.API.HealthGrace > 0 {
+		grace = p.config.API.HealthGrace
+	}
+	h := health{Scheduler: p.scheduler != nil, Running: p.running, Paused: p.paused, Degraded: p.degraded(), Version: getBuildInfo().Version}
+	h.Health = p.runHealth()
+	if p.paused {
+		// 主动暂停不算故障
+		h.Status = "paused"
+		return h, true
+	}
+	healthy := h.Scheduler && !p.lastRun.IsZero()
+	if p.lastRun.IsZero() && !p.started.IsZero() {
+		first := p.started.Add(p.config.StartDelay)
+		if h.Scheduler {
+			if next, ok := p.nextRun(first); ok {
+				h.NextRun = &next
+				first = next
+			}
+		}
+		healthy = time.Now().Before(first.Add(grace))
+	}
+	if !p.lastRun.IsZero() {
+		lastRun := p.lastRun
+		h.LastRun = &lastRun
+		if next, ok := p.nextRun(lastRun); ok {
+			h.NextRun = &next
+			// 定时任务没有按时执行，不论之前的结果如何都已不再清理
+			if !time.Now().Before(next.Add(grace)) {
+				healthy, h.Health = false, healthFailing
+			}
+		}
+	}
+	// degraded 仍返回 200，只在内容中标记，避免探针因个别文件失败重启服务
+	h.Status = "ok"
+	if !healthy {
+		h.Status = "unhealthy"
+	} else if h.Degraded {
+		h.Status = "degraded"
+	}
+	return h, healthy
+}
+
+// handleHealth 健康时返回 200，否则返回 503
+func (p *program) handleHealth(w http.ResponseWriter, r *http.Request) {
+	h, healthy := p.health()
+	status := http.StatusOK
+	if !healthy {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(h)
+}
+
+// handleStatus 返回最近一次任务的统计，包括各目录释放的空间
+func (p *program) handleStatus(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	st := status{Running: p.running, Paused: p.paused, Degraded: p.degraded(), Disks: p.diskTrends, Groups: p.groupStatuses()}
+	if n := len(p.history); n > 0 {
+		st.LastRun = p.history[n-1]
+	}
+	p.mu.Unlock()
+	h, _ := p.health()
+	st.Health = h.Health
+	st.Holds = p.holdList()
+	st.Canaries = p.pendingCanaries()
+	st.LogDestination = p.logDest
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
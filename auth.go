@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+// AuthConfig 控制接口的认证和 TLS 设置，HTTP 接口和 RPC 接口共用
+type AuthConfig struct {
+	Token     string `yaml:"token" mapstructure:"token"`           // 访问令牌，HTTP 请求头 Authorization: Bearer <token>，RPC 请求中的 token 字段
+	TokenFile string `yaml:"token_file" mapstructure:"token_file"` // 从文件读取令牌，避免明文写在配置中
+	TLSCert   string `yaml:"tls_cert" mapstructure:"tls_cert"`     // 证书文件，与 tls_key 一起配置后启用 TLS
+	TLSKey    string `yaml:"tls_key" mapstructure:"tls_key"`
+}
+
+// resolvePaths 展开环境变量并把相对路径解析为相对于 base
+func (a *AuthConfig) resolvePaths(base string) {
+	for _, p := range []*string{&a.TokenFile, &a.TLSCert, &a.TLSKey} {
+		*p = cleaner.ResolvePath(base, cleaner.ExpandPath(*p))
+	}
+}
+
+// setup 读取令牌和证书。监听非本机地址时必须配置令牌，否则返回错误，接口不启动
+func (a AuthConfig) setup(listen string) (string, *tls.Config, error) {
+	token := a.Token
+	if a.TokenFile != "" {
+		data, err := os.ReadFile(a.TokenFile)
+		if err != nil {
+			return "", nil, i18n.Errorf("读取 token_file 失败: %s", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token == "" && !isLoopback(listen) {
+		return "", nil, i18n.Errorf("监听非本机地址 %s 时必须配置 token 或 token_file", listen)
+	}
+	if (a.TLSCert == "") != (a.TLSKey == "") {
+		return "", nil, i18n.Errorf("tls_cert 和 tls_key 需要同时配置")
+	}
+	if a.TLSCert == "" {
+		return token, nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(a.TLSCert, a.TLSKey)
+	if err != nil {
+		return "", nil, i18n.Errorf("加载 TLS 证书失败: %s", err)
+	}
+	return token, &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
+
+// isLoopback 判断监听地址是否只能从本机访问：回环地址、localhost 或只有运行服务的账户可以连接的本地套接字
+func isLoopback(listen string) bool {
+	if strings.HasPrefix(listen, "unix:") {
+		return privateUnixSocket
+	}
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func validToken(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// requireToken 配置了令牌时要求请求带上 Authorization: Bearer <token>
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !validToken(got, token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="cleanlogservice"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin 拒绝其他网站的页面在浏览器中发起的修改请求（GET、HEAD 以外）：Sec-Fetch-Site 为 cross-site、same-site，
+// 或 Origin 与请求的 Host 不同。未配置令牌的本机接口因此不会被跨站表单触发；命令行工具和脚本不带这些头，不受影响
+func sameOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			cross := false
+			switch r.Header.Get("Sec-Fetch-Site") {
+			case "cross-site", "same-site":
+				cross = true
+			}
+			if origin := r.Header.Get("Origin"); origin != "" {
+				u, err := url.Parse(origin)
+				cross = cross || err != nil || u.Host != r.Host
+			}
+			if cross {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tokenConn 在 RPC 连接上检查每个 JSON-RPC 请求的 token 字段，去掉该字段后交给 jsonrpc 编解码器；
+// 令牌错误时返回错误响应并断开连接
+type tokenConn struct {
+	conn  net.Conn
+	token string
+	dec   *json.Decoder
+	buf   []byte
+	mu    sync.Mutex // 错误响应和 jsonrpc 的正常响应可能同时写入
+}
+
+func newTokenConn(conn net.Conn, token string) io.ReadWriteCloser {
+	return &tokenConn{conn: conn, token: token, dec: json.NewDecoder(bufio.NewReader(conn))}
+}
+
+func (c *tokenConn) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		var req map[string]json.RawMessage
+		if err := c.dec.Decode(&req); err != nil {
+			return 0, err
+		}
+		var token string
+		json.Unmarshal(req["token"], &token)
+		if !validToken(token, c.token) {
+			id := req["id"]
+			if id == nil {
+				id = json.RawMessage("null")
+			}
+			resp, _ := json.Marshal(map[string]interface{}{"id": id, "result": nil, "error": i18n.T("令牌无效")})
+			c.Write(append(resp, '\n'))
+			return 0, io.EOF
+		}
+		delete(req, "token")
+		data, err := json.Marshal(req)
+		if err != nil {
+			return 0, err
+		}
+		c.buf = append(data, '\n')
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+func (c *tokenConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.Write(p)
+}
+
+func (c *tokenConn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// processNames 返回当前运行的进程名（backupProcessName 的形式）：有 /proc 时读取各进程的 argv[0]，
+// /proc/<pid>/comm 只有 15 个字符，仅在 cmdline 为空（内核线程）时使用；其他系统使用 ps
+func processNames() (map[string]bool, error) {
+	names := map[string]bool{}
+	dirs, err := filepath.Glob("/proc/[0-9]*")
+	if err != nil || len(dirs) == 0 {
+		out, err := exec.Command("ps", "-A", "-o", "comm=").Output()
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				names[backupProcessName(line)] = true
+			}
+		}
+		return names, nil
+	}
+	for _, dir := range dirs {
+		if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil && len(cmdline) > 0 {
+			argv0, _, _ := bytes.Cut(cmdline, []byte{0})
+			names[backupProcessName(string(argv0))] = true
+			continue
+		}
+		if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+			names[backupProcessName(strings.TrimSpace(string(comm)))] = true
+		}
+	}
+	return names, nil
+}
+
+// serviceRunning 通过 systemctl 检查 systemd 单元是否处于活动状态，没有 systemd 时返回 false
+func serviceRunning(name string) bool {
+	return exec.Command("systemctl", "is-active", "--quiet", name).Run() == nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// processNames 返回当前运行的进程名（backupProcessName 的形式）
+func processNames() (map[string]bool, error) {
+	snap, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(snap)
+	names := map[string]bool{}
+	e := windows.ProcessEntry32{Size: uint32(unsafe.Sizeof(windows.ProcessEntry32{}))}
+	for err = windows.Process32First(snap, &e); err == nil; err = windows.Process32Next(snap, &e) {
+		names[backupProcessName(windows.UTF16ToString(e.ExeFile[:]))] = true
+	}
+	return names, nil
+}
+
+// serviceRunning 服务处于运行、正在启动或正在停止状态时返回 true，服务不存在或无法查询时返回 false
+func serviceRunning(name string) bool {
+	m, err := mgr.Connect()
+	if err != nil {
+		return false
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(name)
+	if err != nil {
+		return false
+	}
+	defer s.Close()
+	status, err := s.Query()
+	if err != nil {
+		return false
+	}
+	return status.State != svc.Stopped
+}
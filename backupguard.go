@@ -0,0 +1,98 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+const (
+	defaultBackupRetryDelay = 10 * time.Minute
+	defaultBackupRetries    = 12
+)
+
+// vssServices 卷影复制服务和软件提供程序，按需启动，只在创建卷影副本（备份开始时冻结卷）期间运行
+var vssServices = []string{"VSS", "swprv"}
+
+// BackupGuardConfig 定时任务开始前检查备份作业是否正在运行，运行中时推迟，
+// 避免清理与夜间备份争抢磁盘，或删除备份正在读取、卷影副本正在冻结的文件
+type BackupGuardConfig struct {
+	Processes  []string      `yaml:"processes" mapstructure:"processes"`     // 备份程序的进程名，如 wbengine、VeeamAgent.exe、rsync，不区分大小写，.exe 可省略
+	Services   []string      `yaml:"services" mapstructure:"services"`       // 处于运行状态即表示备份进行中的服务（Windows 服务名或 systemd 单元）
+	VSS        bool          `yaml:"vss" mapstructure:"vss"`                 // 卷影复制服务正在运行（正在创建卷影副本）时推迟，仅 Windows
+	RetryDelay time.Duration `yaml:"retry_delay" mapstructure:"retry_delay"` // 推迟多久后重新检查，默认 10m
+	MaxRetries int           `yaml:"max_retries" mapstructure:"max_retries"` // 最多推迟的次数，默认 12
+	Proceed    bool          `yaml:"proceed" mapstructure:"proceed"`         // 推迟 max_retries 次后备份仍在运行时照常执行，默认跳过本次任务
+}
+
+// running 返回正在运行的备份进程和服务的说明，都未运行时返回空
+func (c *BackupGuardConfig) running() []string {
+	var found []string
+	if len(c.Processes) > 0 {
+		procs, err := processNames()
+		if err == nil {
+			for _, name := range c.Processes {
+				if procs[backupProcessName(name)] {
+					found = append(found, i18n.Sprintf("进程 %s", name))
+				}
+			}
+		}
+	}
+	services := c.Services
+	if c.VSS {
+		services = append(append([]string{}, services...), vssServices...)
+	}
+	for _, name := range services {
+		if serviceRunning(name) {
+			found = append(found, i18n.Sprintf("服务 %s", name))
+		}
+	}
+	return found
+}
+
+// backupProcessName 进程名的比较形式：只取文件名、小写、去掉 .exe
+func backupProcessName(name string) string {
+	name = strings.ToLower(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	return strings.TrimSuffix(name, ".exe")
+}
+
+// waitForBackup 备份作业正在运行时推迟任务，最多推迟 max_retries 次，之后按 proceed 执行或跳过；
+// 跳过本次任务或服务停止时返回 false
+func (p *program) waitForBackup() bool {
+	p.mu.Lock()
+	cfg := p.config.BackupGuard
+	p.mu.Unlock()
+	if cfg == nil {
+		return true
+	}
+	delay, retries := cfg.RetryDelay, cfg.MaxRetries
+	if delay <= 0 {
+		delay = defaultBackupRetryDelay
+	}
+	if retries <= 0 {
+		retries = defaultBackupRetries
+	}
+	for i := 1; ; i++ {
+		found := cfg.running()
+		if len(found) == 0 {
+			return true
+		}
+		reason := strings.Join(found, "、")
+		if i > retries {
+			if cfg.Proceed {
+				p.logger.Printf(i18n.T("备份仍在运行（%s），已推迟 %d 次，开始执行"), reason, retries)
+				return true
+			}
+			p.logger.Printf(i18n.T("备份仍在运行（%s），已推迟 %d 次，跳过本次任务"), reason, retries)
+			return false
+		}
+		p.logger.Printf(i18n.T("备份正在运行（%s），%s 后重新检查（第 %d/%d 次推迟）"), reason, delay, i, retries)
+		select {
+		case <-time.After(delay):
+		case <-p.exit:
+			return false
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+// bench 只扫描配置的目录，不删除任何文件，输出各目录的目录项数、扫描速度和 stat 耗时分位数，返回退出码。
+// args 中指定文件时同时把扫描到的目录项保存为快照，供 simulate 使用
+func (p *program) bench(configFilePath string, args []string) int {
+	config, err := p.loadConfig(configFilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("加载配置文件时发生错误: %s")+"\n", err)
+		return exitConfigError
+	}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		p.cancel()
+	}()
+	var snap *cleaner.Snapshot
+	if len(args) > 0 {
+		host, _ := os.Hostname()
+		snap = &cleaner.Snapshot{Taken: time.Now(), Host: host}
+	}
+	results, err := cleaner.New(config.Config).Bench(p.ctx, snap)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, i18n.T("目录\t目录项\t文件\t子目录\t到期\t大小\t耗时\t项/秒\tstat p50\tp90\tp99\t最大\t错误"))
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\t%s\t%.0f\t%s\t%s\t%s\t%s\t%s\n",
+			r.Path, r.Entries, r.Files, r.Dirs, r.Matched, cleaner.ByteSize(r.Bytes), r.Duration.Round(time.Millisecond), r.Rate(),
+			r.StatP50, r.StatP90, r.StatP99, r.StatMax, r.Error)
+	}
+	tw.Flush()
+	if snap != nil && err == nil {
+		if err := cleaner.WriteSnapshot(args[0], snap); err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("保存快照失败: %s")+"\n", err)
+			return exitFailures
+		}
+		fmt.Printf(i18n.T("快照已保存到 %s")+"\n", args[0])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("清理任务失败: %s")+"\n", err)
+		return exitFailures
+	}
+	return exitOK
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+	"cleanlogservice/pkg/state"
+)
+
+const defaultCanaryRuns = 3
+
+// CanaryConfig 新加入配置的目录先以 canary 模式运行：只报告到期文件、不处理，
+// 至少完成 runs 次任务并通过 confirm 命令确认后才开始删除。目录项中的 canary: false 跳过该阶段
+type CanaryConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	Runs    int  `yaml:"runs" mapstructure:"runs"` // 确认前至少完成的 canary 任务数，默认 3
+}
+
+// canaryEntry 一个配置目录的 canary 状态
+type canaryEntry struct {
+	Path      string    `json:"path"` // 目录项在配置中的路径，可以是通配符
+	Added     time.Time `json:"added"`
+	Runs      int       `json:"runs"` // 已完成的 canary 任务数
+	Confirmed bool      `json:"confirmed"`
+}
+
+// canaryPath 返回 canary 状态文件的路径，多实例时附加服务名称
+func canaryPath(name string) string {
+	base := "canary"
+	if name != defaultServiceName {
+		base += "-" + name
+	}
+	return filepath.Join(defaultLogDir(), base+".json")
+}
+
+func (c *CanaryConfig) runs() int {
+	if c.Runs > 0 {
+		return c.Runs
+	}
+	return defaultCanaryRuns
+}
+
+// syncCanary 加载配置后对比状态文件中已知的目录：新出现的目录进入 canary 模式，已从配置中删除的目录移除。
+// 第一次启用时现有的目录都视为已确认
+func (p *program) syncCanary(config appConfig) {
+	cfg := config.Canary
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+	p.canaryMu.Lock()
+	defer p.canaryMu.Unlock()
+	path := canaryPath(p.name)
+	var entries []canaryEntry
+	err := state.Read(stateFs, path, &entries)
+	// 损坏的状态文件已另存为 .corrupt，之后不再视为第一次启用，以免全部目录被当作已确认
+	_, cerr := os.Stat(path + ".corrupt")
+	first := os.IsNotExist(err) && os.IsNotExist(cerr)
+	if err != nil && !os.IsNotExist(err) {
+		// 状态文件损坏时不能把全部目录当作已确认，也不能全部重新进入 canary，保留上次加载的状态
+		p.logger.Printf(i18n.T("读取 canary 状态失败: %s"), err)
+		if p.canaries != nil {
+			if err := p.saveCanary(); err != nil {
+				p.logger.Printf(i18n.T("保存 canary 状态失败: %s"), err)
+			}
+		}
+		return
+	}
+	known := map[string]canaryEntry{}
+	for _, e := range entries {
+		known[holdKey(e.Path)] = e
+	}
+	p.canaries = map[string]canaryEntry{}
+	now := time.Now()
+	for _, dir := range config.Directories {
+		if dir.Canary != nil {
+			continue
+		}
+		key := holdKey(dir.Path)
+		e, ok := known[key]
+		if !ok {
+			e = canaryEntry{Path: dir.Path, Added: now, Confirmed: first}
+			if !first {
+				p.logger.Printf(i18n.T("新目录 %s 进入 canary 模式：至少 %d 次任务只报告不处理，之后需确认才开始删除"), dir.Path, cfg.runs())
+			}
+		}
+		p.canaries[key] = e
+	}
+	if first {
+		p.logger.Printf(i18n.T("首次启用 canary，现有的 %d 个目录视为已确认"), len(p.canaries))
+	}
+	if err := p.saveCanary(); err != nil {
+		p.logger.Printf(i18n.T("保存 canary 状态失败: %s"), err)
+	}
+}
+
+// saveCanary 保存 canary 状态，调用方需持有 p.canaryMu
+func (p *program) saveCanary() error {
+	entries := make([]canaryEntry, 0, len(p.canaries))
+	for _, e := range p.canaries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return state.Write(stateFs, canaryPath(p.name), entries)
+}
+
+// isCanary 供清理任务查询目录是否处于 canary 模式
+func (p *program) isCanary(path string) bool {
+	p.canaryMu.Lock()
+	defer p.canaryMu.Unlock()
+	e, ok := p.canaries[holdKey(path)]
+	return ok && !e.Confirmed
+}
+
+// pendingCanaries 返回尚未确认的目录
+func (p *program) pendingCanaries() []canaryEntry {
+	p.canaryMu.Lock()
+	defer p.canaryMu.Unlock()
+	var entries []canaryEntry
+	for _, e := range p.canaries {
+		if !e.Confirmed {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries
+}
+
+// countCanary 任务结束后累计各 canary 目录完成的任务数，通配符展开的多个目录只计一次
+func (p *program) countCanary(report cleaner.Report) {
+	p.mu.Lock()
+	cfg := p.config.Canary
+	p.mu.Unlock()
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+	p.canaryMu.Lock()
+	defer p.canaryMu.Unlock()
+	counted := map[string]bool{}
+	for _, d := range report.Directories {
+		path := d.Source
+		if path == "" {
+			path = d.Path
+		}
+		key := holdKey(path)
+		e, ok := p.canaries[key]
+		if !d.Canary || !ok || e.Confirmed || counted[key] {
+			continue
+		}
+		counted[key] = true
+		e.Runs++
+		p.canaries[key] = e
+		if e.Runs == cfg.runs() {
+			p.logger.Printf(i18n.T("目录 %s 已完成 %d 次 canary 任务，确认无误后执行 ctl confirm %s 开始处理"), e.Path, e.Runs, e.Path)
+		}
+	}
+	if len(counted) > 0 {
+		if err := p.saveCanary(); err != nil {
+			p.logger.Printf(i18n.T("保存 canary 状态失败: %s"), err)
+		}
+	}
+}
+
+// confirmCanary 确认 canary 目录，之后的任务开始处理其中的文件
+func (p *program) confirmCanary(path string) error {
+	p.mu.Lock()
+	cfg := p.config.Canary
+	p.mu.Unlock()
+	if cfg == nil || !cfg.Enabled {
+		return i18n.Errorf("未启用 canary")
+	}
+	p.canaryMu.Lock()
+	defer p.canaryMu.Unlock()
+	key := holdKey(path)
+	e, ok := p.canaries[key]
+	if !ok || e.Confirmed {
+		return i18n.Errorf("目录 %s 不在 canary 模式中", path)
+	}
+	if e.Runs < cfg.runs() {
+		return i18n.Errorf("目录 %s 只完成了 %d 次 canary 任务，至少需要 %d 次", e.Path, e.Runs, cfg.runs())
+	}
+	e.Confirmed = true
+	p.canaries[key] = e
+	if err := p.saveCanary(); err != nil {
+		return err
+	}
+	p.logger.Printf(i18n.T("目录 %s 已确认，之后的任务开始处理其中的文件"), e.Path)
+	return nil
+}
+
+// handleConfirm POST 确认 canary 目录（参数 path）
+func (p *program) handleConfirm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if err := p.confirmCanary(r.FormValue("path")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p.pendingCanaries())
+}
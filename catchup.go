@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"cleanlogservice/pkg/state"
+)
+
+const (
+	catchUpInterval = time.Minute
+	catchUpGrace    = 2 * time.Minute // 定时到点后超过该时间仍未执行才视为错过，避免与调度器本身的触发重复
+)
+
+// lastRunPath 返回各定时任务上次到点时间的状态文件路径，多实例时附加服务名称
+func lastRunPath(name string) string {
+	base := "lastrun"
+	if name != defaultServiceName {
+		base += "-" + name
+	}
+	return filepath.Join(defaultLogDir(), base+".json")
+}
+
+// loadLastRuns 读取各定时任务（job.key）上次到点的时间，服务重启后用于判断停机期间错过的定时
+func (p *program) loadLastRuns() map[string]time.Time {
+	runs := map[string]time.Time{}
+	if err := state.Read(stateFs, lastRunPath(p.name), &runs); err != nil && !os.IsNotExist(err) {
+		p.logger.Printf(i18n.T("读取上次执行时间失败: %s"), err)
+	}
+	return runs
+}
+
+// recordScheduled 记录定时任务到点的时间，key 为空时为全部目录的任务（启动时执行的一次）。
+// 到点时即记录，因暂停或上一次任务仍在运行而跳过的定时不视为错过
+func (p *program) recordScheduled(key string, at time.Time) {
+	p.mu.Lock()
+	if p.lastRuns == nil {
+		p.lastRuns = p.loadLastRuns()
+	}
+	keys := map[string]bool{}
+	for _, j := range p.jobs {
+		keys[j.key()] = true
+		if key == "" {
+			p.lastRuns[j.key()] = at
+		}
+	}
+	if key != "" {
+		p.lastRuns[key] = at
+	}
+	// 重新加载配置后不再存在的定时任务不再保留
+	for k := range p.lastRuns {
+		if !keys[k] && len(keys) > 0 {
+			delete(p.lastRuns, k)
+		}
+	}
+	runs := make(map[string]time.Time, len(p.lastRuns))
+	for k, v := range p.lastRuns {
+		runs[k] = v
+	}
+	p.mu.Unlock()
+	if err := state.Write(stateFs, lastRunPath(p.name), runs); err != nil {
+		p.logger.Printf(i18n.T("保存上次执行时间失败: %s"), err)
+	}
+}
+
+// missedJobs 返回上次到点后又有定时到点、但没有执行的任务（服务停止、主机休眠期间），调用方需持有 p.mu。
+// 没有记录的任务（第一次启动或新加入的定时）在 all 为 true 时同样返回
+func (p *program) missedJobs(now time.Time, all bool) []*job {
+	if p.lastRuns == nil {
+		p.lastRuns = p.loadLastRuns()
+	}
+	var missed []*job
+	for _, j := range p.jobs {
+		if j.schedule == nil {
+			continue
+		}
+		last, ok := p.lastRuns[j.key()]
+		if !ok {
+			if all {
+				missed = append(missed, j)
+			}
+			continue
+		}
+		if due := j.schedule.Next(last); now.Sub(due) > catchUpGrace {
+			p.logger.Printf(i18n.T("定时任务 %s 错过了 %s 的执行（上次到点于 %s）"), j.key(), displayTime(due), displayTime(last))
+			missed = append(missed, j)
+		}
+	}
+	return missed
+}
+
+// startupRun 服务启动后的第一次任务。未配置 catch_up 时与之前一样立即清理全部目录；
+// catch_up: true 时只补执行停机期间错过定时（或没有执行记录）的任务，之后每分钟检查一次，
+// 主机休眠唤醒后同样补上错过的定时；catch_up: false 时启动时不执行，只记录错过的定时
+func (p *program) startupRun() {
+	p.mu.Lock()
+	catchUp := p.config.CatchUp
+	var missed []*job
+	if catchUp != nil {
+		missed = p.missedJobs(time.Now(), true)
+	}
+	all := len(missed) == len(p.jobs)
+	p.mu.Unlock()
+	switch {
+	case catchUp == nil:
+		go p.cleanDirectories("")
+	case *catchUp:
+		go func() {
+			// 错过的任务依次执行，避免同时开始时因上一次任务仍在运行而被跳过
+			if all && len(missed) > 0 {
+				p.logger.Printf(i18n.T("补执行全部定时任务"))
+				p.cleanDirectories("")
+			} else {
+				p.runMissed(missed)
+			}
+			p.watchMissed()
+		}()
+	}
+}
+
+// watchMissed 每分钟检查一次错过的定时：进程挂起期间调度器的计时暂停，唤醒后的第一次定时可能推迟很久
+func (p *program) watchMissed() {
+	ticker := time.NewTicker(catchUpInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.exit:
+			return
+		case <-ticker.C:
+		}
+		p.mu.Lock()
+		var missed []*job
+		if p.config.CatchUp != nil && *p.config.CatchUp && !p.running {
+			missed = p.missedJobs(time.Now(), false)
+		}
+		p.mu.Unlock()
+		p.runMissed(missed)
+	}
+}
+
+func (p *program) runMissed(missed []*job) {
+	for _, j := range missed {
+		p.logger.Printf(i18n.T("补执行定时任务 %s"), j.key())
+		p.cleanDirectories(j.key())
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/spf13/viper"
+)
+
+// confDirName 主配置文件旁存放配置片段的目录
+const confDirName = "conf.d"
+
+// fragmentKeys 配置片段中允许出现的键，片段中的列表追加到主配置的同名列表之后；
+// 其他全局配置只能在主配置文件中修改，避免各应用的片段相互覆盖
+var fragmentKeys = []string{"directories", "tables", "elasticsearch", "groups"}
+
+// mergeConfDir 按文件名顺序读取主配置文件旁 conf.d 目录中的 *.yml、*.yaml，
+// 将其中的 directories、tables、elasticsearch、groups 追加到主配置中，返回合并的片段。
+// 片段中的相对路径与主配置一样相对于主配置文件所在目录
+func mergeConfDir(v *viper.Viper) ([]string, error) {
+	used := v.ConfigFileUsed()
+	if used == "" {
+		return nil, nil
+	}
+	dir := filepath.Join(filepath.Dir(used), confDirName)
+	var files []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		f := viper.New()
+		f.SetConfigFile(file)
+		f.SetConfigType("yaml")
+		if err := f.ReadInConfig(); err != nil {
+			return nil, i18n.Errorf("读取配置片段 %s 失败: %s", file, err)
+		}
+		for _, key := range f.AllKeys() {
+			top, _, _ := strings.Cut(key, ".")
+			if !isFragmentKey(top) {
+				return nil, i18n.Errorf("配置片段 %s 中不能设置 %s，只能包含 %s", file, top, strings.Join(fragmentKeys, "、"))
+			}
+		}
+		for _, key := range fragmentKeys {
+			raw := f.Get(key)
+			if raw == nil {
+				continue
+			}
+			items, ok := raw.([]interface{})
+			if !ok {
+				return nil, i18n.Errorf("配置片段 %s 中的 %s 应为列表", file, key)
+			}
+			var merged []interface{}
+			if v.IsSet(key) {
+				existing, ok := v.Get(key).([]interface{})
+				if !ok {
+					return nil, i18n.Errorf("主配置中的 %s 应为列表", key)
+				}
+				merged = append(merged, existing...)
+			}
+			// 合并到配置层而不是用 Set 覆盖，重新加载时 ReadInConfig 会丢弃上次合并的结果
+			if err := v.MergeConfigMap(map[string]interface{}{key: append(merged, items...)}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+func isFragmentKey(key string) bool {
+	for _, k := range fragmentKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
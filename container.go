@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+const (
+	// defaultContainerConfig 容器中挂载 ConfigMap 的默认位置
+	defaultContainerConfig = "/etc/cleanlogservice/config.yml"
+	// defaultProbeListen 容器模式下未配置 api 时 /healthz 和 /metrics 的监听地址
+	defaultProbeListen = ":8089"
+	// containerStopGrace 收到 SIGTERM 后等待当前任务取消完成的时间，小于 Kubernetes 默认的 30 秒
+	containerStopGrace = 25 * time.Second
+)
+
+// containerMode 是否以容器模式运行：--container 或环境变量 CLEANLOG_CONTAINER=true
+func containerMode(flag bool) bool {
+	if flag {
+		return true
+	}
+	switch strings.ToLower(os.Getenv("CLEANLOG_CONTAINER")) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// containerConfigPath 容器模式下的配置文件：--config、环境变量 CLEANLOG_CONFIG，
+// 都未指定时使用 ConfigMap 的默认挂载位置（存在时）
+func containerConfigPath(path string) string {
+	if path != "" {
+		return path
+	}
+	if env := os.Getenv("CLEANLOG_CONFIG"); env != "" {
+		return env
+	}
+	if _, err := os.Stat(defaultContainerConfig); err == nil {
+		return defaultContainerConfig
+	}
+	return ""
+}
+
+// jsonLogWriter 把每条日志写成一行 JSON，便于容器日志采集。log.Logger 每条日志调用一次 Write
+type jsonLogWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	service string
+	runID   func() string // 正在执行的任务的 ID
+}
+
+func (j *jsonLogWriter) Write(p []byte) (int, error) {
+	line, err := json.Marshal(struct {
+		Time    string `json:"time"`
+		Service string `json:"service"`
+		RunID   string `json:"run_id,omitempty"`
+		Msg     string `json:"msg"`
+	}{logTime.format(time.Now(), time.RFC3339Nano), j.service, j.runID(), strings.TrimRight(string(p), "\n")})
+	if err != nil {
+		return 0, err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.w.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// startProbes 容器模式下未配置 api 时只提供 /healthz 和 /metrics，供存活/就绪探针和 Prometheus 使用；
+// 不包含控制接口，因此不需要令牌。监听地址默认为 :8089，可通过环境变量 CLEANLOG_PROBE_LISTEN 修改
+func (p *program) startProbes() {
+	listen := os.Getenv("CLEANLOG_PROBE_LISTEN")
+	if listen == "" {
+		listen = defaultProbeListen
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", p.handleHealth)
+	mux.HandleFunc("/metrics", p.handleMetrics)
+	p.api = &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		p.logger.Printf(i18n.T("HTTP 接口监听 %s"), listen)
+		if err := p.api.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			p.logger.Printf(i18n.T("HTTP 接口启动失败: %s"), err)
+		}
+	}()
+}
+
+// runContainer 不经过服务管理器直接运行，收到 SIGTERM 或 Ctrl-C 后取消当前任务，
+// 等待任务结束（最多 containerStopGrace）后退出
+func (p *program) runContainer() int {
+	if err := p.Start(nil); err != nil {
+		p.logger.Printf("%s", err)
+		return 1
+	}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	sig := <-stop
+	p.logger.Printf(i18n.T("收到 %s，停止服务"), sig)
+	p.Stop(nil)
+
+	done := make(chan struct{})
+	go func() {
+		p.runMu.Lock()
+		p.runMu.Unlock()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(containerStopGrace):
+		p.logger.Printf(i18n.T("等待任务结束超时，直接退出"))
+	}
+	return 0
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+)
+
+// webFiles 内嵌的管理页面，由 HTTP 接口在 / 提供
+//
+//go:embed web
+var webFiles embed.FS
+
+func dashboardHandler() http.Handler {
+	sub, _ := fs.Sub(webFiles, "web")
+	return http.FileServer(http.FS(sub))
+}
+
+// handleRun POST /run 立即执行一次任务，不等待结束
+func (p *program) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	run, ok := p.begin("")
+	if ok {
+		go run()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"started": ok})
+}
+
+// handleReload POST /reload 重新加载配置，校验失败时返回 422 并保留原配置
+func (p *program) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := p.reload(); err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]bool{"reloaded": true})
+}
+
+// handlePause POST /pause、/resume 暂停或恢复定时任务
+func (p *program) handlePause(paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		p.setPaused(paused)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]bool{"paused": paused})
+	}
+}
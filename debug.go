@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+const defaultPprofListen = "127.0.0.1:6060"
+
+// DebugConfig 调试选项
+type DebugConfig struct {
+	Pprof       bool   `yaml:"pprof" mapstructure:"pprof"`               // 在本机提供 net/http/pprof，用于分析内存和 goroutine
+	PprofListen string `yaml:"pprof_listen" mapstructure:"pprof_listen"` // 默认 127.0.0.1:6060，不建议监听外部地址
+}
+
+// startPprof 单独监听 pprof，避免随 HTTP 接口暴露到外部
+func (p *program) startPprof() {
+	cfg := p.config.Debug
+	if cfg == nil || !cfg.Pprof {
+		return
+	}
+	addr := cfg.PprofListen
+	if addr == "" {
+		addr = defaultPprofListen
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		p.logger.Printf(i18n.T("pprof 监听 %s"), addr)
+		if err := server.ListenAndServe(); err != nil {
+			p.logger.Printf(i18n.T("pprof 启动失败: %s"), err)
+		}
+	}()
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+	"cleanlogservice/pkg/state"
+)
+
+const (
+	digestDaily      = "daily"
+	digestWeekly     = "weekly"
+	defaultDigestAt  = "08:00"
+	defaultDigestTop = 5
+	digestInterval   = time.Minute // 检查是否到了发送时间的间隔
+)
+
+// DigestConfig 摘要通知：按天或按周汇总期间所有任务的结果，发送一条 digest 通知（合计、与上一期的对比、
+// 释放空间最多的目录、仍未解决的失败），配合渠道的 on 只订阅 digest，减少平时没有异常时的逐次通知
+type DigestConfig struct {
+	Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+	Period  string `yaml:"period" mapstructure:"period"`   // daily（默认）或 weekly
+	At      string `yaml:"at" mapstructure:"at"`           // 发送时间，如 08:00，默认 08:00
+	Weekday string `yaml:"weekday" mapstructure:"weekday"` // weekly 时发送的星期，如 monday（默认）
+	Top     int    `yaml:"top" mapstructure:"top"`         // 列出释放空间最多的目录数，默认 5
+	Path    string `yaml:"path" mapstructure:"path"`       // 默认为服务日志默认目录下的 digest.json，多实例时附加服务名称
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// spec 返回发送时间对应的定时表达式
+func (c *DigestConfig) spec() (string, error) {
+	at := c.At
+	if at == "" {
+		at = defaultDigestAt
+	}
+	m := clockTime.FindStringSubmatch(at)
+	if m == nil {
+		return "", i18n.Errorf("digest.at %q 无效，应为 HH:MM，如 08:00", c.At)
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	switch c.Period {
+	case "", digestDaily:
+		return fmt.Sprintf("0 %d %d * * *", minute, hour), nil
+	case digestWeekly:
+		day := time.Monday
+		if c.Weekday != "" {
+			d, ok := weekdays[strings.ToLower(c.Weekday)]
+			if !ok {
+				return "", i18n.Errorf("digest.weekday %q 无效，应为 monday 至 sunday", c.Weekday)
+			}
+			day = d
+		}
+		return fmt.Sprintf("0 %d %d * * %d", minute, hour, day), nil
+	}
+	return "", i18n.Errorf("digest.period %q 无效，可选 daily、weekly", c.Period)
+}
+
+// digestPath 返回摘要状态文件的路径，未配置时按服务名称生成默认值
+func digestPath(cfg *DigestConfig, name string) string {
+	if cfg.Path != "" {
+		return cleaner.ExpandPath(cfg.Path)
+	}
+	base := "digest"
+	if name != defaultServiceName {
+		base += "-" + name
+	}
+	return filepath.Join(defaultLogDir(), base+".json")
+}
+
+// digestDir 一个目录在本期内的合计
+type digestDir struct {
+	Path       string `json:"path"`
+	Deleted    int    `json:"deleted"`
+	Failed     int    `json:"failed"`
+	FreedBytes int64  `json:"freed_bytes"`
+	LastFailed int    `json:"last_failed"` // 最近一次任务中失败的文件数，大于 0 时为仍未解决的失败
+}
+
+// digestTotals 一期的合计
+type digestTotals struct {
+	Runs       int            `json:"runs"`
+	FailedRuns int            `json:"failed_runs"` // 出错或有文件处理失败的任务数
+	Deleted    int            `json:"deleted"`
+	Failed     int            `json:"failed"`
+	FreedBytes int64          `json:"freed_bytes"`
+	Categories map[string]int `json:"categories,omitempty"`
+}
+
+// digestState 保存在状态文件中的本期记录，服务重启后继续累计
+type digestState struct {
+	Since       time.Time             `json:"since"` // 本期开始的时间
+	Totals      digestTotals          `json:"totals"`
+	Directories map[string]*digestDir `json:"directories"`
+	LastError   string                `json:"last_error,omitempty"` // 本期最近一次出错的任务的错误
+	Previous    *digestTotals         `json:"previous,omitempty"`   // 上一期的合计，用于对比
+}
+
+// digest digest 通知的 details
+type digest struct {
+	From         time.Time     `json:"from"`
+	To           time.Time     `json:"to"`
+	digestTotals               // 本期合计
+	Previous     *digestTotals `json:"previous,omitempty"`
+	Top          []digestDir   `json:"top"`         // 释放空间最多的目录
+	Outstanding  []digestDir   `json:"outstanding"` // 最近一次任务仍有失败的目录
+	LastError    string        `json:"last_error,omitempty"`
+}
+
+func readDigest(path string) (*digestState, error) {
+	st := &digestState{}
+	if err := state.Read(stateFs, path, st); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if st.Directories == nil {
+		st.Directories = map[string]*digestDir{}
+	}
+	return st, nil
+}
+
+// recordDigest 把一次任务的结果累计到本期的摘要
+func (p *program) recordDigest(result *runResult) {
+	p.mu.Lock()
+	cfg := p.config.Digest
+	p.mu.Unlock()
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+	p.digestMu.Lock()
+	defer p.digestMu.Unlock()
+	path := digestPath(cfg, p.name)
+	st, err := readDigest(path)
+	if err != nil {
+		p.logger.Printf(i18n.T("读取摘要记录失败: %s"), err)
+		return
+	}
+	if st.Since.IsZero() {
+		st.Since = result.Start
+	}
+	r := result.Report
+	t := &st.Totals
+	t.Runs++
+	if result.Error != "" || r.Failed > 0 {
+		t.FailedRuns++
+	}
+	if result.Error != "" {
+		st.LastError = result.Error
+	}
+	t.Deleted += r.Deleted
+	t.Failed += r.Failed
+	t.FreedBytes += r.FreedBytes
+	for k, v := range r.Errors {
+		if t.Categories == nil {
+			t.Categories = map[string]int{}
+		}
+		t.Categories[k] += v
+	}
+	for _, d := range r.Directories {
+		if d.OnHold || d.Canary {
+			continue
+		}
+		dd := st.Directories[d.Path]
+		if dd == nil {
+			dd = &digestDir{Path: d.Path}
+			st.Directories[d.Path] = dd
+		}
+		dd.Deleted += d.Deleted
+		dd.Failed += d.Failed
+		dd.FreedBytes += d.FreedBytes
+		dd.LastFailed = d.Failed
+	}
+	if err := state.Write(stateFs, path, st); err != nil {
+		p.logger.Printf(i18n.T("保存摘要记录失败: %s"), err)
+	}
+}
+
+// digestLoop 到了发送时间时发送本期摘要并开始新的一期；服务停止期间错过的发送时间在启动后补发一次
+func (p *program) digestLoop() {
+	ticker := time.NewTicker(digestInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.exit:
+			return
+		case now := <-ticker.C:
+			p.mu.Lock()
+			cfg := p.config.Digest
+			p.mu.Unlock()
+			if cfg == nil || !cfg.Enabled {
+				continue
+			}
+			p.sendDigest(cfg, now)
+		}
+	}
+}
+
+func (p *program) sendDigest(cfg *DigestConfig, now time.Time) {
+	spec, err := cfg.spec()
+	if err != nil {
+		return
+	}
+	sched, err := cronParser.Parse(spec)
+	if err != nil {
+		return
+	}
+	p.digestMu.Lock()
+	defer p.digestMu.Unlock()
+	path := digestPath(cfg, p.name)
+	st, err := readDigest(path)
+	if err != nil {
+		p.logger.Printf(i18n.T("读取摘要记录失败: %s"), err)
+		return
+	}
+	if st.Since.IsZero() {
+		// 还没有记录时从服务启动时开始计算，刚启动时不发送空的摘要
+		st.Since = p.started
+	}
+	if now.Before(sched.Next(st.Since)) {
+		return
+	}
+	d := p.buildDigest(cfg, st, now)
+	p.logger.Printf("%s: %s", i18n.T("清理任务摘要"), d.describe())
+	p.mu.Lock()
+	notify := p.config.Notify
+	p.mu.Unlock()
+	p.send(notify, p.newNotification("digest", i18n.T("清理任务摘要"), d.describe(), d))
+
+	prev := st.Totals
+	next := &digestState{Since: now, Directories: map[string]*digestDir{}, Previous: &prev}
+	// 仍有失败的目录带到下一期，直到之后的任务成功
+	for path, dd := range st.Directories {
+		if dd.LastFailed > 0 {
+			next.Directories[path] = &digestDir{Path: path, LastFailed: dd.LastFailed}
+		}
+	}
+	if err := state.Write(stateFs, path, next); err != nil {
+		p.logger.Printf(i18n.T("保存摘要记录失败: %s"), err)
+	}
+}
+
+func (p *program) buildDigest(cfg *DigestConfig, st *digestState, now time.Time) digest {
+	d := digest{From: st.Since, To: now, digestTotals: st.Totals, Previous: st.Previous, LastError: st.LastError, Top: []digestDir{}, Outstanding: []digestDir{}}
+	top := cfg.Top
+	if top <= 0 {
+		top = defaultDigestTop
+	}
+	var dirs []digestDir
+	for _, dd := range st.Directories {
+		dirs = append(dirs, *dd)
+		if dd.LastFailed > 0 {
+			d.Outstanding = append(d.Outstanding, *dd)
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if dirs[i].FreedBytes != dirs[j].FreedBytes {
+			return dirs[i].FreedBytes > dirs[j].FreedBytes
+		}
+		return dirs[i].Path < dirs[j].Path
+	})
+	for _, dd := range dirs {
+		if len(d.Top) == top || dd.FreedBytes == 0 {
+			break
+		}
+		d.Top = append(d.Top, dd)
+	}
+	sort.Slice(d.Outstanding, func(i, j int) bool { return d.Outstanding[i].Path < d.Outstanding[j].Path })
+	return d
+}
+
+// describe 摘要的文字内容，每项一行
+func (d digest) describe() string {
+	lines := []string{i18n.Sprintf("%s 至 %s：任务 %d 次（其中出错或有失败 %d 次），删除 %d 个文件，释放 %s，失败 %d 个",
+		displayTime(d.From), displayTime(d.To), d.Runs, d.FailedRuns, d.Deleted, cleaner.ByteSize(d.FreedBytes), d.Failed)}
+	if d.Failed > 0 {
+		lines = append(lines, i18n.Sprintf("失败原因: %s", cleaner.DescribeErrors(d.Categories)))
+	}
+	if prev := d.Previous; prev != nil {
+		lines = append(lines, i18n.Sprintf("上一期: 任务 %d 次，删除 %d 个文件，释放 %s（本期 %s），失败 %d 个",
+			prev.Runs, prev.Deleted, cleaner.ByteSize(prev.FreedBytes), change(d.FreedBytes, prev.FreedBytes), prev.Failed))
+	}
+	if len(d.Top) > 0 {
+		var parts []string
+		for _, dd := range d.Top {
+			parts = append(parts, fmt.Sprintf("%s %s", dd.Path, cleaner.ByteSize(dd.FreedBytes)))
+		}
+		lines = append(lines, i18n.Sprintf("释放空间最多的目录: %s", strings.Join(parts, "; ")))
+	}
+	if len(d.Outstanding) > 0 {
+		var parts []string
+		for _, dd := range d.Outstanding {
+			parts = append(parts, i18n.Sprintf("%s（最近一次任务失败 %d 个）", dd.Path, dd.LastFailed))
+		}
+		lines = append(lines, i18n.Sprintf("仍未解决的失败: %s", strings.Join(parts, "; ")))
+	}
+	if d.LastError != "" {
+		lines = append(lines, i18n.Sprintf("最近一次任务错误: %s", d.LastError))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// change 本期相对上一期的变化，如 +12%、-30%；上一期为 0 时无法比较
+func change(cur, prev int64) string {
+	if prev == 0 {
+		if cur == 0 {
+			return i18n.T("持平")
+		}
+		return i18n.T("上一期为 0")
+	}
+	return fmt.Sprintf("%+.0f%%", float64(cur-prev)*100/float64(prev))
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+const defaultDiskAlertTop = 5
+
+// DiskAlertConfig 任务结束后检查清理目录所在磁盘的剩余空间，低于下限说明清理跟不上写入
+type DiskAlertConfig struct {
+	MinFree        cleaner.ByteSize `yaml:"min_free" mapstructure:"min_free"`                 // 剩余空间下限，如 "10GB"
+	MinFreePercent float64          `yaml:"min_free_percent" mapstructure:"min_free_percent"` // 剩余空间占总容量的百分比下限
+	Top            int              `yaml:"top" mapstructure:"top"`                           // 通知中列出的最大文件数，默认 5
+}
+
+// volumeSpace 一个磁盘（卷）的空间情况
+type volumeSpace struct {
+	Volume      string     `json:"volume"`
+	Directories []string   `json:"directories"`
+	Free        uint64     `json:"free_bytes"`
+	Total       uint64     `json:"total_bytes"`
+	Top         []fileSize `json:"top_files"`
+}
+
+type fileSize struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+func (c *DiskAlertConfig) low(free, total uint64) bool {
+	if c.MinFree > 0 && free < uint64(c.MinFree) {
+		return true
+	}
+	return c.MinFreePercent > 0 && total > 0 && float64(free)/float64(total)*100 < c.MinFreePercent
+}
+
+// checkDiskSpace 检查本次任务清理过的本地目录所在磁盘，低于下限时发送通知并附上占用空间最大的文件
+func (p *program) checkDiskSpace(report cleaner.Report) {
+	p.mu.Lock()
+	cfg := p.config.DiskAlert
+	p.mu.Unlock()
+	if cfg == nil || (cfg.MinFree <= 0 && cfg.MinFreePercent <= 0) {
+		return
+	}
+	top := cfg.Top
+	if top <= 0 {
+		top = defaultDiskAlertTop
+	}
+	for _, v := range p.reportVolumes(report) {
+		if !cfg.low(v.Free, v.Total) {
+			continue
+		}
+		v.Top = largestFiles(v.Directories, top)
+		var msg strings.Builder
+		msg.WriteString(i18n.Sprintf("磁盘 %s 剩余 %s / %s，低于配置的下限；清理目录: %s", v.Volume,
+			cleaner.ByteSize(v.Free), cleaner.ByteSize(v.Total), strings.Join(v.Directories, ", ")))
+		for _, f := range v.Top {
+			fmt.Fprintf(&msg, "\n  %s  %s", cleaner.ByteSize(f.Size), f.Path)
+		}
+		p.notifyDetails("disk_space", i18n.T("清理后磁盘剩余空间不足"), msg.String(), v)
+	}
+}
+
+// reportVolumes 按磁盘分组任务中清理过的本地目录，返回各磁盘的剩余空间
+func (p *program) reportVolumes(report cleaner.Report) []*volumeSpace {
+	var volumes []*volumeSpace
+	byID := map[string]*volumeSpace{}
+	for _, d := range report.Directories {
+		if strings.Contains(d.Path, "://") {
+			continue
+		}
+		id, free, total, err := diskSpace(d.Path)
+		if err != nil {
+			p.logger.Printf(i18n.T("获取 %s 所在磁盘的剩余空间失败: %s"), d.Path, err)
+			continue
+		}
+		v, ok := byID[id]
+		if !ok {
+			v = &volumeSpace{Volume: id, Free: free, Total: total}
+			byID[id] = v
+			volumes = append(volumes, v)
+		}
+		v.Directories = append(v.Directories, d.Path)
+	}
+	return volumes
+}
+
+// largestFiles 返回目录树中最大的 n 个文件
+func largestFiles(dirs []string, n int) []fileSize {
+	var files []fileSize
+	seen := map[string]bool{} // 嵌套的清理目录只统计一次
+	for _, dir := range dirs {
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || seen[path] {
+				return nil
+			}
+			seen[path] = true
+			if info, err := d.Info(); err == nil {
+				files = append(files, fileSize{Path: path, Size: info.Size()})
+			}
+			return nil
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Size > files[j].Size })
+	if len(files) > n {
+		files = files[:n]
+	}
+	return files
+}
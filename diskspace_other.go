@@ -0,0 +1,27 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// diskSpace 返回路径所在的文件系统（按设备号区分）以及非 root 用户可用的空间和总空间
+func diskSpace(path string) (string, uint64, uint64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return "", 0, 0, err
+	}
+	volume := path
+	if info, err := os.Stat(path); err == nil {
+		if sys, ok := info.Sys().(*syscall.Stat_t); ok {
+			volume = fmt.Sprintf("dev %d", sys.Dev)
+		}
+	}
+	bsize := uint64(st.Bsize)
+	return volume, uint64(st.Bavail) * bsize, uint64(st.Blocks) * bsize, nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// diskSpace 返回路径所在卷（如 D: 或 \\server\share）以及可用和总空间
+func diskSpace(path string) (string, uint64, uint64, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	p, err := windows.UTF16PtrFromString(abs)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	var free, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &free, &total, &totalFree); err != nil {
+		return "", 0, 0, err
+	}
+	return strings.ToUpper(filepath.VolumeName(abs)), free, total, nil
+}
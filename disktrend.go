@@ -0,0 +1,162 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+	"cleanlogservice/pkg/state"
+)
+
+const (
+	defaultTrendWindow = 7 * 24 * time.Hour
+	minTrendSpan       = time.Hour // 样本跨度不足时不预测，避免一两次任务之间的波动被放大
+)
+
+// DiskTrendConfig 每次任务结束后记录清理目录所在磁盘的剩余空间，按时间窗口内的变化速度预测多久后写满
+type DiskTrendConfig struct {
+	Enabled   bool          `yaml:"enabled" mapstructure:"enabled"`
+	Path      string        `yaml:"path" mapstructure:"path"`             // 默认为服务日志默认目录下的 disktrend.json，多实例时附加服务名称
+	Window    time.Duration `yaml:"window" mapstructure:"window"`         // 计算速度所用的时间窗口，默认 168h（7 天），更早的样本丢弃
+	AlertDays float64       `yaml:"alert_days" mapstructure:"alert_days"` // 预计在该天数内写满时通过 notify 通知，0 不通知
+}
+
+// diskSample 一次任务结束后磁盘的剩余空间
+type diskSample struct {
+	Time  time.Time `json:"time"`
+	Free  uint64    `json:"free"`
+	Total uint64    `json:"total"`
+}
+
+// diskTrend /status 中一个磁盘的剩余空间趋势
+type diskTrend struct {
+	Volume      string     `json:"volume"`
+	Directories []string   `json:"directories"`
+	Free        uint64     `json:"free_bytes"`
+	Total       uint64     `json:"total_bytes"`
+	RatePerDay  float64    `json:"rate_per_day"`           // 剩余空间每天的变化（字节），负数为减少
+	FullInDays  *float64   `json:"full_in_days,omitempty"` // 按当前速度预计多少天后写满，空间未减少时为空
+	FullAt      *time.Time `json:"full_at,omitempty"`
+}
+
+// diskTrendPath 返回样本文件的路径，未配置时按服务名称生成默认值
+func diskTrendPath(cfg *DiskTrendConfig, name string) string {
+	if cfg.Path != "" {
+		return cleaner.ExpandPath(cfg.Path)
+	}
+	base := "disktrend"
+	if name != defaultServiceName {
+		base += "-" + name
+	}
+	return filepath.Join(defaultLogDir(), base+".json")
+}
+
+func readDiskSamples(path string) (map[string][]diskSample, error) {
+	samples := map[string][]diskSample{}
+	if err := state.Read(stateFs, path, &samples); err != nil {
+		if os.IsNotExist(err) {
+			return samples, nil
+		}
+		return nil, err
+	}
+	return samples, nil
+}
+
+func writeDiskSamples(path string, samples map[string][]diskSample) error {
+	return state.Write(stateFs, path, samples)
+}
+
+// project 用最小二乘拟合剩余空间随时间的变化，返回每天的变化量；样本跨度不足时返回 false
+func project(samples []diskSample) (float64, bool) {
+	if len(samples) < 2 || samples[len(samples)-1].Time.Sub(samples[0].Time) < minTrendSpan {
+		return 0, false
+	}
+	t0 := samples[0].Time
+	var sx, sy, sxx, sxy float64
+	for _, s := range samples {
+		x, y := s.Time.Sub(t0).Hours()/24, float64(s.Free)
+		sx, sy, sxx, sxy = sx+x, sy+y, sxx+x*x, sxy+x*y
+	}
+	n := float64(len(samples))
+	d := n*sxx - sx*sx
+	if d == 0 {
+		return 0, false
+	}
+	return (n*sxy - sx*sy) / d, true
+}
+
+// recordDiskTrend 记录本次任务清理过的本地目录所在磁盘的剩余空间，更新 /status 中的趋势，
+// 预计在 alert_days 内写满时通知（同一磁盘恢复前只通知一次）
+func (p *program) recordDiskTrend(report cleaner.Report) {
+	p.mu.Lock()
+	cfg := p.config.DiskTrend
+	p.mu.Unlock()
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+	path := diskTrendPath(cfg, p.name)
+	samples, err := readDiskSamples(path)
+	if err != nil {
+		p.logger.Printf(i18n.T("读取磁盘空间记录失败: %s"), err)
+		samples = map[string][]diskSample{}
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = defaultTrendWindow
+	}
+	now := time.Now()
+	var trends []diskTrend
+	for _, v := range p.reportVolumes(report) {
+		kept := []diskSample{}
+		for _, s := range samples[v.Volume] {
+			if now.Sub(s.Time) <= window {
+				kept = append(kept, s)
+			}
+		}
+		kept = append(kept, diskSample{Time: now, Free: v.Free, Total: v.Total})
+		samples[v.Volume] = kept
+		t := diskTrend{Volume: v.Volume, Directories: v.Directories, Free: v.Free, Total: v.Total}
+		if rate, ok := project(kept); ok {
+			t.RatePerDay = rate
+			if rate < 0 {
+				days := float64(v.Free) / -rate
+				at := now.Add(time.Duration(days * 24 * float64(time.Hour)))
+				t.FullInDays, t.FullAt = &days, &at
+			}
+		}
+		trends = append(trends, t)
+	}
+	// 不再清理的目录所在的磁盘，样本全部过期后删除
+	for volume, s := range samples {
+		if now.Sub(s[len(s)-1].Time) > window {
+			delete(samples, volume)
+		}
+	}
+	if err := writeDiskSamples(path, samples); err != nil {
+		p.logger.Printf(i18n.T("保存磁盘空间记录失败: %s"), err)
+	}
+
+	p.mu.Lock()
+	p.diskTrends = trends
+	if p.trendAlerted == nil {
+		p.trendAlerted = map[string]bool{}
+	}
+	var alerts []diskTrend
+	for _, t := range trends {
+		soon := cfg.AlertDays > 0 && t.FullInDays != nil && *t.FullInDays < cfg.AlertDays
+		if soon && !p.trendAlerted[t.Volume] {
+			alerts = append(alerts, t)
+		}
+		p.trendAlerted[t.Volume] = soon
+	}
+	p.mu.Unlock()
+	for _, t := range alerts {
+		msg := i18n.Sprintf("磁盘 %s 按当前速度预计约 %.1f 天后（%s）写满：剩余 %s / %s，每天减少 %s；清理目录: %s",
+			t.Volume, *t.FullInDays, displayTime(*t.FullAt), cleaner.ByteSize(t.Free), cleaner.ByteSize(t.Total),
+			cleaner.ByteSize(-t.RatePerDay), strings.Join(t.Directories, ", "))
+		p.notifyDetails("disk_trend", i18n.T("磁盘预计即将写满"), msg, t)
+	}
+}
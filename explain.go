@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// explain 输出合并后的生效配置（配置文件、profile 和默认值，路径已展开为绝对路径）以及各目录按当前时间计算的处理阈值；
+// 指定文件时逐步说明该文件是否会被处理。返回退出码
+func (p *program) explain(configFilePath string, files []string) int {
+	config, err := p.loadConfig(configFilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("加载配置文件时发生错误: %s")+"\n", err)
+		return exitConfigError
+	}
+	cl := cleaner.New(config.Config)
+	now := time.Now()
+	if len(files) > 0 {
+		for _, f := range files {
+			abs, _ := filepath.Abs(f)
+			fmt.Println(abs)
+			steps, err := cl.ExplainFile(abs, now)
+			if err != nil {
+				fmt.Println("  " + err.Error())
+				continue
+			}
+			for _, s := range steps {
+				fmt.Println("  " + s)
+			}
+		}
+		return exitOK
+	}
+
+	if used := viper.ConfigFileUsed(); used != "" {
+		fmt.Printf("# "+i18n.T("配置文件: %s")+"\n", used)
+	}
+	if configProfile != "" {
+		fmt.Printf("# profile: %s\n", configProfile)
+	}
+	var node yaml.Node
+	if err := node.Encode(config); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailures
+	}
+	pruneZero(&node)
+	maskSecrets(&node)
+	out, _ := yaml.Marshal(&node)
+	os.Stdout.Write(out)
+
+	plans, err := cl.Plan(now)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitConfigError
+	}
+	fmt.Println("---")
+	fmt.Printf("# "+i18n.T("按当前时间 %s 计算的处理阈值")+"\n", displayTime(now))
+	for _, d := range plans {
+		spec := config.spec()
+		for _, dc := range config.Directories {
+			if dc.Path == d.Path && strings.TrimSpace(dc.Time) != "" {
+				spec = strings.TrimSpace(dc.Time)
+			}
+		}
+		fmt.Printf("%s  (%s)\n", d.Path, spec)
+		for _, r := range d.Rules {
+			fmt.Printf("  "+i18n.T("%s：修改时间早于 %s 的文件")+"\n", r.Tier, displayTime(r.Before))
+		}
+		if !d.QuietSince.IsZero() {
+			fmt.Printf("  "+i18n.T("静默期：修改时间晚于 %s 的文件不处理")+"\n", displayTime(d.QuietSince))
+		}
+		if d.TargetSize > 0 {
+			fmt.Printf("  "+i18n.T("目录总大小降到 %s 以下后停止")+"\n", d.TargetSize)
+		}
+	}
+	return exitOK
+}
+
+// pruneZero 去掉未设置（零值、空列表、null）的配置项，只显示实际生效的设置
+func pruneZero(n *yaml.Node) bool {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			pruneZero(c)
+		}
+		return false
+	case yaml.MappingNode:
+		kept := n.Content[:0]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if !pruneZero(n.Content[i+1]) {
+				kept = append(kept, n.Content[i], n.Content[i+1])
+			}
+		}
+		n.Content = kept
+		return len(kept) == 0
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			pruneZero(c)
+		}
+		return len(n.Content) == 0
+	case yaml.ScalarNode:
+		// 时长和大小以字符串输出，零值为 0s、0B
+		return n.Value == "" || n.Tag == "!!null" || n.Value == "0s" || n.Value == "0B" ||
+			n.Tag != "!!str" && (n.Value == "0" || n.Value == "false")
+	}
+	return false
+}
+
+// maskSecrets 把密码、密钥、令牌类配置项替换为 ******
+func maskSecrets(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, val := strings.ToLower(n.Content[i].Value), n.Content[i+1]
+			if val.Kind == yaml.ScalarNode && val.Value != "" &&
+				(strings.Contains(key, "password") || strings.Contains(key, "secret") || strings.Contains(key, "token")) {
+				val.Value, val.Tag, val.Style = "******", "!!str", 0
+			}
+		}
+	}
+	for _, c := range n.Content {
+		maskSecrets(c)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+// sampleConfig 带注释的示例配置，列出全部选项及默认值
+//
+//go:embed config.yml
+var sampleConfig []byte
+
+// runGenerateConfig 输出示例配置或 JSON Schema：generate-config [文件]、generate-config schema [文件]。
+// 未指定文件时输出到标准输出，文件已存在时不覆盖
+func runGenerateConfig(args []string) error {
+	data := sampleConfig
+	if len(args) > 0 && args[0] == "schema" {
+		var err error
+		if data, err = json.MarshalIndent(configSchema(), "", "  "); err != nil {
+			return err
+		}
+		data = append(data, '\n')
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	f, err := os.OpenFile(args[0], os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if os.IsExist(err) {
+			return i18n.Errorf("%s 已存在，不覆盖", args[0])
+		}
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// configSchema 根据配置结构体的 yaml 标签生成 JSON Schema，供编辑器校验和补全
+func configSchema() map[string]interface{} {
+	defs := map[string]interface{}{}
+	schema := schemaFor(reflect.TypeOf(appConfig{}), defs)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "cleanlogservice config"
+	schema["$defs"] = defs
+	// profiles 下是按名称覆盖的部分配置，加载时合并，不对应结构体字段
+	schema["properties"].(map[string]interface{})["profiles"] = map[string]interface{}{
+		"type": "object", "additionalProperties": map[string]interface{}{"type": "object"},
+	}
+	return schema
+}
+
+var (
+	durationType  = reflect.TypeOf(time.Duration(0))
+	byteSizeType  = reflect.TypeOf(cleaner.ByteSize(0))
+	dirConfigType = reflect.TypeOf(cleaner.DirConfig{})
+)
+
+func schemaFor(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	switch t {
+	case durationType:
+		return map[string]interface{}{"type": []string{"string", "integer"}, "description": "30s, 5m, 6h"}
+	case byteSizeType:
+		return map[string]interface{}{"type": []string{"string", "integer"}, "description": "500MB, 10GB"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaFor(t.Elem(), defs)
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		// 逗号分隔的字符串同样可以解码为列表
+		return map[string]interface{}{"anyOf": []interface{}{
+			map[string]interface{}{"type": "array", "items": schemaFor(t.Elem(), defs)},
+			map[string]interface{}{"type": "string"},
+		}}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem(), defs)}
+	case reflect.Struct:
+		if t.Name() == "" || t == reflect.TypeOf(appConfig{}) {
+			return structSchema(t, defs)
+		}
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = map[string]interface{}{} // 先占位，避免递归类型无限展开
+			s := structSchema(t, defs)
+			if t == dirConfigType {
+				// 目录项也可以只写路径
+				s = map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "string"}, s}}
+			}
+			defs[t.Name()] = s
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema 展开结构体的字段，inline 的嵌入结构体合并到同一层，inline 的 map 表示允许任意附加参数
+func structSchema(t reflect.Type, defs map[string]interface{}) map[string]interface{} {
+	props := map[string]interface{}{}
+	s := map[string]interface{}{"type": "object", "properties": props}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("yaml")
+		name, opts, _ := strings.Cut(tag, ",")
+		if tag == "-" || !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			if f.Type.Kind() == reflect.Map {
+				s["additionalProperties"] = true
+				continue
+			}
+			inner := structSchema(f.Type, defs)
+			for k, v := range inner["properties"].(map[string]interface{}) {
+				props[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		props[name] = schemaFor(f.Type, defs)
+	}
+	return s
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+// GroupConfig 策略组：一组目录及其单独的定时、通知和限制，供一个服务同时为主机上的多个应用清理日志。
+// 各组按自己的定时分别执行，任务结果和历史中标记所属的组；未配置的项使用全局配置
+type GroupConfig struct {
+	Name            string              `yaml:"name" mapstructure:"name"`
+	Time            string              `yaml:"time" mapstructure:"time"`   // 该组的定时表达式，为空时使用全局定时；组内目录仍可单独配置 time
+	Every           time.Duration       `yaml:"every" mapstructure:"every"` // 按固定间隔执行，设置后忽略 time
+	Directories     []cleaner.DirConfig `yaml:"directories" mapstructure:"directories"`
+	Notify          *NotifyConfig       `yaml:"notify" mapstructure:"notify"`                       // 该组任务的通知除全局 notify 外另外发送到这里
+	AlertOnFailures *int                `yaml:"alert_on_failures" mapstructure:"alert_on_failures"` // 覆盖全局 alert_on_failures
+	MaxBytesPerRun  cleaner.ByteSize    `yaml:"max_bytes_per_run" mapstructure:"max_bytes_per_run"` // 覆盖全局 max_bytes_per_run
+	MaxRunDuration  time.Duration       `yaml:"max_run_duration" mapstructure:"max_run_duration"`   // 覆盖全局 max_run_duration
+}
+
+// spec 返回该组的定时表达式，未配置时为空
+func (g GroupConfig) spec() string {
+	if g.Every > 0 {
+		return "@every " + g.Every.String()
+	}
+	return strings.TrimSpace(g.Time)
+}
+
+// group 返回名称为 name 的策略组，name 为空或不存在时返回 nil
+func (c appConfig) group(name string) *GroupConfig {
+	if name == "" {
+		return nil
+	}
+	for i := range c.Groups {
+		if c.Groups[i].Name == name {
+			return &c.Groups[i]
+		}
+	}
+	return nil
+}
+
+// flattenGroups 校验策略组，并把各组的目录加入 directories，目录的 Group 标记所属的组，
+// 之后的预设展开、路径展开和校验与其他目录相同
+func (c *appConfig) flattenGroups() error {
+	seen := map[string]bool{}
+	for i := range c.Groups {
+		g := &c.Groups[i]
+		g.Name = strings.TrimSpace(g.Name)
+		if g.Name == "" {
+			return i18n.Errorf("第 %d 个策略组缺少 name", i+1)
+		}
+		if seen[g.Name] {
+			return i18n.Errorf("策略组 %s 重复", g.Name)
+		}
+		seen[g.Name] = true
+		if spec := g.spec(); spec != "" {
+			if _, err := cronParser.Parse(spec); err != nil {
+				return i18n.Errorf("策略组 %s 的定时表达式 %q 无效: %s", g.Name, spec, err)
+			}
+		}
+		if len(g.Directories) == 0 {
+			return i18n.Errorf("策略组 %s 没有配置 directories", g.Name)
+		}
+		for _, d := range g.Directories {
+			d.Group = g.Name
+			c.Directories = append(c.Directories, d)
+		}
+		g.Directories = nil
+	}
+	return nil
+}
+
+// groupStatus /status 中一个策略组的状态
+type groupStatus struct {
+	Name        string     `json:"name"`
+	Directories int        `json:"directories"`
+	Next        *time.Time `json:"next,omitempty"`
+	Degraded    bool       `json:"degraded"`
+	LastRun     *runResult `json:"last_run,omitempty"`
+}
+
+// groupStatuses 返回各策略组的下次执行时间和最近一次任务，调用方需持有 p.mu
+func (p *program) groupStatuses() []groupStatus {
+	var groups []groupStatus
+	now := time.Now()
+	for _, g := range p.config.Groups {
+		st := groupStatus{Name: g.Name}
+		for _, d := range p.config.Directories {
+			if d.Group == g.Name {
+				st.Directories++
+			}
+		}
+		for _, j := range p.jobs {
+			if j.group != g.Name || j.schedule == nil {
+				continue
+			}
+			if next := j.schedule.Next(now); st.Next == nil || next.Before(*st.Next) {
+				st.Next = &next
+			}
+		}
+		for i := len(p.history) - 1; i >= 0; i-- {
+			if p.history[i].Group == g.Name {
+				st.LastRun = p.history[i]
+				st.Degraded = p.overThreshold(st.LastRun)
+				break
+			}
+		}
+		groups = append(groups, st)
+	}
+	return groups
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/controlpb"
+	"cleanlogservice/pkg/i18n"
+)
+
+// GRPCConfig gRPC 控制接口配置，服务定义见 pkg/controlpb/control.proto（cleanlog.control.v1.Cleaner），
+// 方法与 JSON-RPC 接口相同。配置令牌后每个请求需带 metadata authorization: Bearer <token>
+type GRPCConfig struct {
+	Listen     string `yaml:"listen" mapstructure:"listen"` // 如 127.0.0.1:8091，或 unix:/run/cleanlog-grpc.sock 使用本地套接字
+	AuthConfig `yaml:",inline" mapstructure:",squash"`
+}
+
+// grpcControl 实现 controlpb.CleanerServer
+type grpcControl struct {
+	controlpb.UnimplementedCleanerServer
+	p *program
+}
+
+func (c *grpcControl) TriggerRun(ctx context.Context, req *controlpb.TriggerRunRequest) (*controlpb.TriggerRunResponse, error) {
+	run, ok := c.p.begin("")
+	if !ok {
+		return &controlpb.TriggerRunResponse{}, nil
+	}
+	if !req.Wait {
+		go run()
+		return &controlpb.TriggerRunResponse{Started: true}, nil
+	}
+	return &controlpb.TriggerRunResponse{Started: true, Result: runResultPB(run())}, nil
+}
+
+func (c *grpcControl) GetStatus(ctx context.Context, req *controlpb.GetStatusRequest) (*controlpb.GetStatusResponse, error) {
+	h, healthy := c.p.health()
+	return &controlpb.GetStatusResponse{
+		Healthy: healthy, Status: h.Status, Scheduler: h.Scheduler, LastRun: timestampPB(h.LastRun), NextRun: timestampPB(h.NextRun),
+		Running: h.Running, Paused: h.Paused, Degraded: h.Degraded, Health: h.Health, Version: h.Version,
+	}, nil
+}
+
+func (c *grpcControl) ReloadConfig(ctx context.Context, req *controlpb.ReloadConfigRequest) (*controlpb.ReloadConfigResponse, error) {
+	config, err := c.p.reload()
+	if err != nil {
+		return nil, grpcstatus.Error(codes.InvalidArgument, err.Error())
+	}
+	return &controlpb.ReloadConfigResponse{Directories: int32(len(config.Directories))}, nil
+}
+
+func (c *grpcControl) ListRecentRuns(ctx context.Context, req *controlpb.ListRecentRunsRequest) (*controlpb.ListRecentRunsResponse, error) {
+	c.p.mu.Lock()
+	runs := latest(c.p.history, int(req.Limit))
+	c.p.mu.Unlock()
+	resp := &controlpb.ListRecentRunsResponse{}
+	for _, r := range runs {
+		resp.Runs = append(resp.Runs, runResultPB(r))
+	}
+	return resp, nil
+}
+
+func (c *grpcControl) Pause(ctx context.Context, req *controlpb.PauseRequest) (*controlpb.PauseResponse, error) {
+	c.p.setPaused(true)
+	return &controlpb.PauseResponse{Paused: true}, nil
+}
+
+func (c *grpcControl) Resume(ctx context.Context, req *controlpb.ResumeRequest) (*controlpb.PauseResponse, error) {
+	c.p.setPaused(false)
+	return &controlpb.PauseResponse{}, nil
+}
+
+func (c *grpcControl) Hold(ctx context.Context, req *controlpb.HoldRequest) (*controlpb.HoldResponse, error) {
+	if err := c.p.setHold(req.Path, req.Reason, true); err != nil {
+		return nil, grpcstatus.Error(codes.InvalidArgument, err.Error())
+	}
+	return c.holds(), nil
+}
+
+func (c *grpcControl) Release(ctx context.Context, req *controlpb.ReleaseRequest) (*controlpb.HoldResponse, error) {
+	if err := c.p.setHold(req.Path, "", false); err != nil {
+		return nil, grpcstatus.Error(codes.InvalidArgument, err.Error())
+	}
+	return c.holds(), nil
+}
+
+func (c *grpcControl) holds() *controlpb.HoldResponse {
+	resp := &controlpb.HoldResponse{}
+	for _, h := range c.p.holdList() {
+		resp.Holds = append(resp.Holds, &controlpb.HoldEntry{Path: h.Path, Reason: h.Reason, Since: timestamppb.New(h.Since)})
+	}
+	return resp
+}
+
+func (c *grpcControl) Confirm(ctx context.Context, req *controlpb.ConfirmRequest) (*controlpb.ConfirmResponse, error) {
+	if err := c.p.confirmCanary(req.Path); err != nil {
+		return nil, grpcstatus.Error(codes.InvalidArgument, err.Error())
+	}
+	resp := &controlpb.ConfirmResponse{}
+	for _, e := range c.p.pendingCanaries() {
+		resp.Canaries = append(resp.Canaries, &controlpb.CanaryEntry{Path: e.Path, Added: timestamppb.New(e.Added), Runs: int32(e.Runs)})
+	}
+	return resp, nil
+}
+
+func timestampPB(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// runResultPB 转换任务结果，完整的统计以 JSON 放在 details_json 中
+func runResultPB(r *runResult) *controlpb.RunResult {
+	if r == nil {
+		return nil
+	}
+	pb := &controlpb.RunResult{
+		Id: r.ID, Group: r.Group, Start: timestamppb.New(r.Start), DurationSeconds: r.Duration, Error: r.Error,
+		Totals: runStatsPB(r.Report.Stats),
+	}
+	for _, d := range r.Report.Directories {
+		pb.Directories = append(pb.Directories, &controlpb.DirectoryResult{
+			Path: d.Path, Stats: runStatsPB(d.Stats), DurationSeconds: d.Duration, OnHold: d.OnHold, Canary: d.Canary, Aborted: d.Aborted, TimedOut: d.TimedOut,
+		})
+	}
+	if data, err := json.Marshal(r); err == nil {
+		pb.DetailsJson = string(data)
+	}
+	return pb
+}
+
+func runStatsPB(s cleaner.Stats) *controlpb.RunStats {
+	pb := &controlpb.RunStats{
+		Scanned: int64(s.Scanned), Matched: int64(s.Matched), Deleted: int64(s.Deleted), Failed: int64(s.Failed), Skipped: int64(s.Skipped()),
+		Compressed: int64(s.Compressed), Archived: int64(s.Archived), Truncated: int64(s.Truncated), FreedBytes: s.FreedBytes,
+	}
+	if len(s.Errors) > 0 {
+		pb.Errors = map[string]int64{}
+		for k, v := range s.Errors {
+			pb.Errors[k] = int64(v)
+		}
+	}
+	return pb
+}
+
+// grpcToken 配置了令牌时检查每个请求 metadata 中的 authorization: Bearer <token>
+func grpcToken(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, v := range md.Get("authorization") {
+			if got, ok := strings.CutPrefix(v, "Bearer "); ok && validToken(got, token) {
+				return handler(ctx, req)
+			}
+		}
+		return nil, grpcstatus.Error(codes.Unauthenticated, i18n.T("令牌无效"))
+	}
+}
+
+// startGRPC 启动 gRPC 控制接口，监听失败只记录日志，不影响清理任务
+func (p *program) startGRPC() {
+	cfg := p.config.GRPC
+	if cfg == nil || cfg.Listen == "" {
+		return
+	}
+	token, tlsConfig, err := cfg.setup(cfg.Listen)
+	if err != nil {
+		p.logger.Printf(i18n.T("gRPC 接口启动失败: %s"), err)
+		return
+	}
+	ln, err := listenControl(cfg.Listen)
+	if err != nil {
+		p.logger.Printf(i18n.T("gRPC 接口启动失败: %s"), err)
+		return
+	}
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	if token != "" {
+		opts = append(opts, grpc.UnaryInterceptor(grpcToken(token)))
+	}
+	p.grpc = grpc.NewServer(opts...)
+	controlpb.RegisterCleanerServer(p.grpc, &grpcControl{p: p})
+	p.logger.Printf(i18n.T("gRPC 接口监听 %s"), cfg.Listen)
+	go func() {
+		if err := p.grpc.Serve(ln); err != nil {
+			p.logger.Printf(i18n.T("gRPC 接口启动失败: %s"), err)
+		}
+	}()
+}
+
+func (p *program) stopGRPC() {
+	if p.grpc != nil {
+		p.grpc.Stop()
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import "cleanlogservice/pkg/i18n"
+
+// 服务的健康状态，由最近的任务结果得出，供 /status、/healthz、指标和 Windows 服务描述使用
+const (
+	healthHealthy  = "healthy"  // 最近一次任务顺利完成
+	healthDegraded = "degraded" // 最近一次任务出错或有文件处理失败，但仍在清理
+	healthFailing  = "failing"  // 连续 failing_after 次任务出错或一个文件也没处理成功，或定时任务没有按时执行
+)
+
+const defaultFailingAfter = 3
+
+// runFailed 任务出错（包括超时和达到 max_bytes_per_run），或有文件失败而一个文件也没有处理成功
+func runFailed(r *runResult) bool {
+	s := r.Report.Stats
+	return r.Error != "" || (s.Failed > 0 && s.Deleted+s.Compressed+s.Archived+s.Truncated+s.DeletedDirs == 0)
+}
+
+// runPartial 任务有文件处理失败：配置了 alert_on_failures 时失败数超过该值，否则有任何失败。调用方需持有 p.mu
+func (p *program) runPartial(r *runResult) bool {
+	if p.failureLimit(r) != nil {
+		return p.overThreshold(r)
+	}
+	return r.Report.Failed > 0
+}
+
+// runHealth 按最近的任务结果判断健康状态，还没有任务结果时为 healthy。调用方需持有 p.mu
+func (p *program) runHealth() string {
+	if len(p.history) == 0 {
+		return healthHealthy
+	}
+	limit := p.config.FailingAfter
+	if limit <= 0 {
+		limit = defaultFailingAfter
+	}
+	failed := 0
+	for i := len(p.history) - 1; i >= 0 && runFailed(p.history[i]); i-- {
+		failed++
+	}
+	last := p.history[len(p.history)-1]
+	switch {
+	case failed >= limit:
+		return healthFailing
+	case failed > 0 || p.runPartial(last):
+		return healthDegraded
+	}
+	return healthHealthy
+}
+
+// healthValue 健康状态对应的指标值：0 healthy、1 degraded、2 failing
+func healthValue(state string) int {
+	switch state {
+	case healthDegraded:
+		return 1
+	case healthFailing:
+		return 2
+	}
+	return 0
+}
+
+// updateHealth 任务结束后把健康状态写入服务描述，状态变化时记录日志
+func (p *program) updateHealth(prev string) {
+	h, _ := p.health()
+	if h.Health != prev {
+		p.logger.Printf(i18n.T("服务健康状态: %s -> %s"), prev, h.Health)
+	}
+	p.describeHealth(h.Health)
+}
@@ -0,0 +1,6 @@
+//go:build !windows
+
+package main
+
+// describeHealth 只有 Windows 的服务管理器显示服务描述，其他系统上通过 /status、/healthz 和指标查看健康状态
+func (p *program) describeHealth(state string) {}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"unsafe"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/kardianos/service"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// healthSeparator 服务描述中原有描述与健康状态之间的分隔
+const healthSeparator = " | "
+
+// describeHealth 在服务管理器（services.msc）的描述末尾附加健康状态，使部分失败在服务列表中也能看到；
+// state 为空时恢复原来的描述。只在作为服务运行时修改，失败只记录日志
+func (p *program) describeHealth(state string) {
+	if service.Interactive() || p.container {
+		return
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(p.name)
+	if err != nil {
+		return
+	}
+	defer s.Close()
+	cfg, err := s.Config()
+	if err != nil {
+		return
+	}
+	base, _, _ := strings.Cut(cfg.Description, healthSeparator)
+	desc := base
+	if state != "" {
+		desc += healthSeparator + i18n.Sprintf("健康状态: %s", state)
+	}
+	if desc == cfg.Description {
+		return
+	}
+	ptr, err := windows.UTF16PtrFromString(desc)
+	if err != nil {
+		return
+	}
+	d := windows.SERVICE_DESCRIPTION{Description: ptr}
+	if err := windows.ChangeServiceConfig2(s.Handle, windows.SERVICE_CONFIG_DESCRIPTION, (*byte)(unsafe.Pointer(&d))); err != nil {
+		p.logger.Printf(i18n.T("更新服务描述失败: %s"), err)
+	}
+}
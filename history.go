@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+const (
+	defaultHistoryRetention = 30 * 24 * time.Hour
+	historyLockTimeout      = 5 * time.Second // 等待其他进程（服务或 history 命令）释放数据库的时间
+)
+
+// historyBucket 保存任务记录的 bucket，键为开始时间（纳秒，大端序）加任务 ID，按时间排序
+var historyBucket = []byte("runs")
+
+// HistoryConfig 任务历史。每次任务的结果（时间、耗时、各目录统计和错误）保存到内嵌的 bbolt 数据库，
+// 服务重启后 /status、/history 和 history 命令仍可查看
+type HistoryConfig struct {
+	Enabled   bool          `yaml:"enabled" mapstructure:"enabled"`
+	Path      string        `yaml:"path" mapstructure:"path"`           // 默认为服务日志默认目录下的 history.db，多实例时附加服务名称
+	Retention time.Duration `yaml:"retention" mapstructure:"retention"` // 保留多久的记录，默认 720h（30 天）
+}
+
+// historyPath 返回历史数据库的路径，未配置时按服务名称生成默认值
+func historyPath(cfg *HistoryConfig, name string) string {
+	if cfg.Path != "" {
+		return cleaner.ExpandPath(cfg.Path)
+	}
+	base := "history"
+	if name != defaultServiceName {
+		base += "-" + name
+	}
+	return filepath.Join(defaultLogDir(), base+".db")
+}
+
+// openHistory 打开历史数据库。bbolt 打开期间独占文件，服务只在读写时短暂打开，history 命令可以同时读取
+func openHistory(path string, readOnly bool) (*bolt.DB, error) {
+	if !readOnly {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return bolt.Open(path, 0o644, &bolt.Options{Timeout: historyLockTimeout, ReadOnly: readOnly})
+}
+
+func historyKey(r *runResult) []byte {
+	key := make([]byte, 8, 8+len(r.ID))
+	binary.BigEndian.PutUint64(key, uint64(r.Start.UnixNano()))
+	return append(key, r.ID...)
+}
+
+func historySince(retention time.Duration) time.Time {
+	if retention <= 0 {
+		retention = defaultHistoryRetention
+	}
+	return time.Now().Add(-retention)
+}
+
+// readHistory 读取保留期内的记录，按时间从旧到新排列；数据库不存在时返回空列表
+func readHistory(path string, retention time.Duration) ([]*runResult, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	db, err := openHistory(path, true)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	since := historySince(retention)
+	var runs []*runResult
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyBucket)
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		from := make([]byte, 8)
+		binary.BigEndian.PutUint64(from, uint64(since.UnixNano()))
+		for k, v := c.Seek(from); k != nil; k, v = c.Next() {
+			var r runResult
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			runs = append(runs, &r)
+		}
+		return nil
+	})
+	return runs, err
+}
+
+// loadHistory 启动时读取历史记录，恢复最近的任务结果
+func (p *program) loadHistory() {
+	cfg := p.config.History
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+	runs, err := readHistory(historyPath(cfg, p.name), cfg.Retention)
+	if err != nil {
+		p.logger.Printf(i18n.T("读取任务历史失败: %s"), err)
+		return
+	}
+	if len(runs) > maxHistory {
+		runs = runs[len(runs)-maxHistory:]
+	}
+	p.history = runs
+}
+
+// saveHistory 保存一次任务的结果，同一事务中删除超过保留期的记录
+func (p *program) saveHistory(result *runResult) {
+	cfg := p.config.History
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+	if err := appendHistory(historyPath(cfg, p.name), result, cfg.Retention); err != nil {
+		p.logger.Printf(i18n.T("保存任务历史失败: %s"), err)
+	}
+}
+
+func appendHistory(path string, result *runResult, retention time.Duration) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	db, err := openHistory(path, false)
+	if err != nil {
+		return err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(historyBucket)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(historyKey(result), data); err != nil {
+			return err
+		}
+		since := uint64(historySince(retention).UnixNano())
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && binary.BigEndian.Uint64(k) < since; k, _ = c.Next() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if cerr := db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// handleHistory 返回保留期内的任务记录，最新的在前，limit 参数限制条数
+func (p *program) handleHistory(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	cfg := p.config.History
+	p.mu.Unlock()
+	if cfg == nil || !cfg.Enabled {
+		http.Error(w, i18n.T("未启用任务历史"), http.StatusNotFound)
+		return
+	}
+	runs, err := readHistory(historyPath(cfg, p.name), cfg.Retention)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(latest(runs, limit))
+}
+
+// latest 返回最新的 limit 条记录（limit 为 0 时全部），最新的在前
+func latest(runs []*runResult, limit int) []*runResult {
+	out := []*runResult{}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+		out = append(out, runs[i])
+	}
+	return out
+}
+
+// runHistory 实现 history 子命令：直接读取历史文件，按表格输出最近的任务
+func runHistory(configFilePath, name string, args []string) error {
+	limit := 0
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			return i18n.Errorf("用法: history [条数]")
+		}
+		limit = n
+	}
+	cfg := &HistoryConfig{}
+	if err := readConfigKey(configFilePath, "history", cfg); err != nil {
+		return err
+	}
+	runs, err := readHistory(historyPath(cfg, name), cfg.Retention)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, i18n.T("任务 ID\t开始时间\t耗时\t扫描\t删除\t失败\t跳过\t释放\t错误"))
+	for _, r := range latest(runs, limit) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			r.ID, displayTime(r.Start), time.Duration(r.Duration*float64(time.Second)).Round(time.Millisecond),
+			r.Report.Scanned, r.Report.Deleted, r.Report.Failed, r.Report.Skipped(), cleaner.ByteSize(r.Report.FreedBytes), r.Error)
+	}
+	return tw.Flush()
+}
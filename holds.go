@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"cleanlogservice/pkg/state"
+	"github.com/spf13/afero"
+)
+
+// holdEntry 通过管理接口设置的目录保留（legal hold），保存到状态文件，服务重启后仍然有效
+type holdEntry struct {
+	Path   string    `json:"path"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since"`
+}
+
+// holdsPath 返回保留状态文件的路径，多实例时附加服务名称
+func holdsPath(name string) string {
+	base := "holds"
+	if name != defaultServiceName {
+		base += "-" + name
+	}
+	return filepath.Join(defaultLogDir(), base+".json")
+}
+
+// holdKey 比较目录时使用的形式，Windows 上不区分大小写
+func holdKey(path string) string {
+	path = filepath.Clean(path)
+	if runtime.GOOS == "windows" {
+		path = strings.ToLower(path)
+	}
+	return path
+}
+
+// stateFs 服务的状态文件（保留状态、canary、磁盘空间记录、任务历史）所在的文件系统
+var stateFs = afero.NewOsFs()
+
+// loadHolds 启动时读取保留状态
+func (p *program) loadHolds() {
+	var entries []holdEntry
+	if err := state.Read(stateFs, holdsPath(p.name), &entries); err != nil {
+		if !os.IsNotExist(err) {
+			p.logger.Printf(i18n.T("读取目录保留状态失败: %s"), err)
+		}
+		return
+	}
+	p.holdMu.Lock()
+	defer p.holdMu.Unlock()
+	p.holds = map[string]holdEntry{}
+	for _, e := range entries {
+		p.holds[holdKey(e.Path)] = e
+		p.logger.Printf(i18n.T("目录 %s 处于保留状态（自 %s）"), e.Path, displayTime(e.Since))
+	}
+}
+
+// isHeld 供清理任务查询目录是否处于保留状态
+func (p *program) isHeld(path string) bool {
+	p.holdMu.Lock()
+	defer p.holdMu.Unlock()
+	_, ok := p.holds[holdKey(path)]
+	return ok
+}
+
+// holdList 返回按路径排序的保留目录
+func (p *program) holdList() []holdEntry {
+	p.holdMu.Lock()
+	defer p.holdMu.Unlock()
+	entries := make([]holdEntry, 0, len(p.holds))
+	for _, e := range p.holds {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries
+}
+
+// setHold 设置或解除目录的保留状态并保存，正在执行的任务中尚未处理的目录立即生效
+func (p *program) setHold(path, reason string, hold bool) error {
+	if !filepath.IsAbs(path) {
+		return i18n.Errorf("目录 %q 应为绝对路径", path)
+	}
+	path = filepath.Clean(path)
+	p.holdMu.Lock()
+	if p.holds == nil {
+		p.holds = map[string]holdEntry{}
+	}
+	_, existed := p.holds[holdKey(path)]
+	if hold {
+		p.holds[holdKey(path)] = holdEntry{Path: path, Reason: reason, Since: time.Now()}
+	} else {
+		delete(p.holds, holdKey(path))
+	}
+	p.holdMu.Unlock()
+	if !hold && !existed {
+		return i18n.Errorf("目录 %s 未处于保留状态", path)
+	}
+	if err := p.saveHolds(); err != nil {
+		return err
+	}
+	if hold {
+		p.logger.Printf(i18n.T("目录 %s 已设置保留，暂停处理其中的文件: %s"), path, reason)
+	} else {
+		p.logger.Printf(i18n.T("目录 %s 已解除保留"), path)
+	}
+	return nil
+}
+
+func (p *program) saveHolds() error {
+	return state.Write(stateFs, holdsPath(p.name), p.holdList())
+}
+
+// handleHold GET 列出保留的目录，POST 设置保留（参数 path、reason），DELETE 解除保留（参数 path）
+func (p *program) handleHold(w http.ResponseWriter, r *http.Request) {
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		err = p.setHold(r.FormValue("path"), r.FormValue("reason"), true)
+	case http.MethodDelete:
+		err = p.setHold(r.FormValue("path"), "", false)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p.holdList())
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+	"gopkg.in/yaml.v3"
+)
+
+// initListed 预览时每个目录最多列出的到期文件数
+const initListed = 10
+
+// initDir init 生成的目录项，只包含向导中询问的配置
+type initDir struct {
+	Path       string   `yaml:"path"`
+	Extensions []string `yaml:"extensions,omitempty"`
+}
+
+// initConfig init 生成的配置文件内容
+type initConfig struct {
+	Time        string        `yaml:"time"`
+	Days        int           `yaml:"days,omitempty"`
+	MaxAge      time.Duration `yaml:"max_age,omitempty"`
+	Directories []initDir     `yaml:"directories"`
+}
+
+// clockTime 每天执行的时刻，如 5:00、23:30
+var clockTime = regexp.MustCompile(`^([01]?\d|2[0-3]):([0-5]\d)$`)
+
+// prompter 从标准输入读取回答，输入结束（Ctrl+D、管道关闭）时返回 io.EOF
+type prompter struct {
+	r *bufio.Reader
+}
+
+// ask 显示问题和默认值，返回去掉首尾空白的回答，直接回车时返回默认值
+func (p prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	line, err := p.r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Println()
+		return "", io.EOF
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		return line, nil
+	}
+	return def, nil
+}
+
+// confirm 询问是否继续，def 为直接回车时的选择
+func (p prompter) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	answer, err := p.ask(question+" ("+hint+")", "")
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// runInit 首次使用的配置向导：依次询问清理目录、保留期限和执行时间，校验后按当前文件预览将被删除的文件，
+// 确认后写入配置文件，并可直接安装、启动服务。path 为空时写入程序目录下的 config.yml
+func runInit(path, name string) error {
+	if path == "" {
+		path = filepath.Join(configSearchPaths()[0], "config.yml")
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	p := prompter{r: bufio.NewReader(os.Stdin)}
+	err = initWizard(p, path, name)
+	if err == io.EOF {
+		return i18n.Errorf("输入已结束，未写入配置")
+	}
+	return err
+}
+
+func initWizard(p prompter, path, name string) error {
+	fmt.Println(i18n.T("配置向导：回答以下问题生成配置文件，方括号中为默认值，直接回车使用默认值"))
+	if _, err := os.Stat(path); err == nil {
+		ok, err := p.confirm(i18n.Sprintf("%s 已存在，是否覆盖？", path), false)
+		if err != nil || !ok {
+			fmt.Println(i18n.T("已取消，未修改配置文件"))
+			return err
+		}
+	}
+	var cfg initConfig
+	for {
+		// 目录
+		cfg.Directories = nil
+		fmt.Println(i18n.T("依次输入要清理的目录（可以使用通配符，如 D:\\apps\\*\\logs），输入空行结束："))
+		for {
+			dir, err := p.ask(i18n.Sprintf("目录 %d", len(cfg.Directories)+1), "")
+			if err != nil {
+				return err
+			}
+			if dir == "" {
+				if len(cfg.Directories) > 0 {
+					break
+				}
+				fmt.Println(i18n.T("至少需要一个目录"))
+				continue
+			}
+			if abs, err := filepath.Abs(cleaner.ExpandPath(dir)); err == nil {
+				dir = abs
+			}
+			if !strings.ContainsAny(dir, "*?[") {
+				if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+					ok, err := p.confirm(i18n.Sprintf("%s 不存在或不是目录，仍然加入？", dir), false)
+					if err != nil {
+						return err
+					}
+					if !ok {
+						continue
+					}
+				}
+			}
+			exts, err := p.ask(i18n.T("  只清理这些扩展名（逗号分隔，如 .log,.txt；留空为全部文件）"), "")
+			if err != nil {
+				return err
+			}
+			d := initDir{Path: dir}
+			for _, ext := range strings.Split(exts, ",") {
+				if ext = strings.TrimSpace(ext); ext != "" {
+					d.Extensions = append(d.Extensions, ext)
+				}
+			}
+			cfg.Directories = append(cfg.Directories, d)
+		}
+
+		// 保留期限
+		for {
+			answer, err := p.ask(i18n.T("保留期限：天数（如 7）或时长（如 36h）"), "3")
+			if err != nil {
+				return err
+			}
+			cfg.Days, cfg.MaxAge = 0, 0
+			if n, err := strconv.Atoi(answer); err == nil && n > 0 {
+				cfg.Days = n
+				break
+			}
+			if d, err := time.ParseDuration(answer); err == nil && d > 0 {
+				cfg.MaxAge = d
+				break
+			}
+			fmt.Println(i18n.Sprintf("%q 不是有效的天数或时长", answer))
+		}
+
+		// 执行时间
+		for {
+			answer, err := p.ask(i18n.T("每天执行的时间（如 05:00），也可以输入 cron 表达式"), "05:00")
+			if err != nil {
+				return err
+			}
+			spec := answer
+			if m := clockTime.FindStringSubmatch(answer); m != nil {
+				hour, _ := strconv.Atoi(m[1])
+				minute, _ := strconv.Atoi(m[2])
+				spec = fmt.Sprintf("0 %d %d * * *", minute, hour)
+			}
+			if _, err := cronParser.Parse(spec); err != nil {
+				fmt.Println(i18n.Sprintf("定时表达式 %q 无效: %s", spec, err))
+				continue
+			}
+			cfg.Time = spec
+			break
+		}
+
+		if err := previewInit(cfg); err != nil {
+			fmt.Println(i18n.Sprintf("配置无效: %s", err))
+			ok, err := p.confirm(i18n.T("重新填写？"), true)
+			if err != nil || !ok {
+				fmt.Println(i18n.T("已取消，未修改配置文件"))
+				return err
+			}
+			continue
+		}
+		ok, err := p.confirm(i18n.Sprintf("写入 %s？", path), true)
+		if err != nil {
+			return err
+		}
+		if ok {
+			break
+		}
+		if ok, err := p.confirm(i18n.T("重新填写？"), true); err != nil || !ok {
+			fmt.Println(i18n.T("已取消，未修改配置文件"))
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(i18n.T("# 由 cleanlogservice init 生成；全部选项及说明见 cleanlogservice generate-config") + "\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	// 按服务启动时的方式完整加载一次，确认生成的文件可以使用
+	check := &program{logger: log.New(io.Discard, "", 0), name: name}
+	if _, err := check.loadConfig(path); err != nil {
+		return i18n.Errorf("已写入 %s，但加载失败: %s", path, err)
+	}
+	fmt.Printf(i18n.T("配置已写入 %s")+"\n", path)
+
+	// 配置已写入，之后输入结束按不安装处理
+	if ok, _ := p.confirm(i18n.T("现在安装并启动服务？"), false); !ok {
+		fmt.Println(i18n.Sprintf("之后可以执行 %s 安装服务", installCommand(path, name)))
+		return nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	for _, action := range []string{"install", "start"} {
+		cmd := exec.Command(exe, append(serviceArgs(path, name), action)...)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return i18n.Errorf("执行 %s 失败: %s", action, err)
+		}
+	}
+	fmt.Println(i18n.T("服务已安装并启动"))
+	return nil
+}
+
+// previewInit 按向导的回答构建配置并校验，列出各目录中当前已到期、服务第一次执行时将被删除的文件
+func previewInit(cfg initConfig) error {
+	config := cleaner.Config{Days: cfg.Days, MaxAge: cfg.MaxAge}
+	for _, d := range cfg.Directories {
+		config.Directories = append(config.Directories, cleaner.DirConfig{Path: d.Path, Extensions: d.Extensions})
+	}
+	cleaner.LabelDirectories(config.Directories, "directories")
+	config.ExpandPaths()
+	if err := config.Check(false); err != nil {
+		return err
+	}
+	results, err := cleaner.New(config).Scan(context.Background(), time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Println(i18n.T("按以上配置，当前已到期、第一次执行时将被删除的文件："))
+	var files int
+	var size int64
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Printf(i18n.T("目录 %s: %s")+"\n", r.Path, r.Error)
+			continue
+		}
+		fmt.Printf(i18n.T("目录 %s: 扫描 %d，到期 %d，共 %s")+"\n", r.Path, r.Scanned, len(r.Files), cleaner.ByteSize(r.Bytes))
+		for i, f := range r.Files {
+			if i == initListed {
+				fmt.Printf(i18n.T("  ……另有 %d 个")+"\n", len(r.Files)-initListed)
+				break
+			}
+			fmt.Printf("  %s  %s  %s\n", f.Path, cleaner.ByteSize(f.Size), displayTime(f.ModTime))
+		}
+		files += len(r.Files)
+		size += r.Bytes
+	}
+	fmt.Printf(i18n.T("合计到期 %d 项，共 %s")+"\n", files, cleaner.ByteSize(size))
+	return nil
+}
+
+// serviceArgs 安装服务时的参数，非默认名称时附带 --name
+func serviceArgs(path, name string) []string {
+	args := []string{"--config", path}
+	if name != defaultServiceName {
+		args = append(args, "--name", name)
+	}
+	return args
+}
+
+func installCommand(path, name string) string {
+	args := append([]string{"cleanlogservice"}, serviceArgs(path, name)...)
+	for i, a := range args {
+		if strings.ContainsAny(a, " \t") {
+			args[i] = strconv.Quote(a)
+		}
+	}
+	return strings.Join(append(args, "install"), " ")
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sampleLoad 读取 /proc 中的平均负载、CPU 使用率（间隔 1 秒的两次 /proc/stat 之差）和磁盘正在处理的 I/O 数
+func sampleLoad() (hostLoad, error) {
+	l := hostLoad{load: -1, cpu: -1, diskQueue: -1}
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return l, err
+	}
+	if fields := strings.Fields(string(data)); len(fields) > 0 {
+		l.load, _ = strconv.ParseFloat(fields[0], 64)
+	}
+	if busy1, total1, err := cpuTimes(); err == nil {
+		time.Sleep(time.Second)
+		if busy2, total2, err := cpuTimes(); err == nil && total2 > total1 {
+			l.cpu = float64(busy2-busy1) / float64(total2-total1) * 100
+		}
+	}
+	if q, err := diskInFlight(); err == nil {
+		l.diskQueue = q
+	}
+	return l, nil
+}
+
+// cpuTimes 返回 /proc/stat 第一行的忙碌时间和总时间（idle 和 iowait 不算忙碌）
+func cpuTimes() (busy, total uint64, err error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	s.Scan()
+	fields := strings.Fields(s.Text())
+	for i, v := range fields[1:] {
+		n, _ := strconv.ParseUint(v, 10, 64)
+		total += n
+		if i != 3 && i != 4 {
+			busy += n
+		}
+	}
+	return busy, total, s.Err()
+}
+
+// diskInFlight 返回 /proc/diskstats 中正在处理的 I/O 数最多的磁盘的值，忽略 loop、ram 等虚拟设备
+func diskInFlight() (float64, error) {
+	data, err := os.ReadFile("/proc/diskstats")
+	if err != nil {
+		return 0, err
+	}
+	var max float64
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 12 {
+			continue
+		}
+		if name := fields[2]; strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") || strings.HasPrefix(name, "zram") {
+			continue
+		}
+		if n, err := strconv.ParseFloat(fields[11], 64); err == nil && n > max {
+			max = n
+		}
+	}
+	return max, nil
+}
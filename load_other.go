@@ -0,0 +1,27 @@
+//go:build !windows && !linux
+
+package main
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/sys/unix"
+)
+
+// sampleLoad 只提供平均负载（sysctl vm.loadavg），CPU 使用率和磁盘队列不检查
+func sampleLoad() (hostLoad, error) {
+	l := hostLoad{load: -1, cpu: -1, diskQueue: -1}
+	// struct loadavg { fixpt_t ldavg[3]; long fscale; }，64 位系统上 fscale 按 8 字节对齐，位于偏移 16
+	raw, err := unix.SysctlRaw("vm.loadavg")
+	if err != nil {
+		return l, err
+	}
+	if len(raw) >= 24 {
+		ld := binary.LittleEndian.Uint32(raw[0:4])
+		scale := binary.LittleEndian.Uint64(raw[16:24])
+		if scale > 0 {
+			l.load = float64(ld) / float64(scale)
+		}
+	}
+	return l, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modkernel32 = windows.NewLazySystemDLL("kernel32.dll")
+	modpdh      = windows.NewLazySystemDLL("pdh.dll")
+
+	procGetSystemTimes              = modkernel32.NewProc("GetSystemTimes")
+	procPdhOpenQueryW               = modpdh.NewProc("PdhOpenQueryW")
+	procPdhAddEnglishCounterW       = modpdh.NewProc("PdhAddEnglishCounterW")
+	procPdhCollectQueryData         = modpdh.NewProc("PdhCollectQueryData")
+	procPdhGetFormattedCounterValue = modpdh.NewProc("PdhGetFormattedCounterValue")
+	procPdhCloseQuery               = modpdh.NewProc("PdhCloseQuery")
+)
+
+const pdhFmtDouble = 0x00000200
+
+// pdhFmtCounterValue PDH_FMT_COUNTERVALUE，取 double 值
+type pdhFmtCounterValue struct {
+	CStatus uint32
+	_       uint32
+	Double  float64
+}
+
+func filetime(ft windows.Filetime) uint64 {
+	return uint64(ft.HighDateTime)<<32 | uint64(ft.LowDateTime)
+}
+
+// systemTimes 返回 GetSystemTimes 的忙碌时间和总时间，内核时间中包含空闲时间
+func systemTimes() (busy, total uint64, err error) {
+	var idle, kernel, user windows.Filetime
+	r, _, err := procGetSystemTimes.Call(uintptr(unsafe.Pointer(&idle)), uintptr(unsafe.Pointer(&kernel)), uintptr(unsafe.Pointer(&user)))
+	if r == 0 {
+		return 0, 0, err
+	}
+	total = filetime(kernel) + filetime(user)
+	return total - filetime(idle), total, nil
+}
+
+// diskQueueLength 通过性能计数器读取所有物理磁盘的当前队列长度
+func diskQueueLength() (float64, error) {
+	var query, counter windows.Handle
+	if r, _, _ := procPdhOpenQueryW.Call(0, 0, uintptr(unsafe.Pointer(&query))); r != 0 {
+		return 0, windows.Errno(r)
+	}
+	defer procPdhCloseQuery.Call(uintptr(query))
+	path, _ := windows.UTF16PtrFromString(`\PhysicalDisk(_Total)\Current Disk Queue Length`)
+	if r, _, _ := procPdhAddEnglishCounterW.Call(uintptr(query), uintptr(unsafe.Pointer(path)), 0, uintptr(unsafe.Pointer(&counter))); r != 0 {
+		return 0, windows.Errno(r)
+	}
+	if r, _, _ := procPdhCollectQueryData.Call(uintptr(query)); r != 0 {
+		return 0, windows.Errno(r)
+	}
+	var v pdhFmtCounterValue
+	if r, _, _ := procPdhGetFormattedCounterValue.Call(uintptr(counter), pdhFmtDouble, 0, uintptr(unsafe.Pointer(&v))); r != 0 {
+		return 0, windows.Errno(r)
+	}
+	return v.Double, nil
+}
+
+// sampleLoad 采样 CPU 使用率（间隔 1 秒的两次 GetSystemTimes 之差）和磁盘队列长度，Windows 没有平均负载
+func sampleLoad() (hostLoad, error) {
+	l := hostLoad{load: -1, cpu: -1, diskQueue: -1}
+	busy1, total1, err := systemTimes()
+	if err != nil {
+		return l, err
+	}
+	time.Sleep(time.Second)
+	if busy2, total2, err := systemTimes(); err == nil && total2 > total1 {
+		l.cpu = float64(busy2-busy1) / float64(total2-total1) * 100
+	}
+	if q, err := diskQueueLength(); err == nil {
+		l.diskQueue = q
+	}
+	return l, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+const (
+	defaultLoadRetryDelay = 5 * time.Minute
+	defaultLoadRetries    = 6
+)
+
+// LoadGuardConfig 定时任务开始前检查主机负载，超过阈值时推迟，避免清理和业务高峰争抢 CPU 和磁盘。
+// 未配置的阈值不检查，本系统上无法获取的指标忽略
+type LoadGuardConfig struct {
+	MaxLoad      float64       `yaml:"max_load" mapstructure:"max_load"`             // 1 分钟平均负载上限（Linux、macOS）
+	MaxCPU       float64       `yaml:"max_cpu" mapstructure:"max_cpu"`               // CPU 使用率上限（百分比，Linux、Windows）
+	MaxDiskQueue float64       `yaml:"max_disk_queue" mapstructure:"max_disk_queue"` // 磁盘队列长度上限：Linux 上为最忙的磁盘正在处理的 I/O 数，Windows 上为所有物理磁盘的当前队列长度
+	RetryDelay   time.Duration `yaml:"retry_delay" mapstructure:"retry_delay"`       // 推迟多久后重新检查，默认 5m
+	MaxRetries   int           `yaml:"max_retries" mapstructure:"max_retries"`       // 最多推迟的次数，默认 6，之后不论负载如何都开始任务
+}
+
+// hostLoad 主机负载的一次采样，无法获取的指标为 -1
+type hostLoad struct {
+	load      float64
+	cpu       float64
+	diskQueue float64
+}
+
+// over 返回超过阈值的指标说明，未超过时返回空
+func (c *LoadGuardConfig) over(l hostLoad) string {
+	var over []string
+	if c.MaxLoad > 0 && l.load >= 0 && l.load > c.MaxLoad {
+		over = append(over, i18n.Sprintf("负载 %.2f > %.2f", l.load, c.MaxLoad))
+	}
+	if c.MaxCPU > 0 && l.cpu >= 0 && l.cpu > c.MaxCPU {
+		over = append(over, i18n.Sprintf("CPU %.0f%% > %.0f%%", l.cpu, c.MaxCPU))
+	}
+	if c.MaxDiskQueue > 0 && l.diskQueue >= 0 && l.diskQueue > c.MaxDiskQueue {
+		over = append(over, i18n.Sprintf("磁盘队列 %.1f > %.1f", l.diskQueue, c.MaxDiskQueue))
+	}
+	return strings.Join(over, "，")
+}
+
+// waitForLoad 主机负载超过阈值时推迟任务，最多推迟 max_retries 次；服务停止时返回 false
+func (p *program) waitForLoad() bool {
+	p.mu.Lock()
+	cfg := p.config.LoadGuard
+	p.mu.Unlock()
+	if cfg == nil {
+		return true
+	}
+	delay, retries := cfg.RetryDelay, cfg.MaxRetries
+	if delay <= 0 {
+		delay = defaultLoadRetryDelay
+	}
+	if retries <= 0 {
+		retries = defaultLoadRetries
+	}
+	for i := 1; ; i++ {
+		l, err := sampleLoad()
+		if err != nil {
+			p.logger.Printf(i18n.T("获取主机负载失败，不推迟任务: %s"), err)
+			return true
+		}
+		reason := cfg.over(l)
+		if reason == "" {
+			return true
+		}
+		if i > retries {
+			p.logger.Printf(i18n.T("主机负载仍然过高（%s），已推迟 %d 次，开始执行"), reason, retries)
+			return true
+		}
+		p.logger.Printf(i18n.T("主机负载过高（%s），%s 后重新检查（第 %d/%d 次推迟）"), reason, delay, i, retries)
+		select {
+		case <-time.After(delay):
+		case <-p.exit:
+			return false
+		}
+	}
+}
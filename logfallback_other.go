@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// fallbackLog 日志文件无法创建时的输出：标准错误，以 systemd、launchd 运行时由其收集到 journal 或系统日志。
+// 返回的位置用于 status 中的 log_destination
+func fallbackLog(name string) (io.Writer, string) {
+	return os.Stderr, "stderr"
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventLogWriter 把每条日志作为一条信息事件写入 Windows 应用程序事件日志
+type eventLogWriter struct {
+	l *eventlog.Log
+}
+
+func (w eventLogWriter) Write(p []byte) (int, error) {
+	if err := w.l.Info(1, strings.TrimRight(string(p), "\r\n")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// fallbackLog 日志文件无法创建时的输出：以服务名称为来源写入应用程序事件日志（安装服务时已注册），
+// 无法打开时为标准错误。返回的位置用于 status 中的 log_destination
+func fallbackLog(name string) (io.Writer, string) {
+	if l, err := eventlog.Open(name); err == nil {
+		return eventLogWriter{l: l}, "eventlog:" + name
+	}
+	return os.Stderr, "stderr"
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// LoggingConfig 服务自身日志 cleanlog.log 的位置和轮转设置
+type LoggingConfig struct {
+	Dir        string `yaml:"dir" mapstructure:"dir"`                 // 日志目录，相对路径相对于程序目录，默认 logs（macOS 上为 /Library/Logs/cleanlogservice）
+	MaxSize    int    `yaml:"max_size" mapstructure:"max_size"`       // 单个日志文件的最大大小（MB），默认 10
+	MaxBackups int    `yaml:"max_backups" mapstructure:"max_backups"` // 最多保留的旧日志文件数，默认 5
+	MaxAge     int    `yaml:"max_age" mapstructure:"max_age"`         // 旧日志文件保留的天数，默认 10
+	Compress   bool   `yaml:"compress" mapstructure:"compress"`       // 是否 gzip 压缩轮转后的旧日志
+
+	TimeFormat string `yaml:"time_format" mapstructure:"time_format"` // 日志和报告中的时间格式：rfc3339、rfc3339ms 或 Go 的时间格式（如 2006-01-02 15:04:05.000），默认 2006/01/02 15:04:05
+	UTC        bool   `yaml:"utc" mapstructure:"utc"`                 // 以 UTC 显示时间，默认本地时间
+}
+
+// timeFormat 日志和报告中时间的显示方式
+type timeFormat struct {
+	layout string
+	utc    bool
+}
+
+// logTime 启动时按 logging 配置设置，之后只读
+var logTime timeFormat
+
+// newTimeFormat 把 time_format 的简写转换为时间格式
+func newTimeFormat(cfg LoggingConfig) timeFormat {
+	layout := cfg.TimeFormat
+	switch strings.ToLower(layout) {
+	case "rfc3339":
+		layout = time.RFC3339
+	case "rfc3339ms":
+		layout = "2006-01-02T15:04:05.000Z07:00"
+	}
+	return timeFormat{layout: layout, utc: cfg.UTC}
+}
+
+// custom 是否配置了与默认不同的时间格式或时区
+func (f timeFormat) custom() bool {
+	return f.layout != "" || f.utc
+}
+
+// format 按配置的时区和格式输出时间，未配置格式时使用 def
+func (f timeFormat) format(t time.Time, def string) string {
+	if f.utc {
+		t = t.UTC()
+	} else {
+		t = t.Local()
+	}
+	if f.layout != "" {
+		def = f.layout
+	}
+	return t.Format(def)
+}
+
+// displayTime 报告、通知和日志内容中显示的时间
+func displayTime(t time.Time) string {
+	return logTime.format(t, timeLayout)
+}
+
+// timestampWriter 配置了时间格式时代替 log.LstdFlags 在每条日志前加上时间
+type timestampWriter struct {
+	w io.Writer
+}
+
+func (t *timestampWriter) Write(p []byte) (int, error) {
+	line := append([]byte(logTime.format(time.Now(), "2006/01/02 15:04:05")+" "), p...)
+	if _, err := t.w.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// probeLogFile 检查能否创建并写入日志文件。lumberjack 在第一次写入时才创建目录和文件，写入失败时 log 包不报告错误，
+// 日志目录只读（安装在只读位置、权限不足）时所有输出都会静默丢失，因此启动时先检查
+func probeLogFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// newLogFile 按配置创建轮转日志，多实例时各自写入以服务名称命名的日志
+func newLogFile(cfg LoggingConfig, name string) *lumberjack.Logger {
+	if cfg.Dir == "" {
+		cfg.Dir = defaultLogDir()
+	}
+	cfg.Dir = cleaner.ExpandPath(cfg.Dir)
+	if !filepath.IsAbs(cfg.Dir) {
+		cfg.Dir = filepath.Join(getCurrentAbPathByExecutable(), cfg.Dir)
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = 10
+	}
+	if cfg.MaxBackups <= 0 {
+		cfg.MaxBackups = 5
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 10
+	}
+	fileName := "cleanlog.log"
+	if name != defaultServiceName {
+		fileName = "cleanlog-" + name + ".log"
+	}
+	return &lumberjack.Logger{
+		Filename:   filepath.Join(cfg.Dir, fileName),
+		MaxSize:    cfg.MaxSize,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAge,
+		Compress:   cfg.Compress,
+		LocalTime:  true,
+	}
+}
@@ -1,99 +1,675 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"github.com/kardianos/service"
 	"github.com/spf13/viper"
-	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+	_ "github.com/go-sql-driver/mysql" // tables: 的数据库驱动
+	_ "github.com/lib/pq"
 	"github.com/robfig/cron/v3"
+	"google.golang.org/grpc"
 )
 
-type Config struct {
-	Directories []string `yaml:"directories"`
-	Days        int      `yaml:"days"`
-	Time        string   `yaml:"time"`
+// appConfig 服务的完整配置：清理配置加上服务本身的设置
+type appConfig struct {
+	cleaner.Config `yaml:",inline" mapstructure:",squash"`
+
+	API     *APIConfig     `yaml:"api" mapstructure:"api"`         // 本地 HTTP 接口，未配置时不启用
+	RPC     *RPCConfig     `yaml:"rpc" mapstructure:"rpc"`         // 供管理程序调用的 JSON-RPC 控制接口
+	GRPC    *GRPCConfig    `yaml:"grpc" mapstructure:"grpc"`       // 供管理程序调用的 gRPC 控制接口
+	Admin   *AdminConfig   `yaml:"admin" mapstructure:"admin"`     // 本机管理通道（Unix 套接字/命名管道）
+	History *HistoryConfig `yaml:"history" mapstructure:"history"` // 任务历史
+	Logging LoggingConfig  `yaml:"logging" mapstructure:"logging"` // 服务日志的目录和轮转设置，启动时生效
+	Debug   *DebugConfig   `yaml:"debug" mapstructure:"debug"`     // 调试选项
+	OTel    *OTelConfig    `yaml:"otel" mapstructure:"otel"`       // 以 OTLP 导出任务的 trace 和指标
+
+	Notify    *NotifyConfig    `yaml:"notify" mapstructure:"notify"`         // 告警通知的发送方式
+	Watchdog  *WatchdogConfig  `yaml:"watchdog" mapstructure:"watchdog"`     // 任务卡住或长时间没有成功时通知
+	DiskAlert *DiskAlertConfig `yaml:"disk_alert" mapstructure:"disk_alert"` // 任务结束后磁盘剩余空间低于下限时通知
+	DiskTrend *DiskTrendConfig `yaml:"disk_trend" mapstructure:"disk_trend"` // 记录磁盘剩余空间的变化，预测多久后写满
+	Digest    *DigestConfig    `yaml:"digest" mapstructure:"digest"`         // 按天或按周汇总任务结果发送一条摘要通知
+
+	AlertOnFailures    *int               `yaml:"alert_on_failures" mapstructure:"alert_on_failures"`       // 单次任务失败的文件数超过该值时通知，并在 /status、/healthz 中标记为 degraded；未配置时不通知
+	FailingAfter       int                `yaml:"failing_after" mapstructure:"failing_after"`               // 连续多少次任务出错时健康状态为 failing，默认 3
+	AllowRelativePaths bool               `yaml:"allow_relative_paths" mapstructure:"allow_relative_paths"` // 允许相对路径，相对于配置文件所在的目录；默认要求绝对路径
+	SelfCleanup        *SelfCleanupConfig `yaml:"self_cleanup" mapstructure:"self_cleanup"`                 // 服务自身的旧日志、损坏的状态文件和报告目录的保留
+	ScanSecret         string             `yaml:"scan_secret" mapstructure:"scan_secret"`                   // scan 报告的 HMAC-SHA256 签名密钥，可使用 ${ENV}；未配置时只附带 SHA-256 校验和
+
+	Overlap     string             `yaml:"overlap" mapstructure:"overlap"`           // 上一次任务未结束时：skip（默认）跳过、queue 等待结束后执行、cancel 取消正在运行的任务
+	Every       time.Duration      `yaml:"every" mapstructure:"every"`               // 按固定间隔执行，如 6h，设置后忽略 time
+	StartDelay  time.Duration      `yaml:"start_delay" mapstructure:"start_delay"`   // 服务启动后等待多久再执行第一次任务和启动定时，等待杀毒软件、文件服务器和业务程序就绪
+	CatchUp     *bool              `yaml:"catch_up" mapstructure:"catch_up"`         // true：启动和休眠唤醒后只补执行错过的定时；false：启动时不执行；未配置时启动后立即执行一次
+	LowPriority bool               `yaml:"low_priority" mapstructure:"low_priority"` // 任务执行期间降低进程的 CPU 和 I/O 优先级，结束后恢复
+	LoadGuard   *LoadGuardConfig   `yaml:"load_guard" mapstructure:"load_guard"`     // 定时任务开始前主机负载过高时推迟
+	BackupGuard *BackupGuardConfig `yaml:"backup_guard" mapstructure:"backup_guard"` // 定时任务开始前备份作业正在运行时推迟
+	Canary      *CanaryConfig      `yaml:"canary" mapstructure:"canary"`             // 新加入的目录先只报告、确认后才处理
+	Groups      []GroupConfig      `yaml:"groups" mapstructure:"groups"`             // 策略组：各自的目录、定时、通知和限制，分别执行和报告
+
+	RestartScheduler bool `yaml:"restart_scheduler" mapstructure:"restart_scheduler"` // 调度器意外退出（如定时表达式注册失败）时 10 秒后重启
+
+	Watch         bool             `yaml:"watch" mapstructure:"watch"`                     // 监视本地目录，文件数或总大小超过阈值时立即清理该目录，启动时生效
+	WatchMaxFiles int              `yaml:"watch_max_files" mapstructure:"watch_max_files"` // 目录中（不含子目录）的文件数阈值
+	WatchMaxSize  cleaner.ByteSize `yaml:"watch_max_size" mapstructure:"watch_max_size"`   // 目录中（不含子目录）文件的总大小阈值，如 "5GB"
+	WatchCooldown time.Duration    `yaml:"watch_cooldown" mapstructure:"watch_cooldown"`   // 同一目录两次触发之间的最短间隔，默认 5m
+}
+
+// cronParser 解析 time 配置：6 位（带秒，如 "0 0 5 * * *"）或 5 位 cron 表达式，以及 @daily、@every 6h 等写法
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// timeLayout 未配置 logging.time_format 时报告和日志内容中显示时间的格式
+const timeLayout = "2006-01-02 15:04:05"
+
+// spec 返回定时表达式，设置了 every 时转换为 @every
+func (c appConfig) spec() string {
+	if c.Every > 0 {
+		return "@every " + c.Every.String()
+	}
+	return strings.TrimSpace(c.Time)
+}
+
+// job 同一策略组中使用同一定时表达式的一组目录
+type job struct {
+	group    string // 所属的策略组，不属于任何组时为空
+	spec     string
+	cleaner  *cleaner.Cleaner
+	entry    cron.EntryID
+	schedule cron.Schedule
+}
+
+// key 标识定时任务，begin 按它找到对应的一组目录
+func (j *job) key() string {
+	if j.group == "" {
+		return j.spec
+	}
+	return "[" + j.group + "] " + j.spec
+}
+
+// dirSpec 返回目录使用的定时表达式，未单独配置 time 时使用所在策略组的定时，再使用全局定时
+func (c appConfig) dirSpec(dir cleaner.DirConfig) string {
+	if spec := strings.TrimSpace(dir.Time); spec != "" {
+		return spec
+	}
+	if g := c.group(dir.Group); g != nil && g.spec() != "" {
+		return g.spec()
+	}
+	return c.spec()
+}
+
+// specs 返回所有目录用到的定时表达式，按第一次出现的顺序
+func (c appConfig) specs() []string {
+	var specs []string
+	seen := map[string]bool{}
+	for _, dir := range c.Directories {
+		if spec := c.dirSpec(dir); !seen[spec] {
+			seen[spec] = true
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// jobs 按策略组和定时表达式分组：未单独配置 time 的目录使用所在组或全局的定时，其余目录按各自的 time 分组；
+// 策略组的任务使用该组的 max_bytes_per_run、max_run_duration
+func (c appConfig) jobs() []*job {
+	var jobs []*job
+	configs := map[string]*cleaner.Config{}
+	for _, dir := range c.Directories {
+		j := &job{group: dir.Group, spec: c.dirSpec(dir)}
+		cfg, ok := configs[j.key()]
+		if !ok {
+			copied := c.Config
+			copied.Directories = nil
+			if g := c.group(dir.Group); g != nil {
+				if g.MaxBytesPerRun > 0 {
+					copied.MaxBytesPerRun = g.MaxBytesPerRun
+				}
+				if g.MaxRunDuration > 0 {
+					copied.MaxRunDuration = g.MaxRunDuration
+				}
+			}
+			cfg = &copied
+			configs[j.key()] = cfg
+			jobs = append(jobs, j)
+		}
+		cfg.Directories = append(cfg.Directories, dir)
+	}
+	for _, j := range jobs {
+		j.cleaner = cleaner.New(*configs[j.key()])
+	}
+	return jobs
 }
 
+// overlap 策略
+const (
+	overlapSkip   = "skip"
+	overlapQueue  = "queue"
+	overlapCancel = "cancel"
+)
+
+// maxHistory 保留的最近任务结果数
+const maxHistory = 20
+
 type program struct {
-	exit    chan struct{}
-	logger  *log.Logger
-	config  Config
-	logFile *lumberjack.Logger
+	exit        chan struct{}
+	ctx         context.Context // 服务停止时取消，中断正在进行的清理
+	cancel      context.CancelFunc
+	logger      *log.Logger
+	name        string // 服务名称
+	configPath  string
+	output      io.Writer // 日志输出：日志文件，控制台模式下同时输出到标准输出
+	logDest     string    // 日志实际写入的位置：日志文件路径，无法创建时为 eventlog:<服务名称> 或 stderr，容器中为 stdout
+	logFallback bool      // 日志文件无法创建，已改为输出到 logDest
+	api         *http.Server
+	rpc         net.Listener
+	grpc        *grpc.Server
+	admin       adminListener
+
+	mu           sync.Mutex // 保护配置和运行状态，重新加载配置时替换，供 HTTP/RPC 接口读取
+	config       appConfig
+	cleaner      *cleaner.Cleaner
+	scheduler    *cron.Cron
+	jobs         []*job    // 各组目录的定时任务，调度器启动后才有 entry 和 schedule
+	lastRun      time.Time // 最近一次任务的开始时间
+	started      time.Time // 服务启动时间
+	lastSuccess  time.Time // 最近一次成功完成的任务的结束时间
+	running      bool
+	queued       bool               // 有任务在等待当前任务结束
+	runCancel    context.CancelFunc // 取消当前任务
+	runMu        sync.Mutex         // 任务执行锁
+	reloadMu     sync.Mutex         // 重新加载配置时读取全局 viper，同一时间只允许一个
+	paused       bool               // 通过管理通道暂停时跳过定时任务，手动触发不受影响
+	history      []*runResult       // 最近完成的任务，最新的在最后
+	totals       runTotals          // 服务启动以来的累计统计
+	container    bool               // 容器模式：不经过服务管理器，日志以 JSON 输出到标准输出
+	runID        atomic.Value       // 正在执行的任务的 ID（string）
+	runGroup     string             // 正在执行的任务所属的策略组，任务期间的通知同时发送到该组的 notify
+	diskTrends   []diskTrend        // 最近一次任务后各磁盘的剩余空间趋势
+	trendAlerted map[string]bool    // 已发送过即将写满通知的磁盘
+	digestMu     sync.Mutex         // 读写摘要状态文件
+	holdMu       sync.Mutex
+	holds        map[string]holdEntry // 通过管理接口设置保留的目录，键为 holdKey
+	canaryMu     sync.Mutex
+	canaries     map[string]canaryEntry // 各配置目录的 canary 状态，键为 holdKey
+	lastRuns     map[string]time.Time   // 各定时任务（job.key）上次到点的时间，保存在 lastrun.json 中
+
 }
 
 func (p *program) Start(s service.Service) error {
 	p.logger.Printf("Service started")
-	go p.cleanDirectories()
-	go p.run()
+	p.mu.Lock()
+	p.started = time.Now()
+	p.mu.Unlock()
+	p.startAPI()
+	p.startRPC()
+	p.startGRPC()
+	p.startAdmin()
+	p.startPprof()
+	go p.supervise()
+	go p.watchdog()
+	go p.digestLoop()
+	p.watchReloadSignal()
 	return nil
 }
 
-func (p *program) run() {
-	c := cron.New(cron.WithSeconds(),
-		cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)),
+// run 启动调度器直到服务停止。restarted 为 true 表示调度器意外退出后重启，不再等待 start_delay 和立即执行
+func (p *program) run(restarted bool) {
+	if delay := p.config.StartDelay; delay > 0 && !restarted {
+		p.logger.Printf(i18n.T("等待 %s 后开始执行"), delay)
+		select {
+		case <-time.After(delay):
+		case <-p.exit:
+			p.logger.Printf("Service stopped")
+			return
+		}
+	}
+	if !restarted {
+		go p.watchDirectories()
+	}
+
+	c := cron.New(cron.WithParser(cronParser),
 		cron.WithLogger(
 			cron.VerbosePrintfLogger(
-				log.New(p.logFile, "", log.LstdFlags),
+				log.New(p.output, "", p.logger.Flags()),
 			),
 		),
 	)
-	_, err := c.AddFunc(p.config.Time, p.cleanDirectories)
-	if err != nil {
+	p.mu.Lock()
+	jobs := p.config.jobs()
+	if err := p.addJobs(c, jobs); err != nil {
+		p.mu.Unlock()
+		p.logger.Printf("%s", err)
 		return
 	}
+	p.scheduler, p.jobs = c, jobs
+	p.mu.Unlock()
 	c.Start()
+	if !restarted {
+		p.startupRun()
+	}
+	p.logSchedule(3)
 
 	<-p.exit
 	c.Stop()
+	p.mu.Lock()
+	p.scheduler = nil
+	p.mu.Unlock()
 
 	p.logger.Printf("Service stopped")
 }
 
 func (p *program) Stop(s service.Service) error {
+	p.cancel()
 	close(p.exit)
+	p.stopAPI()
+	p.stopRPC()
+	p.stopGRPC()
+	p.stopAdmin()
+	p.describeHealth("")
+	return nil
+}
+
+// addJobs 把各组目录注册为定时任务，调用方需持有 p.mu
+func (p *program) addJobs(c *cron.Cron, jobs []*job) error {
+	for i, j := range jobs {
+		spec, key := j.spec, j.key()
+		id, err := c.AddFunc(spec, func() { p.cleanDirectories(key) })
+		if err != nil {
+			for _, added := range jobs[:i] {
+				c.Remove(added.entry)
+			}
+			return i18n.Errorf("定时表达式 %q 无效: %s", spec, err)
+		}
+		j.entry, j.schedule = id, c.Entry(id).Schedule
+	}
 	return nil
 }
 
-func (p *program) loadConfig(configFilePath string) (Config, error) {
-	var config Config
+// cleanDirectories 执行一次清理任务，key 为空时清理所有目录，否则只清理该定时任务（job.key）对应的目录
+func (p *program) cleanDirectories(key string) {
+	defer p.recoverPanic(i18n.T("定时任务"))
+	p.mu.Lock()
+	paused := p.paused
+	p.mu.Unlock()
+	p.recordScheduled(key, time.Now())
+	if paused {
+		p.logger.Printf(i18n.T("定时任务已暂停，跳过"))
+		return
+	}
+	if !p.waitForLoad() || !p.waitForBackup() {
+		return
+	}
+	if run, ok := p.begin(key); ok {
+		run()
+	}
+	p.logSchedule(1)
+}
+
+// setPaused 暂停或恢复定时任务，服务和接口继续运行，正在执行的任务不受影响
+func (p *program) setPaused(paused bool) {
+	p.mu.Lock()
+	changed := p.paused != paused
+	p.paused = paused
+	p.mu.Unlock()
+	if !changed {
+		return
+	}
+	if paused {
+		p.logger.Printf(i18n.T("定时任务已暂停"))
+		return
+	}
+	p.logger.Printf(i18n.T("定时任务已恢复"))
+	p.logSchedule(1)
+}
+
+// nextRun 返回 t 之后最早的定时任务时间，调用方需持有 p.mu
+func (p *program) nextRun(t time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, j := range p.jobs {
+		if n := j.schedule.Next(t); next.IsZero() || n.Before(next) {
+			next = n
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// logSchedule 记录接下来 n 次定时任务的时间（本地时间）
+func (p *program) logSchedule(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	next := time.Now()
+	for i := 0; i < n; i++ {
+		var ok bool
+		if next, ok = p.nextRun(next); !ok {
+			return
+		}
+		p.logger.Printf(i18n.T("下次执行时间: %s"), displayTime(next))
+	}
+}
+
+// runOnce 清理所有目录，已有任务在运行时直接返回 false
+func (p *program) runOnce() (*runResult, bool) {
+	run, ok := p.begin("")
+	if !ok {
+		return nil, false
+	}
+	return run(), true
+}
+
+// begin 按 overlap 策略决定是否执行新的任务，返回执行任务的函数；
+// 定时、启动时和手动触发的任务都经过这里，同一时间只有一个任务在运行。
+// key 为空时清理所有目录，否则只清理该定时任务（job.key）对应的一组目录
+func (p *program) begin(key string) (func() *runResult, bool) {
+	return p.beginWith(key, nil)
+}
+
+// beginWith 同 begin，target 不为空时使用 target 清理（如 watch 触发时只清理超过阈值的目录）
+func (p *program) beginWith(key string, target *cleaner.Cleaner) (func() *runResult, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ctx, cancel := context.WithCancel(p.ctx)
+	queued := p.running
+	if queued {
+		switch {
+		case p.queued || p.config.Overlap == "" || p.config.Overlap == overlapSkip:
+			cancel()
+			p.logger.Printf(i18n.T("上一次任务仍在运行，跳过"))
+			return nil, false
+		case p.config.Overlap == overlapCancel:
+			p.logger.Printf(i18n.T("取消正在运行的任务"))
+			p.runCancel()
+		default:
+			p.logger.Printf(i18n.T("上一次任务仍在运行，排队等待"))
+		}
+		p.queued = true
+	} else {
+		p.running, p.runCancel = true, cancel
+	}
+	return func() *runResult {
+		p.runMu.Lock()
+		defer p.runMu.Unlock()
+		defer cancel()
+		p.mu.Lock()
+		if queued {
+			p.queued, p.running, p.runCancel = false, true, cancel
+		}
+		start := time.Now()
+		p.lastRun = start
+		id := newRunID(start)
+		p.setRunID(id)
+		defer p.setRunID("")
+		cl, low := p.cleaner, p.config.LowPriority
+		var group string
+		if target != nil {
+			cl = target
+		} else if key != "" {
+			cl = nil
+			for _, j := range p.jobs {
+				if j.key() == key {
+					cl, group = j.cleaner, j.group
+				}
+			}
+		}
+		p.runGroup = group
+		p.mu.Unlock()
+		defer func() {
+			p.mu.Lock()
+			p.runGroup = ""
+			p.mu.Unlock()
+		}()
+		if cl == nil {
+			// 重新加载配置后该组目录已不存在
+			p.mu.Lock()
+			p.running = false
+			p.mu.Unlock()
+			return nil
+		}
+
+		if low {
+			if restore, err := lowerPriority(); err != nil {
+				p.logger.Printf(i18n.T("降低进程优先级失败: %s"), err)
+			} else {
+				defer func() {
+					if err := restore(); err != nil {
+						p.logger.Printf(i18n.T("恢复进程优先级失败: %s"), err)
+					}
+				}()
+			}
+		}
+		report, err := p.runCleaner(ctx, cl)
+		if err != nil {
+			p.logger.Printf(i18n.T("清理任务失败: %s"), err)
+		}
+		result := &runResult{ID: id, Group: group, Start: start, Duration: time.Since(start).Seconds(), Report: report}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		prev, _ := p.health()
+		p.mu.Lock()
+		p.running = false
+		if err == nil {
+			p.lastSuccess = time.Now()
+		}
+		p.history = append(p.history, result)
+		p.totals.add(result)
+		if len(p.history) > maxHistory {
+			p.history = p.history[len(p.history)-maxHistory:]
+		}
+		p.mu.Unlock()
+		p.saveHistory(result)
+		p.updateHealth(prev.Health)
+		p.checkFailures(result)
+		p.notifyRun(result)
+		p.checkByteCap(report)
+		p.checkAborted(report)
+		p.notifyOwners(result)
+		go p.exportTelemetry(result)
+		p.checkDiskSpace(report)
+		p.recordDiskTrend(report)
+		p.recordDigest(result)
+		p.countCanary(report)
+		return result
+	}, true
+}
+
+// reload 重新读取并校验配置文件，替换清理配置并重新注册各组目录的定时任务；
+// 校验失败时不做任何替换，继续使用原来的配置。HTTP/RPC 接口的监听地址需重启服务才能生效
+func (p *program) reload() (appConfig, error) {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+	lang := i18n.Language()
+	config, err := p.loadConfig(p.configPath)
+	if err == nil {
+		err = cleaner.New(config.Config).Err()
+	}
+	if err != nil {
+		i18n.SetLanguage(lang)
+		p.logger.Printf(i18n.T("重新加载配置失败，继续使用原配置: %s"), err)
+		return config, err
+	}
+	cl := cleaner.New(config.Config)
+	jobs := config.jobs()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.scheduler != nil {
+		if err := p.addJobs(p.scheduler, jobs); err != nil {
+			i18n.SetLanguage(lang)
+			p.logger.Printf(i18n.T("重新加载配置失败，继续使用原配置: %s"), err)
+			return config, err
+		}
+		for _, j := range p.jobs {
+			p.scheduler.Remove(j.entry)
+		}
+		p.jobs = jobs
+	}
+	config.API, config.RPC, config.GRPC, config.Admin, config.Debug = p.config.API, p.config.RPC, p.config.GRPC, p.config.Admin, p.config.Debug
+	config.History = p.config.History
+	config.StartDelay = p.config.StartDelay
+	p.config, p.cleaner = config, cl
+	p.syncCanary(config)
+	p.logger.Printf(i18n.T("配置已重新加载"))
+	return config, nil
+}
+
+func (p *program) loadConfig(configFilePath string) (appConfig, error) {
+	var config appConfig
 	executable, err := os.Executable()
-	p.logger.Printf("当前文件夹路径：" + executable)
+	p.logger.Printf(i18n.T("当前文件夹路径：") + executable)
 	if err != nil {
-		return Config{}, err
+		return appConfig{}, err
 	}
 	if configFilePath != "" {
 		viper.SetConfigFile(configFilePath)
 	} else {
 		viper.SetConfigName("config")
 		viper.SetConfigType("yaml")
-		viper.AddConfigPath(getCurrentAbPathByExecutable())
-
+		for _, dir := range configSearchPaths() {
+			viper.AddConfigPath(dir)
+		}
 	}
 
 	err = viper.ReadInConfig()
 	if err != nil {
 		return config, err
 	}
+	if configProfile != "" {
+		if err := applyProfile(viper.GetViper(), configProfile); err != nil {
+			return config, err
+		}
+		p.logger.Printf(i18n.T("使用配置 profile: %s"), configProfile)
+	}
+	fragments, err := mergeConfDir(viper.GetViper())
+	if err != nil {
+		return config, err
+	}
+	for _, file := range fragments {
+		p.logger.Printf(i18n.T("合并配置片段: %s"), file)
+	}
 
 	viper.SetDefault("days", 3)
 
-	err = viper.Unmarshal(&config)
+	err = viper.Unmarshal(&config, viper.DecodeHook(cleaner.DecodeHook()))
 	if err != nil {
 		return config, err
 	}
-	p.logger.Printf("配置信息读取结果如下：")
-	p.logger.Printf("Time:" + config.Time)
-	p.logger.Printf("Days:", config.Days)
-	p.logger.Printf("Directories:", config.Directories)
+	cleaner.LabelDirectories(config.Directories, "directories")
+	for i := range config.Groups {
+		cleaner.LabelDirectories(config.Groups[i].Directories, fmt.Sprintf("groups[%d].directories", i))
+	}
+	if err := config.flattenGroups(); err != nil {
+		return config, err
+	}
+	if err := i18n.SetLanguage(config.Language); err != nil {
+		return config, err
+	}
+	p.logger.Printf(i18n.T("配置信息读取结果如下："))
+	p.logger.Printf("Time:" + config.spec())
+	if _, err := cronParser.Parse(config.spec()); err != nil {
+		return config, i18n.Errorf("定时表达式 %q 无效: %s", config.spec(), err)
+	}
+	switch config.Overlap {
+	case "", overlapSkip, overlapQueue, overlapCancel:
+	default:
+		return config, i18n.Errorf("overlap %q 无效，可选 skip、queue、cancel", config.Overlap)
+	}
+	p.logger.Printf("Days: %d", config.Days)
+	if config.MaxAge > 0 {
+		p.logger.Printf("MaxAge: %s", config.MaxAge)
+	}
+	if err := config.ApplyPresets(); err != nil {
+		return config, err
+	}
+	config.ExpandPaths()
+	if err := config.Check(config.AllowRelativePaths); err != nil {
+		return config, err
+	}
+	config.Directories = append(config.Directories, p.selfDirectories(config)...)
+	if used := viper.ConfigFileUsed(); used != "" {
+		if abs, err := filepath.Abs(used); err == nil {
+			p.logger.Printf(i18n.T("配置文件: %s，相对路径相对于 %s"), abs, filepath.Dir(abs))
+			config.ResolvePaths(filepath.Dir(abs))
+			if config.API != nil {
+				config.API.resolvePaths(filepath.Dir(abs))
+			}
+			if config.RPC != nil {
+				config.RPC.resolvePaths(filepath.Dir(abs))
+			}
+			if config.GRPC != nil {
+				config.GRPC.resolvePaths(filepath.Dir(abs))
+			}
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return config, err
+	}
+	if config.Digest != nil && config.Digest.Enabled {
+		if _, err := config.Digest.spec(); err != nil {
+			return config, err
+		}
+	}
+	if err := config.Notify.validate("notify"); err != nil {
+		return config, err
+	}
+	for _, g := range config.Groups {
+		if err := g.Notify.validate(i18n.Sprintf("策略组 %s 的 notify", g.Name)); err != nil {
+			return config, err
+		}
+	}
+	for _, dir := range config.Directories {
+		p.logger.Printf(i18n.T("Directory: %s 保留策略: %v 扩展名: %v 排除扩展名: %v"), dir.Path, config.Tiers(dir), dir.Extensions, dir.ExcludeExtensions)
+		if err := validateOwner(dir.Owner, config.Notify); err != nil {
+			return config, i18n.Errorf("目录 %s: %w", dir.Path, err)
+		}
+		if spec := strings.TrimSpace(dir.Time); spec != "" {
+			if _, err := cronParser.Parse(spec); err != nil {
+				return config, i18n.Errorf("目录 %s 的定时表达式 %q 无效: %s", dir.Path, spec, err)
+			}
+			p.logger.Printf(i18n.T("目录 %s 单独定时: %s"), dir.Path, spec)
+		}
+	}
+	config.Logger = p.logger
+	config.Hold = p.isHeld
+	config.InCanary = p.isCanary
 
 	return config, nil
 }
 
+// readConfigKey 只读取配置文件中的一项，供 ctl、history 等不启动服务的命令使用；配置文件不存在时保持默认值
+func readConfigKey(configFilePath, key string, out interface{}) error {
+	v := viper.New()
+	if configFilePath != "" {
+		v.SetConfigFile(configFilePath)
+	} else {
+		v.SetConfigName("config")
+		v.SetConfigType("yaml")
+		for _, dir := range configSearchPaths() {
+			v.AddConfigPath(dir)
+		}
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil
+	}
+	if err := applyProfile(v, configProfile); err != nil {
+		return err
+	}
+	return v.UnmarshalKey(key, out, viper.DecodeHook(cleaner.DecodeHook()))
+}
+
 // 获取当前执行程序所在的绝对路径
 func getCurrentAbPathByExecutable() string {
 	exePath, err := os.Executable()
@@ -104,113 +680,313 @@ func getCurrentAbPathByExecutable() string {
 	return res
 }
 
+// defaultServiceName 未指定 --name 时的服务名称
+const defaultServiceName = "A乐榜日志清理服务"
+
+// maskArgs 隐藏命令行中的密码，避免写入日志
+func maskArgs(args []string) []string {
+	masked := append([]string(nil), args...)
+	for i, arg := range masked {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == "password" && i+1 < len(masked) {
+			masked[i+1] = "***"
+		} else if strings.HasPrefix(name, "password=") {
+			masked[i] = arg[:strings.Index(arg, "=")+1] + "***"
+		}
+	}
+	return masked
+}
+
 func main() {
-	sArgs := fmt.Sprint(os.Args)
+	// 作为 sftp 的 SSH_ASKPASS 程序被调用时只输出密码
+	if cleaner.HandleAskpass() {
+		return
+	}
+	sArgs := fmt.Sprint(maskArgs(os.Args))
+	once := flag.Bool("once", false, i18n.T("只执行一次清理后退出，摘要输出到标准输出；退出码 0 成功、2 有失败、3 配置错误"))
+	console := flag.Bool("console", false, i18n.T("前台运行，日志同时输出到控制台，Ctrl-C 退出"))
+	configFilePath := flag.String("config", "", i18n.T("配置文件路径，默认为程序目录下的 config.yml；与 install 一起使用时记录到服务的启动参数中"))
+	userName := flag.String("user", "", i18n.T("install 时指定运行服务的账户，如 DOMAIN\\svc-clean，默认 LocalSystem"))
+	password := flag.String("password", "", i18n.T("运行账户的密码，也可通过环境变量 CLEANLOG_SERVICE_PASSWORD 传入"))
+	depends := flag.String("depends", "", i18n.T("服务依赖，逗号分隔，如 LanmanWorkstation"))
+	name := flag.String("name", defaultServiceName, i18n.T("服务名称，同一台机器上安装多个实例时使用不同的名称"))
+	displayName := flag.String("display-name", "", i18n.T("服务显示名称，默认与服务名称相同"))
+	description := flag.String("description", "", i18n.T("服务描述"))
+	onFailure := flag.String("on-failure", "restart", i18n.T("install 时设置服务崩溃后的操作：restart、reboot 或 none"))
+	restartDelay := flag.Duration("restart-delay", time.Minute, i18n.T("崩溃后多久重启服务"))
+	resetPeriod := flag.Duration("reset-period", 24*time.Hour, i18n.T("多久没有再次失败后重置失败计数（仅 Windows）"))
+	profile := flag.String("profile", "", i18n.T("使用配置文件中 profiles 下的指定配置（如 prod），也可通过环境变量 CLEANLOG_PROFILE 指定"))
+	purgeState := flag.Bool("purge", false, i18n.T("uninstall 时同时删除本实例的日志、任务历史和状态文件，删除前会列出并确认"))
+	yes := flag.Bool("yes", false, i18n.T("与 --purge 一起使用时不再确认"))
+	container := flag.Bool("container", false, i18n.T("容器模式：不经过服务管理器，日志以 JSON 输出到标准输出，收到 SIGTERM 后取消任务并退出；也可通过环境变量 CLEANLOG_CONTAINER=true 启用"))
+	flag.Parse()
+	args := flag.Args()
+	// 参数也可以写在命令之后，如 install --config D:\etc\clean.yml
+	if len(args) > 0 {
+		flag.CommandLine.Parse(args[1:])
+		args = append(args[:1], flag.Args()...)
+	}
+	// ctl、history、schedule-task、systemd 子命令不经过服务管理器，如 ctl status --config D:\etc\clean.yml
+	if len(args) > 1 && (args[0] == "ctl" || args[0] == "history" || args[0] == "schedule-task" || args[0] == "systemd") {
+		flag.CommandLine.Parse(args[2:])
+		args = append(args[:2], flag.Args()...)
+	}
+	configProfile = profileFromEnv(*profile)
+	inContainer := containerMode(*container) && (len(args) == 0) && !*once
+	if inContainer {
+		*configFilePath = containerConfigPath(*configFilePath)
+	}
+	// 日志设置需在创建日志前读取，读取失败时使用默认值；时间格式同样用于 history 等命令的输出
+	var logging LoggingConfig
+	loggingErr := readConfigKey(*configFilePath, "logging", &logging)
+	logTime = newTimeFormat(logging)
+	if len(args) > 0 && args[0] == "generate-config" {
+		if err := runGenerateConfig(args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "init" {
+		path := *configFilePath
+		if len(args) > 1 {
+			path = args[1]
+		}
+		if err := runInit(path, *name); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "version" {
+		fmt.Println("cleanlogservice " + getBuildInfo().String())
+		return
+	}
+	if len(args) > 0 && args[0] == "ctl" {
+		if err := runCtl(*configFilePath, *name, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "history" {
+		if err := runHistory(*configFilePath, *name, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	// 创建一个新的程序实例
 	prg := &program{
 		exit: make(chan struct{}),
+		name: *name,
 	}
+	prg.ctx, prg.cancel = context.WithCancel(context.Background())
+	prg.container = inContainer
 
-	// 打开日志文件
-	logFileName := "cleanlog.log"
-	logFilePath := filepath.Join(getCurrentAbPathByExecutable(), "logs", logFileName)
-	logFile := &lumberjack.Logger{
-		Filename:   logFilePath,
-		MaxSize:    10, // 每个日志文件最大10MB
-		MaxBackups: 5,  // 最多保留3个旧日志文件
-		MaxAge:     10, // 保留最近30天的日志文件
-		Compress:   false,
-		LocalTime:  true,
-	}
-	prg.logFile = logFile
-	prg.logger = log.New(logFile, "", log.LstdFlags)
-	prg.logger.Printf("开始执行")
+	logFile := newLogFile(logging, *name)
+	var logOut io.Writer = logFile
+	prg.logDest = logFile.Filename
+	// 日志文件无法创建时改为输出到事件日志或标准错误，继续运行
+	logFileErr := probeLogFile(logFile.Filename)
+	if logFileErr != nil && !prg.container {
+		logOut, prg.logDest = fallbackLog(*name)
+		prg.logFallback = true
+	}
+	prg.output = logOut
+	// 在终端中直接运行（或指定 --console）时同时输出到控制台，便于在 Docker 中或调试时查看
+	// --once 时控制台只输出摘要
+	// bench、explain、simulate、scan、systemd 的结果输出到标准输出，控制台不再输出日志
+	report := len(args) > 0 && (args[0] == "bench" || args[0] == "explain" || args[0] == "simulate" || args[0] == "scan" || args[0] == "systemd")
+	if *console || (service.Interactive() && !*once && !report) {
+		if logOut == os.Stderr {
+			prg.output = os.Stdout
+		} else {
+			prg.output = io.MultiWriter(os.Stdout, logOut)
+		}
+	}
+	prg.logger = log.New(prg.output, "", log.LstdFlags)
+	if logTime.custom() {
+		prg.output = &timestampWriter{w: prg.output}
+		prg.logger = log.New(prg.output, "", 0)
+	}
+	if prg.container {
+		// 容器中只输出到标准输出，由容器运行时收集，不写日志文件
+		prg.output = &jsonLogWriter{w: os.Stdout, service: *name, runID: prg.currentRunID}
+		prg.logger = log.New(prg.output, "", 0)
+		prg.logDest = "stdout"
+	}
+	prg.logger.Printf(i18n.T("开始执行"))
+	prg.logger.Printf(i18n.T("版本: %s"), getBuildInfo())
 	prg.logger.Printf("Args:" + sArgs)
+	if loggingErr != nil {
+		prg.logger.Printf(i18n.T("读取 logging 配置失败，使用默认设置: %s"), loggingErr)
+	}
+	if prg.logFallback {
+		prg.logger.Printf(i18n.T("警告: 无法创建日志文件，日志改为输出到 %s，请检查 logging.dir 的权限或改到可写的目录: %s"), prg.logDest, logFileErr)
+	}
+	if *once {
+		os.Exit(prg.once(*configFilePath))
+	}
+	if report && args[0] == "bench" {
+		os.Exit(prg.bench(*configFilePath, args[1:]))
+	}
+	if report && args[0] == "simulate" {
+		os.Exit(prg.simulate(*configFilePath, args[1:]))
+	}
+	if report && args[0] == "scan" {
+		os.Exit(prg.scan(*configFilePath, args[1:]))
+	}
+	if report && args[0] == "explain" {
+		os.Exit(prg.explain(*configFilePath, args[1:]))
+	}
+	// 不允许安装额外服务的环境中，改为注册计划任务定时执行 --once
+	if len(args) > 0 && args[0] == "schedule-task" {
+		if *password == "" {
+			*password = os.Getenv("CLEANLOG_SERVICE_PASSWORD")
+		}
+		description := *description
+		if description == "" {
+			description = "乐榜日志清理任务，配置在文件同目录下的config.yml"
+		}
+		err := prg.scheduleTask(args[1:], taskOptions{
+			name: *name, description: description,
+			configPath: *configFilePath, profile: configProfile,
+			user: *userName, password: *password,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	// 生成 systemd 的 unit，用于 kardianos/service 生成的 unit 不适用的发行版
+	if report && args[0] == "systemd" {
+		description := *description
+		if description == "" {
+			description = "乐榜日志清理服务"
+		}
+		opts := unitOptions{
+			name: *name, description: description,
+			configPath: *configFilePath, profile: configProfile,
+			user: *userName, onFailure: *onFailure, restartDelay: *restartDelay,
+		}
+		for _, dep := range strings.Split(*depends, ",") {
+			if dep = strings.TrimSpace(dep); dep != "" {
+				opts.depends = append(opts.depends, dep)
+			}
+		}
+		if err := prg.systemdUnits(args[1:], opts); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if prg.container {
+		prg.logger.Printf(i18n.T("以容器模式运行"))
+		config, err := prg.loadConfig(*configFilePath)
+		if err != nil {
+			prg.logger.Fatalf(i18n.T("加载配置文件时发生错误: %s"), err)
+		}
+		prg.configPath = *configFilePath
+		prg.config = config
+		prg.cleaner = cleaner.New(config.Config)
+		prg.loadHistory()
+		prg.loadHolds()
+		prg.syncCanary(config)
+		prg.logger.Printf(i18n.T("配置加载完成！"))
+		os.Exit(prg.runContainer())
+	}
 
 	// 创建一个新的服务
 	svcConfig := &service.Config{
-		Name:        "A乐榜日志清理服务",
-		DisplayName: "A乐榜日志清理服务",
-		Description: "乐榜日志清理服务，配置在文件同目录下的config.yml"}
+		Name:        *name,
+		DisplayName: *displayName,
+		Description: *description}
+	if svcConfig.DisplayName == "" {
+		svcConfig.DisplayName = *name
+	}
+	if svcConfig.Description == "" {
+		svcConfig.Description = "乐榜日志清理服务，配置在文件同目录下的config.yml"
+	}
+	// 服务运行时需要知道自己的名称，非默认名称同样记录到启动参数中
+	if *name != defaultServiceName {
+		svcConfig.Arguments = append(svcConfig.Arguments, "--name", *name)
+	}
+	if *configFilePath != "" {
+		// 服务由系统启动时工作目录不确定，记录绝对路径
+		path, err := filepath.Abs(*configFilePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		*configFilePath = path
+		svcConfig.Arguments = append(svcConfig.Arguments, "--config", path)
+	}
+	if configProfile != "" {
+		svcConfig.Arguments = append(svcConfig.Arguments, "--profile", configProfile)
+	}
+	// 以域账户运行时才能访问需要权限的网络共享
+	svcConfig.UserName = *userName
+	if *password == "" {
+		*password = os.Getenv("CLEANLOG_SERVICE_PASSWORD")
+	}
+	// 崩溃后由服务管理器自动重启
+	options, err := recoveryOptions(*onFailure, *restartDelay, *resetPeriod)
+	if err != nil {
+		log.Fatal(err)
+	}
+	svcConfig.Option = options
+	if *password != "" {
+		svcConfig.Option["Password"] = *password
+	}
+	for _, dep := range strings.Split(*depends, ",") {
+		if dep = strings.TrimSpace(dep); dep != "" {
+			svcConfig.Dependencies = append(svcConfig.Dependencies, dep)
+		}
+	}
 	// 创建一个新的服务对象
 	s, err := service.New(prg, svcConfig)
 	if err != nil {
 		log.Fatal(err)
 	}
-	prg.logger.Printf("服务创建！")
+	prg.logger.Printf(i18n.T("服务创建！"))
 	// 检查命令行参数
-	if len(os.Args) > 1 {
-		prg.logger.Printf("有参数：" + os.Args[1])
-		err := service.Control(s, os.Args[1])
+	if len(args) > 0 {
+		prg.logger.Printf(i18n.T("有参数：") + args[0])
+		err := service.Control(s, args[0])
 		if err != nil {
-			log.Fatalf("Failed to %s service: %s", os.Args[1], err)
+			log.Fatalf("Failed to %s service: %s", args[0], err)
+		}
+		if args[0] == "uninstall" && *purgeState {
+			// Windows 上无法删除仍打开的日志文件
+			logFile.Close()
+			if err := purge(*configFilePath, *name, logging, *yes); err != nil {
+				log.Fatal(err)
+			}
 		}
 		return
 	}
-	// 从命令行参数获取配置文件路径
-	configFilePath := "" // 在这里设置默认的配置文件路径
-	if len(os.Args) > 2 {
-		configFilePath = os.Args[2]
-	}
-	prg.logger.Printf("开始加载配置！")
+	prg.logger.Printf(i18n.T("开始加载配置！"))
 	// 从文件加载配置
-	config, err := prg.loadConfig(configFilePath)
+	config, err := prg.loadConfig(*configFilePath)
 	if err != nil {
-		log.Fatalf("加载配置文件时发生错误: %s", err)
+		log.Fatalf(i18n.T("加载配置文件时发生错误: %s"), err)
 	}
+	prg.configPath = *configFilePath
 	prg.config = config
-	prg.logger.Printf("配置加载完成！")
+	prg.cleaner = cleaner.New(config.Config)
+	prg.loadHistory()
+	prg.loadHolds()
+	prg.syncCanary(config)
+	prg.logger.Printf(i18n.T("配置加载完成！"))
 	// 检查服务是否已经在运行
 	status, err := s.Status()
 	if err == nil {
-		prg.logger.Printf("Service is already %s", status)
+		prg.logger.Printf("Service is already %d", status)
 	}
 
-	// 启动服务
+	// 启动服务，前台运行时收到 Ctrl-C 后停止并退出
 	err = s.Run()
 	if err != nil {
 		prg.logger.Fatal(err)
 	}
-
-	select {}
-}
-
-func (p *program) cleanDirectories() {
-	//now := time.Now()
-	p.logger.Printf("---------------   执行一次任务！ ---------------")
-	successCount := 0
-	failureCount := 0
-	threshold := time.Now().AddDate(0, 0, -p.config.Days).Unix()
-	//threshold := time.Now().AddDate(0, 0, -p.config.Days).Unix()
-	for _, dir := range p.config.Directories {
-		files, err := os.ReadDir(dir)
-		if err != nil {
-			continue
-		}
-
-		for _, file := range files {
-			filePath := filepath.Join(dir, file.Name())
-			info, err := file.Info()
-			if err != nil {
-				fmt.Println("获取文件信息失败:", err)
-				failureCount++
-				continue // 获取文件信息失败，跳过当前文件，继续下一个文件
-			}
-
-			if !file.IsDir() && info.ModTime().Unix() < threshold {
-				err := os.Remove(filePath)
-				if err != nil {
-					p.logger.Println("删除文件失败:", err)
-					failureCount++
-					continue // 删除失败，跳过当前文件，继续下一个文件
-				}
-				//fmt.Println("删除文件成功:", filePath)
-				successCount++
-			}
-		}
-	}
-
-	p.logger.Printf("成功删除文件数: %d\n", successCount)
-	p.logger.Printf("删除文件失败数: %d\n", failureCount)
 }
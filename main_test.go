@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"github.com/robfig/cron/v3"
+)
+
+// 文档中列出的数据库驱动都应编译进程序，否则 tables: 配置无法通过校验
+func TestTableDriversLinked(t *testing.T) {
+	for _, driver := range []string{"mysql", "postgres"} {
+		config := cleaner.Config{Days: 30, Tables: []cleaner.TableConfig{{
+			Driver: driver, DSN: "app:secret@tcp(db:3306)/app", Table: "app.audit_log", Column: "created_at",
+		}}}
+		if err := config.Validate(); err != nil {
+			t.Errorf("driver %s: %s", driver, err)
+		}
+	}
+}
+
+// 启动后还未执行过任务时，到第一次定时任务加 health_grace 之前为健康
+func TestHealthBeforeFirstRun(t *testing.T) {
+	hourly, err := cronParser.Parse("0 0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cases := []struct {
+		name      string
+		started   time.Time
+		delay     time.Duration
+		scheduler bool
+		healthy   bool
+	}{
+		{"等待 start_delay", now.Add(-time.Hour), 2 * time.Hour, false, true},
+		{"start_delay 结束后调度器未启动", now.Add(-time.Hour), 0, false, false},
+		{"等待第一次定时任务", now.Add(-time.Minute), 0, true, true},
+		{"第一次定时任务未按时执行", now.Add(-3 * time.Hour), 0, true, false},
+	}
+	for _, c := range cases {
+		p := &program{started: c.started}
+		p.config.StartDelay = c.delay
+		if c.scheduler {
+			p.scheduler, p.jobs = &cron.Cron{}, []*job{{schedule: hourly}}
+		}
+		if _, healthy := p.health(); healthy != c.healthy {
+			t.Errorf("%s: healthy = %v，应为 %v", c.name, healthy, c.healthy)
+		}
+	}
+}
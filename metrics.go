@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cleanlogservice/pkg/cleaner"
+)
+
+// runTotals 服务启动以来的累计统计，供 /metrics 使用
+type runTotals struct {
+	runs, failedRuns    int
+	deleted, failed     int
+	avContention        int
+	unverified          int
+	failedBy            map[string]int // 按原因分类的失败数
+	freedBytes, scanned int64
+}
+
+// add 累计一次任务的结果，调用方需持有 p.mu
+func (t *runTotals) add(result *runResult) {
+	t.runs++
+	if result.Error != "" {
+		t.failedRuns++
+	}
+	t.deleted += result.Report.Deleted
+	t.failed += result.Report.Failed
+	t.avContention += result.Report.AVContention
+	t.unverified += result.Report.Unverified
+	for c, n := range result.Report.Errors {
+		if t.failedBy == nil {
+			t.failedBy = map[string]int{}
+		}
+		t.failedBy[c] += n
+	}
+	t.freedBytes += result.Report.FreedBytes
+	t.scanned += int64(result.Report.Scanned)
+}
+
+// handleMetrics 以 Prometheus 文本格式输出运行状态、累计统计和最近一次任务的结果
+func (p *program) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	h, healthy := p.health()
+	p.mu.Lock()
+	totals, running, paused, degraded := p.totals, p.running, p.paused, p.degraded()
+	var last *runResult
+	if n := len(p.history); n > 0 {
+		last = p.history[n-1]
+	}
+	p.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metric := func(name, typ, help string, value interface{}, labels ...string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s%s %v\n", name, help, name, typ, name, strings.Join(labels, ""), value)
+	}
+	metric("cleanlog_up", "gauge", "1 if the service is healthy.", boolMetric(healthy))
+	metric("cleanlog_running", "gauge", "1 while a run is in progress.", boolMetric(running))
+	metric("cleanlog_paused", "gauge", "1 if scheduled runs are paused.", boolMetric(paused))
+	metric("cleanlog_degraded", "gauge", "1 if the last run had more failures than alert_on_failures.", boolMetric(degraded))
+	metric("cleanlog_health", "gauge", "Health from recent runs: 0 healthy, 1 degraded, 2 failing.", healthValue(h.Health))
+	metric("cleanlog_runs_total", "counter", "Runs completed since start.", totals.runs)
+	metric("cleanlog_run_errors_total", "counter", "Runs that ended with an error since start.", totals.failedRuns)
+	metric("cleanlog_scanned_files_total", "counter", "Files scanned since start.", totals.scanned)
+	metric("cleanlog_deleted_files_total", "counter", "Files deleted since start.", totals.deleted)
+	metric("cleanlog_failed_files_total", "counter", "Files that failed to be processed since start.", totals.failed)
+	metric("cleanlog_av_contention_files_total", "counter", "Files denied access while deleting, usually held by antivirus or indexing, since start.", totals.avContention)
+	fmt.Fprintf(w, "# HELP cleanlog_failed_files_by_category_total Failed and transient files by cause since start.\n# TYPE cleanlog_failed_files_by_category_total counter\n")
+	for _, c := range cleaner.ErrorCategories {
+		fmt.Fprintf(w, "cleanlog_failed_files_by_category_total{category=\"%s\"} %d\n", c, totals.failedBy[c])
+	}
+	metric("cleanlog_verification_failed_files_total", "counter", "Expired files still present after a run with no skip or failure reason (verify_after_run), since start.", totals.unverified)
+	metric("cleanlog_freed_bytes_total", "counter", "Bytes freed since start.", totals.freedBytes)
+	if last == nil {
+		return
+	}
+	// 最近一次任务的指标带上 run_id 标签，便于与日志对应
+	run := fmt.Sprintf("{run_id=\"%s\"}", labelEscaper.Replace(last.ID))
+	metric("cleanlog_last_run_timestamp_seconds", "gauge", "Start time of the last run.", last.Start.Unix(), run)
+	metric("cleanlog_last_run_duration_seconds", "gauge", "Duration of the last run.", strconv.FormatFloat(last.Duration, 'f', 3, 64), run)
+	writeDirMetric(w, "cleanlog_last_run_dir_freed_bytes", "Bytes freed per directory in the last run.", last, func(d cleaner.DirReport) int64 { return d.FreedBytes })
+	writeDirMetric(w, "cleanlog_last_run_dir_failed_files", "Failed files per directory in the last run.", last, func(d cleaner.DirReport) int64 { return int64(d.Failed) })
+	writeDirMetric(w, "cleanlog_last_run_dir_verification_failed_files", "Expired files still present per directory after the last run.", last, func(d cleaner.DirReport) int64 { return int64(d.Unverified) })
+}
+
+// writeDirMetric 按目录输出最近一次任务的一项统计，目录作为 dir 标签
+func writeDirMetric(w io.Writer, name, help string, last *runResult, value func(cleaner.DirReport) int64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	for _, d := range last.Report.Directories {
+		fmt.Fprintf(w, "%s{dir=\"%s\",run_id=\"%s\"} %d\n", name, labelEscaper.Replace(d.Path), labelEscaper.Replace(last.ID), value(d))
+	}
+}
+
+// labelEscaper 按 Prometheus 文本格式转义标签值
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func boolMetric(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// NotifyConfig 告警通知的发送方式。webhook 和 email 为原有的两种方式，相当于未配置 on 的同类渠道；
+// channels 中可以配置任意多个渠道，并按 on 选择发送的事件
+type NotifyConfig struct {
+	Webhook  string          `yaml:"webhook" mapstructure:"webhook"` // 以 JSON POST 通知内容的地址
+	Email    *EmailConfig    `yaml:"email" mapstructure:"email"`
+	Channels []ChannelConfig `yaml:"channels" mapstructure:"channels"`
+}
+
+// EmailConfig 通过 SMTP 发送邮件通知
+type EmailConfig struct {
+	SMTP     string   `yaml:"smtp" mapstructure:"smtp"` // 如 smtp.example.com:587，服务器支持时使用 STARTTLS
+	Username string   `yaml:"username" mapstructure:"username"`
+	Password string   `yaml:"password" mapstructure:"password"`
+	From     string   `yaml:"from" mapstructure:"from"`
+	To       []string `yaml:"to" mapstructure:"to"`
+}
+
+// ChannelConfig 一个通知渠道
+type ChannelConfig struct {
+	Type    string        `yaml:"type" mapstructure:"type"`       // webhook、email、slack、dingtalk、wecom 或 exec
+	Name    string        `yaml:"name" mapstructure:"name"`       // 日志中显示的名称，默认为 type
+	URL     string        `yaml:"url" mapstructure:"url"`         // webhook、slack、dingtalk、wecom 的地址
+	Secret  string        `yaml:"secret" mapstructure:"secret"`   // 钉钉机器人的加签密钥
+	Email   *EmailConfig  `yaml:"email" mapstructure:"email"`     // type 为 email 时的 SMTP 设置
+	Command []string      `yaml:"command" mapstructure:"command"` // type 为 exec 时执行的命令及参数，通知内容以 JSON 写入标准输入
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"` // exec 命令的最长执行时间，默认 30s
+	// On 发送的事件：success（任务成功）、failure（任务出错、panic、卡住、长时间未成功）、threshold（超过阈值的告警）、
+	// all 或具体的事件名；未配置时发送除 run_success、run_failed 之外的全部事件，与原有的 webhook、email 相同
+	On []string `yaml:"on" mapstructure:"on"`
+}
+
+// notifier 通知渠道的实现。新增渠道只需实现 send 并在 notifierTypes 中注册，清理引擎和发送通知的调用方不需要修改
+type notifier interface {
+	send(n notification) error
+}
+
+// notifierTypes 各渠道类型的构造函数，配置不完整时返回错误
+var notifierTypes = map[string]func(c ChannelConfig) (notifier, error){
+	"webhook":  newWebhookNotifier,
+	"email":    newEmailNotifier,
+	"slack":    newSlackNotifier,
+	"dingtalk": newDingTalkNotifier,
+	"wecom":    newWeComNotifier,
+	"exec":     newExecNotifier,
+}
+
+// 事件类别，用于渠道的 on
+const (
+	eventSuccess   = "success"
+	eventFailure   = "failure"
+	eventThreshold = "threshold"
+)
+
+// eventClasses 各事件所属的类别，未列出的事件（owner_report、digest）只按事件名或 all 匹配
+var eventClasses = map[string]string{
+	"run_success": eventSuccess,
+	"run_failed":  eventFailure,
+	"panic":       eventFailure,
+	"stuck":       eventFailure,
+	"missed":      eventFailure,
+	"dir_aborted": eventFailure,
+	"failures":    eventThreshold,
+	"bytes_cap":   eventThreshold,
+	"disk_space":  eventThreshold,
+	"disk_trend":  eventThreshold,
+}
+
+// runEvents 每次任务结束都可能发送的事件，未配置 on 的渠道不发送
+var runEvents = map[string]bool{"run_success": true, "run_failed": true}
+
+// wants 渠道是否发送该事件
+func (c ChannelConfig) wants(event string) bool {
+	if len(c.On) == 0 {
+		return !runEvents[event]
+	}
+	for _, on := range c.On {
+		if on == "all" || on == event || on == eventClasses[event] {
+			return true
+		}
+	}
+	return false
+}
+
+// channels 返回全部渠道：原有的 webhook、email 在前
+func (c *NotifyConfig) channels() []ChannelConfig {
+	if c == nil {
+		return nil
+	}
+	var chans []ChannelConfig
+	if c.Webhook != "" {
+		chans = append(chans, ChannelConfig{Type: "webhook", URL: c.Webhook})
+	}
+	if c.Email != nil && c.Email.SMTP != "" {
+		chans = append(chans, ChannelConfig{Type: "email", Email: c.Email})
+	}
+	return append(chans, c.Channels...)
+}
+
+// validate 检查各渠道的类型和必需的设置，key 为配置中的位置，如 notify
+func (c *NotifyConfig) validate(key string) error {
+	for i, ch := range c.channels() {
+		if _, err := ch.notifier(); err != nil {
+			return i18n.Errorf("%s 的第 %d 个通知渠道: %w", key, i+1, err)
+		}
+		for _, on := range ch.On {
+			if !knownEvent(on) {
+				return i18n.Errorf("%s 的第 %d 个通知渠道: 未知的事件 %q", key, i+1, on)
+			}
+		}
+	}
+	return nil
+}
+
+// knownEvent on 中的项是否为 all、事件类别或已知的事件名
+func knownEvent(on string) bool {
+	if _, ok := eventClasses[on]; ok {
+		return true
+	}
+	switch on {
+	case "all", eventSuccess, eventFailure, eventThreshold, "owner_report", "digest":
+		return true
+	}
+	return false
+}
+
+func (c ChannelConfig) notifier() (notifier, error) {
+	newNotifier, ok := notifierTypes[c.Type]
+	if !ok {
+		types := make([]string, 0, len(notifierTypes))
+		for t := range notifierTypes {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		return nil, i18n.Errorf("未知的通知渠道类型 %q，可选 %s", c.Type, strings.Join(types, "、"))
+	}
+	return newNotifier(c)
+}
+
+func (c ChannelConfig) label() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Type
+}
+
+// notification webhook 收到的内容
+type notification struct {
+	Event   string      `json:"event"`
+	Title   string      `json:"title"`
+	Message string      `json:"message"`
+	Service string      `json:"service"`
+	RunID   string      `json:"run_id,omitempty"` // 任务期间发送的通知所属任务的 ID
+	Group   string      `json:"group,omitempty"`  // 任务所属的策略组
+	Host    string      `json:"host"`
+	Time    time.Time   `json:"time"`
+	Details interface{} `json:"details,omitempty"` // 事件相关的结构化数据
+}
+
+var notifyClient = &http.Client{Timeout: 10 * time.Second}
+
+// notify 记录日志并按配置发送通知，发送失败只记录日志
+func (p *program) notify(event, title, message string) {
+	p.notifyDetails(event, title, message, nil)
+}
+
+// notifyDetails 同 notify，details 放在 webhook 内容的 details 字段中
+func (p *program) notifyDetails(event, title, message string, details interface{}) {
+	p.logger.Printf("%s: %s", title, message)
+	p.dispatch(event, title, message, details)
+}
+
+// dispatch 把通知发送到全局和当前策略组的渠道，不记录日志
+func (p *program) dispatch(event, title, message string, details interface{}) {
+	p.mu.Lock()
+	cfg, group := p.config.Notify, p.runGroup
+	var groupCfg *NotifyConfig
+	if g := p.config.group(group); g != nil {
+		groupCfg = g.Notify
+	}
+	p.mu.Unlock()
+	if cfg == nil && groupCfg == nil {
+		return
+	}
+	n := p.newNotification(event, title, message, details)
+	n.Group = group
+	p.send(cfg, n)
+	p.send(groupCfg, n)
+}
+
+func (p *program) newNotification(event, title, message string, details interface{}) notification {
+	host, _ := os.Hostname()
+	return notification{Event: event, Title: title, Message: message, Service: p.name, RunID: p.currentRunID(), Host: host, Time: time.Now(), Details: details}
+}
+
+// send 把通知发送到 cfg 中按 on 选择了该事件的渠道，发送失败只记录日志
+func (p *program) send(cfg *NotifyConfig, n notification) {
+	for _, ch := range cfg.channels() {
+		if !ch.wants(n.Event) {
+			continue
+		}
+		s, err := ch.notifier()
+		if err == nil {
+			err = s.send(n)
+		}
+		if err != nil {
+			p.logger.Printf(i18n.T("发送 %s 通知失败: %s"), ch.label(), err)
+		}
+	}
+}
+
+// webhookNotifier 以 JSON POST 完整的通知内容
+type webhookNotifier struct{ url string }
+
+func newWebhookNotifier(c ChannelConfig) (notifier, error) {
+	if c.URL == "" {
+		return nil, i18n.Errorf("%s 渠道需要配置 url", c.Type)
+	}
+	return webhookNotifier{url: c.URL}, nil
+}
+
+func (w webhookNotifier) send(n notification) error {
+	return postJSON(w.url, n)
+}
+
+// postJSON 以 JSON POST body，非 2xx 响应返回错误
+func postJSON(url string, body interface{}) error {
+	_, err := postJSONResponse(url, body)
+	return err
+}
+
+// postJSONResponse 同 postJSON，返回响应内容供检查机器人接口的错误码
+func postJSONResponse(url string, body interface{}) ([]byte, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := notifyClient.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s: %s", redactURL(url), resp.Status)
+	}
+	return buf.Bytes(), nil
+}
+
+// redactURL 去掉地址中的查询参数，机器人的 access_token 通常放在这里
+func redactURL(url string) string {
+	if i := strings.IndexByte(url, '?'); i >= 0 {
+		return url[:i]
+	}
+	return url
+}
+
+// emailNotifier 通过 SMTP 发送邮件
+type emailNotifier struct{ cfg *EmailConfig }
+
+func newEmailNotifier(c ChannelConfig) (notifier, error) {
+	if c.Email == nil || c.Email.SMTP == "" || len(c.Email.To) == 0 {
+		return nil, i18n.Errorf("email 渠道需要配置 email.smtp 和 email.to")
+	}
+	return emailNotifier{cfg: c.Email}, nil
+}
+
+func (e emailNotifier) send(n notification) error {
+	cfg := e.cfg
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		host, _, err := net.SplitHostPort(cfg.SMTP)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [%s@%s] %s\r\n", n.Service, n.Host, n.Title)
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\n%s\r\n", n.Message, displayTime(n.Time))
+	if n.RunID != "" {
+		fmt.Fprintf(&msg, "run: %s\r\n", n.RunID)
+	}
+	return smtp.SendMail(cfg.SMTP, auth, cfg.From, cfg.To, []byte(msg.String()))
+}
+
+// summary 聊天机器人消息的标题行，包含服务名和主机名
+func (n notification) summary() string {
+	return fmt.Sprintf("[%s@%s] %s", n.Service, n.Host, n.Title)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+const defaultExecTimeout = 30 * time.Second
+
+// slackNotifier Slack incoming webhook
+type slackNotifier struct{ url string }
+
+func newSlackNotifier(c ChannelConfig) (notifier, error) {
+	if c.URL == "" {
+		return nil, i18n.Errorf("%s 渠道需要配置 url", c.Type)
+	}
+	return slackNotifier{url: c.URL}, nil
+}
+
+func (s slackNotifier) send(n notification) error {
+	return postJSON(s.url, map[string]string{"text": "*" + n.summary() + "*\n" + n.Message})
+}
+
+// robotResult 钉钉、企业微信机器人接口的返回，HTTP 200 时以 errcode 表示是否成功
+type robotResult struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func postRobot(url string, body interface{}) error {
+	data, err := postJSONResponse(url, body)
+	if err != nil {
+		return err
+	}
+	var r robotResult
+	if err := json.Unmarshal(data, &r); err == nil && r.ErrCode != 0 {
+		return fmt.Errorf("%s: errcode %d: %s", redactURL(url), r.ErrCode, r.ErrMsg)
+	}
+	return nil
+}
+
+// dingTalkNotifier 钉钉自定义机器人，配置了 secret 时按加签方式附加 timestamp 和 sign
+type dingTalkNotifier struct{ url, secret string }
+
+func newDingTalkNotifier(c ChannelConfig) (notifier, error) {
+	if c.URL == "" {
+		return nil, i18n.Errorf("%s 渠道需要配置 url", c.Type)
+	}
+	return dingTalkNotifier{url: c.URL, secret: c.Secret}, nil
+}
+
+func (d dingTalkNotifier) send(n notification) error {
+	target := d.url
+	if d.secret != "" {
+		ts := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(d.secret))
+		mac.Write([]byte(ts + "\n" + d.secret))
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + "timestamp=" + ts + "&sign=" + url.QueryEscape(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	}
+	return postRobot(target, map[string]interface{}{
+		"msgtype":  "markdown",
+		"markdown": map[string]string{"title": n.Title, "text": "### " + n.summary() + "\n\n" + n.Message},
+	})
+}
+
+// weComNotifier 企业微信群机器人
+type weComNotifier struct{ url string }
+
+func newWeComNotifier(c ChannelConfig) (notifier, error) {
+	if c.URL == "" {
+		return nil, i18n.Errorf("%s 渠道需要配置 url", c.Type)
+	}
+	return weComNotifier{url: c.URL}, nil
+}
+
+func (w weComNotifier) send(n notification) error {
+	return postRobot(w.url, map[string]interface{}{
+		"msgtype":  "markdown",
+		"markdown": map[string]string{"content": "**" + n.summary() + "**\n" + n.Message},
+	})
+}
+
+// execNotifier 执行命令，通知内容以 JSON 写入标准输入，事件、标题和消息同时通过环境变量传入，
+// 用于对接短信网关、工单系统等没有内置的渠道
+type execNotifier struct {
+	command []string
+	timeout time.Duration
+}
+
+func newExecNotifier(c ChannelConfig) (notifier, error) {
+	if len(c.Command) == 0 || c.Command[0] == "" {
+		return nil, i18n.Errorf("exec 渠道需要配置 command")
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	return execNotifier{command: c.Command, timeout: timeout}, nil
+}
+
+func (e execNotifier) send(n notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, e.command[0], e.command[1:]...)
+	cmd.Stdin = strings.NewReader(string(data))
+	cmd.Env = append(os.Environ(),
+		"CLEANLOG_EVENT="+n.Event,
+		"CLEANLOG_TITLE="+n.Title,
+		"CLEANLOG_MESSAGE="+n.Message,
+		"CLEANLOG_SERVICE="+n.Service,
+		"CLEANLOG_RUN_ID="+n.RunID)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+// --once 的退出码
+const (
+	exitOK          = 0
+	exitFailures    = 2 // 任务完成但有文件处理失败，或任务中途失败
+	exitConfigError = 3
+)
+
+// once 加载配置并执行一次任务，摘要输出到标准输出，返回退出码
+func (p *program) once(configFilePath string) int {
+	config, err := p.loadConfig(configFilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("加载配置文件时发生错误: %s")+"\n", err)
+		return exitConfigError
+	}
+	p.configPath = configFilePath
+	p.config = config
+	p.cleaner = cleaner.New(config.Config)
+	p.loadHistory()
+	p.loadHolds()
+	p.syncCanary(config)
+
+	// Ctrl-C 时中断当前任务
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		p.cancel()
+	}()
+	result, _ := p.runOnce()
+	printSummary(os.Stdout, result)
+	if result.Error != "" || result.Report.Failed > 0 {
+		return exitFailures
+	}
+	return exitOK
+}
+
+// printSummary 输出便于阅读的任务摘要
+func printSummary(w io.Writer, result *runResult) {
+	r := result.Report
+	if result.ID != "" {
+		fmt.Fprintf(w, i18n.T("任务 %s")+"\n", result.ID)
+	}
+	if r.Frozen != "" {
+		fmt.Fprintf(w, i18n.T("处于保留例外期 %s，未处理任何文件")+"\n", r.Frozen)
+	}
+	for _, d := range r.Directories {
+		if d.OnHold {
+			fmt.Fprintf(w, i18n.T("目录 %s: 处于保留状态，未处理")+"\n", d.Path)
+			continue
+		}
+		if d.Canary {
+			fmt.Fprintf(w, i18n.T("目录 %s: canary 模式，到期 %d 个（%s），未处理")+"\n", d.Path, d.Matched, cleaner.ByteSize(d.WouldFree))
+			continue
+		}
+		if d.Unchanged > 0 && d.Scanned == 0 {
+			fmt.Fprintf(w, i18n.T("目录 %s: 自上次扫描后没有变化，跳过")+"\n", d.Path)
+			continue
+		}
+		fmt.Fprintf(w, i18n.T("目录 %s: 扫描 %d，匹配 %d，删除 %d，跳过 %d，失败 %d，释放 %s")+"\n",
+			d.Path, d.Scanned, d.Matched, d.Deleted, d.Skipped(), d.Failed, cleaner.ByteSize(d.FreedBytes))
+		if d.Aborted != "" {
+			fmt.Fprintf(w, i18n.T("  %s，已停止处理该目录")+"\n", d.Aborted)
+		}
+		if d.TimedOut {
+			fmt.Fprintln(w, i18n.T("  处理超时，已停止处理该目录"))
+		}
+		if d.Top != nil {
+			for _, f := range d.Top.Files {
+				fmt.Fprintf(w, "  %10s  %s\n", cleaner.ByteSize(f.Size), f.Path)
+			}
+			for _, s := range d.Top.Dirs {
+				fmt.Fprintf(w, "  %10s  %s%c\n", cleaner.ByteSize(s.Size), s.Path, filepath.Separator)
+			}
+		}
+		if d.Unverified > 0 {
+			fmt.Fprintf(w, i18n.T("  校验失败 %d 个：任务结束后仍然存在、未记录原因的到期文件")+"\n", d.Unverified)
+			for _, f := range d.Discrepancies {
+				fmt.Fprintf(w, "  %s\n", f.Path)
+			}
+		}
+	}
+	for _, t := range r.Tables {
+		fmt.Fprintf(w, i18n.T("数据库表 %s: 删除 %d 行")+"\n", t.Table, t.Deleted)
+	}
+	for _, e := range r.Elasticsearch {
+		if e.DryRun {
+			fmt.Fprintf(w, i18n.T("Elasticsearch 索引 %s: dry_run，到期 %d 个")+"\n", e.Index, len(e.Indices))
+		} else {
+			fmt.Fprintf(w, i18n.T("Elasticsearch 索引 %s: %s %d 个，释放 %s")+"\n", e.Index, e.Action, len(e.Indices), cleaner.ByteSize(e.FreedBytes))
+		}
+	}
+	fmt.Fprintf(w, i18n.T("合计: 扫描 %d，删除 %d，失败 %d，释放 %s，耗时 %.1fs")+"\n",
+		r.Scanned, r.Deleted, r.Failed, cleaner.ByteSize(r.FreedBytes), result.Duration)
+	if len(r.Errors) > 0 {
+		fmt.Fprintf(w, i18n.T("失败原因: %s")+"\n", cleaner.DescribeErrors(r.Errors))
+	}
+	if result.Error != "" {
+		fmt.Fprintf(w, i18n.T("清理任务失败: %s")+"\n", result.Error)
+	}
+}
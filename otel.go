@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+const defaultOTelTimeout = 10 * time.Second
+
+// OTelConfig 以 OTLP/HTTP（JSON 编码）导出任务的 trace 和指标，可直接发送到 OpenTelemetry Collector 或兼容的后端。
+// 每次任务一个 span，各目录为子 span；指标为服务启动以来的累计值
+type OTelConfig struct {
+	Endpoint    string            `yaml:"endpoint" mapstructure:"endpoint"`         // 如 http://otel-collector:4318，未配置时使用环境变量 OTEL_EXPORTER_OTLP_ENDPOINT
+	Headers     map[string]string `yaml:"headers" mapstructure:"headers"`           // 附加的请求头，如认证令牌；同时读取 OTEL_EXPORTER_OTLP_HEADERS（k1=v1,k2=v2）
+	ServiceName string            `yaml:"service_name" mapstructure:"service_name"` // 默认为环境变量 OTEL_SERVICE_NAME，再次为服务名称
+	Timeout     time.Duration     `yaml:"timeout" mapstructure:"timeout"`           // 单次导出的超时，默认 10s
+}
+
+// OTLP JSON 编码中用到的结构，字段名与 opentelemetry-proto 的 JSON 映射一致：
+// 64 位整数和纳秒时间戳写成字符串，traceId、spanId 写成十六进制
+type (
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+	otlpAttr struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttr `json:"attributes"`
+	}
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"` // 2 为 ERROR
+		Message string `json:"message,omitempty"`
+	}
+	otlpSpan struct {
+		TraceID      string     `json:"traceId"`
+		SpanID       string     `json:"spanId"`
+		ParentSpanID string     `json:"parentSpanId,omitempty"`
+		Name         string     `json:"name"`
+		Kind         int        `json:"kind"` // 1 为 INTERNAL
+		Start        string     `json:"startTimeUnixNano"`
+		End          string     `json:"endTimeUnixNano"`
+		Attributes   []otlpAttr `json:"attributes"`
+		Status       otlpStatus `json:"status"`
+	}
+	otlpPoint struct {
+		Attributes []otlpAttr `json:"attributes,omitempty"`
+		Start      string     `json:"startTimeUnixNano,omitempty"`
+		Time       string     `json:"timeUnixNano"`
+		AsInt      *string    `json:"asInt,omitempty"`
+		AsDouble   *float64   `json:"asDouble,omitempty"`
+	}
+	otlpSum struct {
+		DataPoints  []otlpPoint `json:"dataPoints"`
+		Temporality int         `json:"aggregationTemporality"` // 2 为 CUMULATIVE
+		Monotonic   bool        `json:"isMonotonic"`
+	}
+	otlpGauge struct {
+		DataPoints []otlpPoint `json:"dataPoints"`
+	}
+	otlpMetric struct {
+		Name        string     `json:"name"`
+		Description string     `json:"description,omitempty"`
+		Unit        string     `json:"unit,omitempty"`
+		Sum         *otlpSum   `json:"sum,omitempty"`
+		Gauge       *otlpGauge `json:"gauge,omitempty"`
+	}
+)
+
+func strAttr(key, v string) otlpAttr {
+	return otlpAttr{Key: key, Value: otlpValue{StringValue: &v}}
+}
+
+func intAttr(key string, v int64) otlpAttr {
+	s := strconv.FormatInt(v, 10)
+	return otlpAttr{Key: key, Value: otlpValue{IntValue: &s}}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// statsAttrs 任务和目录 span 共用的统计属性
+func statsAttrs(s cleaner.Stats) []otlpAttr {
+	return []otlpAttr{
+		intAttr("cleanlog.scanned", int64(s.Scanned)),
+		intAttr("cleanlog.matched", int64(s.Matched)),
+		intAttr("cleanlog.deleted", int64(s.Deleted)),
+		intAttr("cleanlog.failed", int64(s.Failed)),
+		intAttr("cleanlog.skipped", int64(s.Skipped())),
+		intAttr("cleanlog.freed_bytes", s.FreedBytes),
+	}
+}
+
+// runSpans 任务的 span 和各目录的子 span
+func runSpans(result *runResult) []otlpSpan {
+	traceID, runSpanID := randomHex(16), randomHex(8)
+	end := result.Start.Add(time.Duration(result.Duration * float64(time.Second)))
+	run := otlpSpan{
+		TraceID: traceID, SpanID: runSpanID, Name: "cleanlog.run", Kind: 1,
+		Start: unixNano(result.Start), End: unixNano(end),
+		Attributes: append([]otlpAttr{strAttr("cleanlog.run_id", result.ID)}, statsAttrs(result.Report.Stats)...),
+	}
+	if result.Error != "" {
+		run.Status = otlpStatus{Code: 2, Message: result.Error}
+	}
+	spans := []otlpSpan{run}
+	for _, d := range result.Report.Directories {
+		dirEnd := d.Start.Add(time.Duration(d.Duration * float64(time.Second)))
+		spans = append(spans, otlpSpan{
+			TraceID: traceID, SpanID: randomHex(8), ParentSpanID: runSpanID, Name: "cleanlog.directory", Kind: 1,
+			Start: unixNano(d.Start), End: unixNano(dirEnd),
+			Attributes: append([]otlpAttr{strAttr("cleanlog.directory", d.Path)}, statsAttrs(d.Stats)...),
+		})
+	}
+	return spans
+}
+
+// runMetrics 服务启动以来的累计指标和最近一次任务的耗时
+func runMetrics(totals runTotals, started time.Time, result *runResult, health string) []otlpMetric {
+	now, since := unixNano(time.Now()), unixNano(started)
+	sum := func(name, unit, desc string, v int64) otlpMetric {
+		s := strconv.FormatInt(v, 10)
+		return otlpMetric{Name: name, Unit: unit, Description: desc, Sum: &otlpSum{
+			DataPoints: []otlpPoint{{Start: since, Time: now, AsInt: &s}}, Temporality: 2, Monotonic: true,
+		}}
+	}
+	duration, level := result.Duration, strconv.Itoa(healthValue(health))
+	return []otlpMetric{
+		sum("cleanlog.runs", "{run}", "Runs completed since start", int64(totals.runs)),
+		sum("cleanlog.run.errors", "{run}", "Runs that ended with an error since start", int64(totals.failedRuns)),
+		sum("cleanlog.files.scanned", "{file}", "Files scanned since start", totals.scanned),
+		sum("cleanlog.files.deleted", "{file}", "Files deleted since start", int64(totals.deleted)),
+		sum("cleanlog.files.failed", "{file}", "Files that failed to be processed since start", int64(totals.failed)),
+		sum("cleanlog.freed", "By", "Bytes freed since start", totals.freedBytes),
+		{Name: "cleanlog.run.duration", Unit: "s", Description: "Duration of the last run", Gauge: &otlpGauge{
+			DataPoints: []otlpPoint{{Attributes: []otlpAttr{strAttr("cleanlog.run_id", result.ID)}, Time: now, AsDouble: &duration}},
+		}},
+		{Name: "cleanlog.health", Unit: "1", Description: "Health from recent runs: 0 healthy, 1 degraded, 2 failing", Gauge: &otlpGauge{
+			DataPoints: []otlpPoint{{Attributes: []otlpAttr{strAttr("cleanlog.health", health)}, Time: now, AsInt: &level}},
+		}},
+	}
+}
+
+// otelHeaders 合并 OTEL_EXPORTER_OTLP_HEADERS 和配置中的请求头，配置优先
+func otelHeaders(cfg *OTelConfig) map[string]string {
+	headers := map[string]string{}
+	for _, kv := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	for k, v := range cfg.Headers {
+		headers[k] = v
+	}
+	return headers
+}
+
+// exportTelemetry 任务结束后导出 trace 和指标，导出失败只记录日志
+func (p *program) exportTelemetry(result *runResult) {
+	p.mu.Lock()
+	cfg, totals, started := p.config.OTel, p.totals, p.started
+	p.mu.Unlock()
+	h, _ := p.health()
+	if cfg == nil {
+		return
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if endpoint == "" {
+		return
+	}
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		if serviceName = os.Getenv("OTEL_SERVICE_NAME"); serviceName == "" {
+			serviceName = p.name
+		}
+	}
+	host, _ := os.Hostname()
+	resource := otlpResource{Attributes: []otlpAttr{
+		strAttr("service.name", serviceName),
+		strAttr("service.version", getBuildInfo().Version),
+		strAttr("host.name", host),
+	}}
+	scope := otlpScope{Name: "cleanlogservice", Version: getBuildInfo().Version}
+	traces := map[string]interface{}{"resourceSpans": []interface{}{map[string]interface{}{
+		"resource":   resource,
+		"scopeSpans": []interface{}{map[string]interface{}{"scope": scope, "spans": runSpans(result)}},
+	}}}
+	metrics := map[string]interface{}{"resourceMetrics": []interface{}{map[string]interface{}{
+		"resource":     resource,
+		"scopeMetrics": []interface{}{map[string]interface{}{"scope": scope, "metrics": runMetrics(totals, started, result, h.Health)}},
+	}}}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultOTelTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	headers := otelHeaders(cfg)
+	base := strings.TrimSuffix(endpoint, "/")
+	for _, e := range []struct {
+		path string
+		body interface{}
+	}{{"/v1/traces", traces}, {"/v1/metrics", metrics}} {
+		if err := postOTLP(client, base+e.path, headers, e.body); err != nil {
+			p.logger.Printf(i18n.T("导出 OpenTelemetry 数据失败: %s"), err)
+		}
+	}
+}
+
+func postOTLP(client *http.Client, url string, headers map[string]string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+// ownerReport owner 通知的 details：本次任务中该负责人名下各目录的统计
+type ownerReport struct {
+	Owner       string              `json:"owner"`
+	Error       string              `json:"error,omitempty"` // 任务整体失败时的错误
+	Directories []cleaner.DirReport `json:"directories"`
+}
+
+// isWebhook owner 为 http(s) 地址时按 webhook 发送，否则视为邮件地址
+func isWebhook(owner string) bool {
+	return strings.HasPrefix(owner, "http://") || strings.HasPrefix(owner, "https://")
+}
+
+// validateOwner 校验目录的 owner；邮件地址需要 notify.email 中的 SMTP 设置
+func validateOwner(owner string, notify *NotifyConfig) error {
+	if owner == "" {
+		return nil
+	}
+	if isWebhook(owner) {
+		if u, err := url.Parse(owner); err != nil || u.Host == "" {
+			return i18n.Errorf("owner %q 不是有效的 webhook 地址", owner)
+		}
+		return nil
+	}
+	if _, err := mail.ParseAddress(owner); err != nil {
+		return i18n.Errorf("owner %q 应为邮箱或 http(s) webhook 地址", owner)
+	}
+	if notify == nil || notify.Email == nil || notify.Email.SMTP == "" {
+		return i18n.Errorf("owner %q 为邮箱，需要配置 notify.email 的 SMTP 服务器", owner)
+	}
+	return nil
+}
+
+// ownerChannel 生成发给 owner 的通知方式，邮件沿用 notify.email 的服务器和发件人
+func ownerChannel(owner string, global *NotifyConfig) *NotifyConfig {
+	if isWebhook(owner) {
+		return &NotifyConfig{Webhook: owner}
+	}
+	email := *global.Email
+	email.To = []string{owner}
+	return &NotifyConfig{Email: &email}
+}
+
+// notifyOwners 任务结束后把配置了 owner 的目录的结果发给各自的负责人，全局通知照常发送。
+// 没有处理任何文件也没有失败的目录不通知，避免每次任务都发送
+func (p *program) notifyOwners(result *runResult) {
+	p.mu.Lock()
+	global := p.config.Notify
+	p.mu.Unlock()
+	byOwner := map[string][]cleaner.DirReport{}
+	for _, d := range result.Report.Directories {
+		if d.Owner == "" || d.Matched+d.Deleted+d.DeletedDirs+d.Truncated+d.Failed == 0 {
+			continue
+		}
+		byOwner[d.Owner] = append(byOwner[d.Owner], d)
+	}
+	owners := make([]string, 0, len(byOwner))
+	for owner := range byOwner {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+	for _, owner := range owners {
+		dirs := byOwner[owner]
+		var failed int
+		var lines []string
+		for _, d := range dirs {
+			failed += d.Failed
+			lines = append(lines, i18n.Sprintf("%s: 删除 %d，失败 %d，释放 %s", d.Path, d.Deleted+d.DeletedDirs, d.Failed, cleaner.ByteSize(d.FreedBytes)))
+		}
+		title := i18n.T("日志目录已清理")
+		if failed > 0 || result.Error != "" {
+			title = i18n.T("日志目录清理失败")
+		}
+		msg := strings.Join(lines, "\n")
+		if result.Error != "" {
+			msg += "\n" + i18n.Sprintf("任务出错: %s", result.Error)
+		}
+		p.logger.Printf(i18n.T("通知目录负责人 %s: %s"), owner, title)
+		if !isWebhook(owner) && (global == nil || global.Email == nil) {
+			continue
+		}
+		n := p.newNotification("owner_report", title, msg, ownerReport{Owner: owner, Error: result.Error, Directories: dirs})
+		n.RunID, n.Group = result.ID, result.Group
+		p.send(ownerChannel(owner, global), n)
+	}
+}
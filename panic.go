@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+// schedulerRestartDelay 调度器意外退出后等待多久重启
+const schedulerRestartDelay = 10 * time.Second
+
+// runCleaner 执行清理任务，任务中的 panic 记录堆栈并发送通知后作为任务错误返回，不影响调度器和之后的任务
+func (p *program) runCleaner(ctx context.Context, cl *cleaner.Cleaner) (report cleaner.Report, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = p.reportPanic(i18n.T("清理任务"), r)
+		}
+	}()
+	return cl.Run(ctx)
+}
+
+// reportPanic 记录 panic 的堆栈并通过告警渠道通知，返回描述该 panic 的错误
+func (p *program) reportPanic(where string, r interface{}) error {
+	err := i18n.Errorf("%s 发生 panic: %v", where, r)
+	p.logger.Printf("%s\n%s", err, debug.Stack())
+	p.notify("panic", i18n.T("清理服务发生 panic"), err.Error())
+	return err
+}
+
+// recoverPanic 在 defer 中调用，恢复 where 中的 panic 并报告
+func (p *program) recoverPanic(where string) {
+	if r := recover(); r != nil {
+		p.reportPanic(where, r)
+	}
+}
+
+// supervise 运行调度器，服务停止前调度器因 panic 或错误退出时，配置了 restart_scheduler 则等待后重启
+func (p *program) supervise() {
+	restarted := false
+	for {
+		func() {
+			defer p.recoverPanic(i18n.T("调度器"))
+			p.run(restarted)
+		}()
+		select {
+		case <-p.exit:
+			return
+		default:
+		}
+		p.mu.Lock()
+		restart := p.config.RestartScheduler
+		p.mu.Unlock()
+		if !restart {
+			p.logger.Printf(i18n.T("调度器意外退出，定时任务已停止"))
+			return
+		}
+		p.logger.Printf(i18n.T("调度器意外退出，%s 后重启"), schedulerRestartDelay)
+		select {
+		case <-time.After(schedulerRestartDelay):
+		case <-p.exit:
+			return
+		}
+		restarted = true
+	}
+}
@@ -0,0 +1,131 @@
+package cleaner
+
+import (
+	"errors"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+func init() {
+	RegisterAction(actionDelete, func(t Tier) (Action, error) { return newDeleteAction(t.DeleteMode, t.ShredPasses) })
+	RegisterAction(actionCompress, func(t Tier) (Action, error) { return compressAction{}, nil })
+	RegisterAction(actionArchive, func(t Tier) (Action, error) {
+		if t.ArchiveDir == "" && t.Upload == "" {
+			return nil, errors.New(i18n.T("archive 缺少 archive_dir 或 upload"))
+		}
+		a := archiveAction{dir: t.ArchiveDir, verify: t.Verify, backlog: t.UploadBacklog}
+		if t.Upload != "" {
+			up, err := openUploader(t)
+			if err != nil {
+				return nil, err
+			}
+			a.upload = up
+		}
+		return a, nil
+	})
+	RegisterAction(actionTruncate, func(t Tier) (Action, error) { return truncateAction{keep: int64(t.KeepSize)}, nil })
+}
+
+// deleteAction 删除文件，shred 大于 0 时先覆写文件内容，soft 为 rename、trash 时只改名（软删除）
+type deleteAction struct {
+	shred int // 覆写次数
+	soft  string
+}
+
+func (deleteAction) Name() string      { return actionDelete }
+func (deleteAction) RemovesFile() bool { return true }
+
+func (a deleteAction) Apply(f *File) (Result, error) {
+	if a.soft != "" {
+		return softDelete(f, a.soft == deleteModeTrash)
+	}
+	size := f.Info.Size()
+	if a.shred > 0 {
+		if err := shredFile(f.FS, f.Path, size, a.shred); err != nil {
+			return Result{}, err
+		}
+	}
+	if err := f.FS.Remove(f.Path); err != nil {
+		return Result{}, err
+	}
+	return Result{Freed: size, Removed: true}, nil
+}
+
+// compressAction 原地 gzip 压缩，已压缩的文件跳过
+type compressAction struct{}
+
+func (compressAction) Name() string { return actionCompress }
+
+func (compressAction) Apply(f *File) (Result, error) {
+	if isCompressed(f.Path) {
+		return Result{}, errSkipped
+	}
+	size, err := gzipFile(f.FS, f.Path, f.Info)
+	if err != nil {
+		return Result{}, err
+	}
+	freed := f.Info.Size() - size
+	f.Path += ".gz"
+	if info, err := f.FS.Stat(f.Path); err == nil {
+		f.Info = info
+	}
+	return Result{Freed: freed}, nil
+}
+
+// archiveAction 移动到归档目录；配置了 upload 时先上传，确认成功后再归档或删除本地文件
+type archiveAction struct {
+	dir     string
+	verify  bool
+	upload  uploader
+	backlog string // 上传失败的文件移入的目录
+}
+
+func (archiveAction) Name() string { return actionArchive }
+
+func (a archiveAction) Apply(f *File) (Result, error) {
+	if a.upload != nil {
+		if err := a.uploadFile(f); err != nil {
+			return Result{}, a.toBacklog(f, err)
+		}
+	}
+	return a.store(f)
+}
+
+// store 保存到本地归档目录，只上传不归档时删除原文件
+func (a archiveAction) store(f *File) (Result, error) {
+	if a.dir == "" {
+		if err := f.FS.Remove(f.Path); err != nil {
+			return Result{}, err
+		}
+		return Result{Freed: f.Info.Size(), Removed: true}, nil
+	}
+	archive := archiveFile
+	if a.verify {
+		archive = archiveVerified
+	}
+	if _, err := archive(f.FS, f.Path, a.dir); err != nil {
+		return Result{}, err
+	}
+	return Result{Removed: true}, nil
+}
+
+// truncateAction 截断到只保留末尾 keep 字节
+type truncateAction struct {
+	keep int64
+}
+
+func (truncateAction) Name() string             { return actionTruncate }
+func (truncateAction) HandlesActiveFiles() bool { return true }
+
+func (t truncateAction) Apply(f *File) (Result, error) {
+	if isCompressed(f.Path) || f.Info.Size() <= t.keep {
+		return Result{}, errSkipped
+	}
+	if err := truncateFile(f.FS, f.Path, f.Info.Size(), t.keep); err != nil {
+		return Result{}, err
+	}
+	return Result{Freed: f.Info.Size() - t.keep}, nil
+}
+
+// errSkipped 动作判断无需处理该文件时返回，不计入失败
+var errSkipped = errors.New("skipped")
@@ -0,0 +1,49 @@
+package cleaner
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+const defaultAVDelay = 500 * time.Millisecond
+
+// isAccessDenied 判断是否为删除时的拒绝访问（Windows ERROR_ACCESS_DENIED）。只读属性导致的拒绝访问
+// os.Remove 已经处理，其余多数是杀毒软件或索引服务在删除的同时打开了文件，通常几百毫秒内释放
+func isAccessDenied(err error) bool {
+	var errno syscall.Errno
+	return errors.As(err, &errno) && accessDeniedErrno(errno)
+}
+
+// avRetry 杀毒软件、索引服务造成的拒绝访问按 av_delay 退避原地重试 av_retries 次；
+// 仍被拒绝且开启了 av_defer_delete 时以关闭后删除的方式打开文件，由系统在其他进程关闭句柄后删除。
+// 遇到这类错误的文件计入 AVContention，延迟删除的文件另外计入 AVDeferred
+func (cl *Cleaner) avRetry(action Action, f *File, orig File, res Result, err error, stats *Stats) (Result, error) {
+	if cl.config.AVRetries <= 0 || !isOsFs(orig.FS) || !isAccessDenied(err) {
+		return res, err
+	}
+	stats.AVContention++
+	delay := cl.config.AVDelay
+	if delay <= 0 {
+		delay = defaultAVDelay
+	}
+	for i := 0; i < cl.config.AVRetries && err != nil && isAccessDenied(err); i++ {
+		cl.debugf("%s %s 被拒绝访问（可能被杀毒软件或索引服务占用），%s 后重试", action.Name(), orig.Path, delay)
+		time.Sleep(delay)
+		delay *= 2
+		*f = orig
+		res, err = action.Apply(f)
+	}
+	a, ok := action.(deleteAction)
+	if err == nil || !isAccessDenied(err) || !cl.config.AVDeferDelete || !ok || a.soft != "" || a.shred > 0 {
+		return res, err
+	}
+	*f = orig
+	if derr := deleteOnClose(orig.Path); derr != nil {
+		cl.debugf("%s 关闭后删除失败: %s", orig.Path, derr)
+		return res, err
+	}
+	cl.warnf("%s 持续被拒绝访问，已改为关闭后删除，由系统在其他进程释放文件后删除", orig.Path)
+	stats.AVDeferred++
+	return Result{Freed: orig.Info.Size(), Removed: true}, nil
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package cleaner
+
+import "syscall"
+
+// 其他系统上删除同一目录中的文件不受其他进程打开文件的影响，拒绝访问都是真实的权限问题
+func accessDeniedErrno(errno syscall.Errno) bool {
+	return false
+}
+
+func deleteOnClose(path string) error {
+	return syscall.ENOTSUP
+}
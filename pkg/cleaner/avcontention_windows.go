@@ -0,0 +1,26 @@
+package cleaner
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+func accessDeniedErrno(errno syscall.Errno) bool {
+	return errno == windows.ERROR_ACCESS_DENIED
+}
+
+// deleteOnClose 以 FILE_FLAG_DELETE_ON_CLOSE 打开文件并立即关闭，文件进入删除挂起状态，
+// 其他进程（如杀毒软件）关闭句柄后由系统删除
+func deleteOnClose(path string) error {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	h, err := windows.CreateFile(p, windows.DELETE, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_DELETE_ON_CLOSE|windows.FILE_FLAG_OPEN_REPARSE_POINT, 0)
+	if err != nil {
+		return err
+	}
+	return windows.CloseHandle(h)
+}
@@ -0,0 +1,71 @@
+package cleaner
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// batcher 分批删除：每删除 size 个文件暂停 pause，避免一次清理大量小文件时
+// NTFS 元数据更新和杀毒软件扫描造成磁盘负载尖峰
+type batcher struct {
+	size  int
+	pause time.Duration
+	n     int
+	sync  func() // 机械硬盘上每批结束后同步目录，让元数据写入在暂停期间完成，而不是堆积到之后的批次
+}
+
+// batcher 按目录所在卷的类型分批：SSD 上不分批；机械硬盘上未配置 batch_size、batch_pause 时使用较大的批量和较短的暂停，
+// 每批结束后同步目录；其他情况目录配置优先于全局配置，batch_size 为 0 时不分批
+func (cl *Cleaner) batcher(d DirConfig, path string) *batcher {
+	c := cl.config
+	b := &batcher{size: d.BatchSize, pause: d.BatchPause}
+	if b.size <= 0 {
+		b.size = c.BatchSize
+	}
+	if b.pause <= 0 {
+		b.pause = c.BatchPause
+	}
+	switch cl.storageKind(d, path) {
+	case storageSSD:
+		return &batcher{}
+	case storageHDD:
+		if b.size <= 0 {
+			b.size = defaultHDDBatchSize
+		}
+		if b.pause <= 0 {
+			b.pause = defaultHDDBatchPause
+		}
+		b.sync = func() { syncDir(cl.fs, path) }
+	}
+	return b
+}
+
+// done 记录一次删除，达到一批时暂停；ctx 取消时立即返回
+func (b *batcher) done(ctx context.Context) {
+	if b.size <= 0 || b.pause <= 0 {
+		return
+	}
+	b.n++
+	if b.n%b.size != 0 {
+		return
+	}
+	if b.sync != nil {
+		b.sync()
+	}
+	t := time.NewTimer(b.pause)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// syncDir 把目录的元数据刷到磁盘，Windows 上无法打开目录同步时忽略
+func syncDir(fsys afero.Fs, path string) {
+	if d, err := fsys.Open(path); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,124 @@
+package cleaner
+
+import (
+	"context"
+	"io/fs"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/spf13/afero"
+)
+
+// statSamples 每个目录最多保留的 stat 耗时样本数，超过后随机替换（蓄水池抽样）
+const statSamples = 100000
+
+// BenchResult 单个目录的扫描测试结果
+type BenchResult struct {
+	Path     string        `json:"path"`
+	Entries  int           `json:"entries"` // 目录项数
+	Files    int           `json:"files"`
+	Dirs     int           `json:"dirs"`
+	Matched  int           `json:"matched"` // 按当前配置会被处理的文件数
+	Bytes    int64         `json:"bytes"`   // 文件总大小
+	Duration time.Duration `json:"duration"`
+	StatP50  time.Duration `json:"stat_p50"`
+	StatP90  time.Duration `json:"stat_p90"`
+	StatP99  time.Duration `json:"stat_p99"`
+	StatMax  time.Duration `json:"stat_max"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Rate 每秒扫描的目录项数
+func (r BenchResult) Rate() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Entries) / r.Duration.Seconds()
+}
+
+// Bench 只扫描配置的目录、不做任何处理，统计目录项数、扫描速度和获取文件信息（stat）的耗时分布，
+// 用于在开启删除前评估批次大小和定时。snap 不为空时同时把各目录的目录项记录到快照中，供 Simulate 使用
+func (cl *Cleaner) Bench(ctx context.Context, snap *Snapshot) ([]BenchResult, error) {
+	if cl.err != nil {
+		return nil, cl.err
+	}
+	now := time.Now()
+	var results []BenchResult
+	dirs, _ := cl.directories()
+	for _, dir := range dirs {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		var sd *SnapshotDir
+		if snap != nil {
+			sd = &SnapshotDir{Path: dir.Path}
+		}
+		results = append(results, cl.benchDir(dir, now, sd))
+		if sd != nil && results[len(results)-1].Error == "" {
+			snap.Directories = append(snap.Directories, *sd)
+		}
+	}
+	return results, nil
+}
+
+func (cl *Cleaner) benchDir(dir DirConfig, now time.Time, sd *SnapshotDir) BenchResult {
+	res := BenchResult{Path: dir.Path}
+	if isRemote(dir.Path) || dir.Docker != nil {
+		res.Error = i18n.T("不支持远程目录和容器日志")
+		return res
+	}
+	if err := cl.connectShare(dir); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	var samples []time.Duration
+	seen := 0
+	start := time.Now()
+	err := cl.scanDir(dir, dir.Path, func(entries []fs.DirEntry) {
+		for _, entry := range entries {
+			res.Entries++
+			t := time.Now()
+			info, err := entry.Info()
+			d := time.Since(t)
+			if seen++; len(samples) < statSamples {
+				samples = append(samples, d)
+			} else if i := rand.Intn(seen); i < statSamples {
+				samples[i] = d
+			}
+			if err != nil {
+				continue
+			}
+			if sd != nil {
+				sd.Entries = append(sd.Entries, SnapshotEntry{Name: info.Name(), Size: info.Size(), Mode: info.Mode(), ModTime: info.ModTime()})
+				if info.Name() == ignoreFileName {
+					data, _ := afero.ReadFile(cl.fs, filepath.Join(dir.Path, ignoreFileName))
+					sd.Ignore = string(data)
+				}
+			}
+			if info.IsDir() {
+				res.Dirs++
+				continue
+			}
+			res.Files++
+			res.Bytes += info.Size()
+			p := filepath.Join(dir.Path, info.Name())
+			f := File{FS: cl.fs, Path: p, Info: info, Time: cl.fileTime(dir, p, info)}
+			if matchAll(dir.policy.filters, f, now) && selectRule(dir.policy.rules, f, now) >= 0 {
+				res.Matched++
+			}
+		}
+	})
+	res.Duration = time.Since(start)
+	if err != nil {
+		res.Error = err.Error()
+	}
+	if len(samples) > 0 {
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		at := func(p float64) time.Duration { return samples[int(p*float64(len(samples)-1))] }
+		res.StatP50, res.StatP90, res.StatP99, res.StatMax = at(0.5), at(0.9), at(0.99), samples[len(samples)-1]
+	}
+	return res
+}
@@ -0,0 +1,71 @@
+package cleaner
+
+import (
+	"context"
+	"sync"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// byteCap max_bytes_per_run 的状态：全局上限由一次任务的各目录共用，目录上限只对当前目录生效。
+// 达到任一上限后取消任务，之后的文件都不再处理
+type byteCap struct {
+	limit  int64 // 全局上限，0 不限制
+	done   int64 // 已处理完的目录释放的空间
+	dir    int64 // 当前目录的上限，0 不限制
+	hit    string
+	cancel context.CancelFunc
+
+	mu        sync.Mutex // 只保护 abandoned：放弃等待的目录仍在其 goroutine 中调用 allow
+	abandoned bool
+}
+
+// fork 为按 timeout 单独运行的目录复制一份状态，目录按时结束后用 merge 合并回来，
+// 放弃等待的目录之后的文件都不再处理，也不会读到下一个目录的上限
+func (c *byteCap) fork() *byteCap {
+	if c == nil {
+		return nil
+	}
+	return &byteCap{limit: c.limit, done: c.done, dir: c.dir, hit: c.hit, cancel: c.cancel}
+}
+
+func (c *byteCap) merge(o *byteCap) {
+	if c != nil && c.hit == "" {
+		c.hit = o.hit
+	}
+}
+
+// abandon 放弃等待目录后调用，之后 allow 都返回 false
+func (c *byteCap) abandon() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.abandoned = true
+	c.mu.Unlock()
+}
+
+// allow 判断再释放 size 字节后是否仍在上限内；超过时记录原因并停止任务，返回 false
+func (cl *Cleaner) allow(s *Stats, path string, size int64) bool {
+	c := s.cap
+	if c == nil {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.abandoned || c.hit != "" {
+		return false
+	}
+	freed := s.FreedBytes + size
+	switch {
+	case c.dir > 0 && freed > c.dir:
+		c.hit = i18n.Sprintf("目录释放空间将超过 %s", ByteSize(c.dir))
+	case c.limit > 0 && c.done+freed > c.limit:
+		c.hit = i18n.Sprintf("任务释放空间将超过 %s", ByteSize(c.limit))
+	default:
+		return true
+	}
+	cl.errorf("跳过 %s：%s（max_bytes_per_run），停止任务", path, c.hit)
+	c.cancel()
+	return false
+}
@@ -0,0 +1,90 @@
+package cleaner
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// canaryListed canary 模式下逐个记录的到期文件数，超过后只计数
+const canaryListed = 20
+
+// Configured 返回目录项在配置中的路径：目录通配符展开后的目录返回通配符本身
+func (d DirConfig) Configured() string {
+	if d.source != "" {
+		return d.source
+	}
+	return d.Path
+}
+
+// canary 返回目录本次是否只报告不处理：目录项配置了 canary: true，或服务判断为新加入的目录
+func (cl *Cleaner) canary(d DirConfig) bool {
+	if d.Canary != nil {
+		return *d.Canary
+	}
+	return cl.config.InCanary != nil && cl.config.InCanary(d.Configured())
+}
+
+// previewDir canary 模式：只扫描目录中的文件，统计按当前配置会被处理的文件数和大小，不做任何处理。
+// 远程目录按与实际任务相同的规则（含 target_size）列出将删除的文件
+func (cl *Cleaner) previewDir(ctx context.Context, dir DirConfig, now time.Time, dr *DirReport) {
+	if dir.Docker != nil {
+		cl.logf("目录 %s 处于 canary 模式，容器日志只跳过，不统计", dir.Path)
+		return
+	}
+	if isRemote(dir.Path) {
+		// Matched 与本地目录的预览一致，为将处理的文件数
+		dr.Matched, dr.WouldFree = cl.cleanRemote(dir, now, &dr.Stats, true)
+		cl.logf("目录 %s 处于 canary 模式，未处理任何文件：到期 %d 个，共 %s", dir.Path, dr.Matched, ByteSize(dr.WouldFree))
+		return
+	}
+	if err := cl.connectShare(dir); err != nil {
+		cl.errorf("连接共享目录 %s 失败: %s", dir.Path, err)
+		dr.fail(err)
+		return
+	}
+	if !cl.checkDir(dir, &dr.Stats) {
+		return
+	}
+	err := cl.expired(ctx, dir, now, &dr.Stats, func(f File, r rule) {
+		if dr.Matched++; dr.Matched <= canaryListed {
+			cl.logf("canary：%s 到期，将执行 %s", f.Path, r.action.Name())
+		}
+		dr.WouldFree += f.Info.Size()
+	})
+	if err != nil {
+		cl.errorf("读取目录 %s 失败: %s", dir.Path, err)
+		dr.fail(err)
+	}
+	cl.logf("目录 %s 处于 canary 模式，未处理任何文件：到期 %d 个，共 %s", dir.Path, dr.Matched, ByteSize(dr.WouldFree))
+}
+
+// expired 只扫描目录本身（不含子目录）中的文件，对按当前配置到期的文件调用 fn，参数为文件满足的规则，不做任何处理。
+// 与实际任务一样跳过 .cleanignore 保护的文件和静默期内的文件；target_size、max_files、去重等依赖处理结果的规则不计算
+func (cl *Cleaner) expired(ctx context.Context, dir DirConfig, now time.Time, stats *Stats, fn func(f File, r rule)) error {
+	quietSince := cl.config.quietSince(dir, now)
+	ignore := cl.loadIgnore(dir.Path)
+	return cl.scanDir(dir, dir.Path, func(entries []fs.DirEntry) {
+		for _, entry := range entries {
+			if ctx.Err() != nil || entry.IsDir() || entry.Name() == ignoreFileName || ignore.match(entry.Name(), false) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			stats.Scanned++
+			p := filepath.Join(dir.Path, entry.Name())
+			f := File{FS: cl.fs, Path: p, Info: info, Time: cl.fileTime(dir, p, info)}
+			if !matchAll(dir.policy.filters, f, now) {
+				continue
+			}
+			k := selectRule(dir.policy.rules, f, now)
+			if k < 0 || (!quietSince.IsZero() && info.ModTime().After(quietSince)) {
+				continue
+			}
+			fn(f, dir.policy.rules[k])
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package cleaner
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"cleanlogservice/pkg/state"
+)
+
+const defaultFailureMaxAttempts = 5
+
+// failureRecord 删除失败、留到之后的任务继续尝试的文件
+type failureRecord struct {
+	Path        string    `json:"path"`
+	Attempts    int       `json:"attempts"` // 连续失败的任务数
+	FirstFailed time.Time `json:"first_failed"`
+	LastError   string    `json:"last_error"`
+}
+
+func (c Config) failureMaxAttempts() int {
+	if c.FailureMaxAttempts > 0 {
+		return c.FailureMaxAttempts
+	}
+	return defaultFailureMaxAttempts
+}
+
+// loadFailures 读取上次任务保存的失败列表，文件不存在时返回空列表
+func (cl *Cleaner) loadFailures() []failureRecord {
+	if cl.config.FailureState == "" {
+		return nil
+	}
+	var records []failureRecord
+	if err := state.Read(cl.fs, cl.config.FailureState, &records); err != nil && !os.IsNotExist(err) {
+		cl.warnf("读取失败列表 %s 失败: %s", cl.config.FailureState, err)
+		return nil
+	}
+	return records
+}
+
+// retryCarried 重新删除之前任务遗留的失败文件，不要求它们仍满足扫描条件。
+// 仍然失败的记录累加次数后返回，达到 failure_max_attempts 的文件记录错误并不再尝试；
+// 处于保留状态的目录中的记录原样保留，解除保留后再继续尝试
+func (cl *Cleaner) retryCarried(records []failureRecord, held []string, stats *Stats) map[string]failureRecord {
+	pending := map[string]failureRecord{}
+	for _, rec := range records {
+		if underHold(rec.Path, held) {
+			pending[rec.Path] = rec
+			continue
+		}
+		info, err := cl.fs.Stat(rec.Path)
+		if err != nil {
+			// 文件已被其他途径删除
+			cl.debugf("失败记录 %s 已不存在", rec.Path)
+			continue
+		}
+		f := File{FS: cl.fs, Path: rec.Path, Info: info, Time: info.ModTime()}
+		res, err := deleteAction{}.Apply(&f)
+		if err == nil {
+			cl.logf("重试 %s %s 成功（释放 %s）", actionDelete, rec.Path, ByteSize(res.Freed))
+			stats.record(actionDelete, res)
+			continue
+		}
+		rec.Attempts++
+		rec.LastError = err.Error()
+		if rec.Attempts >= cl.config.failureMaxAttempts() {
+			cl.errorf("文件 %s 已连续 %d 次删除失败，不再重试: %s", rec.Path, rec.Attempts, err)
+			stats.fail(err)
+			stats.account(rec.Path)
+			continue
+		}
+		cl.warnf("文件 %s 第 %d 次删除失败: %s", rec.Path, rec.Attempts, err)
+		stats.account(rec.Path)
+		pending[rec.Path] = rec
+	}
+	return pending
+}
+
+// saveFailures 合并本次任务仍未删除成功的文件并保存失败列表
+func (cl *Cleaner) saveFailures(pending map[string]failureRecord, dirs []DirReport, now time.Time) {
+	if cl.config.FailureState == "" {
+		return
+	}
+	for _, d := range dirs {
+		for _, item := range d.retries {
+			if n := item.action.Name(); n != actionDelete && n != actionPurge {
+				continue
+			}
+			if _, ok := pending[item.file.Path]; ok {
+				continue
+			}
+			pending[item.file.Path] = failureRecord{Path: item.file.Path, Attempts: 1, FirstFailed: now, LastError: item.err}
+		}
+	}
+	records := make([]failureRecord, 0, len(pending))
+	for _, rec := range pending {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Path < records[j].Path })
+	if err := state.Write(cl.fs, cl.config.FailureState, records); err != nil {
+		cl.errorf("保存失败列表 %s 失败: %s", cl.config.FailureState, err)
+	}
+}
@@ -0,0 +1,232 @@
+package cleaner
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// LabelDirectories 记录各目录项在配置文件中的位置（如 directories[2]、groups[0].directories[1]），
+// 校验出错时以此指出出错的键；应在展开预设和策略组之前调用
+func LabelDirectories(dirs []DirConfig, prefix string) {
+	for i := range dirs {
+		dirs[i].key = fmt.Sprintf("%s[%d]", prefix, i)
+	}
+}
+
+// field 返回目录项中某个键的完整名称，附带目录便于在展开的预设、conf.d 片段中定位
+func (d DirConfig) field(name string) string {
+	key := d.key
+	if key == "" {
+		key = "directories[]"
+	}
+	if name != "" {
+		key += "." + name
+	}
+	if d.Path == "" {
+		return key
+	}
+	return i18n.Sprintf("%s（%s）", key, d.Path)
+}
+
+// isRelative 本地路径是否为相对路径；以 / 或 \ 开头的路径在 Windows 上为当前盘符的根目录，视为绝对路径
+func isRelative(path string) bool {
+	return path != "" && !isRemote(path) && !filepath.IsAbs(path) && !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, `\`)
+}
+
+// Check 加载配置时的完整校验，错误信息指出配置中出错的键：
+// 没有任何清理目录、本地路径为相对路径（allowRelative 时允许，相对于配置文件所在的目录）、
+// 同一目录重复配置且处理相同的文件、扩展名或模式无效，以及相互矛盾、其中一项不会生效的保留策略。
+// 应在 ApplyPresets、ExpandPaths 之后，ResolvePaths 之前调用
+func (c *Config) Check(allowRelative bool) error {
+	if len(c.Directories) == 0 && len(c.Tables) == 0 && len(c.Elasticsearch) == 0 {
+		return i18n.Errorf("directories 为空：至少需要配置一个清理目录（或 tables、elasticsearch）")
+	}
+	if c.Days < 0 || c.MaxAge < 0 {
+		return i18n.Errorf("days、max_age 不能为负数")
+	}
+	if c.RemoteTimeout < 0 {
+		return i18n.Errorf("remote_timeout 不能为负数")
+	}
+	if c.Workers < 0 || c.WorkersPerVolume < 0 {
+		return i18n.Errorf("workers、workers_per_volume 不能为负数")
+	}
+	if err := checkStopLimits(c.StopAfterFailures, c.StopFailurePercent); err != nil {
+		return err
+	}
+	if c.StaleFiles != nil {
+		if err := c.StaleFiles.check(); err != nil {
+			return i18n.Errorf("stale_files: %w", err)
+		}
+	}
+	if !allowRelative {
+		for key, path := range map[string]string{"failure_state": c.FailureState, "mark_state": c.MarkState, "scan_cache": c.ScanCache} {
+			if isRelative(path) {
+				return i18n.Errorf("%s: %q 是相对路径，请使用绝对路径，或配置 allow_relative_paths: true 使其相对于配置文件所在的目录", key, path)
+			}
+		}
+	}
+	for i := range c.Directories {
+		if err := c.checkDir(c.Directories[i], allowRelative); err != nil {
+			return err
+		}
+	}
+	return c.checkOverlap()
+}
+
+func (c *Config) checkDir(d DirConfig, allowRelative bool) error {
+	if d.Path == "" && d.Docker == nil {
+		return i18n.Errorf("%s: 不能为空", d.field("path"))
+	}
+	if !allowRelative && d.Docker == nil {
+		if isRelative(d.Path) {
+			return i18n.Errorf("%s: %q 是相对路径，请使用绝对路径，或配置 allow_relative_paths: true 使其相对于配置文件所在的目录", d.field("path"), d.Path)
+		}
+		for j, t := range d.Tiers {
+			for name, path := range map[string]string{"archive_dir": t.ArchiveDir, "upload_backlog": t.UploadBacklog} {
+				if isRelative(path) {
+					return i18n.Errorf("%s: %q 是相对路径，请使用绝对路径，或配置 allow_relative_paths: true 使其相对于配置文件所在的目录",
+						d.field(fmt.Sprintf("tiers[%d].%s", j, name)), path)
+				}
+			}
+		}
+	}
+	if d.Days < 0 || d.MaxAge < 0 {
+		return i18n.Errorf("%s: days、max_age 不能为负数", d.field(""))
+	}
+	if d.Timeout < 0 {
+		return i18n.Errorf("%s: 不能为负数", d.field("timeout"))
+	}
+	if err := checkStopLimits(d.StopAfterFailures, d.StopFailurePercent); err != nil {
+		return fmt.Errorf("%s: %w", d.field(""), err)
+	}
+	if d.StaleFiles != nil {
+		if err := d.StaleFiles.check(); err != nil {
+			return fmt.Errorf("%s: %w", d.field("stale_files"), err)
+		}
+	}
+	if d.Days > 0 && d.MaxAge > 0 {
+		return i18n.Errorf("%s: 与 days 同时配置时只有 max_age 生效，请只保留一项", d.field("max_age"))
+	}
+	for name, exts := range map[string][]string{"extensions": d.Extensions, "exclude_extensions": d.ExcludeExtensions} {
+		for _, ext := range exts {
+			if strings.ContainsAny(ext, `*?[/\`) {
+				return i18n.Errorf("%s: %q 不是扩展名，按文件名模式匹配请使用 glob 过滤器", d.field(name), ext)
+			}
+		}
+	}
+	fold := c.caseInsensitive(d)
+	for _, ext := range d.Extensions {
+		if ext != "" && hasExtension("x"+normalizeExt(ext), d.ExcludeExtensions, fold) {
+			return i18n.Errorf("%s: %q 同时出现在 extensions 中，这类文件永远不会被处理", d.field("exclude_extensions"), ext)
+		}
+	}
+	if d.TruncateKeep > 0 && d.Action != actionTruncate {
+		return i18n.Errorf("%s: 只在 action: truncate 时生效", d.field("truncate_keep"))
+	}
+	if len(d.Tiers) > 0 {
+		for _, name := range []string{"days", "max_age", "compress_after_days", "action", "truncate_keep"} {
+			if v := dirValue(d, name); v.IsValid() && !v.IsZero() {
+				return i18n.Errorf("%s: 配置了 tiers 时不生效，请在 tiers 中配置", d.field(name))
+			}
+		}
+		return checkTiers(d)
+	}
+	if d.CompressAfterDays > 0 && time.Duration(d.CompressAfterDays)*24*time.Hour >= c.retention(d) {
+		return i18n.Errorf("%s: %d 天不早于保留期 %s，压缩永远不会执行", d.field("compress_after_days"), d.CompressAfterDays, c.retention(d))
+	}
+	return nil
+}
+
+// checkTiers 各 tier 的年龄不能相同，删除或归档（文件移出目录）之后的 tier 不会执行
+func checkTiers(d DirConfig) error {
+	final := -1
+	for j, t := range d.Tiers {
+		if t.Days > 0 && t.MaxAge > 0 {
+			return i18n.Errorf("%s: 与 days 同时配置时只有 max_age 生效，请只保留一项", d.field(fmt.Sprintf("tiers[%d].max_age", j)))
+		}
+		for k := range d.Tiers[:j] {
+			if d.Tiers[k].age() == t.age() && !t.filtered() && !d.Tiers[k].filtered() {
+				return i18n.Errorf("%s: 与 tiers[%d] 的年龄相同（%s），只有其中一个会执行", d.field(fmt.Sprintf("tiers[%d]", j)), k, t.age())
+			}
+		}
+		if (t.Action == actionDelete || t.Action == actionArchive) && !t.filtered() && (final < 0 || t.age() < d.Tiers[final].age()) {
+			final = j
+		}
+	}
+	if final < 0 {
+		return nil
+	}
+	for j, t := range d.Tiers {
+		if t.age() > d.Tiers[final].age() {
+			return i18n.Errorf("%s: 年龄 %s 晚于 tiers[%d] 的 %s（%s），文件届时已不存在，不会执行",
+				d.field(fmt.Sprintf("tiers[%d]", j)), t.age(), final, d.Tiers[final].Action, d.Tiers[final].age())
+		}
+	}
+	return nil
+}
+
+// checkOverlap 同一本地目录重复配置且处理相同的文件时，无法判断哪一项的策略应当生效。
+// 含通配符的目录在任务开始时才展开，与嵌套的目录一样在任务中按 resolveOverlaps 处理
+func (c *Config) checkOverlap() error {
+	var local []DirConfig
+	for _, d := range c.Directories {
+		if !isRemote(d.Path) && d.Docker == nil && !hasGlob(d.Path) {
+			local = append(local, d)
+		}
+	}
+	for i, a := range local {
+		for _, b := range local[i+1:] {
+			if dirKey(a.Path) == dirKey(b.Path) && sameFiles(a, b) {
+				return i18n.Errorf("%s: 与 %s 是同一目录且处理相同的文件，请合并为一项", b.field("path"), a.field(""))
+			}
+		}
+	}
+	return nil
+}
+
+// dirKey 比较目录时使用的形式，Windows 上不区分大小写
+func dirKey(path string) string {
+	path = filepath.Clean(path)
+	if runtime.GOOS == "windows" {
+		path = strings.ToLower(path)
+	}
+	return path
+}
+
+// isWithin child 是否为 parent 之下（不含 parent 本身）的目录
+func isWithin(child, parent string) bool {
+	rel, err := filepath.Rel(parent, child)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// sameFiles 两个目录项是否处理相同的文件：扩展名相同且都没有额外的过滤器
+func sameFiles(a, b DirConfig) bool {
+	return len(a.Filters) == 0 && len(b.Filters) == 0 && a.MinSize == b.MinSize && a.MaxSize == b.MaxSize &&
+		reflect.DeepEqual(a.Extensions, b.Extensions) && reflect.DeepEqual(a.ExcludeExtensions, b.ExcludeExtensions)
+}
+
+// normalizeExt 补全扩展名前的点
+func normalizeExt(ext string) string {
+	if strings.HasPrefix(ext, ".") {
+		return ext
+	}
+	return "." + ext
+}
+
+// dirValue 按 yaml 键取目录项中的字段值
+func dirValue(d DirConfig, key string) reflect.Value {
+	v := reflect.ValueOf(d)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); name == key {
+			return v.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
@@ -0,0 +1,66 @@
+package cleaner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/spf13/afero"
+)
+
+// manifestName 归档目录中的校验清单，格式与 sha256sum 相同，可用 sha256sum -c 验证
+const manifestName = "SHA256SUMS"
+
+func fileSHA256(fsys afero.Fs, path string) (string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// archiveVerified 复制到归档目录并校验 SHA-256，一致后写入清单再删除原文件。
+// 即使在同一文件系统上也不使用重命名，确保删除前归档副本经过校验
+func archiveVerified(fsys afero.Fs, path, archiveDir string) (string, error) {
+	sum, err := fileSHA256(fsys, path)
+	if err != nil {
+		return "", err
+	}
+	dst, err := archiveTarget(fsys, path, archiveDir)
+	if err != nil {
+		return "", err
+	}
+	if err := copyFile(fsys, path, dst); err != nil {
+		fsys.Remove(dst)
+		return "", err
+	}
+	got, err := fileSHA256(fsys, dst)
+	if err == nil && got != sum {
+		err = i18n.Errorf("归档副本 %s 校验失败：%s != %s", dst, got, sum)
+	}
+	if err != nil {
+		fsys.Remove(dst)
+		return "", err
+	}
+	manifest, err := fsys.OpenFile(filepath.Join(archiveDir, manifestName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", err
+	}
+	_, err = fmt.Fprintf(manifest, "%s  %s\n", sum, filepath.Base(dst))
+	if closeErr := manifest.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	return dst, fsys.Remove(path)
+}
@@ -0,0 +1,602 @@
+package cleaner
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// candidate 满足清理条件、等待处理的文件
+type candidate struct {
+	file File
+	rule int // 文件满足的最后一条规则
+}
+
+// Stats 处理统计，Report 中的汇总和各目录的统计共用
+type Stats struct {
+	Scanned      int            `json:"scanned"` // 扫描的文件数
+	Matched      int            `json:"matched"` // 满足过滤条件且到期的文件数
+	Deleted      int            `json:"deleted"`
+	Failed       int            `json:"failed"`
+	Spared       int            `json:"spared"`
+	Compressed   int            `json:"compressed"`
+	Archived     int            `json:"archived"`
+	DeletedDirs  int            `json:"deleted_dirs"` // 按日期删除的子目录数
+	Truncated    int            `json:"truncated"`
+	InUse        int            `json:"in_use"`           // 因正在使用而跳过的文件数
+	Quiet        int            `json:"quiet"`            // 因处于静默期而跳过的文件数
+	Marked       int            `json:"marked"`           // 两阶段删除中本次只标记、留到下次任务删除的文件数
+	Linked       int            `json:"linked"`           // 因有多个硬链接而跳过的文件数（skip_hard_links）
+	SharedLinks  int            `json:"shared_links"`     // 删除后数据仍被其他硬链接引用、未计入释放空间的文件数
+	Held         int            `json:"held"`             // 因被 protected_processes 中的进程打开而跳过的文件数
+	Deduplicated int            `json:"deduplicated"`     // 去重删除的文件数，已计入 Deleted
+	Trimmed      int            `json:"trimmed"`          // 因目录文件数超过 max_files 删除的文件数，已计入 Deleted
+	Purged       int            `json:"purged"`           // 软删除（delete_mode: rename、trash）后超过 soft_delete_grace 永久删除的文件数
+	Transient    int            `json:"transient"`        // 重试后仍因网络文件系统临时错误失败的文件数，不计入 Failed
+	Errors       map[string]int `json:"errors,omitempty"` // Failed 和 Transient 按原因的分类计数：permission、in_use、not_found、path_too_long、network、other
+	AVContention int            `json:"av_contention"`    // 删除时被拒绝访问（多为杀毒软件或索引服务占用）并按 av_retries 重试的文件数，不论最终是否成功
+	AVDeferred   int            `json:"av_deferred"`      // 重试后仍被拒绝访问、改为关闭后删除的文件数，已计入 Deleted
+	FreedBytes   int64          `json:"freed_bytes"`
+	Other        map[string]int `json:"other,omitempty"`      // 自定义动作的处理文件数
+	Duplicates   []Duplicate    `json:"duplicates,omitempty"` // 去重删除的文件清单，只在各目录的统计中列出
+	Unverified   int            `json:"unverified"`           // verify_after_run 校验发现仍然存在、本次任务未说明原因的到期文件数
+	Unchanged    int            `json:"unchanged"`            // 按 scan_cache 自上次扫描后没有变化、本次跳过的目录数
+
+	retries   []retryItem     // 删除失败、任务结束前重试的文件
+	accounted map[string]bool // 本次任务跳过并说明了原因或报告了失败的文件和目录，任务结束后的校验不计入
+	marks     *markSet        // 两阶段删除的状态，各目录共用
+	scans     *scanCache      // scan_cache 的记录，各目录共用
+	scan      *scanTracker    // 正在完整扫描、可以缓存结果的目录
+	progress  *progress       // 当前目录的进度
+	matchLog  matchLog        // 当前目录的匹配日志策略
+	cap       *byteCap        // max_bytes_per_run 的状态，各目录共用
+	failStop  *failureStop    // 当前目录的 stop_after_failures、stop_failure_percent 状态
+}
+
+// Report 一次任务的结果：全部目录的汇总加上各目录的统计
+type Report struct {
+	Stats
+	Directories   []DirReport     `json:"directories"`
+	Tables        []TableReport   `json:"tables,omitempty"`
+	Elasticsearch []ElasticReport `json:"elasticsearch,omitempty"`
+	Frozen        string          `json:"frozen,omitempty"`   // 任务处于保留例外期时为例外期的说明，未处理任何文件
+	Capped        string          `json:"capped,omitempty"`   // 因达到 max_bytes_per_run 停止任务时为达到的上限
+	Overlaps      []Overlap       `json:"overlaps,omitempty"` // 重叠目录项的处理结果
+}
+
+// DirReport 单个配置目录的统计
+type DirReport struct {
+	Path      string     `json:"path"`
+	Owner     string     `json:"owner,omitempty"`
+	Start     time.Time  `json:"start"`
+	Duration  float64    `json:"duration_seconds"`
+	Top       *Offenders `json:"top,omitempty"`              // top_offenders 开启时清理后占用空间最大的文件和子目录
+	OnHold    bool       `json:"on_hold,omitempty"`          // 目录处于保留状态，本次未处理
+	Source    string     `json:"source,omitempty"`           // 目录由通配符展开时为配置中的通配符
+	Canary    bool       `json:"canary,omitempty"`           // 目录处于 canary 模式，本次只统计到期文件（Matched）、未处理
+	WouldFree int64      `json:"would_free_bytes,omitempty"` // canary 模式下到期文件的总大小
+	Storage   string     `json:"storage,omitempty"`          // 目录所在卷的类型 ssd 或 hdd，未配置 storage 或无法检测时为空
+	Aborted   string     `json:"aborted,omitempty"`          // 因失败过多（stop_after_failures、stop_failure_percent）停止处理该目录时为原因
+	TimedOut  bool       `json:"timed_out,omitempty"`        // 超过 timeout、remote_timeout 停止处理，统计只含已完成的部分
+	// Discrepancies verify_after_run 校验发现的文件，最多列出 maxDiscrepancies 个
+	Discrepancies []Discrepancy `json:"discrepancies,omitempty"`
+	Stats
+}
+
+// Skipped 到期但未处理的文件数：使用中、静默期内、达到目标大小后保留或两阶段删除中只标记
+func (s Stats) Skipped() int {
+	return s.InUse + s.Quiet + s.Spared + s.Marked + s.Linked + s.Held
+}
+
+// account 记录本次任务已说明原因（跳过或失败）的文件或目录
+func (s *Stats) account(path string) {
+	if s.accounted == nil {
+		s.accounted = map[string]bool{}
+	}
+	s.accounted[path] = true
+}
+
+// record 按动作名称记录一次成功的处理
+func (s *Stats) record(action string, res Result) {
+	s.FreedBytes += res.Freed
+	switch action {
+	case actionDelete:
+		s.Deleted++
+	case actionCompress:
+		s.Compressed++
+	case actionArchive:
+		s.Archived++
+	case actionTruncate:
+		s.Truncated++
+	case actionPurge:
+		s.Purged++
+	default:
+		if s.Other == nil {
+			s.Other = map[string]int{}
+		}
+		s.Other[action]++
+	}
+}
+
+// add 累加另一组统计，用于汇总各目录的结果
+func (s *Stats) add(o Stats) {
+	s.Scanned += o.Scanned
+	s.Matched += o.Matched
+	s.Deleted += o.Deleted
+	s.Failed += o.Failed
+	s.Spared += o.Spared
+	s.Compressed += o.Compressed
+	s.Archived += o.Archived
+	s.DeletedDirs += o.DeletedDirs
+	s.Truncated += o.Truncated
+	s.InUse += o.InUse
+	s.Quiet += o.Quiet
+	s.Marked += o.Marked
+	s.Linked += o.Linked
+	s.SharedLinks += o.SharedLinks
+	s.Held += o.Held
+	s.Deduplicated += o.Deduplicated
+	s.Trimmed += o.Trimmed
+	s.Purged += o.Purged
+	s.Transient += o.Transient
+	s.AVContention += o.AVContention
+	s.AVDeferred += o.AVDeferred
+	s.Unverified += o.Unverified
+	s.Unchanged += o.Unchanged
+	for k, v := range o.Errors {
+		if s.Errors == nil {
+			s.Errors = map[string]int{}
+		}
+		s.Errors[k] += v
+	}
+	s.FreedBytes += o.FreedBytes
+	for k, v := range o.Other {
+		if s.Other == nil {
+			s.Other = map[string]int{}
+		}
+		s.Other[k] += v
+	}
+}
+
+// logSummary 输出一次任务的统计结果
+func (cl *Cleaner) logSummary(stats Report) {
+	for _, d := range stats.Directories {
+		if d.matchLog.mode == matchLogNone {
+			continue
+		}
+		if d.OnHold {
+			cl.logf("目录 %s: 处于保留状态，未处理", d.Path)
+			continue
+		}
+		if d.Canary {
+			cl.logf("目录 %s: canary 模式，到期 %d 个（%s），未处理", d.Path, d.Matched, ByteSize(d.WouldFree))
+			continue
+		}
+		if d.Unchanged > 0 && d.Scanned == 0 {
+			cl.logf("目录 %s: 自上次扫描后没有变化，跳过", d.Path)
+			continue
+		}
+		cl.logf("目录 %s: 扫描 %d，匹配 %d，删除 %d，跳过 %d，失败 %d，释放 %s",
+			d.Path, d.Scanned, d.Matched, d.Deleted, d.Skipped(), d.Failed, ByteSize(d.FreedBytes))
+		if d.Aborted != "" {
+			cl.warnf("目录 %s: %s，已停止处理", d.Path, d.Aborted)
+		}
+		if d.TimedOut {
+			cl.warnf("目录 %s: 处理超时，已停止处理", d.Path)
+		}
+	}
+	cl.logf("成功删除文件数: %d\n", stats.Deleted)
+	cl.logf("删除文件失败数: %d\n", stats.Failed)
+	if len(stats.Errors) > 0 {
+		cl.logf("失败原因: %s\n", DescribeErrors(stats.Errors))
+	}
+	if stats.Transient > 0 {
+		cl.logf("临时错误未完成文件数: %d\n", stats.Transient)
+	}
+	if stats.AVContention > 0 {
+		cl.logf("被拒绝访问（疑似杀毒软件或索引服务占用）文件数: %d，其中关闭后删除: %d\n", stats.AVContention, stats.AVDeferred)
+	}
+	if stats.Unverified > 0 {
+		cl.logf("校验失败（任务结束后仍然存在的到期文件）数: %d\n", stats.Unverified)
+	}
+	if stats.Unchanged > 0 {
+		cl.logf("没有变化、跳过扫描的目录数: %d\n", stats.Unchanged)
+	}
+	if stats.Compressed > 0 {
+		cl.logf("压缩文件数: %d\n", stats.Compressed)
+	}
+	if stats.Archived > 0 {
+		cl.logf("归档文件数: %d\n", stats.Archived)
+	}
+	if stats.Truncated > 0 {
+		cl.logf("截断文件数: %d\n", stats.Truncated)
+	}
+	if stats.InUse > 0 {
+		cl.logf("跳过（使用中）文件数: %d\n", stats.InUse)
+	}
+	if stats.Quiet > 0 {
+		cl.logf("跳过（静默期内修改）文件数: %d\n", stats.Quiet)
+	}
+	if stats.Deduplicated > 0 {
+		cl.logf("删除重复文件数: %d\n", stats.Deduplicated)
+	}
+	if stats.Trimmed > 0 {
+		cl.logf("超过 max_files 删除文件数: %d\n", stats.Trimmed)
+	}
+	if stats.Purged > 0 {
+		cl.logf("软删除到期永久删除文件数: %d\n", stats.Purged)
+	}
+	if stats.Marked > 0 {
+		cl.logf("标记待下次删除文件数: %d\n", stats.Marked)
+	}
+	if stats.Linked > 0 {
+		cl.logf("跳过（多个硬链接）文件数: %d\n", stats.Linked)
+	}
+	if stats.Held > 0 {
+		cl.logf("跳过（被保护进程打开）文件数: %d\n", stats.Held)
+	}
+	if stats.SharedLinks > 0 {
+		cl.logf("仍有其他硬链接、未释放空间的文件数: %d\n", stats.SharedLinks)
+	}
+	if stats.DeletedDirs > 0 {
+		cl.logf("删除日期目录数: %d\n", stats.DeletedDirs)
+	}
+	if stats.Spared > 0 {
+		cl.logf("达到目标后保留的候选文件数: %d\n", stats.Spared)
+	}
+	for name, n := range stats.Other {
+		cl.logf("%s 文件数: %d\n", name, n)
+	}
+	cl.logf("释放空间: %s\n", ByteSize(stats.FreedBytes))
+}
+
+// cleanDir 按目录类型清理单个配置目录
+func (cl *Cleaner) cleanDir(ctx context.Context, dir DirConfig, now time.Time, stats *Stats) {
+	if isRemote(dir.Path) {
+		cl.cleanRemote(dir, now, stats, cl.canary(dir))
+		return
+	}
+	if dir.Docker != nil {
+		cl.cleanDockerLogs(dir, now, stats)
+		return
+	}
+	if err := cl.connectShare(dir); err != nil {
+		cl.errorf("连接共享目录 %s 失败: %s", dir.Path, err)
+		stats.fail(err)
+		stats.account(dir.Path)
+		return
+	}
+	if !cl.checkDir(dir, stats) {
+		return
+	}
+	cl.drainUploadBacklog(ctx, dir, stats)
+	if cl.cleanCached(ctx, dir, now, stats) {
+		return
+	}
+	cl.cleanDirectory(ctx, dir.Path, dir, dir.policy.rules, now, stats)
+	if dir.DateDirs {
+		cl.cleanDateDirs(dir, now, stats)
+	}
+	if dir.Subtrees {
+		cl.cleanSubtrees(dir, now, stats)
+	}
+}
+
+// cleanDirectory 按规则处理 path 下的文件。归档到其他目录的文件，
+// 会在对应的归档目录中继续按后续规则处理
+func (cl *Cleaner) cleanDirectory(ctx context.Context, path string, dir DirConfig, rules []rule, now time.Time, stats *Stats) {
+	quietSince := cl.config.quietSince(dir, now)
+	protected := cl.config.protectedProcesses(dir)
+	ignore := cl.loadIgnore(path)
+
+	// 先流式扫描目录，只保留候选文件，移除类动作的文件再按时间从旧到新处理
+	var removals, pending []candidate
+	var eligible []File    // 满足过滤条件的文件，只在去重或配置了 max_files 时收集
+	var softDeleted []File // 之前软删除的文件，到期后永久删除
+	var totalSize int64
+	var count int             // 目录中的文件数
+	gone := map[string]bool{} // 本次已删除或移走的文件，用于 max_files
+	trim := dir.MaxFiles > 0 && path == dir.Path
+	// 需要统计目录总大小时每个文件都要获取文件信息，否则先按文件名过滤
+	byName := dir.TargetSize <= 0 || path != dir.Path
+	err := cl.scanDir(dir, path, func(files []fs.DirEntry) {
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			if _, ok := softDeleteTime(file.Name()); ok {
+				if info, err := file.Info(); err == nil {
+					softDeleted = append(softDeleted, File{FS: cl.fs, Path: filepath.Join(path, file.Name()), Info: info})
+				}
+				continue
+			}
+			// 归档目录中的校验清单随归档文件一起保留
+			if path != dir.Path && file.Name() == manifestName {
+				continue
+			}
+			if file.Name() == ignoreFileName {
+				continue
+			}
+			count++
+			if ignore.match(file.Name(), false) {
+				cl.debugf("跳过 %s：受 %s 保护", filepath.Join(path, file.Name()), ignoreFileName)
+				continue
+			}
+			if byName && !matchNames(dir.policy.filters, file.Name()) {
+				stats.Scanned++
+				cl.tick(stats)
+				cl.debugf("跳过 %s：不满足过滤条件", filepath.Join(path, file.Name()))
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				cl.errorf("获取文件信息失败: %s", err)
+				stats.fail(err)
+				stats.account(filepath.Join(path, file.Name()))
+				continue // 获取文件信息失败，跳过当前文件，继续下一个文件
+			}
+			stats.Scanned++
+			cl.tick(stats)
+			totalSize += info.Size()
+			p := filepath.Join(path, file.Name())
+			f := File{FS: cl.fs, Path: p, Info: info, Time: cl.fileTime(dir, p, info)}
+			if stats.scan != nil && path == dir.Path {
+				stats.scan.observe(f.Time, now)
+			}
+			if !matchAll(dir.policy.filters, f, now) {
+				cl.debugf("跳过 %s：不满足过滤条件", f.Path)
+				continue
+			}
+			if dir.Dedupe || trim {
+				eligible = append(eligible, f)
+			}
+			k := selectRule(rules, f, now)
+			if k < 0 {
+				cl.debugf("跳过 %s：未到期", f.Path)
+				continue
+			}
+			stats.Matched++
+			action := rules[k].action
+			if !cl.guard(dir, f, quietSince, protected, handlesActiveFiles(action), stats) {
+				continue
+			}
+			c := candidate{file: f, rule: k}
+			if removesFile(action) {
+				removals = append(removals, c)
+			} else {
+				pending = append(pending, c)
+			}
+		}
+	})
+	if err != nil {
+		// 归档目录在第一次归档前不存在，属于正常情况
+		if !os.IsNotExist(err) {
+			cl.errorf("读取目录 %s 失败: %s", path, err)
+			stats.fail(err)
+			stats.account(path)
+		}
+		return
+	}
+
+	if dir.Dedupe && path == dir.Path {
+		// 重复文件同样按目录配置的删除方式删除
+		del, _ := newDeleteAction(cl.config.deleteMode(dir, Tier{}))
+		removed := cl.dedupe(ctx, dir, eligible, del, quietSince, protected, stats)
+		for path, size := range removed {
+			totalSize -= size
+			gone[path] = true
+		}
+		removals, pending = withoutRemoved(removals, removed), withoutRemoved(pending, removed)
+	}
+
+	if skip, delay := cl.config.skipInUse(dir); skip {
+		var checked, active []candidate
+		for _, c := range pending {
+			if handlesActiveFiles(rules[c.rule].action) {
+				active = append(active, c)
+			} else {
+				checked = append(checked, c)
+			}
+		}
+		checked = cl.filterInUse(append(checked, removals...), delay, stats)
+		pending, removals = active, nil
+		for _, c := range checked {
+			if removesFile(rules[c.rule].action) {
+				removals = append(removals, c)
+			} else {
+				pending = append(pending, c)
+			}
+		}
+	}
+
+	// 未到移除阶段的文件依次执行已满足的规则（如先压缩再归档），压缩后改名的文件记录新的路径供 max_files 使用
+	renamed := map[string]File{}
+	for _, c := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+		f := c.file
+		for _, r := range rules[:c.rule+1] {
+			if !r.match(f, now) {
+				continue
+			}
+			size := f.Info.Size()
+			res, ok := cl.apply(r.action, &f, stats)
+			if !ok {
+				break
+			}
+			if res.Removed {
+				totalSize -= size
+				gone[c.file.Path] = true
+				break
+			}
+			totalSize -= res.Freed
+		}
+		if f.Path != c.file.Path && !gone[c.file.Path] {
+			renamed[c.file.Path] = f
+		}
+	}
+
+	sort.Slice(removals, func(i, j int) bool {
+		return removals[i].file.Time.Before(removals[j].file.Time)
+	})
+	batch := cl.batcher(dir, path)
+	for i, c := range removals {
+		if ctx.Err() != nil {
+			return
+		}
+		// 已达到目录大小目标，剩余候选文件保留
+		if path == dir.Path && dir.TargetSize > 0 && totalSize <= int64(dir.TargetSize) {
+			for _, c := range removals[i:] {
+				cl.skipf(stats, "保留 %s：目录大小已降到 target_size 以下", c.file.Path)
+				stats.account(c.file.Path)
+			}
+			stats.Spared += len(removals) - i
+			break
+		}
+		f := c.file
+		if !cl.confirmMark(f, rules[c.rule].action, stats) {
+			continue
+		}
+		if _, ok := cl.apply(rules[c.rule].action, &f, stats); ok {
+			totalSize -= c.file.Info.Size()
+			gone[c.file.Path] = true
+			batch.done(ctx)
+		}
+	}
+	if stats.marks != nil {
+		stats.marks.done(path)
+	}
+	if trim {
+		cl.trimFiles(ctx, dir, eligible, count, gone, renamed, quietSince, protected, stats)
+	}
+	cl.purgeSoftDeleted(ctx, dir, path, softDeleted, ignore, now, stats)
+
+	// 只进入第一个归档目录，更后面的归档目录由递归调用处理
+	for i, r := range rules {
+		if a, ok := r.action.(archiveAction); ok {
+			if i+1 < len(rules) && a.dir != "" && rules[i+1].tier.stale == "" {
+				cl.cleanDirectory(ctx, a.dir, dir, rules[i+1:], now, stats)
+			}
+			break
+		}
+	}
+}
+
+// guard 移除文件前的检查：静默期内修改过（active 为 true 的动作可以处理正在写入的文件）、skip_hard_links 时有多个硬链接、
+// 被 protected_processes 中的进程打开的文件跳过，记录原因并返回 false
+func (cl *Cleaner) guard(dir DirConfig, f File, quietSince time.Time, protected []string, active bool, stats *Stats) bool {
+	if !quietSince.IsZero() && f.Info.ModTime().After(quietSince) && !active {
+		cl.skipf(stats, "跳过 %s：静默期内修改过", f.Path)
+		stats.Quiet++
+		stats.account(f.Path)
+		return false
+	}
+	if cl.config.skipHardLinks(dir) {
+		if n := f.links(); n > 1 {
+			cl.skipf(stats, "跳过 %s：有 %d 个硬链接", f.Path, n)
+			stats.Linked++
+			stats.account(f.Path)
+			return false
+		}
+	}
+	if h, ok := f.heldBy(protected); ok {
+		// 不论日志级别都记录，说明文件为何保留
+		cl.logf("跳过 %s：文件被进程 %s（PID %d）打开", f.Path, h.Name, h.PID)
+		stats.Held++
+		stats.account(f.Path)
+		return false
+	}
+	return true
+}
+
+// guardExtra 对去重和 max_files 选出的文件（不必到期）执行与到期文件相同的检查：guard 和 skip_in_use，
+// 返回可以删除的文件，删除前还需 confirmMark。本次任务已跳过或失败的文件不再检查，也不重复计数
+func (cl *Cleaner) guardExtra(dir DirConfig, files []File, quietSince time.Time, protected []string, stats *Stats) []File {
+	var cs []candidate
+	for _, f := range files {
+		if stats.accounted[f.Path] || !cl.guard(dir, f, quietSince, protected, false, stats) {
+			continue
+		}
+		cs = append(cs, candidate{file: f})
+	}
+	if skip, delay := cl.config.skipInUse(dir); skip {
+		cs = cl.filterInUse(cs, delay, stats)
+	}
+	kept := make([]File, 0, len(cs))
+	for _, c := range cs {
+		kept = append(kept, c.file)
+	}
+	return kept
+}
+
+// confirmMark 配置了 mark_state 时，文件须在上次任务中已标记且没有变化才能移除；否则本次只标记，返回 false
+func (cl *Cleaner) confirmMark(f File, action Action, stats *Stats) bool {
+	if stats.marks == nil || stats.marks.confirm(f) {
+		return true
+	}
+	cl.skipf(stats, "标记 %s，下次任务仍满足条件且未变化时再%s", f.Path, action.Name())
+	stats.Marked++
+	stats.account(f.Path)
+	return false
+}
+
+// apply 执行动作并记录统计，返回 false 表示动作失败或跳过，不再执行后续动作
+func (cl *Cleaner) apply(action Action, f *File, stats *Stats) (Result, bool) {
+	path, orig := f.Path, *f
+	// 有其他硬链接时删除这一个链接不释放空间，最后一个链接删除时才计入
+	shared := removesFile(action) && f.links() > 1
+	// 软删除只改名，空间在永久删除（purge）时才释放并计入 max_bytes_per_run
+	var size int64
+	if removesFile(action) && !shared && !softDeletes(action) {
+		size = f.Info.Size()
+	}
+	if !cl.allow(stats, path, size) {
+		return Result{}, false
+	}
+	res, err := action.Apply(f)
+	// 网络文件系统的临时错误在本次任务中按退避时间原地重试
+	retries, delay := cl.config.transientRetries()
+	for i := 0; i < retries && err != nil && isTransient(err); i++ {
+		cl.debugf("%s %s 遇到临时错误，%s 后重试: %s", action.Name(), path, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+		*f = orig
+		res, err = action.Apply(f)
+	}
+	res, err = cl.avRetry(action, f, orig, res, err, stats)
+	if err == errSkipped {
+		cl.debugf("跳过 %s：无需 %s", path, action.Name())
+		return res, false
+	}
+	if err != nil {
+		transient := isTransient(err)
+		if transient {
+			cl.warnf("%s 文件失败（临时错误）: %s", action.Name(), err)
+			stats.Transient++
+			stats.classify(classifyError(err))
+		} else {
+			cl.errorf("%s 文件失败: %s", action.Name(), err)
+			stats.fail(err)
+		}
+		stats.account(orig.Path)
+		cl.attempt(stats, true)
+		if removesFile(action) && (cl.config.RetryFailed > 0 || cl.config.FailureState != "") {
+			stats.retries = append(stats.retries, retryItem{action: action, file: orig, err: err.Error(), category: classifyError(err), transient: transient})
+		}
+		return res, false
+	}
+	if shared && res.Removed {
+		cl.matchf(stats, "%s 仍有其他硬链接，不计入释放空间", path)
+		res.Freed = 0
+		stats.SharedLinks++
+	}
+	cl.matchf(stats, "%s %s（释放 %s）", action.Name(), path, ByteSize(res.Freed))
+	stats.record(action.Name(), res)
+	cl.attempt(stats, false)
+	cl.tick(stats)
+	return res, true
+}
@@ -0,0 +1,424 @@
+// Package cleaner 日志清理引擎：按配置扫描目录，对到期文件执行压缩、归档、截断或删除。
+//
+// 其他程序可以直接嵌入：
+//
+//	c := cleaner.New(cfg)
+//	report, err := c.Run(ctx)
+//
+// 配置可以用 viper/mapstructure 从 YAML 解码，需配合 DecodeHook 使用。
+// 如果用到 sftp 密码认证，程序需在 main 开头调用 HandleAskpass。
+package cleaner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/afero"
+)
+
+type Config struct {
+	Directories   []DirConfig     `yaml:"directories"`
+	Tables        []TableConfig   `yaml:"tables" mapstructure:"tables"`               // 数据库日志表，按时间列删除到期的行
+	Elasticsearch []ElasticConfig `yaml:"elasticsearch" mapstructure:"elasticsearch"` // 按日期命名的 Elasticsearch 索引
+	Freeze        []FreezeWindow  `yaml:"freeze" mapstructure:"freeze"`               // 保留例外期，期间的任务不处理任何文件
+	Days          int             `yaml:"days"`
+	MaxAge        time.Duration   `yaml:"max_age" mapstructure:"max_age"` // 如 "36h"、"45m"，设置后优先于 days
+	Time          string          `yaml:"time"`
+
+	CompressAfterDays  int           `yaml:"compress_after_days" mapstructure:"compress_after_days"`   // 超过该天数的文件原地 gzip 压缩
+	SkipInUse          bool          `yaml:"skip_in_use" mapstructure:"skip_in_use"`                   // 跳过正在被写入/打开的文件
+	SkipHardLinks      bool          `yaml:"skip_hard_links" mapstructure:"skip_hard_links"`           // 跳过有多个硬链接的文件
+	ProtectedProcesses []string      `yaml:"protected_processes" mapstructure:"protected_processes"`   // 跳过被这些进程（如 sqlservr.exe）打开的文件，仅 Windows
+	CaseInsensitive    *bool         `yaml:"case_insensitive" mapstructure:"case_insensitive"`         // 扩展名、glob 和 name_date 正则不区分大小写，默认 Windows 上开启
+	TopOffenders       int           `yaml:"top_offenders" mapstructure:"top_offenders"`               // 任务结果中列出各目录清理后最大的 N 个文件和子目录
+	InUseDelay         time.Duration `yaml:"in_use_delay" mapstructure:"in_use_delay"`                 // 判断文件大小是否稳定的间隔，默认 2s
+	QuietPeriod        time.Duration `yaml:"quiet_period" mapstructure:"quiet_period"`                 // 最近该时长内修改过的文件一律不处理
+	Hooks              *Hooks        `yaml:"hooks" mapstructure:"hooks"`                               // 每次任务前后执行的命令
+	Language           string        `yaml:"language" mapstructure:"language"`                         // 日志和报告的语言：zh（默认）或 en
+	LogLevel           string        `yaml:"log_level" mapstructure:"log_level"`                       // debug 时逐个记录处理和跳过的文件及原因，默认 info 只输出汇总
+	LogMatches         string        `yaml:"log_matches" mapstructure:"log_matches"`                   // 到期文件的记录方式：none、summary（默认）或 full，目录项中也可单独设置
+	LogSkipped         bool          `yaml:"log_skipped" mapstructure:"log_skipped"`                   // 不论日志级别，逐个记录到期但本次跳过的文件及原因
+	MissingDir         string        `yaml:"missing_dir" mapstructure:"missing_dir"`                   // 目录不存在时：ignore、warn（默认）、error 或 create
+	RetryFailed        int           `yaml:"retry_failed" mapstructure:"retry_failed"`                 // 删除失败的文件在任务结束前重试的次数，默认不重试
+	RetryDelay         time.Duration `yaml:"retry_delay" mapstructure:"retry_delay"`                   // 重试前等待的时间，默认 5s
+	FailureState       string        `yaml:"failure_state" mapstructure:"failure_state"`               // 保存删除失败文件列表的路径，之后的任务即使不再扫描到这些文件也会继续尝试删除
+	ProgressEvery      int           `yaml:"progress_every" mapstructure:"progress_every"`             // 每扫描或处理多少个文件输出一次进度，默认只按时间输出
+	ProgressInterval   time.Duration `yaml:"progress_interval" mapstructure:"progress_interval"`       // 单个目录处理超过该间隔时输出进度，默认 30s，-1 关闭
+	MaxDepth           int           `yaml:"max_depth" mapstructure:"max_depth"`                       // 遍历子目录树（date_dirs、subtrees）的最大深度，默认 64，-1 不限制
+	CrossDevices       bool          `yaml:"cross_devices" mapstructure:"cross_devices"`               // 允许 date_dirs、subtrees 删除含挂载点、bind mount 或 NTFS 联接点的子目录，默认不跨越
+	MaxEntriesPerDir   int           `yaml:"max_entries_per_dir" mapstructure:"max_entries_per_dir"`   // 单个目录最多读取的目录项数，超过时警告并只处理已读取的部分，默认不限制
+	TransientRetries   int           `yaml:"transient_retries" mapstructure:"transient_retries"`       // 网络文件系统临时错误（句柄失效、连接重置、共享冲突）的原地重试次数，默认 3，-1 不重试
+	TransientDelay     time.Duration `yaml:"transient_delay" mapstructure:"transient_delay"`           // 临时错误首次重试前的等待时间，之后每次加倍，默认 1s
+	AVRetries          int           `yaml:"av_retries" mapstructure:"av_retries"`                     // 删除时被拒绝访问（杀毒软件、索引服务正在扫描文件）的原地重试次数，默认不重试，仅 Windows
+	AVDelay            time.Duration `yaml:"av_delay" mapstructure:"av_delay"`                         // 拒绝访问首次重试前的等待时间，之后每次加倍，默认 500ms
+	AVDeferDelete      bool          `yaml:"av_defer_delete" mapstructure:"av_defer_delete"`           // 重试后仍被拒绝访问时以 FILE_FLAG_DELETE_ON_CLOSE 打开，其他进程关闭文件后由系统删除
+	FailureMaxAttempts int           `yaml:"failure_max_attempts" mapstructure:"failure_max_attempts"` // 连续失败多少次后记录错误并放弃，默认 5
+	DeleteMode         string        `yaml:"delete_mode" mapstructure:"delete_mode"`                   // unlink（默认）或 shred：删除前用随机数据覆写文件内容，用于含敏感数据的日志
+	ShredPasses        int           `yaml:"shred_passes" mapstructure:"shred_passes"`                 // shred 的覆写次数，默认 3
+	SoftDeleteGrace    time.Duration `yaml:"soft_delete_grace" mapstructure:"soft_delete_grace"`       // delete_mode 为 rename、trash 时软删除的文件保留多久后永久删除，默认 168h
+	MarkState          string        `yaml:"mark_state" mapstructure:"mark_state"`                     // 两阶段删除：到期文件先记录到该文件，下一次任务仍满足条件且大小、修改时间未变时才删除或归档
+	BatchSize          int           `yaml:"batch_size" mapstructure:"batch_size"`                     // 每删除多少个文件暂停一次，默认不分批
+	BatchPause         time.Duration `yaml:"batch_pause" mapstructure:"batch_pause"`                   // 每批之间暂停的时间，如 2s
+	Storage            string        `yaml:"storage" mapstructure:"storage"`                           // 目录所在卷的类型：auto（检测）、ssd（不分批）、hdd（大批量并同步目录），默认按 batch_size 分批
+	MaxRunDuration     time.Duration `yaml:"max_run_duration" mapstructure:"max_run_duration"`         // 单次任务的最长运行时间，超过后取消任务，默认不限
+	RemoteTimeout      time.Duration `yaml:"remote_timeout" mapstructure:"remote_timeout"`             // 远程目标和网络共享中每个目录的最长处理时间，超过后停止该目录、继续其他目录，默认不限
+	Workers            int           `yaml:"workers" mapstructure:"workers"`                           // 同时处理的目录数，默认 1 按顺序处理；配置了 max_bytes_per_run 时总是按顺序处理
+	WorkersPerVolume   int           `yaml:"workers_per_volume" mapstructure:"workers_per_volume"`     // workers 大于 1 时同一卷（Windows 盘符、Linux 块设备）上同时处理的目录数，默认 1
+	VerifyAfterRun     bool          `yaml:"verify_after_run" mapstructure:"verify_after_run"`         // 任务结束后重新扫描，报告仍然存在且本次任务未说明跳过或失败原因的到期文件
+	ScanCache          string        `yaml:"scan_cache" mapstructure:"scan_cache"`                     // 保存各目录上次扫描时的修改时间和大小，之后的任务跳过没有变化、也还没有文件到期的目录
+	MaxBytesPerRun     ByteSize      `yaml:"max_bytes_per_run" mapstructure:"max_bytes_per_run"`       // 单次任务最多释放的空间，将要超过时停止任务并返回错误，防止保留期配错时大量删除，默认不限
+	StopAfterFailures  int           `yaml:"stop_after_failures" mapstructure:"stop_after_failures"`   // 目录中连续多少个文件处理失败后停止处理该目录，默认不限
+	StopFailurePercent int           `yaml:"stop_failure_percent" mapstructure:"stop_failure_percent"` // 目录中处理失败的文件超过该百分比（至少处理 10 个后判断）时停止处理该目录，默认不限
+	StaleFiles         *StaleFiles   `yaml:"stale_files" mapstructure:"stale_files"`                   // 空文件和遗留的锁文件、PID 文件的单独保留期，不受目录 days、tiers 的限制
+
+	Logger   *log.Logger            `yaml:"-" mapstructure:"-"` // 清理日志的输出，为空时不输出
+	Hold     func(path string) bool `yaml:"-" mapstructure:"-"` // 服务运行时设置的保留状态，返回 true 的目录与配置了 hold 一样跳过
+	InCanary func(path string) bool `yaml:"-" mapstructure:"-"` // 参数为目录项在配置中的路径，返回 true 时该目录本次只报告到期文件、不处理
+	FS       afero.Fs               `yaml:"-" mapstructure:"-"` // 本地目录所在的文件系统，为空时使用操作系统文件系统；测试时可用 afero.NewMemMapFs()
+}
+
+// DirConfig 单个清理目录的配置，directories 中直接写字符串时只设置 Path
+type DirConfig struct {
+	Path               string        `yaml:"path" mapstructure:"path"`
+	Extensions         []string      `yaml:"extensions" mapstructure:"extensions"`
+	ExcludeExtensions  []string      `yaml:"exclude_extensions" mapstructure:"exclude_extensions"`
+	Days               int           `yaml:"days" mapstructure:"days"`
+	MaxAge             time.Duration `yaml:"max_age" mapstructure:"max_age"`
+	TargetSize         ByteSize      `yaml:"target_size" mapstructure:"target_size"`   // 目录总大小降到该值以下即停止删除，如 "10GB"
+	MaxFiles           int           `yaml:"max_files" mapstructure:"max_files"`       // 目录中的文件数超过该值时，不论是否到期从最旧的文件开始删除
+	TargetFiles        int           `yaml:"target_files" mapstructure:"target_files"` // max_files 删除到剩余多少个文件，默认等于 max_files
+	CompressAfterDays  int           `yaml:"compress_after_days" mapstructure:"compress_after_days"`
+	Tiers              []Tier        `yaml:"tiers" mapstructure:"tiers"`         // 分级保留策略，配置后忽略 days/max_age/compress_after_days
+	DateDirs           bool          `yaml:"date_dirs" mapstructure:"date_dirs"` // 按子目录名中的日期（如 20240101）删除整个子目录
+	MaxDepth           int           `yaml:"max_depth" mapstructure:"max_depth"`
+	CrossDevices       bool          `yaml:"cross_devices" mapstructure:"cross_devices"`
+	MaxEntriesPerDir   int           `yaml:"max_entries_per_dir" mapstructure:"max_entries_per_dir"`
+	Subtrees           bool          `yaml:"subtrees" mapstructure:"subtrees"`           // 每个直接子目录作为整体：子目录树中最新的文件也到期时删除整个子目录，否则整个保留
+	DateLayouts        []string      `yaml:"date_layouts" mapstructure:"date_layouts"`   // 日期目录名格式（Go 时间格式），默认 20060102、2006-01-02
+	NameDate           *NameDate     `yaml:"name_date" mapstructure:"name_date"`         // 按文件名中的日期判断年龄，如 app-20240315.log
+	ContentDate        *ContentDate  `yaml:"content_date" mapstructure:"content_date"`   // 按文件首行或末行中的时间戳判断年龄，name_date 解析失败时也使用
+	Share              *ShareConfig  `yaml:"share" mapstructure:"share"`                 // UNC 路径（\\server\share\logs）的连接凭据与重试
+	SFTP               *SFTPConfig   `yaml:"sftp" mapstructure:"sftp"`                   // path 为 sftp://user@host/var/log/app 时的连接配置
+	S3                 *S3Config     `yaml:"s3" mapstructure:"s3"`                       // path 为 s3://bucket/prefix/ 时的连接配置
+	OSS                *OSSConfig    `yaml:"oss" mapstructure:"oss"`                     // path 为 oss://bucket/prefix/ 时的连接配置
+	FTP                *FTPConfig    `yaml:"ftp" mapstructure:"ftp"`                     // path 为 ftp://host/path 时的连接配置
+	Docker             *DockerConfig `yaml:"docker" mapstructure:"docker"`               // Docker json-file 日志截断模式
+	Action             string        `yaml:"action" mapstructure:"action"`               // 到期文件的处理方式：delete（默认）或 truncate
+	TruncateKeep       ByteSize      `yaml:"truncate_keep" mapstructure:"truncate_keep"` // truncate 时保留的末尾大小，默认清空
+	SkipInUse          bool          `yaml:"skip_in_use" mapstructure:"skip_in_use"`
+	SkipHardLinks      bool          `yaml:"skip_hard_links" mapstructure:"skip_hard_links"`
+	ProtectedProcesses []string      `yaml:"protected_processes" mapstructure:"protected_processes"` // 与全局配置合并
+	CaseInsensitive    *bool         `yaml:"case_insensitive" mapstructure:"case_insensitive"`
+	InUseDelay         time.Duration `yaml:"in_use_delay" mapstructure:"in_use_delay"`
+	QuietPeriod        time.Duration `yaml:"quiet_period" mapstructure:"quiet_period"`
+	Hooks              *Hooks        `yaml:"hooks" mapstructure:"hooks"`       // 清理该目录前后执行的命令
+	Filters            []FilterSpec  `yaml:"filters" mapstructure:"filters"`   // 额外的过滤器，如 {type: glob, patterns: ["*.log"]}
+	MinSize            ByteSize      `yaml:"min_size" mapstructure:"min_size"` // 只处理不小于该大小的文件，如保护占位用的空文件
+	MaxSize            ByteSize      `yaml:"max_size" mapstructure:"max_size"` // 只处理不大于该大小的文件
+	MissingDir         string        `yaml:"missing_dir" mapstructure:"missing_dir"`
+	LogMatches         string        `yaml:"log_matches" mapstructure:"log_matches"`
+	LogSkipped         bool          `yaml:"log_skipped" mapstructure:"log_skipped"`
+	DeleteMode         string        `yaml:"delete_mode" mapstructure:"delete_mode"`
+	ShredPasses        int           `yaml:"shred_passes" mapstructure:"shred_passes"`
+	SoftDeleteGrace    time.Duration `yaml:"soft_delete_grace" mapstructure:"soft_delete_grace"`
+	Dedupe             bool          `yaml:"dedupe" mapstructure:"dedupe"` // 大小和 SHA-256 相同的文件只保留最新的一个，其余不论是否到期都删除
+	BatchSize          int           `yaml:"batch_size" mapstructure:"batch_size"`
+	BatchPause         time.Duration `yaml:"batch_pause" mapstructure:"batch_pause"`
+	Storage            string        `yaml:"storage" mapstructure:"storage"`
+	MaxBytesPerRun     ByteSize      `yaml:"max_bytes_per_run" mapstructure:"max_bytes_per_run"`
+	StopAfterFailures  int           `yaml:"stop_after_failures" mapstructure:"stop_after_failures"`
+	StopFailurePercent int           `yaml:"stop_failure_percent" mapstructure:"stop_failure_percent"`
+	StaleFiles         *StaleFiles   `yaml:"stale_files" mapstructure:"stale_files"` // 整体覆盖全局的 stale_files，配置为 {} 时该目录不单独处理
+	Timeout            time.Duration `yaml:"timeout" mapstructure:"timeout"`         // 该目录的最长处理时间，优先于 remote_timeout，也可用于挂载了 NFS、SMB 的本地路径
+	Time               string        `yaml:"time" mapstructure:"time"`               // 该目录单独的定时表达式，为空时使用全局 time；由服务调度，Run 不读取
+	Preset             string        `yaml:"preset" mapstructure:"preset"`           // 内置预设，如 iis、nginx，由 ApplyPresets 展开
+	Hold               bool          `yaml:"hold" mapstructure:"hold"`               // 保留（legal hold）：暂停处理该目录中的文件，任务结果中标记为 on_hold
+	Canary             *bool         `yaml:"canary" mapstructure:"canary"`           // true 时只报告到期文件、不处理；false 时不经过新目录的 canary 阶段
+	Owner              string        `yaml:"owner" mapstructure:"owner"`             // 目录负责人的邮箱或 webhook 地址，由服务在任务结束后发送该目录的结果，Run 只复制到 DirReport
+	Group              string        `yaml:"-" mapstructure:"-"`                     // 所属的策略组，由服务展开 groups 时设置
+
+	policy *policy
+	source string   // 由目录通配符展开时为配置中的通配符
+	key    string   // 目录项在配置文件中的位置，如 directories[2]，由 LabelDirectories 设置
+	nested []string // 位于该目录之中、单独配置的目录，subtrees、date_dirs 不整个删除包含它们的子目录
+}
+
+// quietSince 返回静默期的起点，修改时间晚于该时间的文件不处理；未配置时返回零值
+func (c Config) quietSince(d DirConfig, now time.Time) time.Time {
+	period := d.QuietPeriod
+	if period <= 0 {
+		period = c.QuietPeriod
+	}
+	if period <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-period)
+}
+
+// retention 计算目录的保留时长：目录 max_age > 目录 days > 全局 max_age > 全局 days
+func (c Config) retention(d DirConfig) time.Duration {
+	switch {
+	case d.MaxAge > 0:
+		return d.MaxAge
+	case d.Days > 0:
+		return time.Duration(d.Days) * 24 * time.Hour
+	case c.MaxAge > 0:
+		return c.MaxAge
+	}
+	return time.Duration(c.Days) * 24 * time.Hour
+}
+
+// Validate 校验各目录的配置并构建处理策略
+func (c *Config) Validate() error {
+	if err := validateMissingDir(c.MissingDir); err != nil {
+		return err
+	}
+	if err := validateMatchLog(c.LogMatches); err != nil {
+		return err
+	}
+	if err := validateStorage(c.Storage); err != nil {
+		return err
+	}
+	for i := range c.Directories {
+		d := &c.Directories[i]
+		if err := validateAction(d); err != nil {
+			return err
+		}
+		if err := validateMissingDir(d.MissingDir); err != nil {
+			return fmt.Errorf("%s: %w", d.field("missing_dir"), err)
+		}
+		if err := validateMatchLog(d.LogMatches); err != nil {
+			return fmt.Errorf("%s: %w", d.field("log_matches"), err)
+		}
+		if err := validateMaxFiles(d); err != nil {
+			return fmt.Errorf("%s: %w", d.field(""), err)
+		}
+		if err := validateStorage(d.Storage); err != nil {
+			return fmt.Errorf("%s: %w", d.field("storage"), err)
+		}
+		pol, err := c.buildPolicy(*d)
+		if err != nil {
+			return err
+		}
+		d.policy = pol
+		if d.Docker != nil {
+			if err := d.Docker.validate(); err != nil {
+				return fmt.Errorf("%s: %w", d.field("docker"), err)
+			}
+		}
+		if d.NameDate != nil {
+			if err := d.NameDate.compile(c.caseInsensitive(*d)); err != nil {
+				return fmt.Errorf("%s: %w", d.field("name_date"), err)
+			}
+		}
+		if d.ContentDate != nil {
+			if isRemote(d.Path) {
+				return i18n.Errorf("%s: 只支持本地目录", d.field("content_date"))
+			}
+			if err := d.ContentDate.validate(false); err != nil {
+				return fmt.Errorf("%s: %w", d.field("content_date"), err)
+			}
+		}
+	}
+	for i := range c.Freeze {
+		if err := c.Freeze[i].validate(); err != nil {
+			return i18n.Errorf("保留例外期 %d: %w", i+1, err)
+		}
+	}
+	for i := range c.Tables {
+		if err := c.Tables[i].validate(); err != nil {
+			return i18n.Errorf("数据库表 %s: %w", c.Tables[i].Table, err)
+		}
+	}
+	for i := range c.Elasticsearch {
+		if err := c.Elasticsearch[i].validate(); err != nil {
+			return i18n.Errorf("Elasticsearch 索引 %s: %w", c.Elasticsearch[i].Index, err)
+		}
+	}
+	return nil
+}
+
+// DecodeHook 返回解码配置所需的 mapstructure 钩子：目录字符串、大小（"10GB"）、时长（"36h"）和逗号分隔列表
+func DecodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		stringToDirConfigHook,
+		stringToByteSizeHook,
+		timeToStringHook,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)
+}
+
+// timeToStringHook YAML 中不加引号的日期（如 freeze 的 from: 2024-03-25）会被解析为时间，还原为字符串
+func timeToStringHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	t, ok := data.(time.Time)
+	if !ok || to.Kind() != reflect.String {
+		return data, nil
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format("2006-01-02"), nil
+	}
+	return t.Format(time.RFC3339), nil
+}
+
+// stringToDirConfigHook 兼容旧配置：directories 中的字符串项解析为只有路径的 DirConfig
+func stringToDirConfigHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(DirConfig{}) {
+		return data, nil
+	}
+	return DirConfig{Path: data.(string)}, nil
+}
+
+// Cleaner 按配置执行清理任务，可重复调用 Run，多次调用之间不保留状态
+type Cleaner struct {
+	config Config
+	logger *log.Logger
+	level  logLevel
+	fs     afero.Fs
+	err    error // 配置校验错误，Run 时返回
+	dates  *contentDates
+}
+
+// New 根据配置创建 Cleaner。配置错误在 Run 时返回
+func New(config Config) *Cleaner {
+	config.Directories = append([]DirConfig(nil), config.Directories...)
+	cl := &Cleaner{config: config, logger: config.Logger, dates: &contentDates{}}
+	if cl.logger == nil {
+		cl.logger = log.New(io.Discard, "", 0)
+	}
+	cl.fs = config.FS
+	if cl.fs == nil {
+		cl.fs = afero.NewOsFs()
+	}
+	if config.Language != "" {
+		if cl.err = i18n.SetLanguage(config.Language); cl.err != nil {
+			return cl
+		}
+	}
+	if cl.level, cl.err = parseLogLevel(config.LogLevel); cl.err != nil {
+		return cl
+	}
+	cl.err = cl.config.Validate()
+	return cl
+}
+
+// Err 返回创建时的配置错误，可用于在替换正在使用的配置前检查新配置
+func (cl *Cleaner) Err() error {
+	return cl.err
+}
+
+// Run 执行一次清理任务并返回统计结果。
+// 单个文件或目录的失败只计入 Report.Failed；配置错误、pre_run 钩子失败或 ctx 取消时返回错误
+func (cl *Cleaner) Run(ctx context.Context) (Report, error) {
+	var stats Report
+	if cl.err != nil {
+		return stats, cl.err
+	}
+	cl.logf("---------------   执行一次任务！ ---------------")
+	cl.dates.reset()
+	now := time.Now()
+	if w, ok := cl.config.frozen(now); ok {
+		stats.Frozen = w.describe()
+		cl.warnf("处于保留例外期 %s，本次任务不处理任何文件", stats.Frozen)
+		return stats, nil
+	}
+	parent := ctx
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+	bc := &byteCap{limit: int64(cl.config.MaxBytesPerRun), cancel: stop}
+	if d := cl.config.MaxRunDuration; d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	if err := cl.runHook(ctx, cl.config.Hooks, hookPreRun, "", stats.Stats, now); err != nil {
+		cl.errorf("pre_run 钩子执行失败，跳过本次任务: %s", err)
+		return stats, i18n.Errorf("pre_run 钩子执行失败: %w", err)
+	}
+	dirs, overlaps := cl.directories()
+	for _, o := range overlaps {
+		cl.logf("目录重叠: %s", o)
+	}
+	stats.Overlaps = overlaps
+	var held []string
+	for _, dir := range dirs {
+		if cl.onHold(dir) {
+			held = append(held, dir.Path)
+		}
+	}
+	var carried Stats
+	failures := cl.retryCarried(cl.loadFailures(), held, &carried)
+	marks := cl.loadMarks(now)
+	scans := cl.loadScanCache(now)
+	bc.done = carried.FreedBytes
+	newStats := func(dir DirConfig) Stats {
+		return Stats{marks: marks, scans: scans, progress: cl.newProgress(dir.Path), matchLog: cl.config.matchLog(dir), cap: bc}
+	}
+	var stopped string // 超时时正在处理的目录
+	var remaining int  // 未处理的目录数
+	if workers := cl.workers(dirs); workers > 1 {
+		stats.Directories, stopped, remaining = cl.runParallel(ctx, dirs, now, workers, newStats)
+	} else {
+		for i, dir := range dirs {
+			if ctx.Err() != nil {
+				break
+			}
+			bc.dir = int64(dir.MaxBytesPerRun)
+			dr := cl.runDirTimed(ctx, dir, now, newStats(dir))
+			stats.Directories = append(stats.Directories, dr)
+			bc.done += dr.FreedBytes
+			if ctx.Err() != nil {
+				stopped, remaining = dir.Path, len(dirs)-i-1
+			}
+		}
+	}
+	cl.retryFailed(ctx, stats.Directories)
+	cl.saveFailures(failures, stats.Directories, now)
+	cl.saveMarks(marks)
+	cl.saveScanCache(scans)
+	if cl.config.VerifyAfterRun && bc.hit == "" && ctx.Err() == nil {
+		cl.verifyRun(ctx, dirs, now, stats.Directories, carried.accounted)
+	}
+	carried.accounted = nil
+	stats.add(carried)
+	for i := range stats.Directories {
+		stats.Directories[i].retries, stats.Directories[i].marks, stats.Directories[i].progress, stats.Directories[i].cap = nil, nil, nil, nil
+		stats.Directories[i].accounted, stats.Directories[i].scans, stats.Directories[i].failStop = nil, nil, nil
+		stats.add(stats.Directories[i].Stats)
+	}
+	cl.cleanTables(ctx, now, &stats)
+	cl.cleanElastic(ctx, now, &stats)
+
+	cl.logSummary(stats)
+	if bc.hit != "" {
+		stats.Capped = bc.hit
+		if stopped != "" && remaining > 0 {
+			cl.warnf("达到 max_bytes_per_run，在目录 %s 处停止，%d 个目录未处理", stopped, remaining)
+		}
+		return stats, i18n.Errorf("超过 max_bytes_per_run，任务已停止: %s", bc.hit)
+	}
+	if err := ctx.Err(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && parent.Err() == nil {
+			d := cl.config.MaxRunDuration
+			if stopped != "" {
+				cl.warnf("任务超过最长运行时间 %s，在目录 %s 处停止，%d 个目录未处理", d, stopped, remaining)
+			} else {
+				cl.warnf("任务超过最长运行时间 %s，已停止", d)
+			}
+			return stats, i18n.Errorf("任务超过最长运行时间 %s", d)
+		}
+		cl.warnf("任务已取消: %s", err)
+		return stats, err
+	}
+	if err := cl.runHook(ctx, cl.config.Hooks, hookPostRun, "", stats.Stats, now); err != nil {
+		cl.errorf("post_run 钩子执行失败: %s", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,241 @@
+package cleaner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// writeFile 在内存文件系统中创建文件，修改时间为 age 之前
+func writeFile(t *testing.T, fsys afero.Fs, path, content string, age time.Duration) {
+	t.Helper()
+	if err := afero.WriteFile(fsys, path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-age)
+	if err := fsys.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(t *testing.T, fsys afero.Fs, path string) bool {
+	t.Helper()
+	ok, err := afero.Exists(fsys, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ok
+}
+
+func run(t *testing.T, config Config) Report {
+	t.Helper()
+	report, err := New(config).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+const day = 24 * time.Hour
+
+func TestRunDeletesExpiredFiles(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	writeFile(t, fsys, "/logs/old.log", "old", 10*day)
+	writeFile(t, fsys, "/logs/new.log", "new", time.Hour)
+	writeFile(t, fsys, "/logs/old.txt", "txt", 10*day)
+
+	report := run(t, Config{FS: fsys, Directories: []DirConfig{{Path: "/logs", Days: 3, Extensions: []string{".log"}}}})
+
+	if exists(t, fsys, "/logs/old.log") {
+		t.Error("old.log 已到期，应被删除")
+	}
+	if !exists(t, fsys, "/logs/new.log") {
+		t.Error("new.log 未到期，不应删除")
+	}
+	if !exists(t, fsys, "/logs/old.txt") {
+		t.Error("old.txt 不满足 extensions，不应删除")
+	}
+	if report.Deleted != 1 || report.FreedBytes != 3 {
+		t.Errorf("Deleted = %d, FreedBytes = %d，应为 1、3", report.Deleted, report.FreedBytes)
+	}
+}
+
+func TestRunKeepsIgnoredFiles(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	writeFile(t, fsys, "/logs/"+ignoreFileName, "keep-*.log\n", 10*day)
+	writeFile(t, fsys, "/logs/keep-1.log", "keep", 10*day)
+	writeFile(t, fsys, "/logs/drop-1.log", "drop", 10*day)
+
+	run(t, Config{FS: fsys, Directories: []DirConfig{{Path: "/logs", Days: 3}}})
+
+	if !exists(t, fsys, "/logs/keep-1.log") {
+		t.Error("keep-1.log 受 .cleanignore 保护，不应删除")
+	}
+	if exists(t, fsys, "/logs/drop-1.log") {
+		t.Error("drop-1.log 已到期，应被删除")
+	}
+}
+
+func TestMarkStateDeletesOnSecondRun(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	writeFile(t, fsys, "/logs/old.log", "old", 10*day)
+	config := Config{FS: fsys, MarkState: "/state/marks.json", Directories: []DirConfig{{Path: "/logs", Days: 3}}}
+
+	report := run(t, config)
+	if !exists(t, fsys, "/logs/old.log") || report.Marked != 1 {
+		t.Fatalf("第一次任务只应标记：Marked = %d", report.Marked)
+	}
+	report = run(t, config)
+	if exists(t, fsys, "/logs/old.log") || report.Deleted != 1 {
+		t.Fatalf("第二次任务应删除已标记的文件：Deleted = %d", report.Deleted)
+	}
+}
+
+// 只处理部分目录的任务（其他策略组、watch 触发）不应清除其他目录的标记
+func TestMarkStateKeepsOtherDirectories(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	writeFile(t, fsys, "/a/old.log", "a", 10*day)
+	writeFile(t, fsys, "/b/old.log", "b", 10*day)
+	both := Config{FS: fsys, MarkState: "/state/marks.json", Directories: []DirConfig{{Path: "/a", Days: 3}, {Path: "/b", Days: 3}}}
+	onlyA := both
+	onlyA.Directories = []DirConfig{{Path: "/a", Days: 3}}
+
+	run(t, both)
+	run(t, onlyA)
+	if exists(t, fsys, "/a/old.log") {
+		t.Error("/a/old.log 已在第一次任务中标记，应被删除")
+	}
+	report := run(t, both)
+	if exists(t, fsys, "/b/old.log") || report.Deleted != 1 {
+		t.Errorf("/b 的标记应保留到下一次处理该目录的任务：Deleted = %d", report.Deleted)
+	}
+}
+
+func TestDedupeKeepsNewest(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	writeFile(t, fsys, "/logs/a.log", "same", 3*time.Hour)
+	writeFile(t, fsys, "/logs/b.log", "same", 2*time.Hour)
+	writeFile(t, fsys, "/logs/c.log", "same", time.Hour)
+	writeFile(t, fsys, "/logs/d.log", "diff", 4*time.Hour)
+
+	report := run(t, Config{FS: fsys, Directories: []DirConfig{{Path: "/logs", Days: 30, Dedupe: true}}})
+
+	for _, name := range []string{"a.log", "b.log"} {
+		if exists(t, fsys, "/logs/"+name) {
+			t.Errorf("%s 与 c.log 相同，应被删除", name)
+		}
+	}
+	for _, name := range []string{"c.log", "d.log"} {
+		if !exists(t, fsys, "/logs/"+name) {
+			t.Errorf("%s 应保留", name)
+		}
+	}
+	if report.Deduplicated != 2 {
+		t.Errorf("Deduplicated = %d，应为 2", report.Deduplicated)
+	}
+}
+
+func TestDedupeRespectsQuietPeriodAndMarks(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	writeFile(t, fsys, "/logs/a.log", "same", 3*time.Hour)
+	writeFile(t, fsys, "/logs/b.log", "same", time.Hour)
+	writeFile(t, fsys, "/logs/c.log", "same", time.Minute)
+	config := Config{FS: fsys, MarkState: "/state/marks.json", Directories: []DirConfig{{Path: "/logs", Days: 30, Dedupe: true, QuietPeriod: 30 * time.Minute}}}
+
+	report := run(t, config)
+	if !exists(t, fsys, "/logs/a.log") || report.Marked != 1 {
+		t.Fatalf("配置了 mark_state 时重复文件第一次只应标记：Marked = %d", report.Marked)
+	}
+	run(t, config)
+	if exists(t, fsys, "/logs/a.log") {
+		t.Error("a.log 已标记且未变化，第二次任务应被删除")
+	}
+	if !exists(t, fsys, "/logs/b.log") || !exists(t, fsys, "/logs/c.log") {
+		t.Error("b.log 是静默期外最新的副本，c.log 在静默期内，都不应删除")
+	}
+}
+
+func TestMaxFilesTrimsOldest(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	for i, name := range []string{"1.log", "2.log", "3.log", "4.log", "5.log"} {
+		writeFile(t, fsys, "/logs/"+name, name, time.Duration(10-i)*time.Hour)
+	}
+	writeFile(t, fsys, "/logs/"+ignoreFileName, "", time.Hour)
+
+	report := run(t, Config{FS: fsys, Directories: []DirConfig{{Path: "/logs", Days: 30, MaxFiles: 3}}})
+
+	for _, name := range []string{"1.log", "2.log"} {
+		if exists(t, fsys, "/logs/"+name) {
+			t.Errorf("%s 最旧，应被删除", name)
+		}
+	}
+	if report.Trimmed != 2 || report.Failed != 0 {
+		t.Errorf("Trimmed = %d, Failed = %d，应为 2、0（.cleanignore 不计入文件数）", report.Trimmed, report.Failed)
+	}
+}
+
+// 先压缩的文件改名为 .gz 后，max_files 应删除压缩后的文件，而不是因原路径不存在而失败
+func TestMaxFilesAfterCompress(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	for i, name := range []string{"1.log", "2.log", "3.log", "4.log"} {
+		writeFile(t, fsys, "/logs/"+name, name, time.Duration(10-i)*day)
+	}
+	tiers := []Tier{{Days: 3, Action: actionCompress}, {Days: 30, Action: actionDelete}}
+
+	report := run(t, Config{FS: fsys, Directories: []DirConfig{{Path: "/logs", MaxFiles: 2, Tiers: tiers}}})
+
+	if report.Failed != 0 || report.Trimmed != 2 {
+		t.Errorf("Trimmed = %d, Failed = %d，应为 2、0", report.Trimmed, report.Failed)
+	}
+	for _, name := range []string{"1.log.gz", "2.log.gz"} {
+		if exists(t, fsys, "/logs/"+name) {
+			t.Errorf("%s 最旧，应被删除", name)
+		}
+	}
+	for _, name := range []string{"3.log.gz", "4.log.gz"} {
+		if !exists(t, fsys, "/logs/"+name) {
+			t.Errorf("%s 应保留", name)
+		}
+	}
+}
+
+// 软删除文件只在原文件名满足目录的过滤条件时永久删除
+func TestPurgeOnlyServiceSoftDeletedFiles(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	writeFile(t, fsys, "/logs/a.log.deleted-20200101T000000", "a", 10*day)
+	writeFile(t, fsys, "/logs/user.dat.deleted-20200101T000000", "u", 10*day)
+
+	report := run(t, Config{FS: fsys, Directories: []DirConfig{{Path: "/logs", Days: 3, Extensions: []string{".log"}, DeleteMode: deleteModeRename}}})
+
+	if exists(t, fsys, "/logs/a.log.deleted-20200101T000000") || report.Purged != 1 {
+		t.Errorf("a.log 的软删除文件应永久删除：Purged = %d", report.Purged)
+	}
+	if !exists(t, fsys, "/logs/user.dat.deleted-20200101T000000") {
+		t.Error("原文件名不满足 extensions 的文件不应删除")
+	}
+}
+
+// 软删除不释放空间，不计入 max_bytes_per_run；永久删除时才计入
+func TestSoftDeleteNotChargedToByteCap(t *testing.T) {
+	fsys := afero.NewMemMapFs()
+	writeFile(t, fsys, "/logs/big.log", "01234567890123456789", 10*day)
+	config := Config{FS: fsys, MaxBytesPerRun: 15, Directories: []DirConfig{{Path: "/logs", Days: 3, DeleteMode: deleteModeRename}}}
+
+	report := run(t, config)
+	if report.Deleted != 1 || report.FreedBytes != 0 {
+		t.Errorf("Deleted = %d, FreedBytes = %d，软删除 20 字节的文件不应受 15 字节的上限限制", report.Deleted, report.FreedBytes)
+	}
+
+	deleted := ".deleted-" + time.Now().Add(-30*day).Format(softDeleteLayout)
+	for _, name := range []string{"a.log", "b.log", "c.log"} {
+		writeFile(t, fsys, "/purge/"+name+deleted, "0123456789", 30*day)
+	}
+	config.Directories = []DirConfig{{Path: "/purge", Days: 3, DeleteMode: deleteModeRename}}
+	config.MaxBytesPerRun = 25
+	report, err := New(config).Run(context.Background())
+	if err == nil || report.Purged != 2 || report.FreedBytes != 20 {
+		t.Errorf("Purged = %d, FreedBytes = %d, err = %v，永久删除应计入 max_bytes_per_run，在第三个文件前停止", report.Purged, report.FreedBytes, err)
+	}
+}
@@ -0,0 +1,60 @@
+package cleaner
+
+import (
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+func isCompressed(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".gz")
+}
+
+// gzipFile 将文件原地压缩为 path.gz，保留原文件的修改时间后删除原文件，返回压缩后的大小
+func gzipFile(fsys afero.Fs, path string, info fs.FileInfo) (int64, error) {
+	src, err := fsys.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	tmpPath := path + ".gz.tmp"
+	dst, err := fsys.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return 0, err
+	}
+	zw := gzip.NewWriter(dst)
+	zw.Name = info.Name()
+	zw.ModTime = info.ModTime()
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fsys.Remove(tmpPath)
+		return 0, err
+	}
+	// 压缩文件沿用原修改时间，之后仍按原文件的年龄判断是否删除
+	if err := fsys.Chtimes(tmpPath, info.ModTime(), info.ModTime()); err != nil {
+		fsys.Remove(tmpPath)
+		return 0, err
+	}
+	gzInfo, err := fsys.Stat(tmpPath)
+	if err != nil {
+		fsys.Remove(tmpPath)
+		return 0, err
+	}
+	if err := fsys.Rename(tmpPath, path+".gz"); err != nil {
+		fsys.Remove(tmpPath)
+		return 0, err
+	}
+	src.Close()
+	return gzInfo.Size(), fsys.Remove(path)
+}
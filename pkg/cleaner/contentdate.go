@@ -0,0 +1,138 @@
+package cleaner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// contentDateBytes 读取首行或末行时最多读取的字节数，超过的部分不参与解析
+const contentDateBytes = 64 << 10
+
+// ContentDate 从文件首行或末行中的时间戳判断年龄，用于复制、解压或归档后修改时间不可靠的轮转日志；
+// 只用于本地目录，.gz 文件只能读取首行
+type ContentDate struct {
+	NameDate `yaml:",inline" mapstructure:",squash"`
+	Line     string `yaml:"line" mapstructure:"line"` // first（默认，文件中最早的记录）或 last（最后一条记录）
+}
+
+func (c *ContentDate) validate(fold bool) error {
+	if c.Line == "" {
+		c.Line = "first"
+	}
+	if c.Line != "first" && c.Line != "last" {
+		return i18n.Errorf("content_date.line %q 无效，可选 first、last", c.Line)
+	}
+	if err := c.compile(fold); err != nil {
+		return i18n.Errorf("content_date: %w", err)
+	}
+	return nil
+}
+
+// contentDate 缓存的解析结果，文件大小或修改时间变化后重新解析
+type contentDate struct {
+	size int64
+	mod  time.Time
+	t    time.Time
+	ok   bool
+}
+
+// contentDates 本次任务中已解析过的文件，同一文件在扫描、归档目录、max_files 等步骤中只读取一次
+type contentDates struct {
+	mu    sync.Mutex
+	files map[string]contentDate
+}
+
+func (c *contentDates) reset() {
+	c.mu.Lock()
+	c.files = nil
+	c.mu.Unlock()
+}
+
+// fileTime 返回用于判断年龄的时间：依次使用 name_date 解析出的日期、content_date 从内容中解析出的时间和修改时间
+func (cl *Cleaner) fileTime(d DirConfig, path string, info fs.FileInfo) time.Time {
+	if d.NameDate != nil {
+		if t, ok := d.NameDate.parse(info.Name()); ok {
+			return t
+		}
+	}
+	if d.ContentDate == nil || !info.Mode().IsRegular() {
+		return info.ModTime()
+	}
+	cl.dates.mu.Lock()
+	c, ok := cl.dates.files[path]
+	cl.dates.mu.Unlock()
+	if !ok || c.size != info.Size() || !c.mod.Equal(info.ModTime()) {
+		c = contentDate{size: info.Size(), mod: info.ModTime()}
+		line, err := cl.readLine(path, info.Size(), d.ContentDate.Line == "last")
+		if err == nil {
+			c.t, c.ok = d.ContentDate.parse(line)
+		}
+		if !c.ok {
+			cl.debugf("无法从 %s 的%s中解析时间，使用修改时间", path, lineName(d.ContentDate.Line))
+		}
+		cl.dates.mu.Lock()
+		if cl.dates.files == nil {
+			cl.dates.files = map[string]contentDate{}
+		}
+		cl.dates.files[path] = c
+		cl.dates.mu.Unlock()
+	}
+	if c.ok {
+		return c.t
+	}
+	return info.ModTime()
+}
+
+func lineName(line string) string {
+	if line == "last" {
+		return i18n.T("末行")
+	}
+	return i18n.T("首行")
+}
+
+// readLine 读取文件的首行或末行（忽略末尾的空行），最多读取 contentDateBytes 字节
+func (cl *Cleaner) readLine(path string, size int64, last bool) (string, error) {
+	f, err := cl.fs.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		if last {
+			return "", i18n.Errorf("不支持读取 .gz 文件的末行")
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return "", err
+		}
+		defer zr.Close()
+		r = zr
+	} else if last && size > contentDateBytes {
+		if _, err := f.Seek(size-contentDateBytes, io.SeekStart); err != nil {
+			return "", err
+		}
+	}
+	buf := make([]byte, contentDateBytes)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	data := buf[:n]
+	if last {
+		data = bytes.TrimRight(data, "\r\n")
+		if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	} else if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[:i]
+	}
+	return string(bytes.TrimRight(data, "\r")), nil
+}
@@ -0,0 +1,100 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// 默认支持的日期目录名格式
+var defaultDateLayouts = []string{"20060102", "2006-01-02"}
+
+// parseDirDate 按配置的格式解析目录名中的日期
+func (d DirConfig) parseDirDate(name string) (time.Time, bool) {
+	layouts := d.DateLayouts
+	if len(layouts) == 0 {
+		layouts = defaultDateLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, name, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// cleanDateDirs 删除目录名日期早于保留期限的整个子目录。
+// 目录日期按当天结束计算，避免删除仍可能在写入当天日志的目录
+func (cl *Cleaner) cleanDateDirs(dir DirConfig, now time.Time, stats *Stats) {
+	age, ok := cl.config.deleteAge(dir)
+	if !ok {
+		return
+	}
+	threshold := now.Add(-age)
+	quietSince := cl.config.quietSince(dir, now)
+	entries, err := cl.readDir(dir, dir.Path)
+	if err != nil {
+		return
+	}
+	ignore := cl.loadIgnore(dir.Path)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if ignore.match(entry.Name(), true) {
+			cl.debugf("跳过 %s：受 %s 保护", filepath.Join(dir.Path, entry.Name()), ignoreFileName)
+			continue
+		}
+		date, ok := dir.parseDirDate(entry.Name())
+		if !ok || !date.AddDate(0, 0, 1).Before(threshold) {
+			continue
+		}
+		if n, ok := dir.containsNested(filepath.Join(dir.Path, entry.Name())); ok {
+			cl.debugf("跳过 %s：其中的目录 %s 单独配置，按其自身的策略处理", filepath.Join(dir.Path, entry.Name()), n)
+			continue
+		}
+		// 目录本身最近有变化（新增或删除文件）时同样受静默期保护
+		if info, err := entry.Info(); err == nil && !quietSince.IsZero() && info.ModTime().After(quietSince) {
+			cl.skipf(stats, "跳过 %s：静默期内修改过", filepath.Join(dir.Path, entry.Name()))
+			stats.Quiet++
+			stats.account(filepath.Join(dir.Path, entry.Name()))
+			continue
+		}
+		path := filepath.Join(dir.Path, entry.Name())
+		size, walked := treeSize(cl.fs, path, cl.config.walkLimits(dir))
+		if walked.crossed {
+			// RemoveAll 会进入挂载点，删除其他卷上的文件
+			cl.warnf("跳过 %s：其中有挂载点或联接点（cross_devices 未开启）", path)
+			continue
+		}
+		if walked.limited {
+			cl.warnf("目录 %s 超过 %d 层（max_depth），统计的释放空间不完整", path, cl.config.maxDepth(dir))
+		}
+		if !cl.allow(stats, path, size) {
+			return
+		}
+		if err := cl.fs.RemoveAll(path); err != nil {
+			cl.errorf("删除日期目录失败: %s", err)
+			stats.fail(err)
+			stats.account(path)
+			continue
+		}
+		cl.matchf(stats, "删除日期目录 %s（释放 %s）", path, ByteSize(size))
+		stats.FreedBytes += size
+		stats.DeletedDirs++
+	}
+}
+
+// treeSize 统计目录树中所有文件的大小，返回因限制跳过的内容
+func treeSize(fsys afero.Fs, root string, lim walkLimits) (int64, walkResult) {
+	var size int64
+	res := walkTree(fsys, root, lim, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, res
+}
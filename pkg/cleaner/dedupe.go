@@ -0,0 +1,92 @@
+package cleaner
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Duplicate 去重时删除的文件，以及保留的内容相同的最新文件
+type Duplicate struct {
+	Path   string `json:"path"`
+	Kept   string `json:"kept"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// dedupe 在满足过滤条件的文件中查找大小和 SHA-256 都相同的文件，只保留修改时间最新的一个，
+// 其余的不论是否到期都删除；删除前执行与到期文件相同的检查（guardExtra、confirmMark）。返回已删除的文件路径及其大小
+func (cl *Cleaner) dedupe(ctx context.Context, dir DirConfig, files []File, del deleteAction, quietSince time.Time, protected []string, stats *Stats) map[string]int64 {
+	bySize := map[int64][]File{}
+	for _, f := range files {
+		// 空文件内容都相同，不参与去重；静默期内的文件可能仍在写入
+		if f.Info.Size() == 0 || (!quietSince.IsZero() && f.Info.ModTime().After(quietSince)) {
+			continue
+		}
+		bySize[f.Info.Size()] = append(bySize[f.Info.Size()], f)
+	}
+	removed := map[string]int64{}
+	var dups []File
+	kept := map[string]Duplicate{}
+	for _, group := range bySize {
+		if len(group) < 2 {
+			continue
+		}
+		byHash := map[string][]File{}
+		for _, f := range group {
+			if ctx.Err() != nil {
+				return removed
+			}
+			sum, err := fileSHA256(f.FS, f.Path)
+			if err != nil {
+				cl.errorf("计算 %s 的校验和失败: %s", f.Path, err)
+				stats.fail(err)
+				stats.account(f.Path)
+				continue
+			}
+			byHash[sum] = append(byHash[sum], f)
+		}
+		for sum, same := range byHash {
+			if len(same) < 2 {
+				continue
+			}
+			sort.Slice(same, func(i, j int) bool { return same[i].Info.ModTime().After(same[j].Info.ModTime()) })
+			for _, f := range same[1:] {
+				dups = append(dups, f)
+				kept[f.Path] = Duplicate{Path: f.Path, Kept: same[0].Path, Size: f.Info.Size(), SHA256: sum}
+			}
+		}
+	}
+	for _, f := range cl.guardExtra(dir, dups, quietSince, protected, stats) {
+		if ctx.Err() != nil {
+			break
+		}
+		if !cl.confirmMark(f, del, stats) {
+			continue
+		}
+		d := kept[f.Path]
+		if _, ok := cl.apply(del, &f, stats); !ok {
+			continue
+		}
+		cl.matchf(stats, "删除重复文件 %s（与 %s 相同）", d.Path, d.Kept)
+		stats.Deduplicated++
+		stats.Duplicates = append(stats.Duplicates, d)
+		removed[d.Path] = d.Size
+	}
+	sort.Slice(stats.Duplicates, func(i, j int) bool { return stats.Duplicates[i].Path < stats.Duplicates[j].Path })
+	return removed
+}
+
+// withoutRemoved 去掉已被去重删除的候选文件
+func withoutRemoved(cs []candidate, removed map[string]int64) []candidate {
+	if len(removed) == 0 {
+		return cs
+	}
+	kept := cs[:0]
+	for _, c := range cs {
+		if _, ok := removed[c.file.Path]; !ok {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
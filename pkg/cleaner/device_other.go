@@ -0,0 +1,17 @@
+//go:build !windows
+
+package cleaner
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileDevice 返回文件所在的设备号；Unix 上挂载点（包括跨文件系统的 bind mount）以设备号变化识别
+func fileDevice(info os.FileInfo) (dev uint64, reparse bool, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false, false
+	}
+	return uint64(st.Dev), false, true
+}
@@ -0,0 +1,17 @@
+package cleaner
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileDevice Windows 上不比较卷，NTFS 联接点、卷挂载点等重解析点一律视为边界
+func fileDevice(info os.FileInfo) (dev uint64, reparse bool, ok bool) {
+	if info.Mode()&(os.ModeSymlink|os.ModeIrregular) != 0 {
+		return 0, true, false
+	}
+	if attr, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return 0, attr.FileAttributes&syscall.FILE_ATTRIBUTE_REPARSE_POINT != 0, false
+	}
+	return 0, false, false
+}
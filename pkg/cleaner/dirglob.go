@@ -0,0 +1,47 @@
+package cleaner
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// hasGlob 判断本地路径中是否包含通配符
+func hasGlob(path string) bool {
+	return !isRemote(path) && strings.ContainsAny(path, "*?[")
+}
+
+// directories 返回本次任务要清理的目录：路径中带通配符的目录（如 D:\apps\*\logs、/var/log/myapp-*）
+// 在每次任务开始时展开，新部署的应用实例无需修改配置即可被清理；展开后重叠的目录项按 resolveOverlaps 处理
+func (cl *Cleaner) directories() ([]DirConfig, []Overlap) {
+	var dirs []DirConfig
+	for _, dir := range cl.config.Directories {
+		if !hasGlob(dir.Path) {
+			dirs = append(dirs, dir)
+			continue
+		}
+		matches, err := afero.Glob(cl.fs, dir.Path)
+		if err != nil {
+			cl.errorf("目录通配符 %s 无效: %s", dir.Path, err)
+			continue
+		}
+		n := 0
+		for _, m := range matches {
+			if info, err := cl.fs.Stat(m); err != nil || !info.IsDir() {
+				continue
+			}
+			d := dir
+			d.Path = filepath.Clean(m)
+			d.source = dir.Path
+			dirs = append(dirs, d)
+			n++
+		}
+		if n == 0 {
+			cl.warnf("目录通配符 %s 没有匹配的目录", dir.Path)
+		} else {
+			cl.debugf("目录通配符 %s 匹配 %d 个目录", dir.Path, n)
+		}
+	}
+	return resolveOverlaps(dirs)
+}
@@ -0,0 +1,104 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/spf13/afero"
+)
+
+// DockerConfig Docker json-file 日志清理模式：path 指向 /var/lib/docker/containers，
+// 对各容器目录下超过大小或保留期限的 *-json.log 执行截断或轮转，不删除文件以免影响 docker logs
+type DockerConfig struct {
+	MaxSize ByteSize `yaml:"max_size" mapstructure:"max_size"` // 超过该大小即处理，为 0 时只按年龄判断
+	Action  string   `yaml:"action" mapstructure:"action"`     // truncate（默认）或 rotate：先压缩保存副本再截断
+	Keep    int      `yaml:"keep" mapstructure:"keep"`         // rotate 时保留的压缩副本数，默认 1
+}
+
+func (d *DockerConfig) validate() error {
+	switch d.Action {
+	case "":
+		d.Action = "truncate"
+	case "truncate", "rotate":
+	default:
+		return i18n.Errorf("docker.action %q 无效", d.Action)
+	}
+	if d.Keep <= 0 {
+		d.Keep = 1
+	}
+	return nil
+}
+
+// cleanDockerLogs 处理 dir.Path 下各容器目录中的 json-file 日志
+func (cl *Cleaner) cleanDockerLogs(dir DirConfig, now time.Time, stats *Stats) {
+	logs, err := afero.Glob(cl.fs, filepath.Join(dir.Path, "*", "*-json.log"))
+	if err != nil {
+		cl.errorf("查找容器日志失败: %s", err)
+		return
+	}
+	threshold := now.Add(-cl.config.retention(dir))
+	for _, path := range logs {
+		info, err := cl.fs.Stat(path)
+		if err != nil {
+			cl.errorf("获取文件信息失败: %s", err)
+			stats.fail(err)
+			continue
+		}
+		stats.Scanned++
+		tooLarge := dir.Docker.MaxSize > 0 && info.Size() > int64(dir.Docker.MaxSize)
+		if info.Size() == 0 || (!tooLarge && !info.ModTime().Before(threshold)) {
+			continue
+		}
+		stats.Matched++
+		if !cl.allow(stats, path, info.Size()) {
+			return
+		}
+		if dir.Docker.Action == "rotate" {
+			if err := rotateDockerLog(cl.fs, path, info, dir.Docker.Keep); err != nil {
+				cl.errorf("轮转容器日志失败: %s", err)
+				stats.fail(err)
+				continue
+			}
+		}
+		if err := truncateFile(cl.fs, path, info.Size(), 0); err != nil {
+			cl.errorf("截断容器日志失败: %s", err)
+			stats.fail(err)
+			continue
+		}
+		cl.matchf(stats, "%s %s（释放 %s）", actionTruncate, path, ByteSize(info.Size()))
+		stats.FreedBytes += info.Size()
+		stats.Truncated++
+	}
+}
+
+// rotateDockerLog 将当前日志压缩保存为 <name>.<时间>.gz，并只保留最近 keep 个副本
+func rotateDockerLog(fsys afero.Fs, path string, info os.FileInfo, keep int) error {
+	rotated := path + "." + time.Now().Format("20060102150405")
+	if err := copyFile(fsys, path, rotated); err != nil {
+		fsys.Remove(rotated)
+		return err
+	}
+	rotatedInfo, err := fsys.Stat(rotated)
+	if err != nil {
+		return err
+	}
+	if _, err := gzipFile(fsys, rotated, rotatedInfo); err != nil {
+		return err
+	}
+	old, err := afero.Glob(fsys, path+".*.gz")
+	if err != nil {
+		return err
+	}
+	// 时间戳格式的文件名按字典序即为时间顺序
+	sort.Strings(old)
+	for len(old) > keep {
+		if err := fsys.Remove(old[0]); err != nil {
+			return err
+		}
+		old = old[1:]
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package cleaner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+const defaultElasticTimeout = 30 * time.Second
+
+// ElasticConfig Elasticsearch 按时间命名的索引（如 app-logs-2024.03.15）的保留策略，
+// 通过 REST API 删除或关闭索引名中日期早于保留期的索引，适合未配置 ILM 的集群
+type ElasticConfig struct {
+	URL        string        `yaml:"url" mapstructure:"url"`                 // 如 http://es01:9200
+	Index      string        `yaml:"index" mapstructure:"index"`             // 索引名模式，* 为日期所在位置，如 app-logs-*
+	DateFormat string        `yaml:"date_format" mapstructure:"date_format"` // 索引名中日期的格式（Go 时间格式），默认 2006.01.02
+	Action     string        `yaml:"action" mapstructure:"action"`           // delete（默认）或 close
+	Days       int           `yaml:"days" mapstructure:"days"`               // 保留天数，默认使用全局 days
+	MaxAge     time.Duration `yaml:"max_age" mapstructure:"max_age"`
+	Username   string        `yaml:"username" mapstructure:"username"`
+	Password   string        `yaml:"password" mapstructure:"password"` // 可使用 ${ENV} 引用环境变量
+	APIKey     string        `yaml:"api_key" mapstructure:"api_key"`   // Base64 编码的 API key，配置后不使用用户名密码
+	DryRun     bool          `yaml:"dry_run" mapstructure:"dry_run"`   // 只列出到期的索引，不删除或关闭
+	Timeout    time.Duration `yaml:"timeout" mapstructure:"timeout"`   // 单个请求的超时，默认 30s
+}
+
+// ElasticReport 单个索引模式的统计
+type ElasticReport struct {
+	Index      string   `json:"index"`
+	Action     string   `json:"action"`
+	DryRun     bool     `json:"dry_run,omitempty"`
+	Indices    []string `json:"indices,omitempty"` // 已处理（dry_run 时为将要处理）的索引
+	FreedBytes int64    `json:"freed_bytes"`       // 删除的索引占用的存储（含副本）
+	Error      string   `json:"error,omitempty"`
+}
+
+func (e *ElasticConfig) validate() error {
+	if e.DateFormat == "" {
+		e.DateFormat = "2006.01.02"
+	}
+	if e.Action == "" {
+		e.Action = "delete"
+	}
+	if e.Action != "delete" && e.Action != "close" {
+		return i18n.Errorf("action %q 无效，可选 delete、close", e.Action)
+	}
+	u, err := url.Parse(e.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return i18n.Errorf("url %q 无效", e.URL)
+	}
+	if strings.Count(e.Index, "*") != 1 || strings.ContainsAny(e.Index, ",/ ") {
+		return i18n.Errorf("index %q 应包含且只包含一个表示日期的 *", e.Index)
+	}
+	return nil
+}
+
+// indexDate 从索引名中解析日期，不符合模式时返回 false
+func (e ElasticConfig) indexDate(name string) (time.Time, bool) {
+	prefix, suffix, _ := strings.Cut(e.Index, "*")
+	if len(name) < len(prefix)+len(suffix) || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(e.DateFormat, name[len(prefix):len(name)-len(suffix)], time.Local)
+	return t, err == nil
+}
+
+// elasticIndex _cat/indices 返回的一项
+type elasticIndex struct {
+	Index  string `json:"index"`
+	Status string `json:"status"`
+	Size   string `json:"store.size"`
+}
+
+type elasticClient struct {
+	cfg  ElasticConfig
+	base string
+	http *http.Client
+}
+
+func (c *elasticClient) do(ctx context.Context, method, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+ExpandPath(c.cfg.APIKey))
+	} else if c.cfg.Username != "" {
+		req.SetBasicAuth(c.cfg.Username, ExpandPath(c.cfg.Password))
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s %s", method, path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// cleanElastic 依次处理配置的索引模式，单个模式失败计入 Failed，不影响其他模式
+func (cl *Cleaner) cleanElastic(ctx context.Context, now time.Time, stats *Report) {
+	for _, e := range cl.config.Elasticsearch {
+		if ctx.Err() != nil {
+			return
+		}
+		er := ElasticReport{Index: e.Index, Action: e.Action, DryRun: e.DryRun}
+		err := cl.cleanIndices(ctx, e, now.Add(-cl.config.retention(DirConfig{MaxAge: e.MaxAge, Days: e.Days})), &er)
+		switch {
+		case err != nil:
+			er.Error = err.Error()
+			stats.fail(err)
+			cl.errorf("清理 Elasticsearch 索引 %s 失败（已处理 %d 个）: %s", e.Index, len(er.Indices), err)
+		case e.DryRun:
+			cl.logf("Elasticsearch 索引 %s: dry_run，到期 %d 个: %s", e.Index, len(er.Indices), strings.Join(er.Indices, ", "))
+		default:
+			cl.logf("Elasticsearch 索引 %s: %s %d 个，释放 %s", e.Index, e.Action, len(er.Indices), ByteSize(er.FreedBytes))
+		}
+		stats.Elasticsearch = append(stats.Elasticsearch, er)
+	}
+}
+
+// cleanIndices 列出匹配模式的索引，删除或关闭索引名日期早于 before 的索引
+func (cl *Cleaner) cleanIndices(ctx context.Context, e ElasticConfig, before time.Time, er *ElasticReport) error {
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = defaultElasticTimeout
+	}
+	c := &elasticClient{cfg: e, base: strings.TrimSuffix(e.URL, "/"), http: &http.Client{Timeout: timeout}}
+	var indices []elasticIndex
+	err := c.do(ctx, http.MethodGet, "/_cat/indices/"+url.PathEscape(e.Index)+
+		"?format=json&h=index,status,store.size&bytes=b&expand_wildcards=open,closed", &indices)
+	if err != nil {
+		return err
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i].Index < indices[j].Index })
+	for _, idx := range indices {
+		day, ok := e.indexDate(idx.Index)
+		if !ok {
+			cl.debugf("Elasticsearch 索引 %s 的名称中没有 %s 格式的日期，跳过", idx.Index, e.DateFormat)
+			continue
+		}
+		// 索引名为当天的日期，次日才开始计算年龄
+		if !day.AddDate(0, 0, 1).Before(before) || (e.Action == "close" && idx.Status == "close") {
+			continue
+		}
+		if e.DryRun {
+			er.Indices = append(er.Indices, idx.Index)
+			continue
+		}
+		if e.Action == "close" {
+			err = c.do(ctx, http.MethodPost, "/"+url.PathEscape(idx.Index)+"/_close", nil)
+		} else {
+			err = c.do(ctx, http.MethodDelete, "/"+url.PathEscape(idx.Index), nil)
+		}
+		if err != nil {
+			return err
+		}
+		er.Indices = append(er.Indices, idx.Index)
+		if e.Action == "delete" {
+			size, _ := strconv.ParseInt(idx.Size, 10, 64)
+			er.FreedBytes += size
+		}
+		cl.debugf("Elasticsearch 索引 %s: %s", idx.Index, e.Action)
+	}
+	return nil
+}
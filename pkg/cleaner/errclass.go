@@ -0,0 +1,101 @@
+package cleaner
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// 失败原因的分类，用于任务结果、/metrics 和告警中按原因统计
+const (
+	ErrorPermission  = "permission"
+	ErrorInUse       = "in_use"
+	ErrorNotFound    = "not_found"
+	ErrorPathTooLong = "path_too_long"
+	ErrorNetwork     = "network"
+	ErrorOther       = "other"
+)
+
+// ErrorCategories 全部失败分类，按输出顺序排列
+var ErrorCategories = []string{ErrorPermission, ErrorInUse, ErrorNotFound, ErrorPathTooLong, ErrorNetwork, ErrorOther}
+
+var errorCategoryNames = map[string]string{
+	ErrorPermission:  "权限不足",
+	ErrorInUse:       "被占用",
+	ErrorNotFound:    "不存在",
+	ErrorPathTooLong: "路径过长",
+	ErrorNetwork:     "网络",
+	ErrorOther:       "其他",
+}
+
+// classifyError 按错误原因归类：先按系统错误码判断占用和路径过长，再判断权限、不存在和网络错误
+func classifyError(err error) string {
+	if err == nil {
+		return ErrorOther
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		switch {
+		case inUseErrno(errno):
+			return ErrorInUse
+		case errno == syscall.ENAMETOOLONG || pathTooLongErrno(errno):
+			return ErrorPathTooLong
+		}
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, os.ErrPermission):
+		return ErrorPermission
+	case errors.Is(err, os.ErrNotExist):
+		return ErrorNotFound
+	case isTransient(err), errors.As(err, &netErr):
+		return ErrorNetwork
+	}
+	return ErrorOther
+}
+
+// fail 记录一个处理失败的文件或目录
+func (s *Stats) fail(err error) {
+	s.Failed++
+	s.classify(classifyError(err))
+}
+
+// classify 按分类计数，Failed 和 Transient 中的每一项都计入一个分类
+func (s *Stats) classify(category string) {
+	if s.Errors == nil {
+		s.Errors = map[string]int{}
+	}
+	s.Errors[category]++
+}
+
+// unclassify 重试成功后撤销 classify
+func (s *Stats) unclassify(category string) {
+	if s.Errors[category]--; s.Errors[category] <= 0 {
+		delete(s.Errors, category)
+	}
+}
+
+// DescribeErrors 返回按分类统计的失败数说明，如“权限不足 3，被占用 1”，没有失败时返回空
+func DescribeErrors(errs map[string]int) string {
+	var parts []string
+	for _, c := range ErrorCategories {
+		if n := errs[c]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", i18n.T(errorCategoryNames[c]), n))
+		}
+	}
+	// 兼容未知的分类
+	var extra []string
+	for c, n := range errs {
+		if _, ok := errorCategoryNames[c]; !ok && n > 0 {
+			extra = append(extra, fmt.Sprintf("%s %d", c, n))
+		}
+	}
+	sort.Strings(extra)
+	return strings.Join(append(parts, extra...), "，")
+}
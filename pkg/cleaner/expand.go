@@ -0,0 +1,68 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// envPattern 匹配 ${VAR} 和 %VAR%。不展开 $VAR 写法，避免误改 \\server\d$\logs、$Recycle.Bin 等路径
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|%([A-Za-z_][A-Za-z0-9_()]*)%`)
+
+// ExpandPath 展开路径中的环境变量（${LOG_ROOT}、%TEMP%）和开头的 ~（当前用户的主目录），
+// 未设置的环境变量保持原样
+func ExpandPath(path string) string {
+	path = envPattern.ReplaceAllStringFunc(path, func(m string) string {
+		name := strings.Trim(m, "${}%")
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		return m
+	})
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// ExpandPaths 展开目录、归档目录、待上传目录和失败记录文件路径中的环境变量和 ~，
+// 同一份配置可以用于目录结构不同的机器；应在 Validate 之前调用
+func (c *Config) ExpandPaths() {
+	c.mapPaths(ExpandPath)
+}
+
+// ResolvePaths 把相对路径解析为相对于 base（通常为配置文件所在目录）的绝对路径。
+// 服务的工作目录不固定，相对路径直接使用时结果不可预期；应在 ExpandPaths 之后、Validate 之前调用
+func (c *Config) ResolvePaths(base string) {
+	c.mapPaths(func(path string) string {
+		return ResolvePath(base, path)
+	})
+}
+
+// ResolvePath 把单个相对路径解析为相对于 base 的路径，供服务自身的配置项使用
+func ResolvePath(base, path string) string {
+	// 远程地址和以 / 或 \ 开头（Windows 上为当前盘符的根目录）的路径不处理
+	if path == "" || isRemote(path) || filepath.IsAbs(path) || strings.HasPrefix(path, "/") || strings.HasPrefix(path, `\`) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+// mapPaths 对配置中的所有本地路径执行 fn
+func (c *Config) mapPaths(fn func(string) string) {
+	c.FailureState = fn(c.FailureState)
+	c.MarkState = fn(c.MarkState)
+	c.ScanCache = fn(c.ScanCache)
+	for i := range c.Directories {
+		d := &c.Directories[i]
+		d.Path = fn(d.Path)
+		d.Tiers = append([]Tier(nil), d.Tiers...)
+		for j := range d.Tiers {
+			d.Tiers[j].ArchiveDir = fn(d.Tiers[j].ArchiveDir)
+			d.Tiers[j].UploadBacklog = fn(d.Tiers[j].UploadBacklog)
+		}
+	}
+}
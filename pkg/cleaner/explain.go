@@ -0,0 +1,114 @@
+package cleaner
+
+import (
+	"path/filepath"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// DirPlan 目录生效的处理计划，通配符目录展开为实际匹配的目录
+type DirPlan struct {
+	Path       string     `json:"path"`
+	Rules      []RulePlan `json:"rules"`
+	QuietSince time.Time  `json:"quiet_since,omitempty"` // 修改时间晚于该时间的文件不处理
+	TargetSize ByteSize   `json:"target_size,omitempty"`
+}
+
+// RulePlan 一级保留策略及其按当前时间计算的阈值
+type RulePlan struct {
+	Tier   string    `json:"tier"`
+	Before time.Time `json:"before"` // 判断年龄所用的时间早于该时间的文件执行该策略
+}
+
+// Plan 返回各目录生效的保留策略和按 now 计算的处理阈值
+func (cl *Cleaner) Plan(now time.Time) ([]DirPlan, error) {
+	if cl.err != nil {
+		return nil, cl.err
+	}
+	var plans []DirPlan
+	dirs, _ := cl.directories()
+	for _, dir := range dirs {
+		p := DirPlan{Path: dir.Path, QuietSince: cl.config.quietSince(dir, now), TargetSize: dir.TargetSize}
+		for _, r := range dir.policy.rules {
+			p.Rules = append(p.Rules, RulePlan{Tier: r.tier.String(), Before: now.Add(-r.tier.age())})
+		}
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
+
+// ExplainFile 按当前配置逐步判断 path 是否会被处理，返回每一步的结论。只检查文件所在目录直接配置的规则
+func (cl *Cleaner) ExplainFile(path string, now time.Time) ([]string, error) {
+	if cl.err != nil {
+		return nil, cl.err
+	}
+	path = filepath.Clean(path)
+	var dir *DirConfig
+	dirs, _ := cl.directories()
+	for _, d := range dirs {
+		if filepath.Clean(d.Path) == filepath.Dir(path) {
+			d := d
+			dir = &d
+			break
+		}
+	}
+	if dir == nil {
+		return []string{i18n.T("文件不在任何配置的目录中（子目录中的文件不处理）")}, nil
+	}
+	steps := []string{i18n.Sprintf("所属目录: %s", dir.Path)}
+	name := filepath.Base(path)
+	if cl.loadIgnore(dir.Path).match(name, false) {
+		return append(steps, i18n.Sprintf("受 %s 保护，不处理", ignoreFileName)), nil
+	}
+	if !matchNames(dir.policy.filters, name) {
+		return append(steps, i18n.T("文件名不满足 extensions、exclude_extensions 或过滤器，不处理")), nil
+	}
+	info, err := cl.fs.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return append(steps, i18n.T("是目录，只在 date_dirs、subtrees 中按整个目录处理")), nil
+	}
+	f := File{FS: cl.fs, Path: path, Info: info, Time: cl.fileTime(*dir, path, info)}
+	steps = append(steps, i18n.Sprintf("判断年龄所用的时间: %s，大小: %s", f.Time.Format(time.DateTime), ByteSize(info.Size())))
+	if !matchAll(dir.policy.filters, f, now) {
+		return append(steps, i18n.T("不满足目录的过滤器，不处理")), nil
+	}
+	for _, r := range dir.policy.rules {
+		before := now.Add(-r.tier.age())
+		switch {
+		case !f.Time.Before(before):
+			steps = append(steps, i18n.Sprintf("%s: 未到期（需早于 %s）", r.tier, before.Format(time.DateTime)))
+		case !r.match(f, now):
+			steps = append(steps, i18n.Sprintf("%s: 已到期，但不满足该 tier 的过滤器", r.tier))
+		default:
+			steps = append(steps, i18n.Sprintf("%s: 已到期", r.tier))
+		}
+	}
+	k := selectRule(dir.policy.rules, f, now)
+	if k < 0 {
+		return append(steps, i18n.T("没有到期的策略，不处理")), nil
+	}
+	action := dir.policy.rules[k].action
+	if q := cl.config.quietSince(*dir, now); !q.IsZero() && info.ModTime().After(q) && !handlesActiveFiles(action) {
+		return append(steps, i18n.Sprintf("修改时间晚于静默期起点 %s，本次跳过", q.Format(time.DateTime))), nil
+	}
+	if dir.TargetSize > 0 {
+		steps = append(steps, i18n.Sprintf("配置了 target_size %s，目录总大小降到该值以下后剩余文件会保留", dir.TargetSize))
+	}
+	if dir.MaxFiles > 0 {
+		steps = append(steps, i18n.Sprintf("配置了 max_files %d，文件数超过该值时未到期的文件也可能按时间从旧到新删除", dir.MaxFiles))
+	}
+	if skip, _ := cl.config.skipInUse(*dir); skip && isOsFs(cl.fs) && fileLocked(path) {
+		return append(steps, i18n.T("文件正被其他进程使用，本次跳过")), nil
+	}
+	if h, ok := f.heldBy(cl.config.protectedProcesses(*dir)); ok {
+		return append(steps, i18n.Sprintf("文件被进程 %s（PID %d）打开，在 protected_processes 中，本次跳过", h.Name, h.PID)), nil
+	}
+	if cl.config.MarkState != "" && removesFile(action) {
+		steps = append(steps, i18n.T("开启了两阶段删除，首次满足条件时只标记，下次任务才处理"))
+	}
+	return append(steps, i18n.Sprintf("下次任务将执行: %s", dir.policy.rules[k].tier)), nil
+}
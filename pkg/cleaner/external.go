@@ -0,0 +1,227 @@
+package cleaner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+const (
+	defaultExternalTimeout = 10 * time.Second
+	externalIdle           = 30 * time.Second // 外部程序空闲该时长后关闭，下一个文件时重新启动
+)
+
+func init() {
+	RegisterFilter("external", newExternalFilter)
+}
+
+// externalRequest 写入外部过滤程序标准输入的一行
+type externalRequest struct {
+	Path    string    `json:"path"`
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Time    time.Time `json:"time"`        // 判断年龄所用的时间：修改时间或文件名、内容中的日期
+	Age     float64   `json:"age_seconds"` // 按 time 计算的年龄
+}
+
+// externalReply 外部过滤程序对每个文件返回的一行
+type externalReply struct {
+	Path     string `json:"path"`     // 可省略，填写时须与请求相同
+	Decision string `json:"decision"` // delete 处理该文件，keep 保留
+}
+
+// externalFilter 把候选文件的信息以 JSON 行写入外部程序的标准输入，按程序逐行返回的 keep/delete 决定是否处理该文件，
+// 用于实现业务相关的规则而不必修改服务。程序在第一个文件时启动，之后一直复用，空闲 30s 后关闭标准输入。
+// 程序出错、超时或返回无法识别的内容时按 on_error 处理（默认 keep），错误在目录处理完后记录到日志。
+// 配置在 tier 的 filters 中时只对已到期的文件调用；配置在目录的 filters 中时对每个满足前面过滤条件的文件调用
+type externalFilter struct {
+	*externalProc
+}
+
+type externalProc struct {
+	command []string
+	timeout time.Duration
+	onError bool // 出错时是否处理该文件
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	lines   chan string
+	idle    *time.Timer
+	cache   map[string]bool // 本次启动后已判断过的文件，同一任务中可能对一个文件多次匹配
+	errors  int
+	lastErr error
+}
+
+func newExternalFilter(params map[string]interface{}) (Filter, error) {
+	var cfg struct {
+		Command []string      `mapstructure:"command"`
+		Timeout time.Duration `mapstructure:"timeout"`
+		OnError string        `mapstructure:"on_error"`
+	}
+	if err := decodeParams(params, &cfg); err != nil {
+		return nil, err
+	}
+	if len(cfg.Command) == 0 {
+		return nil, i18n.Errorf("external 过滤器需要配置 command")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultExternalTimeout
+	}
+	switch cfg.OnError {
+	case "", "keep", "delete":
+	default:
+		return nil, i18n.Errorf("external 过滤器的 on_error 应为 keep 或 delete")
+	}
+	return externalFilter{&externalProc{command: cfg.Command, timeout: cfg.Timeout, onError: cfg.OnError == "delete"}}, nil
+}
+
+func (e externalFilter) Match(f File, now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := fmt.Sprintf("%s\x00%d\x00%d", f.Path, f.Info.Size(), f.Info.ModTime().UnixNano())
+	if d, ok := e.cache[key]; ok {
+		return d
+	}
+	d, err := e.decide(f, now)
+	if err != nil {
+		e.errors++
+		e.lastErr = err
+		e.stop()
+		return e.onError
+	}
+	e.cache[key] = d
+	return d
+}
+
+// decide 向外部程序发送一个文件并等待回复，需持有 mu
+func (e *externalProc) decide(f File, now time.Time) (bool, error) {
+	if e.cmd == nil {
+		if err := e.start(); err != nil {
+			return false, err
+		}
+	}
+	e.idle.Reset(externalIdle)
+	req := externalRequest{Path: f.Path, Name: f.Info.Name(), Size: f.Info.Size(), ModTime: f.Info.ModTime(), Time: f.Time, Age: now.Sub(f.Time).Seconds()}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return false, err
+	}
+	if _, err := e.stdin.Write(append(data, '\n')); err != nil {
+		return false, err
+	}
+	var line string
+	select {
+	case l, ok := <-e.lines:
+		if !ok {
+			return false, i18n.Errorf("外部程序已退出")
+		}
+		line = l
+	case <-time.After(e.timeout):
+		return false, i18n.Errorf("等待外部程序回复超时（%s）", e.timeout)
+	}
+	var reply externalReply
+	if err := json.Unmarshal([]byte(line), &reply); err != nil {
+		return false, i18n.Errorf("无法解析外部程序的回复 %q: %s", line, err)
+	}
+	if reply.Path != "" && reply.Path != f.Path {
+		return false, i18n.Errorf("外部程序回复的文件 %s 与请求的 %s 不一致", reply.Path, f.Path)
+	}
+	switch reply.Decision {
+	case "delete":
+		return true, nil
+	case "keep":
+		return false, nil
+	}
+	return false, i18n.Errorf("外部程序对 %s 的回复 %q 无效，应为 keep 或 delete", f.Path, reply.Decision)
+}
+
+// start 启动外部程序，标准错误输出到服务的标准错误
+func (e *externalProc) start() error {
+	cmd := exec.Command(e.command[0], e.command[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		sc := bufio.NewScanner(stdout)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+	e.cmd, e.stdin, e.lines, e.cache = cmd, stdin, lines, map[string]bool{}
+	e.idle = time.AfterFunc(externalIdle, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.cmd == cmd {
+			e.stop()
+		}
+	})
+	return nil
+}
+
+// stop 关闭标准输入让外部程序退出，超过 timeout 仍未退出时结束进程，需持有 mu
+func (e *externalProc) stop() {
+	if e.cmd == nil {
+		return
+	}
+	cmd, lines := e.cmd, e.lines
+	e.idle.Stop()
+	e.stdin.Close()
+	e.cmd, e.stdin, e.lines, e.cache = nil, nil, nil, nil
+	go func() {
+		// 丢弃未读的输出，避免程序阻塞在写入上
+		for range lines {
+		}
+	}()
+	timer := time.AfterFunc(e.timeout, func() { cmd.Process.Kill() })
+	go func() {
+		cmd.Wait()
+		timer.Stop()
+	}()
+}
+
+// takeErrors 返回上次调用后的出错次数和最后一个错误
+func (e *externalProc) takeErrors() (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n, err := e.errors, e.lastErr
+	e.errors, e.lastErr = 0, nil
+	return n, err
+}
+
+// reportExternal 记录目录处理期间 external 过滤器的错误
+func (cl *Cleaner) reportExternal(dir DirConfig) {
+	if dir.policy == nil {
+		return
+	}
+	filters := dir.policy.filters
+	for _, r := range dir.policy.rules {
+		filters = append(filters[:len(filters):len(filters)], r.filters...)
+	}
+	for _, f := range filters {
+		if e, ok := f.(externalFilter); ok {
+			if n, err := e.takeErrors(); n > 0 {
+				cl.warnf("目录 %s: external 过滤器 %s 出错 %d 次，按 on_error 处理: %s", dir.Path, e.command[0], n, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cleaner
+
+import (
+	"context"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// minStopAttempts 按 stop_failure_percent 判断前目录中至少处理的文件数，避免最初一两个文件失败就停止
+const minStopAttempts = 10
+
+// failureStop stop_after_failures、stop_failure_percent 在一个目录中的状态。连续或大比例的失败通常说明权限或挂载出了问题，
+// 继续处理只会消耗 I/O，达到任一条件后停止处理该目录，其他目录照常处理
+type failureStop struct {
+	path        string
+	consecutive int // 连续失败多少个文件后停止，0 不限制
+	percent     int // 失败比例超过该百分比后停止，0 不限制
+	attempts    int
+	failed      int
+	run         int // 当前连续失败的文件数
+	hit         string
+	cancel      context.CancelFunc
+}
+
+// stopLimits 返回目录的 stop_after_failures 和 stop_failure_percent，目录项中的配置优先
+func (c Config) stopLimits(d DirConfig) (int, int) {
+	n, pct := c.StopAfterFailures, c.StopFailurePercent
+	if d.StopAfterFailures > 0 {
+		n = d.StopAfterFailures
+	}
+	if d.StopFailurePercent > 0 {
+		pct = d.StopFailurePercent
+	}
+	return n, pct
+}
+
+// newFailureStop 未配置时返回 nil；否则返回只对该目录生效的 ctx，达到条件时取消
+func (cl *Cleaner) newFailureStop(ctx context.Context, dir DirConfig) (*failureStop, context.Context, context.CancelFunc) {
+	n, pct := cl.config.stopLimits(dir)
+	if n <= 0 && pct <= 0 {
+		return nil, ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &failureStop{path: dir.Path, consecutive: n, percent: pct, cancel: cancel}, ctx, cancel
+}
+
+// attempt 记录一个文件的处理结果，达到停止条件时记录原因并停止处理当前目录
+func (cl *Cleaner) attempt(s *Stats, failed bool) {
+	fs := s.failStop
+	if fs == nil || fs.hit != "" {
+		return
+	}
+	fs.attempts++
+	if !failed {
+		fs.run = 0
+		return
+	}
+	fs.failed++
+	fs.run++
+	switch {
+	case fs.consecutive > 0 && fs.run >= fs.consecutive:
+		fs.hit = i18n.Sprintf("连续 %d 个文件处理失败", fs.run)
+	case fs.percent > 0 && fs.attempts >= minStopAttempts && fs.failed*100 > fs.percent*fs.attempts:
+		fs.hit = i18n.Sprintf("%d 个文件中 %d 个处理失败，超过 %d%%", fs.attempts, fs.failed, fs.percent)
+	default:
+		return
+	}
+	cl.errorf("目录 %s %s，停止处理该目录，请检查目录的权限或挂载状态", fs.path, fs.hit)
+	fs.cancel()
+}
+
+func checkStopLimits(n, pct int) error {
+	if n < 0 {
+		return i18n.Errorf("stop_after_failures 不能为负数")
+	}
+	if pct < 0 || pct > 100 {
+		return i18n.Errorf("stop_failure_percent 应在 0 到 100 之间")
+	}
+	return nil
+}
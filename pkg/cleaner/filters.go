@@ -0,0 +1,226 @@
+package cleaner
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+func init() {
+	RegisterFilter("extension", newExtensionFilter)
+	RegisterFilter("glob", newGlobFilter)
+	RegisterFilter("size", newSizeFilter)
+	RegisterFilter("age", newAgeFilter)
+	RegisterFilter("owner", newOwnerFilter)
+	RegisterFilter("group", newGroupFilter)
+	RegisterFilter("regular", newRegularFilter)
+}
+
+// regularFilter 只匹配普通文件，跳过套接字、命名管道、设备文件和符号链接
+type regularFilter struct{}
+
+func newRegularFilter(params map[string]interface{}) (Filter, error) {
+	return regularFilter{}, nil
+}
+
+func (regularFilter) Match(f File, now time.Time) bool {
+	return f.Info.Mode().IsRegular()
+}
+
+// ageFilter 文件年龄不小于 min（且小于 max，max 为 0 表示不限）
+type ageFilter struct {
+	min time.Duration
+	max time.Duration
+}
+
+func newAgeFilter(params map[string]interface{}) (Filter, error) {
+	var cfg struct {
+		Days   int           `mapstructure:"days"`
+		MinAge time.Duration `mapstructure:"min_age"`
+		MaxAge time.Duration `mapstructure:"max_age"`
+	}
+	if err := decodeParams(params, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.MinAge == 0 {
+		cfg.MinAge = time.Duration(cfg.Days) * 24 * time.Hour
+	}
+	return ageFilter{min: cfg.MinAge, max: cfg.MaxAge}, nil
+}
+
+func (a ageFilter) Match(f File, now time.Time) bool {
+	if !f.Time.Before(now.Add(-a.min)) {
+		return false
+	}
+	return a.max <= 0 || f.Time.After(now.Add(-a.max))
+}
+
+// extensionFilter 扩展名白名单/黑名单，fold 时不区分大小写
+type extensionFilter struct {
+	include []string
+	exclude []string
+	trimGz  bool
+	fold    *bool
+}
+
+func newExtensionFilter(params map[string]interface{}) (Filter, error) {
+	var cfg struct {
+		Include         []string `mapstructure:"include"`
+		Exclude         []string `mapstructure:"exclude"`
+		CaseInsensitive *bool    `mapstructure:"case_insensitive"`
+	}
+	if err := decodeParams(params, &cfg); err != nil {
+		return nil, err
+	}
+	return extensionFilter{include: cfg.Include, exclude: cfg.Exclude, fold: cfg.CaseInsensitive}, nil
+}
+
+func (e extensionFilter) withCase(insensitive bool) Filter {
+	if e.fold == nil {
+		e.fold = &insensitive
+	}
+	return e
+}
+
+func (e extensionFilter) Match(f File, now time.Time) bool {
+	return e.MatchName(f.Info.Name())
+}
+
+func (e extensionFilter) MatchName(name string) bool {
+	if e.trimGz {
+		name = strings.TrimSuffix(name, ".gz")
+	}
+	fold := e.fold != nil && *e.fold
+	if len(e.include) > 0 && !hasExtension(name, e.include, fold) {
+		return false
+	}
+	return !hasExtension(name, e.exclude, fold)
+}
+
+func hasExtension(name string, exts []string, fold bool) bool {
+	if fold {
+		name = strings.ToLower(name)
+	}
+	for _, ext := range exts {
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if fold {
+			ext = strings.ToLower(ext)
+		}
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// globFilter 文件名匹配任一 patterns 且不匹配任一 exclude，fold 时不区分大小写
+type globFilter struct {
+	patterns []string
+	exclude  []string
+	fold     *bool
+}
+
+func newGlobFilter(params map[string]interface{}) (Filter, error) {
+	var cfg struct {
+		Patterns        []string `mapstructure:"patterns"`
+		Exclude         []string `mapstructure:"exclude"`
+		CaseInsensitive *bool    `mapstructure:"case_insensitive"`
+	}
+	if err := decodeParams(params, &cfg); err != nil {
+		return nil, err
+	}
+	for _, p := range append(append([]string{}, cfg.Patterns...), cfg.Exclude...) {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return nil, i18n.Errorf("模式 %q 无效: %s", p, err)
+		}
+	}
+	return globFilter{patterns: cfg.Patterns, exclude: cfg.Exclude, fold: cfg.CaseInsensitive}, nil
+}
+
+func (g globFilter) withCase(insensitive bool) Filter {
+	if g.fold == nil {
+		g.fold = &insensitive
+	}
+	return g
+}
+
+func (g globFilter) Match(f File, now time.Time) bool {
+	return g.MatchName(f.Info.Name())
+}
+
+func (g globFilter) MatchName(name string) bool {
+	fold := g.fold != nil && *g.fold
+	if len(g.patterns) > 0 && !matchGlob(g.patterns, name, fold) {
+		return false
+	}
+	return !matchGlob(g.exclude, name, fold)
+}
+
+func matchGlob(patterns []string, name string, fold bool) bool {
+	if fold {
+		name = strings.ToLower(name)
+	}
+	for _, p := range patterns {
+		if fold {
+			p = strings.ToLower(p)
+		}
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sizeFilter 文件大小在 [min, max] 范围内，0 表示不限
+type sizeFilter struct {
+	min ByteSize
+	max ByteSize
+}
+
+func newSizeFilter(params map[string]interface{}) (Filter, error) {
+	var cfg struct {
+		Min ByteSize `mapstructure:"min"`
+		Max ByteSize `mapstructure:"max"`
+	}
+	if err := decodeParams(params, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Max > 0 && cfg.Min > cfg.Max {
+		return nil, errors.New(i18n.T("size 过滤器的 min 大于 max"))
+	}
+	return sizeFilter{min: cfg.Min, max: cfg.Max}, nil
+}
+
+// newSizeBounds 由 min_size、max_size 构建大小过滤器，都未配置时返回 nil
+func newSizeBounds(min, max ByteSize) (Filter, error) {
+	if min <= 0 && max <= 0 {
+		return nil, nil
+	}
+	if max > 0 && min > max {
+		return nil, i18n.Errorf("min_size %s 大于 max_size %s", min, max)
+	}
+	return sizeFilter{min: min, max: max}, nil
+}
+
+func (s sizeFilter) String() string {
+	switch {
+	case s.max <= 0:
+		return i18n.Sprintf("不小于 %s", s.min)
+	case s.min <= 0:
+		return i18n.Sprintf("不大于 %s", s.max)
+	}
+	return i18n.Sprintf("%s 到 %s", s.min, s.max)
+}
+
+func (s sizeFilter) Match(f File, now time.Time) bool {
+	size := ByteSize(f.Info.Size())
+	return size >= s.min && (s.max <= 0 || size <= s.max)
+}
@@ -0,0 +1,106 @@
+package cleaner
+
+import (
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/robfig/cron/v3"
+)
+
+// freezeLayouts from、to 可用的时间格式，只写日期时 to 包含当天全天
+var freezeLayouts = []string{"2006-01-02", "2006-01-02 15:04", time.RFC3339}
+
+var freezeCronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// FreezeWindow 保留例外期：期间的任务不删除、归档或截断任何文件，也不清理数据库表和索引，
+// 用于季度末审计等要求保留全部日志的时段。固定日期用 from/to，周期性的时段用 cron 加 duration
+type FreezeWindow struct {
+	From     string        `yaml:"from" mapstructure:"from"`         // 开始日期或时间，如 2024-03-25 或 2024-03-25 18:00
+	To       string        `yaml:"to" mapstructure:"to"`             // 结束日期或时间，默认与 from 相同；只写日期时包含当天
+	Cron     string        `yaml:"cron" mapstructure:"cron"`         // 周期性例外期的开始时间，如 "0 0 0 25 3,6,9,12 *"
+	Duration time.Duration `yaml:"duration" mapstructure:"duration"` // cron 每次触发后持续的时长，如 168h
+	Reason   string        `yaml:"reason" mapstructure:"reason"`     // 写入日志和任务结果
+
+	from, to time.Time
+	schedule cron.Schedule
+}
+
+// parseFreezeTime 按本地时区解析时间，返回是否只有日期
+func parseFreezeTime(s string) (time.Time, bool, error) {
+	for i, layout := range freezeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, i == 0, nil
+		}
+	}
+	return time.Time{}, false, i18n.Errorf("时间 %q 无效，格式为 2006-01-02、2006-01-02 15:04 或 RFC3339", s)
+}
+
+func (w *FreezeWindow) validate() error {
+	if (w.From == "") == (w.Cron == "") {
+		return i18n.Errorf("需要配置 from 或 cron 之一")
+	}
+	if w.Cron != "" {
+		s, err := freezeCronParser.Parse(w.Cron)
+		if err != nil {
+			return i18n.Errorf("cron %q 无效: %s", w.Cron, err)
+		}
+		if w.Duration <= 0 {
+			return i18n.Errorf("cron 例外期需要配置 duration")
+		}
+		w.schedule = s
+		return nil
+	}
+	from, _, err := parseFreezeTime(w.From)
+	if err != nil {
+		return err
+	}
+	to := w.To
+	if to == "" {
+		to = w.From
+	}
+	end, dateOnly, err := parseFreezeTime(to)
+	if err != nil {
+		return err
+	}
+	if dateOnly {
+		end = end.AddDate(0, 0, 1)
+	}
+	if !end.After(from) {
+		return i18n.Errorf("to %q 早于 from %q", to, w.From)
+	}
+	w.from, w.to = from, end
+	return nil
+}
+
+// active 返回 now 是否在例外期内
+func (w FreezeWindow) active(now time.Time) bool {
+	if w.schedule != nil {
+		// 最近一次触发在 duration 之内：从 now-duration 之后的第一次触发不晚于 now
+		return !w.schedule.Next(now.Add(-w.Duration)).After(now)
+	}
+	return !now.Before(w.from) && now.Before(w.to)
+}
+
+// describe 日志和任务结果中显示的例外期
+func (w FreezeWindow) describe() string {
+	s := w.From
+	if w.Cron != "" {
+		s = w.Cron + " +" + w.Duration.String()
+	} else if w.To != "" && w.To != w.From {
+		s += " ~ " + w.To
+	}
+	if w.Reason != "" {
+		s += "（" + w.Reason + "）"
+	}
+	return s
+}
+
+// frozen 返回 now 所在的例外期
+func (c Config) frozen(now time.Time) (FreezeWindow, bool) {
+	for _, w := range c.Freeze {
+		if w.active(now) {
+			return w, true
+		}
+	}
+	return FreezeWindow{}, false
+}
@@ -0,0 +1,26 @@
+package cleaner
+
+import (
+	"io/fs"
+
+	"github.com/spf13/afero"
+)
+
+// readDirEntries 读取目录，返回按名称排序的目录项
+func readDirEntries(fsys afero.Fs, path string) ([]fs.DirEntry, error) {
+	infos, err := afero.ReadDir(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	return entries, nil
+}
+
+// isOsFs 判断是否为操作系统文件系统，文件锁等检查只对真实文件有意义
+func isOsFs(fsys afero.Fs) bool {
+	_, ok := fsys.(*afero.OsFs)
+	return ok
+}
@@ -0,0 +1,22 @@
+package cleaner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// onHold 返回目录是否处于保留（legal hold）状态：配置了 hold，或服务运行时通过管理接口设置
+func (cl *Cleaner) onHold(d DirConfig) bool {
+	return d.Hold || (cl.config.Hold != nil && cl.config.Hold(d.Path))
+}
+
+// underHold 返回文件是否位于处于保留状态的目录中
+func underHold(path string, held []string) bool {
+	for _, dir := range held {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package cleaner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// holder 持有文件句柄的进程
+type holder struct {
+	PID  uint32
+	Name string // 可执行文件名，如 sqlservr.exe
+}
+
+// protectedProcesses 返回目录需要避让的进程名：全局与目录的 protected_processes 合并
+func (c Config) protectedProcesses(d DirConfig) []string {
+	return append(append([]string{}, c.ProtectedProcesses...), d.ProtectedProcesses...)
+}
+
+// processName 统一进程名的比较形式：只取文件名、小写、去掉 .exe，配置中写 sqlservr 或 sqlservr.exe 均可
+func processName(name string) string {
+	name = strings.ToLower(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	return strings.TrimSuffix(name, ".exe")
+}
+
+// heldBy 返回持有文件句柄且在 names 中的第一个进程，只检查本地文件系统上的文件；
+// 目前只有 Windows 能查询句柄的持有者，其他系统始终返回 false
+func (f File) heldBy(names []string) (holder, bool) {
+	if len(names) == 0 || !isOsFs(f.FS) {
+		return holder{}, false
+	}
+	for _, h := range fileHolders(f.Path) {
+		for _, name := range names {
+			if processName(h.Name) == processName(name) {
+				return h, true
+			}
+		}
+	}
+	return holder{}, false
+}
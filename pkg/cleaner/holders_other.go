@@ -0,0 +1,8 @@
+//go:build !windows
+
+package cleaner
+
+// fileHolders 非 Windows 平台不查询文件句柄的持有者
+func fileHolders(path string) []holder {
+	return nil
+}
@@ -0,0 +1,92 @@
+package cleaner
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modrstrtmgr = windows.NewLazySystemDLL("rstrtmgr.dll")
+
+	procRmStartSession      = modrstrtmgr.NewProc("RmStartSession")
+	procRmRegisterResources = modrstrtmgr.NewProc("RmRegisterResources")
+	procRmGetList           = modrstrtmgr.NewProc("RmGetList")
+	procRmEndSession        = modrstrtmgr.NewProc("RmEndSession")
+)
+
+// rmProcessInfo RM_PROCESS_INFO
+type rmProcessInfo struct {
+	PID              uint32
+	StartTime        windows.Filetime
+	AppName          [256]uint16
+	ServiceShortName [64]uint16
+	AppType          uint32
+	AppStatus        uint32
+	TSSessionID      uint32
+	Restartable      int32
+}
+
+// fileHolders 通过 Restart Manager 查询持有文件句柄的进程，查询失败时返回空
+func fileHolders(path string) []holder {
+	if procRmStartSession.Find() != nil {
+		return nil
+	}
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil
+	}
+	var session uint32
+	var key [33]uint16 // CCH_RM_SESSION_KEY + 1
+	if r, _, _ := procRmStartSession.Call(uintptr(unsafe.Pointer(&session)), 0, uintptr(unsafe.Pointer(&key[0]))); r != 0 {
+		return nil
+	}
+	defer procRmEndSession.Call(uintptr(session))
+	if r, _, _ := procRmRegisterResources.Call(uintptr(session), 1, uintptr(unsafe.Pointer(&p)), 0, 0, 0, 0); r != 0 {
+		return nil
+	}
+	// 两次调用之间可能有进程新打开文件，缓冲区不足时重新分配
+	var infos []rmProcessInfo
+	for attempt := 0; ; attempt++ {
+		var needed, reasons uint32
+		var ptr uintptr
+		n := uint32(len(infos))
+		if n > 0 {
+			ptr = uintptr(unsafe.Pointer(&infos[0]))
+		}
+		r, _, _ := procRmGetList.Call(uintptr(session), uintptr(unsafe.Pointer(&needed)), uintptr(unsafe.Pointer(&n)), ptr, uintptr(unsafe.Pointer(&reasons)))
+		if r == uintptr(windows.ERROR_MORE_DATA) && attempt < 3 {
+			infos = make([]rmProcessInfo, needed+4)
+			continue
+		}
+		if r != 0 {
+			return nil
+		}
+		infos = infos[:n]
+		break
+	}
+	holders := make([]holder, 0, len(infos))
+	for _, info := range infos {
+		name := processImage(info.PID)
+		if name == "" {
+			name = windows.UTF16ToString(info.AppName[:])
+		}
+		holders = append(holders, holder{PID: info.PID, Name: name})
+	}
+	return holders
+}
+
+// processImage 返回进程的可执行文件路径，服务等无权查询的进程返回空
+func processImage(pid uint32) string {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(h)
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(h, 0, &buf[0], &size); err != nil {
+		return ""
+	}
+	return windows.UTF16ToString(buf[:size])
+}
@@ -0,0 +1,89 @@
+package cleaner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+const (
+	hookPreRun  = "pre_run"
+	hookPostRun = "post_run"
+
+	defaultHookTimeout = 5 * time.Minute
+)
+
+// Hooks 清理前后执行的命令，通过系统 shell（Windows 为 cmd /C，其他为 sh -c）执行。
+// pre_run 失败时跳过本次任务（目录级钩子则跳过该目录）
+type Hooks struct {
+	PreRun  string        `yaml:"pre_run" mapstructure:"pre_run"`
+	PostRun string        `yaml:"post_run" mapstructure:"post_run"`
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"` // 默认 5m
+}
+
+// runHook 执行钩子命令，通过环境变量传入本次任务（或目录）的统计结果
+func (cl *Cleaner) runHook(ctx context.Context, hooks *Hooks, phase, dir string, stats Stats, start time.Time) error {
+	if hooks == nil {
+		return nil
+	}
+	command := hooks.PreRun
+	if phase == hookPostRun {
+		command = hooks.PostRun
+	}
+	if command == "" {
+		return nil
+	}
+	timeout := hooks.Timeout
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), hookEnv(phase, dir, stats, start)...)
+	cl.logf("执行 %s 钩子: %s", phase, command)
+	out, err := cmd.CombinedOutput()
+	if output := strings.TrimSpace(string(out)); output != "" {
+		cl.logf("%s 钩子输出: %s", phase, output)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return i18n.Errorf("执行超时（%s）", timeout)
+	}
+	return err
+}
+
+func hookEnv(phase, dir string, stats Stats, start time.Time) []string {
+	env := []string{
+		"CLEANLOG_PHASE=" + phase,
+		"CLEANLOG_DIR=" + dir,
+		"CLEANLOG_START=" + start.Format(time.RFC3339),
+	}
+	if phase == hookPostRun {
+		env = append(env,
+			"CLEANLOG_SCANNED="+strconv.Itoa(stats.Scanned),
+			"CLEANLOG_MATCHED="+strconv.Itoa(stats.Matched),
+			"CLEANLOG_DELETED="+strconv.Itoa(stats.Deleted),
+			"CLEANLOG_FAILED="+strconv.Itoa(stats.Failed),
+			"CLEANLOG_COMPRESSED="+strconv.Itoa(stats.Compressed),
+			"CLEANLOG_ARCHIVED="+strconv.Itoa(stats.Archived),
+			"CLEANLOG_TRUNCATED="+strconv.Itoa(stats.Truncated),
+			"CLEANLOG_DELETED_DIRS="+strconv.Itoa(stats.DeletedDirs),
+			"CLEANLOG_SKIPPED="+strconv.Itoa(stats.Skipped()),
+			"CLEANLOG_FREED_BYTES="+strconv.FormatInt(stats.FreedBytes, 10),
+			"CLEANLOG_DURATION_SECONDS="+strconv.FormatFloat(time.Since(start).Seconds(), 'f', 1, 64),
+		)
+	}
+	return env
+}
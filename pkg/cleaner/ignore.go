@@ -0,0 +1,86 @@
+package cleaner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// ignoreFileName 目录中的忽略规则文件，应用负责人可以在目录里直接保护文件而不必修改服务配置
+const ignoreFileName = ".cleanignore"
+
+// ignorePattern .cleanignore 中的一条规则
+type ignorePattern struct {
+	pattern string
+	negate  bool // "!" 开头，取消之前规则的保护
+	dirOnly bool // "/" 结尾，只匹配目录（如日期目录）
+}
+
+// ignoreRules 按 gitignore 的规则匹配：后面的规则优先，"!" 取消保护
+type ignoreRules []ignorePattern
+
+// loadIgnore 读取目录中的 .cleanignore，文件不存在时返回空规则
+func (cl *Cleaner) loadIgnore(dir string) ignoreRules {
+	rules, err := readIgnore(cl.fs, filepath.Join(dir, ignoreFileName))
+	if err != nil && !os.IsNotExist(err) {
+		cl.warnf("读取 %s 失败: %s", filepath.Join(dir, ignoreFileName), err)
+	}
+	return rules
+}
+
+func readIgnore(fsys afero.Fs, path string) (ignoreRules, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var rules ignoreRules
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var p ignorePattern
+		switch {
+		case strings.HasPrefix(line, `\`):
+			// "\#"、"\!" 表示以该字符开头的文件名
+			line = line[1:]
+		case strings.HasPrefix(line, "!"):
+			p.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		// 只扫描当前目录，"/" 开头的锚定规则与不带 "/" 的规则等价
+		line = strings.TrimPrefix(strings.TrimPrefix(line, "**/"), "/")
+		if line == "" {
+			continue
+		}
+		if _, err := filepath.Match(line, ""); err != nil {
+			continue
+		}
+		p.pattern = line
+		rules = append(rules, p)
+	}
+	return rules, scanner.Err()
+}
+
+// match 判断文件或目录是否受保护
+func (r ignoreRules) match(name string, isDir bool) bool {
+	ignored := false
+	for _, p := range r {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if ok, _ := filepath.Match(p.pattern, name); ok {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,40 @@
+package cleaner
+
+import (
+	"time"
+)
+
+const defaultInUseDelay = 2 * time.Second
+
+// skipInUse 返回目录是否需要跳过正在使用的文件，以及两次检查之间的间隔
+func (c Config) skipInUse(d DirConfig) (bool, time.Duration) {
+	delay := d.InUseDelay
+	if delay <= 0 {
+		delay = c.InUseDelay
+	}
+	if delay <= 0 {
+		delay = defaultInUseDelay
+	}
+	return c.SkipInUse || d.SkipInUse, delay
+}
+
+// filterInUse 去掉正在被使用的文件：Windows 上无法以独占方式打开的文件，
+// 以及间隔 delay 前后两次 stat 大小或修改时间发生变化的文件（所有文件只等待一次）
+func (cl *Cleaner) filterInUse(cs []candidate, delay time.Duration, stats *Stats) []candidate {
+	if len(cs) == 0 {
+		return cs
+	}
+	time.Sleep(delay)
+	kept := cs[:0]
+	for _, c := range cs {
+		info, err := cl.fs.Stat(c.file.Path)
+		if err != nil || info.Size() != c.file.Info.Size() || !info.ModTime().Equal(c.file.Info.ModTime()) || (isOsFs(cl.fs) && fileLocked(c.file.Path)) {
+			cl.skipf(stats, "跳过 %s：文件正在使用", c.file.Path)
+			stats.InUse++
+			stats.account(c.file.Path)
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
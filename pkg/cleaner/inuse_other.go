@@ -0,0 +1,8 @@
+//go:build !windows
+
+package cleaner
+
+// fileLocked 非 Windows 平台没有强制锁，只依靠大小是否稳定判断
+func fileLocked(path string) bool {
+	return false
+}
@@ -0,0 +1,17 @@
+package cleaner
+
+import "golang.org/x/sys/windows"
+
+// fileLocked 以不共享的方式尝试打开文件，出现共享冲突说明有其他进程持有句柄
+func fileLocked(path string) bool {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	h, err := windows.CreateFile(p, windows.GENERIC_READ, 0, nil, windows.OPEN_EXISTING, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return err == windows.ERROR_SHARING_VIOLATION || err == windows.ERROR_LOCK_VIOLATION
+	}
+	windows.CloseHandle(h)
+	return false
+}
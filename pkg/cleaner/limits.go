@@ -0,0 +1,170 @@
+package cleaner
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	defaultMaxDepth = 64
+	readDirChunk    = 1024
+)
+
+// maxDepth 返回遍历目录树时的最大深度：目录 > 全局 > 默认 64，负数表示不限制
+func (c Config) maxDepth(d DirConfig) int {
+	switch {
+	case d.MaxDepth != 0:
+		return d.MaxDepth
+	case c.MaxDepth != 0:
+		return c.MaxDepth
+	}
+	return defaultMaxDepth
+}
+
+// maxEntries 返回单个目录最多读取的目录项数：目录 > 全局，0 表示不限制
+func (c Config) maxEntries(d DirConfig) int {
+	if d.MaxEntriesPerDir > 0 {
+		return d.MaxEntriesPerDir
+	}
+	return c.MaxEntriesPerDir
+}
+
+// readDir 读取目录，返回按名称排序的目录项，用于处理过程中会增删目录项的场景
+func (cl *Cleaner) readDir(dir DirConfig, path string) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	err := cl.scanDir(dir, path, func(batch []fs.DirEntry) {
+		entries = append(entries, batch...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// scanDir 以每批 1024 项的方式流式读取目录并依次交给 fn，内存占用不随目录大小增长；
+// 目录项按文件系统返回的顺序，不排序。网络共享路径在打开失败时重新连接并重试，达到 max_entries_per_dir 时记录警告并停止
+func (cl *Cleaner) scanDir(dir DirConfig, path string, fn func([]fs.DirEntry)) error {
+	f, err := cl.fs.Open(path)
+	if err != nil && isUNC(path) {
+		retries, delay := dir.Share.retries()
+		for i := 0; i < retries && err != nil; i++ {
+			cl.warnf("读取共享目录 %s 失败，%s 后重试: %s", path, delay, err)
+			time.Sleep(delay)
+			if connErr := cl.connectShare(dir); connErr != nil {
+				continue
+			}
+			f, err = cl.fs.Open(path)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	limit, n := cl.config.maxEntries(dir), 0
+	for {
+		entries, err := readDirBatch(f)
+		if limit > 0 && n+len(entries) > limit {
+			entries = entries[:limit-n]
+			err = errTooManyEntries
+		}
+		if len(entries) > 0 {
+			n += len(entries)
+			fn(entries)
+		}
+		switch {
+		case err == errTooManyEntries:
+			cl.warnf("目录 %s 超过 %d 项（max_entries_per_dir），本次只处理前 %d 项", path, limit, limit)
+			return nil
+		case err == io.EOF || err == nil && len(entries) == 0:
+			return nil
+		case err != nil:
+			return err
+		}
+	}
+}
+
+// readDirBatch 读取下一批目录项。操作系统文件（*os.File）使用 ReadDir，目录项的文件信息在调用 Info 时才获取，
+// 被文件名过滤掉的文件不必 stat，在冷缓存的 NAS 上能省下大量元数据请求
+func readDirBatch(f afero.File) ([]fs.DirEntry, error) {
+	if rd, ok := f.(fs.ReadDirFile); ok {
+		return rd.ReadDir(readDirChunk)
+	}
+	infos, err := f.Readdir(readDirChunk)
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	return entries, err
+}
+
+var errTooManyEntries = errors.New("too many entries")
+
+// walkLimits 遍历目录树时的限制
+type walkLimits struct {
+	maxDepth     int  // 最大深度，负数表示不限制
+	crossDevices bool // 是否进入其他文件系统、卷或重解析点
+}
+
+func (c Config) walkLimits(d DirConfig) walkLimits {
+	return walkLimits{maxDepth: c.maxDepth(d), crossDevices: c.CrossDevices || d.CrossDevices}
+}
+
+// walkResult 遍历中因限制跳过的内容
+type walkResult struct {
+	limited bool // 有超过 max_depth 的子目录
+	crossed bool // 有位于其他设备上的目录（挂载点、联接点等）
+}
+
+// walkTree 遍历目录树，超过 maxDepth 层的子目录不再进入；未开启 crossDevices 时，
+// 与 root 的上级目录不在同一设备上的目录（挂载点、bind mount、NTFS 联接点）也不进入。符号链接不会被跟随
+func walkTree(fsys afero.Fs, root string, lim walkLimits, fn func(path string, info os.FileInfo, err error) error) walkResult {
+	var res walkResult
+	var baseDev uint64
+	baseOK := false
+	if !lim.crossDevices {
+		if info, err := lstatIfPossible(fsys, filepath.Dir(root)); err == nil {
+			baseDev, _, baseOK = fileDevice(info)
+		}
+	}
+	afero.Walk(fsys, root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode()&os.ModeSymlink == 0 && !info.IsDir() {
+			return fn(path, info, err)
+		}
+		if err == nil && !lim.crossDevices {
+			if dev, reparse, ok := fileDevice(info); reparse || ok && baseOK && dev != baseDev {
+				res.crossed = true
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if err == nil && info.IsDir() && lim.maxDepth >= 0 && path != root {
+			rel, _ := filepath.Rel(root, path)
+			if strings.Count(rel, string(filepath.Separator))+1 > lim.maxDepth {
+				res.limited = true
+				return filepath.SkipDir
+			}
+		}
+		return fn(path, info, err)
+	})
+	return res
+}
+
+// lstatIfPossible 不跟随符号链接获取文件信息
+func lstatIfPossible(fsys afero.Fs, path string) (os.FileInfo, error) {
+	if l, ok := fsys.(afero.Lstater); ok {
+		info, _, err := l.LstatIfPossible(path)
+		return info, err
+	}
+	return fsys.Stat(path)
+}
@@ -0,0 +1,14 @@
+package cleaner
+
+// skipHardLinks 返回目录是否跳过有多个硬链接的文件，避免破坏去重存储和基于硬链接的备份
+func (c Config) skipHardLinks(d DirConfig) bool {
+	return c.SkipHardLinks || d.SkipHardLinks
+}
+
+// links 返回文件的硬链接数，只检查本地文件系统上的文件
+func (f File) links() uint64 {
+	if !isOsFs(f.FS) {
+		return 1
+	}
+	return linkCount(f.Path, f.Info)
+}
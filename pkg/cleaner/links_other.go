@@ -0,0 +1,23 @@
+//go:build !windows
+
+package cleaner
+
+import (
+	"os"
+	"syscall"
+)
+
+// linkCount 返回文件的硬链接数，无法获取时返回 1。扫描时的 info 已包含链接数，
+// 链接数大于 1 时重新 lstat，同一次任务中先删除的其他链接也计算在内
+func linkCount(path string, info os.FileInfo) uint64 {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st.Nlink <= 1 {
+		return 1
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if st, ok := info.Sys().(*syscall.Stat_t); ok {
+			return uint64(st.Nlink)
+		}
+	}
+	return uint64(st.Nlink)
+}
@@ -0,0 +1,26 @@
+package cleaner
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// linkCount 返回 NTFS 文件的硬链接数，无法获取时返回 1。目录项中的文件信息不含链接数，需要打开文件查询
+func linkCount(path string, info os.FileInfo) uint64 {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 1
+	}
+	h, err := windows.CreateFile(p, windows.FILE_READ_ATTRIBUTES,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return 1
+	}
+	defer windows.CloseHandle(h)
+	var d windows.ByHandleFileInformation
+	if err := windows.GetFileInformationByHandle(h, &d); err != nil || d.NumberOfLinks == 0 {
+		return 1
+	}
+	return uint64(d.NumberOfLinks)
+}
@@ -0,0 +1,54 @@
+package cleaner
+
+import (
+	"strings"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// logLevel 日志级别，debug 级别会逐个记录处理和跳过的文件
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+func parseLogLevel(s string) (logLevel, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return levelDebug, nil
+	case "", "info":
+		return levelInfo, nil
+	case "warn", "warning":
+		return levelWarn, nil
+	case "error":
+		return levelError, nil
+	}
+	return 0, i18n.Errorf("log_level %q 无效，可选 debug、info、warn、error", s)
+}
+
+func (cl *Cleaner) printf(level logLevel, format string, args ...interface{}) {
+	if level >= cl.level {
+		cl.logger.Printf(i18n.T(format), args...)
+	}
+}
+
+func (cl *Cleaner) debugf(format string, args ...interface{}) {
+	cl.printf(levelDebug, format, args...)
+}
+
+// logf 按当前语言输出 info 级别日志
+func (cl *Cleaner) logf(format string, args ...interface{}) {
+	cl.printf(levelInfo, format, args...)
+}
+
+func (cl *Cleaner) warnf(format string, args ...interface{}) {
+	cl.printf(levelWarn, format, args...)
+}
+
+func (cl *Cleaner) errorf(format string, args ...interface{}) {
+	cl.printf(levelError, format, args...)
+}
@@ -0,0 +1,98 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"cleanlogservice/pkg/state"
+)
+
+// markRecord 两阶段删除中已标记、等待下一次任务确认的文件
+type markRecord struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Marked  time.Time `json:"marked"`
+}
+
+// markSet 一次任务中两阶段删除的状态：上次任务标记的文件和本次新标记的文件。并行处理目录时由各 worker 共用
+type markSet struct {
+	mu      sync.Mutex
+	prev    map[string]markRecord
+	next    map[string]markRecord
+	scanned map[string]bool // 本次完整处理过的目录，其中的文件以 next 为准
+	now     time.Time
+}
+
+// markFileMu 各策略组的任务共用同一个 mark_state 时，保存前重新读取并合并，避免互相覆盖
+var markFileMu sync.Mutex
+
+// done 记录目录已完整处理，上次标记但本次不再满足条件的文件不再保留
+func (m *markSet) done(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scanned[filepath.Clean(path)] = true
+}
+
+// confirm 文件在上次任务中已被标记且大小和修改时间都没有变化时返回 true，可以删除；
+// 否则记录为本次标记的文件，等下一次任务再确认
+func (m *markSet) confirm(f File) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rec, ok := m.prev[f.Path]; ok && rec.Size == f.Info.Size() && rec.ModTime.Equal(f.Info.ModTime()) {
+		return true
+	}
+	m.next[f.Path] = markRecord{Path: f.Path, Size: f.Info.Size(), ModTime: f.Info.ModTime(), Marked: m.now}
+	return false
+}
+
+// loadMarks 读取上次任务标记的文件，未配置 mark_state 时返回 nil，不启用两阶段删除
+func (cl *Cleaner) loadMarks(now time.Time) *markSet {
+	if cl.config.MarkState == "" {
+		return nil
+	}
+	m := &markSet{prev: map[string]markRecord{}, next: map[string]markRecord{}, scanned: map[string]bool{}, now: now}
+	for _, rec := range cl.readMarks() {
+		m.prev[rec.Path] = rec
+	}
+	return m
+}
+
+func (cl *Cleaner) readMarks() []markRecord {
+	var records []markRecord
+	if err := state.Read(cl.fs, cl.config.MarkState, &records); err != nil && !os.IsNotExist(err) {
+		cl.warnf("读取标记列表 %s 失败: %s", cl.config.MarkState, err)
+	}
+	return records
+}
+
+// saveMarks 保存本次标记的文件。本次完整处理过的目录中，上次标记但本次不再满足条件（已恢复、改名或时间变化）的文件不再保留；
+// 没有处理的目录（其他策略组的任务、处于保留或 canary、不在本次 watch 触发的任务中、任务中途停止）保留原来的记录
+func (cl *Cleaner) saveMarks(m *markSet) {
+	if m == nil {
+		return
+	}
+	markFileMu.Lock()
+	defer markFileMu.Unlock()
+	// 重新读取，期间其他任务可能已经保存过
+	current := cl.readMarks()
+	// 超时后放弃等待的目录可能仍在记录
+	m.mu.Lock()
+	records := make([]markRecord, 0, len(m.next)+len(current))
+	for _, rec := range m.next {
+		records = append(records, rec)
+	}
+	for _, rec := range current {
+		if _, ok := m.next[rec.Path]; !ok && !m.scanned[filepath.Dir(rec.Path)] {
+			records = append(records, rec)
+		}
+	}
+	m.mu.Unlock()
+	sort.Slice(records, func(i, j int) bool { return records[i].Path < records[j].Path })
+	if err := state.Write(cl.fs, cl.config.MarkState, records); err != nil {
+		cl.errorf("保存标记列表 %s 失败: %s", cl.config.MarkState, err)
+	}
+}
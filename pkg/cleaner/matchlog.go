@@ -0,0 +1,57 @@
+package cleaner
+
+import "cleanlogservice/pkg/i18n"
+
+// log_matches 的取值：目录中到期文件的记录方式
+const (
+	matchLogNone    = "none"    // 不记录到期文件，汇总中也不输出该目录
+	matchLogSummary = "summary" // 只输出目录汇总，逐个文件的记录在 debug 级别（默认）
+	matchLogFull    = "full"    // 不论日志级别，逐个记录处理的文件
+)
+
+// matchLog 目录的匹配日志策略
+type matchLog struct {
+	mode    string
+	skipped bool // 逐个记录到期但本次跳过的文件及原因
+}
+
+func validateMatchLog(mode string) error {
+	switch mode {
+	case "", matchLogNone, matchLogSummary, matchLogFull:
+		return nil
+	}
+	return i18n.Errorf("log_matches %q 无效，可选 none、summary、full", mode)
+}
+
+// matchLog 返回目录的匹配日志策略，目录未配置时使用全局配置
+func (c Config) matchLog(d DirConfig) matchLog {
+	mode := d.LogMatches
+	if mode == "" {
+		mode = c.LogMatches
+	}
+	if mode == "" {
+		mode = matchLogSummary
+	}
+	return matchLog{mode: mode, skipped: c.LogSkipped || d.LogSkipped}
+}
+
+// matchf 记录一个已处理的文件
+func (cl *Cleaner) matchf(s *Stats, format string, args ...interface{}) {
+	switch s.matchLog.mode {
+	case matchLogFull:
+		cl.logf(format, args...)
+	case matchLogNone:
+	default:
+		cl.debugf(format, args...)
+	}
+}
+
+// skipf 记录一个到期但本次跳过的文件
+func (cl *Cleaner) skipf(s *Stats, format string, args ...interface{}) {
+	switch {
+	case s.matchLog.skipped:
+		cl.logf(format, args...)
+	case s.matchLog.mode != matchLogNone:
+		cl.debugf(format, args...)
+	}
+}
@@ -0,0 +1,79 @@
+package cleaner
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// validateMaxFiles 校验 max_files 和 target_files，未配置 target_files 时降到 max_files 为止
+func validateMaxFiles(d *DirConfig) error {
+	if d.MaxFiles < 0 || d.TargetFiles < 0 {
+		return i18n.Errorf("max_files 和 target_files 不能为负数")
+	}
+	if d.MaxFiles == 0 {
+		if d.TargetFiles > 0 {
+			return i18n.Errorf("配置了 target_files 但未配置 max_files")
+		}
+		return nil
+	}
+	if d.TargetFiles == 0 {
+		d.TargetFiles = d.MaxFiles
+	}
+	if d.TargetFiles > d.MaxFiles {
+		return i18n.Errorf("target_files %d 不能大于 max_files %d", d.TargetFiles, d.MaxFiles)
+	}
+	return nil
+}
+
+// trimFiles 目录中剩余的文件数超过 max_files 时，不论是否到期，从最旧的开始删除满足过滤条件的文件，
+// 直到剩余 target_files 个。count 为目录中的文件总数，gone 为本次已删除或移走的文件，renamed 为本次压缩后改名的文件。
+// 删除前执行与到期文件相同的检查（guardExtra、confirmMark），只标记的文件计入要删除的数量，下次任务确认后删除
+func (cl *Cleaner) trimFiles(ctx context.Context, dir DirConfig, files []File, count int, gone map[string]bool, renamed map[string]File, quietSince time.Time, protected []string, stats *Stats) {
+	left := count - len(gone)
+	if left <= dir.MaxFiles {
+		return
+	}
+	cl.logf("目录 %s 有 %d 个文件，超过 max_files %d，从最旧的文件开始删除到 %d 个", dir.Path, left, dir.MaxFiles, dir.TargetFiles)
+	var rest []File
+	for _, f := range files {
+		if gone[f.Path] {
+			continue
+		}
+		if r, ok := renamed[f.Path]; ok {
+			f = r
+		}
+		rest = append(rest, f)
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].Time.Before(rest[j].Time) })
+	del, _ := newDeleteAction(cl.config.deleteMode(dir, Tier{}))
+	marked := 0
+	// 每次只检查还需要删除的个数，被跳过的文件由后面更新的文件补上
+	for len(rest) > 0 && left-marked > dir.TargetFiles && ctx.Err() == nil {
+		n := left - marked - dir.TargetFiles
+		if n > len(rest) {
+			n = len(rest)
+		}
+		batch := cl.guardExtra(dir, rest[:n], quietSince, protected, stats)
+		rest = rest[n:]
+		for _, f := range batch {
+			if ctx.Err() != nil {
+				break
+			}
+			if !cl.confirmMark(f, del, stats) {
+				marked++
+				continue
+			}
+			if _, ok := cl.apply(del, &f, stats); !ok {
+				continue
+			}
+			stats.Trimmed++
+			left--
+		}
+	}
+	if left-marked > dir.TargetFiles {
+		cl.warnf("目录 %s 仍有 %d 个文件，其余文件不满足过滤条件、被跳过或删除失败", dir.Path, left)
+	}
+}
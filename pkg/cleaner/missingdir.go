@@ -0,0 +1,70 @@
+package cleaner
+
+import (
+	"os"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// missing_dir 的取值：目录不存在时的处理方式
+const (
+	missingIgnore = "ignore" // 静默跳过（debug 级别记录）
+	missingWarn   = "warn"   // 记录警告后跳过，默认
+	missingError  = "error"  // 记录错误并计入失败数
+	missingCreate = "create" // 创建目录
+)
+
+func validateMissingDir(policy string) error {
+	switch policy {
+	case "", missingIgnore, missingWarn, missingError, missingCreate:
+		return nil
+	}
+	return i18n.Errorf("missing_dir %q 无效，可选 ignore、warn、error、create", policy)
+}
+
+// missingDir 返回目录不存在时的处理方式，目录配置优先于全局配置
+func (c Config) missingDir(d DirConfig) string {
+	if d.MissingDir != "" {
+		return d.MissingDir
+	}
+	if c.MissingDir != "" {
+		return c.MissingDir
+	}
+	return missingWarn
+}
+
+// checkDir 检查配置的目录是否存在，返回 false 时跳过该目录
+func (cl *Cleaner) checkDir(dir DirConfig, stats *Stats) bool {
+	policy := cl.config.missingDir(dir)
+	info, err := cl.fs.Stat(dir.Path)
+	switch {
+	case err == nil && info.IsDir():
+		return true
+	case err == nil:
+		cl.errorf("%s 不是目录", dir.Path)
+		stats.fail(nil)
+		return false
+	case !os.IsNotExist(err):
+		cl.errorf("无法访问目录 %s: %s", dir.Path, err)
+		stats.fail(err)
+		return false
+	}
+
+	switch policy {
+	case missingIgnore:
+		cl.debugf("目录 %s 不存在，跳过", dir.Path)
+	case missingError:
+		cl.errorf("目录 %s 不存在", dir.Path)
+		stats.fail(os.ErrNotExist)
+	case missingCreate:
+		if err := cl.fs.MkdirAll(dir.Path, 0755); err != nil {
+			cl.errorf("创建目录 %s 失败: %s", dir.Path, err)
+			stats.fail(err)
+		} else {
+			cl.logf("目录 %s 不存在，已创建", dir.Path)
+		}
+	default:
+		cl.warnf("目录 %s 不存在，跳过", dir.Path)
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package cleaner
+
+import (
+	"io/fs"
+	"regexp"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// NameDate 从文件名中解析日期，用于替代修改时间判断文件年龄
+type NameDate struct {
+	Regex      string `yaml:"regex" mapstructure:"regex"`             // 有捕获组时取第一个捕获组，否则取整个匹配
+	TimeFormat string `yaml:"time_format" mapstructure:"time_format"` // Go 时间格式，如 20060102
+
+	re *regexp.Regexp
+}
+
+// layoutTokens Go 时间格式中常用占位符对应的正则
+var layoutTokens = strings.NewReplacer(
+	"2006", `\d{4}`,
+	"01", `\d{2}`,
+	"02", `\d{2}`,
+	"15", `\d{2}`,
+	"04", `\d{2}`,
+	"05", `\d{2}`,
+)
+
+// compile 编译正则，未配置 regex 时根据 time_format 生成；fold 时不区分大小写
+func (n *NameDate) compile(fold bool) error {
+	if n.TimeFormat == "" {
+		return i18n.Errorf("name_date 缺少 time_format")
+	}
+	expr := n.Regex
+	if expr == "" {
+		expr = layoutTokens.Replace(regexp.QuoteMeta(n.TimeFormat))
+	}
+	if fold {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return i18n.Errorf("name_date 正则 %q 无效: %w", expr, err)
+	}
+	n.re = re
+	return nil
+}
+
+// parse 返回文件名中的日期，未匹配或解析失败时返回 false
+func (n *NameDate) parse(name string) (time.Time, bool) {
+	m := n.re.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+	s := m[0]
+	if len(m) > 1 {
+		s = m[1]
+	}
+	t, err := time.ParseInLocation(n.TimeFormat, s, time.Local)
+	return t, err == nil
+}
+
+// fileTime 远程目标判断年龄所用的时间：配置了 name_date 且文件名能解析出日期时使用该日期，否则使用修改时间
+func (d DirConfig) fileTime(info fs.FileInfo) time.Time {
+	if d.NameDate != nil {
+		if t, ok := d.NameDate.parse(info.Name()); ok {
+			return t
+		}
+	}
+	return info.ModTime()
+}
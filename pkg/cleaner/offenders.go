@@ -0,0 +1,87 @@
+package cleaner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// FileSize 文件或子目录占用的空间
+type FileSize struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// Offenders 清理后目录树中占用空间最大的文件和直接子目录，不论是否到期，供容量规划参考
+type Offenders struct {
+	Files []FileSize `json:"files"`
+	Dirs  []FileSize `json:"dirs,omitempty"`
+}
+
+// topN 保留最大的 n 项，按大小从大到小排列
+type topN struct {
+	n     int
+	items []FileSize
+}
+
+func (t *topN) add(item FileSize) {
+	if len(t.items) == t.n && item.Size <= t.items[t.n-1].Size {
+		return
+	}
+	i := len(t.items)
+	for i > 0 && t.items[i-1].Size < item.Size {
+		i--
+	}
+	t.items = append(t.items, FileSize{})
+	copy(t.items[i+1:], t.items[i:])
+	t.items[i] = item
+	if len(t.items) > t.n {
+		t.items = t.items[:t.n]
+	}
+}
+
+// offenders 遍历清理后的目录树（不跟随符号链接，深度受 max_depth 限制），统计最大的 n 个文件和子目录
+func (cl *Cleaner) offenders(ctx context.Context, dir DirConfig, n int) *Offenders {
+	root := filepath.Clean(dir.Path)
+	depth := cl.config.maxDepth(dir)
+	files := topN{n: n}
+	subdirs := map[string]int64{}
+	afero.Walk(cl.fs, root, func(path string, info os.FileInfo, err error) error {
+		if ctx.Err() != nil {
+			return filepath.SkipDir
+		}
+		if err != nil || path == root {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		parts := strings.Split(rel, string(filepath.Separator))
+		if info.IsDir() {
+			if depth >= 0 && len(parts) > depth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		files.add(FileSize{Path: path, Size: info.Size()})
+		if len(parts) > 1 {
+			subdirs[filepath.Join(root, parts[0])] += info.Size()
+		}
+		return nil
+	})
+	names := make([]string, 0, len(subdirs))
+	for path := range subdirs {
+		names = append(names, path)
+	}
+	sort.Strings(names)
+	dirs := topN{n: n}
+	for _, path := range names {
+		dirs.add(FileSize{Path: path, Size: subdirs[path]})
+	}
+	return &Offenders{Files: files.items, Dirs: dirs.items}
+}
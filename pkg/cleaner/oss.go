@@ -0,0 +1,273 @@
+package cleaner
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// OSSConfig oss://bucket/prefix/ 目标的连接配置（阿里云对象存储）
+type OSSConfig struct {
+	Endpoint        string `yaml:"endpoint" mapstructure:"endpoint"` // 如 https://oss-cn-hangzhou.aliyuncs.com，内网可用 -internal 域名
+	AccessKeyID     string `yaml:"access_key_id" mapstructure:"access_key_id"`
+	AccessKeySecret string `yaml:"access_key_secret" mapstructure:"access_key_secret"`
+	SecurityToken   string `yaml:"security_token" mapstructure:"security_token"` // 使用 STS 临时凭证时填写
+
+	RemoteLimits `yaml:",inline" mapstructure:",squash"`
+}
+
+// ossClient 基于 OSS V1 签名的最小客户端
+type ossClient struct {
+	endpoint *url.URL
+	bucket   string
+	keyID    string
+	secret   string
+	token    string
+	http     *http.Client
+	throttle *throttle
+}
+
+func newOSSClient(bucket string, cfg *OSSConfig) (*ossClient, error) {
+	if cfg == nil || cfg.Endpoint == "" {
+		return nil, errors.New(i18n.T("OSS 目标缺少 oss.endpoint 配置"))
+	}
+	endpoint := cfg.Endpoint
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, i18n.Errorf("OSS endpoint %s 无效: %w", cfg.Endpoint, err)
+	}
+	c := &ossClient{
+		endpoint: u,
+		bucket:   bucket,
+		keyID:    cfg.AccessKeyID,
+		secret:   cfg.AccessKeySecret,
+		token:    cfg.SecurityToken,
+		http:     &http.Client{Timeout: 2 * time.Minute},
+		throttle: newThrottle(cfg.RemoteLimits),
+	}
+	if c.keyID == "" {
+		c.keyID = os.Getenv("OSS_ACCESS_KEY_ID")
+	}
+	if c.secret == "" {
+		c.secret = os.Getenv("OSS_ACCESS_KEY_SECRET")
+	}
+	return c, nil
+}
+
+// do 签名并发送请求，subresource 为参与签名的子资源（如 "delete"）
+func (c *ossClient) do(method, key, subresource string, query url.Values, body []byte, header http.Header) ([]byte, error) {
+	u := *c.endpoint
+	u.Host = c.bucket + "." + u.Host
+	u.Path = "/" + key
+	if query == nil {
+		query = url.Values{}
+	}
+	if subresource != "" {
+		query.Set(subresource, "")
+	}
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resource := "/" + c.bucket + "/" + key
+	if subresource != "" {
+		resource += "?" + subresource
+	}
+	c.authorize(req, resource)
+
+	resp, err := c.throttle.do(c.http, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("OSS %s %s: %s %s", method, resource, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// authorize 添加日期、临时凭证和签名头
+func (c *ossClient) authorize(req *http.Request, resource string) {
+	if c.token != "" {
+		req.Header.Set("X-Oss-Security-Token", c.token)
+	}
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("Authorization", "OSS "+c.keyID+":"+c.signature(req, resource))
+}
+
+// objectURL 返回对象的请求地址
+func (c *ossClient) objectURL(key string) string {
+	u := *c.endpoint
+	u.Host = c.bucket + "." + u.Host
+	u.Path = "/" + key
+	return u.String()
+}
+
+// signature 计算 OSS V1 签名：
+// VERB\nContent-MD5\nContent-Type\nDate\nCanonicalizedOSSHeaders + CanonicalizedResource
+func (c *ossClient) signature(req *http.Request, resource string) string {
+	var ossHeaders []string
+	for k, v := range req.Header {
+		if k := strings.ToLower(k); strings.HasPrefix(k, "x-oss-") {
+			ossHeaders = append(ossHeaders, k+":"+strings.Join(v, ","))
+		}
+	}
+	sort.Strings(ossHeaders)
+	var canonical strings.Builder
+	for _, h := range ossHeaders {
+		canonical.WriteString(h + "\n")
+	}
+	stringToSign := req.Method + "\n" +
+		req.Header.Get("Content-MD5") + "\n" +
+		req.Header.Get("Content-Type") + "\n" +
+		req.Header.Get("Date") + "\n" +
+		canonical.String() + resource
+	h := hmac.New(sha1.New, []byte(c.secret))
+	h.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+type ossListResult struct {
+	IsTruncated bool   `xml:"IsTruncated"`
+	NextMarker  string `xml:"NextMarker"`
+	Contents    []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+// listObjects 分页列出 prefix 下的直接子对象
+func (c *ossClient) listObjects(prefix string) ([]fs.FileInfo, error) {
+	var files []fs.FileInfo
+	marker := ""
+	for {
+		query := url.Values{"prefix": {prefix}, "delimiter": {"/"}, "max-keys": {"1000"}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		data, err := c.do(http.MethodGet, "", "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result ossListResult
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		for _, obj := range result.Contents {
+			name := strings.TrimPrefix(obj.Key, prefix)
+			if name == "" {
+				continue
+			}
+			files = append(files, remoteFile{name: name, size: obj.Size, modTime: obj.LastModified})
+		}
+		if !result.IsTruncated || result.NextMarker == "" {
+			return files, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+type ossDeleteResult struct {
+	Deleted []struct {
+		Key string `xml:"Key"`
+	} `xml:"Deleted"`
+}
+
+// deleteObjects 批量删除对象（每次最多 1000 个，按 concurrency 同时发出多批）。使用非 quiet 模式，
+// 未出现在返回的 Deleted 列表中的 key 视为删除失败
+func (c *ossClient) deleteObjects(keys []string) map[string]error {
+	var mu sync.Mutex
+	failures := map[string]error{}
+	inBatches(keys, 1000, func(batch []string) {
+		var body bytes.Buffer
+		body.WriteString("<Delete><Quiet>false</Quiet>")
+		for _, key := range batch {
+			body.WriteString("<Object><Key>")
+			xml.EscapeText(&body, []byte(key))
+			body.WriteString("</Key></Object>")
+		}
+		body.WriteString("</Delete>")
+		sum := md5.Sum(body.Bytes())
+		header := http.Header{
+			"Content-Type": {"application/xml"},
+			"Content-Md5":  {base64.StdEncoding.EncodeToString(sum[:])},
+		}
+		data, err := c.do(http.MethodPost, "", "delete", nil, body.Bytes(), header)
+		var result ossDeleteResult
+		if err == nil {
+			err = xml.Unmarshal(data, &result)
+		}
+		deleted := map[string]bool{}
+		for _, d := range result.Deleted {
+			deleted[d.Key] = true
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, key := range batch {
+			if err != nil {
+				failures[key] = err
+			} else if !deleted[key] {
+				failures[key] = errors.New(i18n.T("OSS 未返回删除成功"))
+			}
+		}
+	})
+	return failures
+}
+
+// ossTarget oss://bucket/prefix/ 清理目标
+type ossTarget struct {
+	client *ossClient
+	prefix string
+}
+
+func newOSSTarget(u *url.URL, cfg *OSSConfig) (*ossTarget, error) {
+	client, err := newOSSClient(u.Host, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ossTarget{client: client, prefix: objectPrefix(u.Path)}, nil
+}
+
+func (t *ossTarget) list() ([]fs.FileInfo, error) {
+	return t.client.listObjects(t.prefix)
+}
+
+func (t *ossTarget) remove(names []string) map[string]error {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = t.prefix + name
+	}
+	failures := map[string]error{}
+	for key, err := range t.client.deleteObjects(keys) {
+		failures[strings.TrimPrefix(key, t.prefix)] = err
+	}
+	return failures
+}
@@ -0,0 +1,98 @@
+package cleaner
+
+import (
+	"strings"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// Overlap 一次重叠的处理结果：同一目录被多个目录项匹配时只按最具体的一项处理，
+// 目录位于按子目录删除（subtrees、date_dirs）的目录之中时，父目录跳过包含它的子目录
+type Overlap struct {
+	Path    string `json:"path"`
+	Kept    string `json:"kept"`              // 生效的目录项（由通配符展开时为通配符）
+	Dropped string `json:"dropped,omitempty"` // 重复匹配、本次不处理该目录的目录项
+	Parent  string `json:"parent,omitempty"`  // 嵌套时为父目录，其 subtrees、date_dirs 不处理该目录
+}
+
+func (o Overlap) String() string {
+	if o.Parent != "" {
+		return i18n.Sprintf("%s 位于 %s 之中，父目录按子目录删除时跳过该目录，其中的文件按该目录自身的策略处理", o.Path, o.Parent)
+	}
+	return i18n.Sprintf("%s 同时被 %s 和 %s 匹配，按 %s 处理", o.Path, o.Kept, o.Dropped, o.Kept)
+}
+
+// entry 目录项在配置中的写法，由通配符展开时为通配符
+func (d DirConfig) entry() string {
+	if d.source != "" {
+		return d.source
+	}
+	return d.Path
+}
+
+// specificity 目录项的具体程度：直接配置的路径最具体，通配符中固定的字符越多越具体
+func (d DirConfig) specificity() int {
+	if d.source == "" {
+		return int(^uint(0) >> 1)
+	}
+	return len(d.source) - strings.Count(d.source, "*") - strings.Count(d.source, "?")
+}
+
+// resolveOverlaps 去掉被多个目录项匹配的重复目录：至少一项由通配符展开，或两项处理相同的文件时，
+// 只保留最具体的一项（相同时保留先配置的一项），避免同一批文件被扫描、统计两次；
+// 并记录位于 subtrees、date_dirs 目录之中的其他目录，父目录按子目录删除时跳过它们
+func resolveOverlaps(dirs []DirConfig) ([]DirConfig, []Overlap) {
+	var overlaps []Overlap
+	var kept []DirConfig
+	index := map[string][]int{} // 本地目录 -> kept 中的下标
+	for _, d := range dirs {
+		if isRemote(d.Path) || d.Docker != nil {
+			kept = append(kept, d)
+			continue
+		}
+		key := dirKey(d.Path)
+		dup := -1
+		for _, i := range index[key] {
+			if k := kept[i]; k.source != "" || d.source != "" || sameFiles(k, d) {
+				dup = i
+				break
+			}
+		}
+		if dup < 0 {
+			index[key] = append(index[key], len(kept))
+			kept = append(kept, d)
+			continue
+		}
+		win, lose := kept[dup], d
+		if d.specificity() > win.specificity() {
+			win, lose = d, win
+			kept[dup] = d
+		}
+		overlaps = append(overlaps, Overlap{Path: d.Path, Kept: win.entry(), Dropped: lose.entry()})
+	}
+	for i := range kept {
+		parent := &kept[i]
+		if (!parent.Subtrees && !parent.DateDirs) || isRemote(parent.Path) || parent.Docker != nil {
+			continue
+		}
+		parent.nested = nil
+		for _, child := range kept {
+			if !isRemote(child.Path) && child.Docker == nil && isWithin(dirKey(child.Path), dirKey(parent.Path)) {
+				parent.nested = append(parent.nested, child.Path)
+				overlaps = append(overlaps, Overlap{Path: child.Path, Kept: child.entry(), Parent: parent.Path})
+			}
+		}
+	}
+	return kept, overlaps
+}
+
+// containsNested 子目录 path 是否为单独配置的目录或包含单独配置的目录，subtrees、date_dirs 不整个删除这样的子目录
+func (d DirConfig) containsNested(path string) (string, bool) {
+	key := dirKey(path)
+	for _, n := range d.nested {
+		if k := dirKey(n); k == key || isWithin(k, key) {
+			return n, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,92 @@
+//go:build !windows
+
+package cleaner
+
+import (
+	"os/user"
+	"strconv"
+	"syscall"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// ownerFilter 文件属主（owner 过滤器）或属组（group 过滤器）在列表中
+type ownerFilter struct {
+	ids   map[uint32]bool
+	group bool
+}
+
+func newOwnerFilter(params map[string]interface{}) (Filter, error) {
+	var cfg struct {
+		Users []string `mapstructure:"users"`
+	}
+	if err := decodeParams(params, &cfg); err != nil {
+		return nil, err
+	}
+	ids, err := lookupIDs(cfg.Users, func(name string) (string, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+		return u.Uid, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ownerFilter{ids: ids}, nil
+}
+
+func newGroupFilter(params map[string]interface{}) (Filter, error) {
+	var cfg struct {
+		Groups []string `mapstructure:"groups"`
+	}
+	if err := decodeParams(params, &cfg); err != nil {
+		return nil, err
+	}
+	ids, err := lookupIDs(cfg.Groups, func(name string) (string, error) {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return "", err
+		}
+		return g.Gid, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ownerFilter{ids: ids, group: true}, nil
+}
+
+// lookupIDs 把用户名/组名解析为数字 ID，纯数字直接作为 ID
+func lookupIDs(names []string, lookup func(string) (string, error)) (map[uint32]bool, error) {
+	if len(names) == 0 {
+		return nil, i18n.Errorf("owner/group 过滤器缺少 users/groups")
+	}
+	ids := map[uint32]bool{}
+	for _, name := range names {
+		id, err := strconv.ParseUint(name, 10, 32)
+		if err != nil {
+			s, lerr := lookup(name)
+			if lerr != nil {
+				return nil, i18n.Errorf("找不到用户或组 %q: %w", name, lerr)
+			}
+			if id, err = strconv.ParseUint(s, 10, 32); err != nil {
+				return nil, err
+			}
+		}
+		ids[uint32(id)] = true
+	}
+	return ids, nil
+}
+
+// Match 无法获取属主的文件（如内存文件系统中的文件）不匹配
+func (o ownerFilter) Match(f File, now time.Time) bool {
+	st, ok := f.Info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	if o.group {
+		return o.ids[st.Gid]
+	}
+	return o.ids[st.Uid]
+}
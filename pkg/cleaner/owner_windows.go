@@ -0,0 +1,15 @@
+package cleaner
+
+import (
+	"errors"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+func newOwnerFilter(params map[string]interface{}) (Filter, error) {
+	return nil, errors.New(i18n.T("owner/group 过滤器仅在 Unix 上支持"))
+}
+
+func newGroupFilter(params map[string]interface{}) (Filter, error) {
+	return nil, errors.New(i18n.T("owner/group 过滤器仅在 Unix 上支持"))
+}
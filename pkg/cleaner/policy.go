@@ -0,0 +1,255 @@
+package cleaner
+
+import (
+	"fmt"
+	"io/fs"
+	"runtime"
+	"sort"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/afero"
+)
+
+// File 待处理的文件
+type File struct {
+	FS   afero.Fs // 文件所在的文件系统，远程目标的文件为 nil
+	Path string
+	Info fs.FileInfo
+	Time time.Time // 判断年龄所用的时间：修改时间或文件名中的日期
+}
+
+// Filter 文件过滤策略，文件需满足目录和 tier 上的全部过滤器才会被处理
+type Filter interface {
+	Match(f File, now time.Time) bool
+}
+
+// NameFilter 只凭文件名就能判断的过滤器（如 extension、glob）。扫描时先执行，
+// 被排除的文件不再获取文件信息
+type NameFilter interface {
+	MatchName(name string) bool
+}
+
+// Result 动作的执行结果
+type Result struct {
+	Freed   int64 // 释放的磁盘空间
+	Removed bool  // 文件已从目录中移除（删除或移走），不再执行后续动作
+}
+
+// Action 对文件执行的处理动作
+type Action interface {
+	// Name 动作名称，用于统计和日志
+	Name() string
+	// Apply 处理文件，文件被原地改变（如压缩）时同步更新 f
+	Apply(f *File) (Result, error)
+}
+
+// RemovingAction 可由 Action 实现，表示动作总是把文件从目录中移除。
+// 这类文件按从旧到新处理并受 target_size 控制提前结束
+type RemovingAction interface {
+	RemovesFile() bool
+}
+
+// ActiveFileAction 可由 Action 实现，表示动作面向仍在写入的文件（如截断），
+// 不受静默期和使用中检查的限制
+type ActiveFileAction interface {
+	HandlesActiveFiles() bool
+}
+
+// FilterFactory 根据 filters 配置项中除 type 外的参数创建过滤器
+type FilterFactory func(params map[string]interface{}) (Filter, error)
+
+// ActionFactory 根据 tier 配置创建动作，自定义参数在 t.Params 中
+type ActionFactory func(t Tier) (Action, error)
+
+var (
+	filterRegistry = map[string]FilterFactory{}
+	actionRegistry = map[string]ActionFactory{}
+)
+
+// RegisterFilter 注册过滤器类型，配置中通过 {type: name, ...} 使用
+func RegisterFilter(name string, factory FilterFactory) {
+	filterRegistry[name] = factory
+}
+
+// RegisterAction 注册动作类型，配置中通过 tier 的 action: name 使用
+func RegisterAction(name string, factory ActionFactory) {
+	actionRegistry[name] = factory
+}
+
+// FilterSpec filters 配置项：type 指定过滤器类型，其余键作为参数
+type FilterSpec struct {
+	Type   string                 `yaml:"type" mapstructure:"type"`
+	Params map[string]interface{} `yaml:",inline" mapstructure:",remain"`
+}
+
+// caseFilter 按文件名匹配、可以不区分大小写的过滤器（extension、glob）。
+// 过滤器中没有配置 case_insensitive 时，withCase 返回使用目录设置的副本
+type caseFilter interface {
+	Filter
+	withCase(insensitive bool) Filter
+}
+
+func (s FilterSpec) build(insensitive bool) (Filter, error) {
+	factory, ok := filterRegistry[s.Type]
+	if !ok {
+		return nil, i18n.Errorf("未知的过滤器类型 %q", s.Type)
+	}
+	f, err := factory(s.Params)
+	if cf, ok := f.(caseFilter); ok && err == nil {
+		f = cf.withCase(insensitive)
+	}
+	return f, err
+}
+
+// decodeParams 将过滤器/动作参数解码到结构体，支持大小和时长字符串
+func decodeParams(params map[string]interface{}, out interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           out,
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			stringToByteSizeHook,
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(params)
+}
+
+// rule 由一个 tier 构建：文件满足全部过滤器（含年龄）时执行动作
+type rule struct {
+	tier    Tier
+	filters []Filter
+	action  Action
+}
+
+func (r rule) match(f File, now time.Time) bool {
+	return matchAll(r.filters, f, now)
+}
+
+// policy 目录的处理策略：目录级过滤器加按年龄从小到大排列的规则
+type policy struct {
+	filters []Filter
+	rules   []rule
+}
+
+func matchAll(filters []Filter, f File, now time.Time) bool {
+	for _, filter := range filters {
+		if !filter.Match(f, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchNames 执行 filters 中的 NameFilter，其余过滤器由 matchAll 在获取文件信息后判断
+func matchNames(filters []Filter, name string) bool {
+	for _, filter := range filters {
+		if nf, ok := filter.(NameFilter); ok && !nf.MatchName(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectRule 返回文件满足的最后一条（年龄最大的）规则，均不满足时返回 -1
+func selectRule(rules []rule, f File, now time.Time) int {
+	selected := -1
+	for i, r := range rules {
+		if r.match(f, now) {
+			selected = i
+		}
+	}
+	return selected
+}
+
+func removesFile(a Action) bool {
+	r, ok := a.(RemovingAction)
+	return ok && r.RemovesFile()
+}
+
+func handlesActiveFiles(a Action) bool {
+	r, ok := a.(ActiveFileAction)
+	return ok && r.HandlesActiveFiles()
+}
+
+// caseInsensitive 返回目录的扩展名、glob 和 name_date 正则是否不区分大小写：
+// 目录配置 > 全局配置 > Windows 上不区分、其他系统上区分
+func (c Config) caseInsensitive(d DirConfig) bool {
+	switch {
+	case d.CaseInsensitive != nil:
+		return *d.CaseInsensitive
+	case c.CaseInsensitive != nil:
+		return *c.CaseInsensitive
+	}
+	return runtime.GOOS == "windows"
+}
+
+// buildPolicy 根据目录配置构建过滤器和规则
+func (c Config) buildPolicy(d DirConfig) (*policy, error) {
+	tiers := c.Tiers(d)
+	sort.SliceStable(tiers, func(i, j int) bool {
+		return tiers[i].age() < tiers[j].age()
+	})
+
+	pol := &policy{}
+	fold := c.caseInsensitive(d)
+	hasCompress := false
+	for i, t := range tiers {
+		t.DeleteMode, t.ShredPasses = c.deleteMode(d, t)
+		if t.Action == actionDelete && t.DeleteMode != "" && t.DeleteMode != deleteModeUnlink && isRemote(d.Path) {
+			return nil, i18n.Errorf("目录 %s: 远程目录不支持 delete_mode: %s", d.Path, t.DeleteMode)
+		}
+		factory, ok := actionRegistry[t.Action]
+		if !ok {
+			return nil, i18n.Errorf("目录 %s 的第 %d 个 tier 的 action %q 无效", d.Path, i+1, t.Action)
+		}
+		action, err := factory(t)
+		if err != nil {
+			return nil, i18n.Errorf("目录 %s 的第 %d 个 tier: %w", d.Path, i+1, err)
+		}
+		r := rule{tier: t, filters: []Filter{ageFilter{min: t.age()}}, action: action}
+		for _, spec := range t.Filters {
+			filter, err := spec.build(fold)
+			if err != nil {
+				return nil, i18n.Errorf("目录 %s 的第 %d 个 tier: %w", d.Path, i+1, err)
+			}
+			r.filters = append(r.filters, filter)
+		}
+		if filter, err := newSizeBounds(t.MinSize, t.MaxSize); err != nil {
+			return nil, i18n.Errorf("目录 %s 的第 %d 个 tier: %w", d.Path, i+1, err)
+		} else if filter != nil {
+			r.filters = append(r.filters, filter)
+		}
+		pol.rules = append(pol.rules, r)
+		hasCompress = hasCompress || t.Action == actionCompress
+	}
+	if r, err := c.staleRule(d); err != nil {
+		return nil, i18n.Errorf("目录 %s 的 stale_files: %w", d.Path, err)
+	} else if r != nil {
+		pol.rules = append(pol.rules, *r)
+	}
+
+	if len(d.Extensions) > 0 || len(d.ExcludeExtensions) > 0 {
+		// 压缩产生的 .gz 文件按原文件名匹配扩展名
+		pol.filters = append(pol.filters, extensionFilter{include: d.Extensions, exclude: d.ExcludeExtensions, trimGz: hasCompress, fold: &fold})
+	}
+	for j, spec := range d.Filters {
+		filter, err := spec.build(fold)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", d.field(fmt.Sprintf("filters[%d]", j)), err)
+		}
+		pol.filters = append(pol.filters, filter)
+	}
+	if filter, err := newSizeBounds(d.MinSize, d.MaxSize); err != nil {
+		return nil, fmt.Errorf("%s: %w", d.field(""), err)
+	} else if filter != nil {
+		pol.filters = append(pol.filters, filter)
+	}
+	return pol, nil
+}
@@ -0,0 +1,169 @@
+package cleaner
+
+import (
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// preset 常见日志布局的内置配置：默认目录（按操作系统）、文件名模式和建议的保留天数
+type preset struct {
+	paths    map[string][]string // GOOS -> 默认目录，"" 为其他系统
+	patterns []string
+	exclude  []string // 始终保留的文件名模式
+	days     int
+
+	// 以下为系统公用目录的保守默认值：强制开启 skip_in_use，目录项未配置 quiet_period 时使用预设的值
+	skipInUse   bool
+	quietPeriod time.Duration
+	regularOnly bool // 只处理普通文件
+}
+
+// rotated 轮转后的文件：logrotate 的 access.log.1、access.log-20240101.gz 等，正在写入的 *.log 不处理
+var rotated = []string{"*.log.*", "*.log-*"}
+
+var presets = map[string]preset{
+	"iis": {
+		paths: map[string][]string{"windows": {
+			`%SystemDrive%\inetpub\logs\LogFiles\*`,
+			`%SystemRoot%\System32\LogFiles\HTTPERR`,
+		}},
+		patterns: []string{"u_ex*.log", "ex*.log", "httperr*.log"},
+		days:     30,
+	},
+	"nginx": {
+		paths: map[string][]string{
+			"windows": {`%SystemDrive%\nginx\logs`},
+			"":        {"/var/log/nginx"},
+		},
+		patterns: rotated,
+		days:     14,
+	},
+	"apache": {
+		paths: map[string][]string{
+			"windows": {`%SystemDrive%\Apache24\logs`},
+			"":        {"/var/log/apache2", "/var/log/httpd"},
+		},
+		patterns: append(append([]string{}, rotated...), "*_log.*", "*_log-*"),
+		days:     14,
+	},
+	"sqlserver": {
+		paths: map[string][]string{
+			"windows": {`%ProgramFiles%\Microsoft SQL Server\MSSQL*\MSSQL\Log`},
+			"linux":   {"/var/opt/mssql/log"},
+		},
+		// ERRORLOG、SQLAGENT.OUT 为当前日志，只处理 sp_cycle_errorlog 轮转出的编号文件和转储
+		patterns: []string{"ERRORLOG.*", "SQLAGENT.[0-9]*", "errorlog.*", "sqlagent.[0-9]*", "SQLDump*", "*.mdmp"},
+		days:     30,
+	},
+	"windows-cbs": {
+		paths:    map[string][]string{"windows": {`%SystemRoot%\Logs\CBS`, `%SystemRoot%\Logs\DISM`}},
+		patterns: []string{"CbsPersist_*.log", "CbsPersist_*.cab", "dism.log.bak", "*.log.bak"},
+		days:     30,
+	},
+	"tomcat": {
+		paths: map[string][]string{
+			"windows": {`%CATALINA_BASE%\logs`, `%CATALINA_HOME%\logs`},
+			"":        {"${CATALINA_BASE}/logs", "/opt/tomcat/logs", "/var/log/tomcat*"},
+		},
+		patterns: []string{"catalina.*.log", "localhost.*.log", "manager.*.log", "host-manager.*.log", "localhost_access_log*.txt", "catalina.out.*", "catalina.out-*"},
+		days:     14,
+	},
+	// 系统临时目录中有其他程序正在使用的文件，以及 X11、systemd 等依赖的锁文件和套接字，
+	// 因此跳过正在使用的文件和非普通文件，最近一天内修改过的文件一律不动
+	"temp": {
+		paths: map[string][]string{
+			"windows": {"%TEMP%", `%SystemRoot%\Temp`},
+			"":        {"/tmp", "/var/tmp"},
+		},
+		exclude:     []string{".X*-lock", "*.pid", "*.lock", "*.sock"},
+		days:        7,
+		skipInUse:   true,
+		quietPeriod: 24 * time.Hour,
+		regularOnly: true,
+	},
+}
+
+// PresetNames 返回内置预设的名称
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyPresets 展开目录项中的 preset：未配置 path 时使用预设在本系统上的默认目录（可能展开为多个目录项，
+// 引用了未设置的环境变量的目录跳过，不存在的目录静默跳过），附加预设的文件名模式，
+// 未配置保留期限时使用建议的天数；目录项中的其他配置保持不变。应在 ExpandPaths 之前调用
+func (c *Config) ApplyPresets() error {
+	var dirs []DirConfig
+	for _, d := range c.Directories {
+		if d.Preset == "" {
+			dirs = append(dirs, d)
+			continue
+		}
+		p, ok := presets[strings.ToLower(d.Preset)]
+		if !ok {
+			return i18n.Errorf("未知的预设 %q，可选 %s", d.Preset, strings.Join(PresetNames(), "、"))
+		}
+		params := map[string]interface{}{}
+		if len(d.Extensions) == 0 && len(p.patterns) > 0 {
+			params["patterns"] = p.patterns
+		}
+		if len(p.exclude) > 0 {
+			params["exclude"] = p.exclude
+		}
+		if len(params) > 0 {
+			d.Filters = append([]FilterSpec{{Type: "glob", Params: params}}, d.Filters...)
+		}
+		if p.regularOnly {
+			d.Filters = append([]FilterSpec{{Type: "regular"}}, d.Filters...)
+		}
+		if d.Days == 0 && d.MaxAge == 0 && len(d.Tiers) == 0 {
+			d.Days = p.days
+		}
+		if p.skipInUse {
+			d.SkipInUse = true
+		}
+		if d.QuietPeriod == 0 {
+			d.QuietPeriod = p.quietPeriod
+		}
+		if d.Path != "" {
+			dirs = append(dirs, d)
+			continue
+		}
+		paths, ok := p.paths[runtime.GOOS]
+		if !ok {
+			paths = p.paths[""]
+		}
+		if d.MissingDir == "" {
+			d.MissingDir = missingIgnore
+		}
+		n := 0
+		seen := map[string]bool{}
+		for _, path := range paths {
+			// 以服务运行时 %TEMP% 通常就是 %SystemRoot%\Temp，同一目录只保留一项
+			path = ExpandPath(path)
+			key := strings.ToLower(filepath.Clean(path))
+			if envPattern.MatchString(path) || seen[key] {
+				continue
+			}
+			seen[key] = true
+			e := d
+			e.Path = path
+			dirs = append(dirs, e)
+			n++
+		}
+		if n == 0 {
+			return i18n.Errorf("预设 %s 在本系统上没有默认目录，请配置 path", d.Preset)
+		}
+	}
+	c.Directories = dirs
+	return nil
+}
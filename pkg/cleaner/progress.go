@@ -0,0 +1,44 @@
+package cleaner
+
+import "time"
+
+const defaultProgressInterval = 30 * time.Second
+
+// progress 记录单个目录的处理进度，超大目录中每隔 progress_every 个文件或 progress_interval 输出一次进度，
+// 便于确认服务仍在工作
+type progress struct {
+	dir      string
+	every    int
+	interval time.Duration
+	start    time.Time
+	last     time.Time
+	n        int // 已扫描和已处理的文件数
+	lastN    int
+}
+
+func (cl *Cleaner) newProgress(dir string) *progress {
+	interval := cl.config.ProgressInterval
+	if interval == 0 {
+		interval = defaultProgressInterval
+	}
+	now := time.Now()
+	return &progress{dir: dir, every: cl.config.ProgressEvery, interval: interval, start: now, last: now}
+}
+
+// tick 扫描或处理一个文件后调用，达到间隔时输出进度
+func (cl *Cleaner) tick(stats *Stats) {
+	p := stats.progress
+	if p == nil {
+		return
+	}
+	p.n++
+	now := time.Now()
+	byCount := p.every > 0 && p.n-p.lastN >= p.every
+	byTime := p.interval > 0 && now.Sub(p.last) >= p.interval
+	if !byCount && !byTime {
+		return
+	}
+	p.last, p.lastN = now, p.n
+	cl.logf("目录 %s 进度: 已扫描 %d，已删除 %d，已释放 %s，用时 %s",
+		p.dir, stats.Scanned, stats.Deleted, ByteSize(stats.FreedBytes), now.Sub(p.start).Round(time.Second))
+}
@@ -0,0 +1,156 @@
+package cleaner
+
+import (
+	"io/fs"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// remoteFile 远程目标中的文件，实现 fs.FileInfo 以复用本地的过滤逻辑
+type remoteFile struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (f remoteFile) Name() string       { return f.name }
+func (f remoteFile) Size() int64        { return f.size }
+func (f remoteFile) Mode() fs.FileMode  { return 0 }
+func (f remoteFile) ModTime() time.Time { return f.modTime }
+func (f remoteFile) IsDir() bool        { return false }
+func (f remoteFile) Sys() interface{}   { return nil }
+
+// remoteTarget 远程清理目标，只提供列出文件和删除文件两种操作
+type remoteTarget interface {
+	// list 列出目标目录（前缀）下的文件，不包含子目录
+	list() ([]fs.FileInfo, error)
+	// remove 删除指定文件，返回删除失败的文件及原因
+	remove(names []string) map[string]error
+}
+
+func isRemote(path string) bool {
+	return strings.Contains(path, "://")
+}
+
+// openRemote 根据目录地址的 scheme 创建远程目标
+func openRemote(dir DirConfig) (remoteTarget, error) {
+	u, err := url.Parse(dir.Path)
+	if err != nil {
+		return nil, i18n.Errorf("远程地址 %s 无效: %w", dir.Path, err)
+	}
+	switch u.Scheme {
+	case "sftp":
+		return newSFTPTarget(u, dir.SFTP)
+	case "s3":
+		return newS3Target(u, dir.S3)
+	case "oss":
+		return newOSSTarget(u, dir.OSS)
+	case "ftp":
+		return newFTPTarget(u, dir.FTP)
+	}
+	return nil, i18n.Errorf("不支持的远程地址类型: %s", u.Scheme)
+}
+
+// remoteExpired 列出远程目标中满足过滤条件、按规则到期且不在静默期内的文件，按时间从旧到新排列，
+// 同时返回目录中所有文件的总大小。只读，不删除任何文件
+func (cl *Cleaner) remoteExpired(dir DirConfig, now time.Time, stats *Stats) (remoteTarget, []candidate, int64, error) {
+	target, err := openRemote(dir)
+	if err != nil {
+		return nil, nil, 0, i18n.Errorf("打开远程目标失败: %w", err)
+	}
+	files, err := target.list()
+	if err != nil {
+		return nil, nil, 0, i18n.Errorf("列出文件失败: %w", err)
+	}
+
+	quietSince := cl.config.quietSince(dir, now)
+	var candidates []candidate
+	var totalSize int64
+	for _, info := range files {
+		stats.Scanned++
+		totalSize += info.Size()
+		f := File{Path: info.Name(), Info: info, Time: dir.fileTime(info)}
+		if !matchAll(dir.policy.filters, f, now) {
+			cl.debugf("跳过 %s：不满足过滤条件", f.Path)
+			continue
+		}
+		k := selectRule(dir.policy.rules, f, now)
+		if k < 0 {
+			cl.debugf("跳过 %s：未到期", f.Path)
+			continue
+		}
+		if _, ok := dir.policy.rules[k].action.(deleteAction); !ok {
+			cl.debugf("跳过 %s：远程目标只支持删除", f.Path)
+			continue
+		}
+		stats.Matched++
+		if !quietSince.IsZero() && info.ModTime().After(quietSince) {
+			cl.skipf(stats, "跳过 %s：静默期内修改过", f.Path)
+			stats.Quiet++
+			continue
+		}
+		candidates = append(candidates, candidate{file: f, rule: k})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].file.Time.Before(candidates[j].file.Time)
+	})
+	return target, candidates, totalSize, nil
+}
+
+// cleanRemote 清理远程目标：只执行规则中的删除动作，其余规则（过滤器、文件名日期、目标大小）与本地目录一致。
+// preview 为 true 时（canary 模式）只在日志中列出将删除的文件及大小，不删除。返回将删除的文件数和大小
+func (cl *Cleaner) cleanRemote(dir DirConfig, now time.Time, stats *Stats, preview bool) (int, int64) {
+	target, candidates, totalSize, err := cl.remoteExpired(dir, now, stats)
+	if err != nil {
+		cl.errorf("远程目录 %s: %s", dir.Path, err)
+		stats.fail(err)
+		return 0, 0
+	}
+
+	var names []string
+	var pending int64
+	sizes := map[string]int64{}
+	for i, c := range candidates {
+		if dir.TargetSize > 0 && totalSize <= int64(dir.TargetSize) {
+			for _, c := range candidates[i:] {
+				cl.skipf(stats, "保留 %s：目录大小已降到 target_size 以下", c.file.Path)
+			}
+			stats.Spared += len(candidates) - i
+			break
+		}
+		if !preview && !cl.allow(stats, c.file.Path, pending+c.file.Info.Size()) {
+			break
+		}
+		pending += c.file.Info.Size()
+		names = append(names, c.file.Path)
+		sizes[c.file.Path] = c.file.Info.Size()
+		totalSize -= c.file.Info.Size()
+	}
+	if preview {
+		for i, name := range names {
+			if i < canaryListed {
+				cl.logf("canary：%s 到期，将执行 %s（%s）", name, actionDelete, ByteSize(sizes[name]))
+			}
+		}
+		return len(names), pending
+	}
+	if len(names) == 0 {
+		return 0, 0
+	}
+	failures := target.remove(names)
+	for _, name := range names {
+		if err, failed := failures[name]; failed {
+			cl.errorf("删除远程文件失败: %s: %s", name, err)
+			stats.fail(err)
+			continue
+		}
+		cl.matchf(stats, "%s %s（释放 %s）", actionDelete, name, ByteSize(sizes[name]))
+		stats.FreedBytes += sizes[name]
+	}
+	stats.Deleted += len(names) - len(failures)
+	return len(names), pending
+}
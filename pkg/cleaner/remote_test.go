@@ -0,0 +1,76 @@
+package cleaner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeS3 返回固定对象列表的最小 S3 服务，记录删除请求的次数
+func fakeS3(t *testing.T, deletes *int32) *httptest.Server {
+	t.Helper()
+	old, fresh := time.Now().Add(-10*day).UTC().Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			atomic.AddInt32(deletes, 1)
+			fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+			return
+		}
+		fmt.Fprintf(w, `<ListBucketResult>
+<Contents><Key>logs/a.log</Key><Size>100</Size><LastModified>%s</LastModified></Contents>
+<Contents><Key>logs/b.log</Key><Size>200</Size><LastModified>%s</LastModified></Contents>
+<Contents><Key>logs/c.log</Key><Size>300</Size><LastModified>%s</LastModified></Contents>
+</ListBucketResult>`, old, old, fresh)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func s3Dir(endpoint string, canary bool) DirConfig {
+	return DirConfig{Path: "s3://bucket/logs", Days: 3, Canary: &canary, S3: &S3Config{Endpoint: endpoint, AccessKey: "key", SecretKey: "secret", PathStyle: true}}
+}
+
+func TestRemoteCanaryListsWithoutDeleting(t *testing.T) {
+	var deletes int32
+	srv := fakeS3(t, &deletes)
+
+	report := run(t, Config{Directories: []DirConfig{s3Dir(srv.URL, true)}})
+
+	if deletes != 0 {
+		t.Fatalf("canary 模式不应发送删除请求，实际 %d 次", deletes)
+	}
+	dr := report.Directories[0]
+	if !dr.Canary || dr.Matched != 2 || dr.WouldFree != 300 || dr.Deleted != 0 {
+		t.Errorf("Canary = %v, Matched = %d, WouldFree = %d, Deleted = %d，应为 true、2、300、0", dr.Canary, dr.Matched, dr.WouldFree, dr.Deleted)
+	}
+
+	report = run(t, Config{Directories: []DirConfig{s3Dir(srv.URL, false)}})
+	if deletes != 1 || report.Deleted != 2 || report.FreedBytes != 300 {
+		t.Errorf("删除请求 %d 次, Deleted = %d, FreedBytes = %d，应为 1、2、300", deletes, report.Deleted, report.FreedBytes)
+	}
+}
+
+func TestScanListsRemoteObjects(t *testing.T) {
+	var deletes int32
+	srv := fakeS3(t, &deletes)
+
+	dirs, err := New(Config{Directories: []DirConfig{s3Dir(srv.URL, false)}}).Scan(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range dirs[0].Files {
+		names = append(names, f.Path)
+	}
+	if got := strings.Join(names, ","); got != "a.log,b.log" || dirs[0].Bytes != 300 || dirs[0].Error != "" {
+		t.Errorf("Files = %s, Bytes = %d, Error = %q，应为 a.log,b.log、300", got, dirs[0].Bytes, dirs[0].Error)
+	}
+	if deletes != 0 {
+		t.Errorf("scan 不应发送删除请求，实际 %d 次", deletes)
+	}
+}
@@ -0,0 +1,82 @@
+package cleaner
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+const defaultRetryDelay = 5 * time.Second
+
+// retryItem 本次任务中删除失败、等待重试的文件
+type retryItem struct {
+	action Action
+	file   File
+	err    string
+	// category 失败原因的分类，重试成功后从 Errors 中扣除
+	category string
+	// transient 失败原因为临时错误，计入 Transient 而不是 Failed
+	transient bool
+}
+
+// retryFailed 任务结束前重试删除失败的文件，多数失败是几秒内就会释放的文件锁。
+// 重试成功的文件从所在目录的 Failed 中扣除并计入对应动作
+func (cl *Cleaner) retryFailed(ctx context.Context, dirs []DirReport) {
+	delay := cl.config.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+	for attempt := 1; attempt <= cl.config.RetryFailed; attempt++ {
+		n := 0
+		for _, d := range dirs {
+			if d.Aborted == "" && !d.TimedOut {
+				n += len(d.retries)
+			}
+		}
+		if n == 0 {
+			return
+		}
+		cl.logf("%d 个文件删除失败，%s 后重试（第 %d 次）", n, delay, attempt)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		for i := range dirs {
+			d := &dirs[i]
+			if d.Aborted != "" || d.TimedOut {
+				// 失败过多或超时已停止处理的目录不在本次任务中重试，配置了 failure_state 时留到之后的任务
+				continue
+			}
+			items := d.retries
+			d.retries = nil
+			for _, item := range items {
+				if _, err := item.file.FS.Stat(item.file.Path); os.IsNotExist(err) {
+					// 已被移走（如移入待上传目录），不再重试
+					continue
+				}
+				f := item.file
+				res, err := item.action.Apply(&f)
+				if err != nil {
+					cl.debugf("重试 %s 失败: %s", item.file.Path, err)
+					item.err = err.Error()
+					if c := classifyError(err); c != item.category {
+						d.unclassify(item.category)
+						d.classify(c)
+						item.category = c
+					}
+					d.retries = append(d.retries, item)
+					continue
+				}
+				cl.logf("重试 %s %s 成功（释放 %s）", item.action.Name(), item.file.Path, ByteSize(res.Freed))
+				if item.transient {
+					d.Transient--
+				} else {
+					d.Failed--
+				}
+				d.unclassify(item.category)
+				d.record(item.action.Name(), res)
+			}
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package cleaner
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// S3Config s3://bucket/prefix/ 目标的连接配置，兼容 MinIO 等 S3 协议存储
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" mapstructure:"endpoint"` // 如 http://minio:9000，默认 https://s3.<region>.amazonaws.com
+	Region    string `yaml:"region" mapstructure:"region"`     // 默认 us-east-1
+	AccessKey string `yaml:"access_key" mapstructure:"access_key"`
+	SecretKey string `yaml:"secret_key" mapstructure:"secret_key"`
+	PathStyle bool   `yaml:"path_style" mapstructure:"path_style"` // 使用 endpoint/bucket/key 形式的地址，MinIO 需要开启
+
+	RemoteLimits `yaml:",inline" mapstructure:",squash"`
+}
+
+// s3Client 基于 AWS Signature V4 的最小 S3 客户端
+type s3Client struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	http      *http.Client
+	throttle  *throttle
+}
+
+func newS3Client(bucket string, cfg *S3Config) (*s3Client, error) {
+	if cfg == nil {
+		cfg = &S3Config{}
+	}
+	c := &s3Client{
+		region:    cfg.Region,
+		bucket:    bucket,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		pathStyle: cfg.PathStyle,
+		http:      &http.Client{Timeout: 2 * time.Minute},
+		throttle:  newThrottle(cfg.RemoteLimits),
+	}
+	if c.region == "" {
+		c.region = "us-east-1"
+	}
+	if c.accessKey == "" {
+		c.accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if c.secretKey == "" {
+		c.secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + c.region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, i18n.Errorf("S3 endpoint %s 无效: %w", endpoint, err)
+	}
+	c.endpoint = u
+	return c, nil
+}
+
+// objectURL 返回对象（key 为空时为存储桶）的请求地址
+func (c *s3Client) objectURL(key string, query url.Values) *url.URL {
+	u := *c.endpoint
+	if c.pathStyle {
+		u.Path = "/" + c.bucket + "/" + key
+	} else {
+		u.Host = c.bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+// do 签名并发送请求，非 2xx 响应返回错误
+func (c *s3Client) do(method string, u *url.URL, body []byte, header http.Header) ([]byte, error) {
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	c.sign(req, sha256Hex(body), time.Now().UTC())
+	resp, err := c.throttle.do(c.http, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("S3 %s %s: %s %s", method, u.Path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// sign 为请求添加 AWS Signature V4 签名，payloadHash 为请求体的 SHA-256
+func (c *s3Client) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsURIEncode(req.URL.Path, false),
+		awsCanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + c.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), day)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+type s3ListResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+// listObjects 分页列出 prefix 下的直接子对象（以 "/" 为分隔符，不进入子目录）
+func (c *s3Client) listObjects(prefix string) ([]fs.FileInfo, error) {
+	var files []fs.FileInfo
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}, "delimiter": {"/"}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		data, err := c.do(http.MethodGet, c.objectURL("", query), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result s3ListResult
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		for _, obj := range result.Contents {
+			name := strings.TrimPrefix(obj.Key, prefix)
+			if name == "" {
+				continue
+			}
+			files = append(files, remoteFile{name: name, size: obj.Size, modTime: obj.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return files, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+type s3DeleteRequest struct {
+	XMLName xml.Name `xml:"Delete"`
+	Quiet   bool     `xml:"Quiet"`
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type s3DeleteResult struct {
+	Errors []struct {
+		Key     string `xml:"Key"`
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+}
+
+// deleteObjects 批量删除对象（每次最多 1000 个，按 concurrency 同时发出多批），返回删除失败的 key
+func (c *s3Client) deleteObjects(keys []string) map[string]error {
+	var mu sync.Mutex
+	failures := map[string]error{}
+	fail := func(key string, err error) {
+		mu.Lock()
+		failures[key] = err
+		mu.Unlock()
+	}
+	inBatches(keys, 1000, func(batch []string) {
+		req := s3DeleteRequest{Quiet: true}
+		for _, key := range batch {
+			req.Objects = append(req.Objects, struct {
+				Key string `xml:"Key"`
+			}{key})
+		}
+		body, _ := xml.Marshal(req)
+		sum := md5.Sum(body)
+		header := http.Header{
+			"Content-Type": {"application/xml"},
+			"Content-Md5":  {base64.StdEncoding.EncodeToString(sum[:])},
+		}
+		data, err := c.do(http.MethodPost, c.objectURL("", url.Values{"delete": {""}}), body, header)
+		var result s3DeleteResult
+		if err == nil {
+			err = xml.Unmarshal(data, &result)
+		}
+		if err != nil {
+			for _, key := range batch {
+				fail(key, err)
+			}
+			return
+		}
+		for _, e := range result.Errors {
+			fail(e.Key, fmt.Errorf("%s: %s", e.Code, e.Message))
+		}
+	})
+	return failures
+}
+
+// s3Target s3://bucket/prefix/ 清理目标
+type s3Target struct {
+	client *s3Client
+	prefix string
+}
+
+func newS3Target(u *url.URL, cfg *S3Config) (*s3Target, error) {
+	client, err := newS3Client(u.Host, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Target{client: client, prefix: objectPrefix(u.Path)}, nil
+}
+
+// objectPrefix 将 URL 路径转换为对象前缀，非空前缀统一以 "/" 结尾
+func objectPrefix(p string) string {
+	p = strings.TrimPrefix(p, "/")
+	if p != "" && !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+	return p
+}
+
+func (t *s3Target) list() ([]fs.FileInfo, error) {
+	return t.client.listObjects(t.prefix)
+}
+
+func (t *s3Target) remove(names []string) map[string]error {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = t.prefix + name
+	}
+	failures := map[string]error{}
+	for key, err := range t.client.deleteObjects(keys) {
+		failures[strings.TrimPrefix(key, t.prefix)] = err
+	}
+	return failures
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsURIEncode 按 AWS 规则编码：只保留 A-Z a-z 0-9 - _ . ~，路径中的 "/" 按需保留
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case 'A' <= ch && ch <= 'Z', 'a' <= ch && ch <= 'z', '0' <= ch && ch <= '9',
+			ch == '-', ch == '_', ch == '.', ch == '~':
+			b.WriteByte(ch)
+		case ch == '/' && !encodeSlash:
+			b.WriteByte(ch)
+		default:
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+func awsCanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string{}, query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, awsURIEncode(k, true)+"="+awsURIEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
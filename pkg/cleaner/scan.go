@@ -0,0 +1,138 @@
+package cleaner
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// ScanFile 审计扫描中按当前配置到期的文件或子目录
+type ScanFile struct {
+	Path    string    `json:"path"`
+	Dir     bool      `json:"dir,omitempty"` // date_dirs、subtrees 按整个子目录删除
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Policy  string    `json:"policy"` // 满足的保留策略，如“72h0m0s 后删除”
+	Action  string    `json:"action"`
+}
+
+// ScanDir 一个目录的审计扫描结果
+type ScanDir struct {
+	Path     string     `json:"path"`
+	Source   string     `json:"source,omitempty"` // 目录由通配符展开时为配置中的通配符
+	Policies []string   `json:"policies"`         // 目录生效的保留策略
+	OnHold   bool       `json:"on_hold,omitempty"`
+	Canary   bool       `json:"canary,omitempty"`
+	Scanned  int        `json:"scanned"`
+	Bytes    int64      `json:"bytes"` // 到期内容的总大小
+	Files    []ScanFile `json:"files"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// Scan 只读审计扫描：按 now 列出各本地目录中满足保留策略、下次任务将被处理的文件，
+// 以及 date_dirs、subtrees 将整个删除的子目录，只读取目录和文件信息，不修改任何文件，也不读写任务状态
+// （失败记录、两阶段删除标记等）。处于保留状态或 canary 模式的目录同样列出并标记；
+// target_size、max_files、去重等依赖处理结果的规则不计算，容器日志不扫描
+func (cl *Cleaner) Scan(ctx context.Context, now time.Time) ([]ScanDir, error) {
+	if cl.err != nil {
+		return nil, cl.err
+	}
+	cl.dates.reset()
+	var results []ScanDir
+	dirs, _ := cl.directories()
+	for _, dir := range dirs {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		res := ScanDir{Path: dir.Path, Source: dir.source, OnHold: cl.onHold(dir), Canary: cl.canary(dir)}
+		for _, r := range dir.policy.rules {
+			res.Policies = append(res.Policies, r.tier.String())
+		}
+		if dir.Docker != nil {
+			res.Error = i18n.T("不支持容器日志")
+			results = append(results, res)
+			continue
+		}
+		var stats Stats
+		if isRemote(dir.Path) {
+			_, candidates, _, err := cl.remoteExpired(dir, now, &stats)
+			for _, c := range candidates {
+				f, r := c.file, dir.policy.rules[c.rule]
+				res.Files = append(res.Files, ScanFile{Path: f.Path, Size: f.Info.Size(), ModTime: f.Info.ModTime(), Policy: r.tier.String(), Action: r.action.Name()})
+				res.Bytes += f.Info.Size()
+			}
+			res.Scanned = stats.Scanned
+			if err != nil {
+				res.Error = err.Error()
+			}
+			results = append(results, res)
+			continue
+		}
+		err := cl.expired(ctx, dir, now, &stats, func(f File, r rule) {
+			res.Files = append(res.Files, ScanFile{Path: f.Path, Size: f.Info.Size(), ModTime: f.Info.ModTime(), Policy: r.tier.String(), Action: r.action.Name()})
+			res.Bytes += f.Info.Size()
+		})
+		res.Scanned = stats.Scanned
+		if err != nil {
+			res.Error = err.Error()
+			results = append(results, res)
+			continue
+		}
+		if dir.DateDirs || dir.Subtrees {
+			cl.scanSubdirs(dir, now, &res)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// scanSubdirs 列出 date_dirs、subtrees 将整个删除的子目录，判断条件与 cleanDateDirs、cleanSubtrees 相同
+func (cl *Cleaner) scanSubdirs(dir DirConfig, now time.Time, res *ScanDir) {
+	age, ok := cl.config.deleteAge(dir)
+	if !ok {
+		return
+	}
+	threshold := now.Add(-age)
+	quietSince := cl.config.quietSince(dir, now)
+	entries, err := cl.readDir(dir, dir.Path)
+	if err != nil {
+		return
+	}
+	ignore := cl.loadIgnore(dir.Path)
+	policy := Tier{MaxAge: age, Action: actionDelete}.String()
+	for _, entry := range entries {
+		path := filepath.Join(dir.Path, entry.Name())
+		if !entry.IsDir() || entry.Name() == trashDirName || ignore.match(entry.Name(), true) {
+			continue
+		}
+		if _, ok := dir.containsNested(path); ok {
+			continue
+		}
+		lim := cl.config.walkLimits(dir)
+		if dir.DateDirs {
+			date, ok := dir.parseDirDate(entry.Name())
+			expired := ok && date.AddDate(0, 0, 1).Before(threshold)
+			if info, err := entry.Info(); expired && err == nil && !quietSince.IsZero() && info.ModTime().After(quietSince) {
+				expired = false
+			}
+			if expired {
+				if size, walked := treeSize(cl.fs, path, lim); !walked.crossed {
+					res.Files = append(res.Files, ScanFile{Path: path, Dir: true, Size: size, ModTime: date, Policy: policy, Action: actionDelete})
+					res.Bytes += size
+				}
+				continue
+			}
+			if !dir.Subtrees {
+				continue
+			}
+		}
+		newest, size, walked := treeNewest(cl.fs, path, lim)
+		if walked.crossed || walked.limited || !newest.Before(threshold) {
+			continue
+		}
+		res.Files = append(res.Files, ScanFile{Path: path, Dir: true, Size: size, ModTime: newest, Policy: policy, Action: actionDelete})
+		res.Bytes += size
+	}
+}
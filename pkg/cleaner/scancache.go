@@ -0,0 +1,234 @@
+package cleaner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"cleanlogservice/pkg/state"
+)
+
+// scanCacheMaxAge 缓存记录的有效期，每个目录至少每隔这么久完整扫描一次
+const scanCacheMaxAge = 30 * 24 * time.Hour
+
+// scanEntry scan_cache 中一个目录上次完整扫描的结果
+type scanEntry struct {
+	Path    string    `json:"path"`
+	Config  string    `json:"config"`   // 目录配置的摘要，配置修改后重新扫描
+	ModTime time.Time `json:"mod_time"` // 目录的修改时间
+	Size    int64     `json:"size"`     // 目录本身的大小
+	Ignore  time.Time `json:"ignore"`   // 目录中 .cleanignore 的修改时间，没有时为零值
+	Due     time.Time `json:"due"`      // 最早有文件到期或进入下一级策略的时间，零值表示不会因时间推移变化
+	Scanned time.Time `json:"scanned"`
+}
+
+// unchanged 目录的修改时间、大小和 .cleanignore 是否与记录相同
+func (e scanEntry) unchanged(o scanEntry) bool {
+	return e.ModTime.Equal(o.ModTime) && e.Size == o.Size && e.Ignore.Equal(o.Ignore)
+}
+
+// scanCache 一次任务中 scan_cache 的记录，本次完整扫描的目录更新或删除记录，
+// 没有处理的目录（处于保留、canary 或不在本次任务中）保留原来的记录
+type scanCache struct {
+	mu      sync.Mutex // 并行处理目录时由各 worker 共用
+	entries map[string]scanEntry
+}
+
+func (c *scanCache) get(path string) (scanEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[path]
+	return e, ok
+}
+
+// put 记录目录本次完整扫描的结果，e 为空时删除原来的记录
+func (c *scanCache) put(path string, e *scanEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e == nil {
+		delete(c.entries, path)
+	} else {
+		c.entries[path] = *e
+	}
+}
+
+// scanTracker 扫描一个目录时记录最早可能到期的时间
+type scanTracker struct {
+	bounds []time.Duration // 目录和各级策略中的年龄界限
+	due    time.Time
+}
+
+// observe 记录文件下一次越过年龄界限的时间
+func (t *scanTracker) observe(fileTime, now time.Time) {
+	for _, b := range t.bounds {
+		at := fileTime.Add(b)
+		if at.After(now) && (t.due.IsZero() || at.Before(t.due)) {
+			t.due = at
+		}
+	}
+}
+
+// loadScanCache 读取 scan_cache，未配置时返回 nil，每次都完整扫描
+func (cl *Cleaner) loadScanCache(now time.Time) *scanCache {
+	if cl.config.ScanCache == "" {
+		return nil
+	}
+	var records []scanEntry
+	if err := state.Read(cl.fs, cl.config.ScanCache, &records); err != nil && !os.IsNotExist(err) {
+		cl.warnf("读取扫描缓存 %s 失败: %s", cl.config.ScanCache, err)
+	}
+	c := &scanCache{entries: map[string]scanEntry{}}
+	for _, rec := range records {
+		if now.Sub(rec.Scanned) < scanCacheMaxAge {
+			c.entries[rec.Path] = rec
+		}
+	}
+	return c
+}
+
+// saveScanCache 保存本次更新后的记录
+func (cl *Cleaner) saveScanCache(c *scanCache) {
+	if c == nil {
+		return
+	}
+	// 超时后放弃等待的目录可能仍在记录
+	c.mu.Lock()
+	records := make([]scanEntry, 0, len(c.entries))
+	for _, rec := range c.entries {
+		records = append(records, rec)
+	}
+	c.mu.Unlock()
+	sort.Slice(records, func(i, j int) bool { return records[i].Path < records[j].Path })
+	if err := state.Write(cl.fs, cl.config.ScanCache, records); err != nil {
+		cl.errorf("保存扫描缓存 %s 失败: %s", cl.config.ScanCache, err)
+	}
+}
+
+// scanKey 返回目录配置的摘要；目录的处理结果不只取决于目录项和文件年龄时返回 false，该目录每次都完整扫描：
+// 远程和 Docker 目录、date_dirs、subtrees（子目录中的变化不改变目录的修改时间）、target_size、dedupe、
+// content_date、按大小或属主过滤（文件原地写入不改变目录），以及截断、软删除、归档到其他目录和自定义动作
+func (cl *Cleaner) scanKey(dir DirConfig) (string, bool) {
+	if isRemote(dir.Path) || dir.Docker != nil || dir.DateDirs || dir.Subtrees || dir.TargetSize > 0 || dir.Dedupe || dir.ContentDate != nil {
+		return "", false
+	}
+	if !staticFilters(dir.policy.filters) {
+		return "", false
+	}
+	for _, r := range dir.policy.rules {
+		if !staticFilters(r.filters) {
+			return "", false
+		}
+		switch a := r.action.(type) {
+		case deleteAction:
+			if a.soft != "" {
+				return "", false
+			}
+		case archiveAction:
+			if a.dir != "" {
+				return "", false
+			}
+		case compressAction:
+		default:
+			return "", false
+		}
+	}
+	c := cl.config
+	data, err := json.Marshal(struct {
+		Dir                DirConfig
+		Tiers              []Tier
+		QuietPeriod        time.Duration
+		SkipInUse          bool
+		SkipHardLinks      bool
+		ProtectedProcesses []string
+		CaseInsensitive    *bool
+	}{dir, c.Tiers(dir), c.QuietPeriod, c.SkipInUse, c.SkipHardLinks, c.ProtectedProcesses, c.CaseInsensitive})
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// staticFilters 过滤器是否只取决于文件名、文件类型和年龄
+func staticFilters(filters []Filter) bool {
+	for _, f := range filters {
+		switch f.(type) {
+		case extensionFilter, globFilter, regularFilter, ageFilter:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ageBounds 返回目录和各级策略中的年龄界限，文件越过这些界限时处理结果可能变化
+func (p *policy) ageBounds() []time.Duration {
+	var bounds []time.Duration
+	add := func(filters []Filter) {
+		for _, f := range filters {
+			if a, ok := f.(ageFilter); ok {
+				bounds = append(bounds, a.min)
+				if a.max > 0 {
+					bounds = append(bounds, a.max)
+				}
+			}
+		}
+	}
+	add(p.filters)
+	for _, r := range p.rules {
+		add(r.filters)
+	}
+	return bounds
+}
+
+// signature 读取目录的修改时间、大小和 .cleanignore 的修改时间
+func (cl *Cleaner) signature(path string) (scanEntry, bool) {
+	info, err := cl.fs.Stat(path)
+	if err != nil {
+		return scanEntry{}, false
+	}
+	e := scanEntry{Path: path, ModTime: info.ModTime(), Size: info.Size()}
+	if info, err := cl.fs.Stat(filepath.Join(path, ignoreFileName)); err == nil {
+		e.Ignore = info.ModTime()
+	}
+	return e, true
+}
+
+// cleanCached 配置了 scan_cache 时按缓存清理本地目录：目录自上次完整扫描后没有变化、配置相同，
+// 且还没有文件到期时跳过；否则完整扫描，扫描前后目录没有变化、没有跳过或失败的文件时记录本次的结果。
+// 目录不能使用缓存时返回 false，由调用方照常清理
+func (cl *Cleaner) cleanCached(ctx context.Context, dir DirConfig, now time.Time, stats *Stats) bool {
+	if stats.scans == nil {
+		return false
+	}
+	key, ok := cl.scanKey(dir)
+	if !ok {
+		return false
+	}
+	before, ok := cl.signature(dir.Path)
+	if !ok {
+		return false
+	}
+	if prev, found := stats.scans.get(dir.Path); found && prev.Config == key && prev.unchanged(before) && (prev.Due.IsZero() || now.Before(prev.Due)) {
+		cl.debugf("目录 %s 自 %s 扫描后没有变化，跳过", dir.Path, prev.Scanned.Format(time.RFC3339))
+		stats.Unchanged++
+		return true
+	}
+	stats.scans.put(dir.Path, nil)
+	stats.scan = &scanTracker{bounds: dir.policy.ageBounds()}
+	cl.cleanDirectory(ctx, dir.Path, dir, dir.policy.rules, now, stats)
+	due := stats.scan.due
+	stats.scan = nil
+	after, ok := cl.signature(dir.Path)
+	if ok && after.unchanged(before) && ctx.Err() == nil && stats.Skipped() == 0 && stats.Failed == 0 && stats.Transient == 0 {
+		after.Config, after.Due, after.Scanned = key, due, now
+		stats.scans.put(dir.Path, &after)
+	}
+	return true
+}
@@ -0,0 +1,216 @@
+package cleaner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// SFTPConfig sftp:// 目标的连接配置。通过系统的 OpenSSH sftp 客户端执行操作
+type SFTPConfig struct {
+	KeyFile  string   `yaml:"key_file" mapstructure:"key_file"` // 私钥文件
+	Password string   `yaml:"password" mapstructure:"password"` // 密码认证，需要 OpenSSH 8.4 及以上（SSH_ASKPASS_REQUIRE）
+	Options  []string `yaml:"options" mapstructure:"options"`   // 额外的 -o 参数，如 StrictHostKeyChecking=accept-new
+	Command  string   `yaml:"command" mapstructure:"command"`   // sftp 可执行文件，默认从 PATH 查找
+
+	RemoteLimits `yaml:",inline" mapstructure:",squash"` // bandwidth 换算为 sftp -l
+}
+
+// askpassEnv 密码认证时，sftp 通过 SSH_ASKPASS 重新调用本程序取得密码
+const askpassEnv = "CLEANLOG_SFTP_PASSWORD"
+
+// HandleAskpass 应在 main 开头调用：本程序作为 sftp 的 SSH_ASKPASS 被调用时输出密码并返回 true，
+// 调用方随即退出即可
+func HandleAskpass() bool {
+	password, ok := os.LookupEnv(askpassEnv)
+	if ok {
+		fmt.Println(password)
+	}
+	return ok
+}
+
+type sftpTarget struct {
+	dest string // user@host
+	dir  string
+	args []string
+	env  []string
+	cmd  string
+	rate *pacer
+}
+
+func newSFTPTarget(u *url.URL, cfg *SFTPConfig) (*sftpTarget, error) {
+	if cfg == nil {
+		cfg = &SFTPConfig{}
+	}
+	t := &sftpTarget{dest: u.Hostname(), dir: u.Path, cmd: cfg.Command, rate: newPacer(cfg.RequestRate)}
+	if t.dir == "" {
+		t.dir = "."
+	}
+	if t.cmd == "" {
+		t.cmd = "sftp"
+	}
+	if u.User != nil {
+		t.dest = u.User.Username() + "@" + t.dest
+	}
+	if u.Port() != "" {
+		t.args = append(t.args, "-P", u.Port())
+	}
+	if cfg.KeyFile != "" {
+		t.args = append(t.args, "-i", cfg.KeyFile)
+	}
+	if cfg.Bandwidth > 0 {
+		// -l 的单位为 Kbit/s
+		kbit := int64(cfg.Bandwidth) * 8 / 1000
+		if kbit < 1 {
+			kbit = 1
+		}
+		t.args = append(t.args, "-l", strconv.FormatInt(kbit, 10))
+	}
+	if cfg.Concurrency > 0 {
+		t.args = append(t.args, "-R", strconv.Itoa(cfg.Concurrency))
+	}
+	for _, opt := range cfg.Options {
+		t.args = append(t.args, "-o", opt)
+	}
+	t.env = append(os.Environ(), "LC_ALL=C")
+	password := cfg.Password
+	if password == "" && u.User != nil {
+		password, _ = u.User.Password()
+	}
+	if password != "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+		// -b 会开启 BatchMode 禁用密码输入，需在其之前显式关闭
+		t.args = append(t.args, "-o", "BatchMode=no")
+		t.env = append(t.env, "SSH_ASKPASS="+exe, "SSH_ASKPASS_REQUIRE=force", "DISPLAY=:0", askpassEnv+"="+password)
+	}
+	return t, nil
+}
+
+// run 以批处理模式执行 sftp 命令
+func (t *sftpTarget) run(batch string) (string, error) {
+	t.rate.take(1)
+	args := append(append([]string{}, t.args...), "-b", "-", t.dest)
+	cmd := exec.Command(t.cmd, args...)
+	cmd.Env = t.env
+	cmd.Stdin = strings.NewReader(batch)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func (t *sftpTarget) list() ([]fs.FileInfo, error) {
+	out, err := t.run("ls -ln " + sftpQuote(t.dir) + "\n")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var files []fs.FileInfo
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "-") {
+			// 跳过命令回显、目录和符号链接
+			continue
+		}
+		f, err := parseSFTPLine(line, now)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func (t *sftpTarget) remove(names []string) map[string]error {
+	var batch strings.Builder
+	for _, name := range names {
+		// 前缀 '-' 使单个文件删除失败时继续执行后续命令
+		batch.WriteString("-rm " + sftpQuote(path.Join(t.dir, name)) + "\n")
+	}
+	failures := map[string]error{}
+	_, runErr := t.run(batch.String())
+	// sftp 的错误输出不便于逐个对应，重新列出目录确认哪些文件仍然存在
+	remaining, err := t.list()
+	if err != nil {
+		for _, name := range names {
+			failures[name] = err
+		}
+		return failures
+	}
+	exists := map[string]bool{}
+	for _, f := range remaining {
+		exists[f.Name()] = true
+	}
+	if runErr == nil {
+		runErr = errors.New(i18n.T("删除后文件仍然存在"))
+	}
+	for _, name := range names {
+		if exists[name] {
+			failures[name] = runErr
+		}
+	}
+	return failures
+}
+
+// parseSFTPLine 解析 sftp "ls -ln" 的输出行：
+// -rw-r--r--    1 0        0            1234 Mar 15 10:00 /var/log/app/a.log
+func parseSFTPLine(line string, now time.Time) (remoteFile, error) {
+	fields := make([]string, 0, 8)
+	rest := line
+	for len(fields) < 8 {
+		rest = strings.TrimLeft(rest, " ")
+		i := strings.IndexByte(rest, ' ')
+		if i < 0 {
+			return remoteFile{}, i18n.Errorf("无法解析 sftp 输出: %q", line)
+		}
+		fields = append(fields, rest[:i])
+		rest = rest[i:]
+	}
+	// 文件名与日期之间只有一个空格，保留文件名中的空格
+	name := path.Base(strings.TrimPrefix(rest, " "))
+	size, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return remoteFile{}, i18n.Errorf("无法解析 sftp 输出: %q", line)
+	}
+	modTime, err := parseLsTime(fields[5], fields[6], fields[7], now)
+	if err != nil {
+		return remoteFile{}, i18n.Errorf("无法解析 sftp 输出: %q", line)
+	}
+	return remoteFile{name: name, size: size, modTime: modTime}, nil
+}
+
+// parseLsTime 解析 ls 风格的时间：近半年内为 "Jan 2 15:04"，更早为 "Jan 2 2006"
+func parseLsTime(month, day, timeOrYear string, now time.Time) (time.Time, error) {
+	if strings.Contains(timeOrYear, ":") {
+		t, err := time.ParseInLocation("Jan 2 15:04 2006", month+" "+day+" "+timeOrYear+" "+strconv.Itoa(now.Year()), time.Local)
+		if err != nil {
+			return t, err
+		}
+		// 没有年份时按不晚于当前时间推断
+		if t.After(now.Add(24 * time.Hour)) {
+			t = t.AddDate(-1, 0, 0)
+		}
+		return t, nil
+	}
+	return time.ParseInLocation("Jan 2 2006", month+" "+day+" "+timeOrYear, time.Local)
+}
+
+// sftpQuote 为 sftp 批处理命令中的路径加引号
+func sftpQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,66 @@
+package cleaner
+
+import (
+	"strings"
+	"time"
+)
+
+// ShareConfig 网络共享（UNC 路径）的连接配置
+type ShareConfig struct {
+	Username   string        `yaml:"username" mapstructure:"username"`     // 如 DOMAIN\user
+	Password   string        `yaml:"password" mapstructure:"password"`     // 明文密码，建议改用 credential
+	Credential string        `yaml:"credential" mapstructure:"credential"` // Windows 凭据管理器中普通凭据的目标名
+	Retries    int           `yaml:"retries" mapstructure:"retries"`       // 连接或读取失败时的重试次数，默认 3
+	RetryDelay time.Duration `yaml:"retry_delay" mapstructure:"retry_delay"`
+}
+
+func isUNC(path string) bool {
+	return strings.HasPrefix(path, `\\`) || strings.HasPrefix(path, "//")
+}
+
+// uncRoot 返回 UNC 路径的共享根，如 \\server\share\logs 返回 \\server\share
+func uncRoot(path string) string {
+	parts := strings.FieldsFunc(path, func(r rune) bool { return r == '\\' || r == '/' })
+	if len(parts) < 2 {
+		return path
+	}
+	return `\\` + parts[0] + `\` + parts[1]
+}
+
+func (s *ShareConfig) retries() (int, time.Duration) {
+	retries, delay := 3, 5*time.Second
+	if s != nil && s.Retries > 0 {
+		retries = s.Retries
+	}
+	if s != nil && s.RetryDelay > 0 {
+		delay = s.RetryDelay
+	}
+	return retries, delay
+}
+
+// connectShare 在执行清理前建立到共享的连接
+func (cl *Cleaner) connectShare(dir DirConfig) error {
+	if dir.Share == nil || !isUNC(dir.Path) {
+		return nil
+	}
+	username, password := dir.Share.Username, dir.Share.Password
+	if dir.Share.Credential != "" {
+		var err error
+		username, password, err = readCredential(dir.Share.Credential)
+		if err != nil {
+			return err
+		}
+	}
+	retries, delay := dir.Share.retries()
+	var err error
+	for i := 0; i <= retries; i++ {
+		if err = addConnection(uncRoot(dir.Path), username, password); err == nil {
+			return nil
+		}
+		cl.warnf("连接共享 %s 失败（第 %d 次）: %s", uncRoot(dir.Path), i+1, err)
+		if i < retries {
+			time.Sleep(delay)
+		}
+	}
+	return err
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package cleaner
+
+import (
+	"errors"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+const shareUnsupported = "网络共享凭据仅在 Windows 上支持"
+
+func addConnection(remote, username, password string) error {
+	return errors.New(i18n.T(shareUnsupported))
+}
+
+func readCredential(target string) (string, string, error) {
+	return "", "", errors.New(i18n.T(shareUnsupported))
+}
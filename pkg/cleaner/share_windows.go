@@ -0,0 +1,102 @@
+package cleaner
+
+import (
+	"fmt"
+	"unsafe"
+
+	"cleanlogservice/pkg/i18n"
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modmpr      = windows.NewLazySystemDLL("mpr.dll")
+	modadvapi32 = windows.NewLazySystemDLL("advapi32.dll")
+
+	procWNetAddConnection2W = modmpr.NewProc("WNetAddConnection2W")
+	procCredReadW           = modadvapi32.NewProc("CredReadW")
+	procCredFree            = modadvapi32.NewProc("CredFree")
+)
+
+const (
+	resourceTypeDisk = 1
+	credTypeGeneric  = 1
+
+	errorAlreadyAssigned           = 85
+	errorSessionCredentialConflict = 1219
+)
+
+type netResource struct {
+	Scope       uint32
+	Type        uint32
+	DisplayType uint32
+	Usage       uint32
+	LocalName   *uint16
+	RemoteName  *uint16
+	Comment     *uint16
+	Provider    *uint16
+}
+
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// addConnection 使用 WNetAddConnection2 建立到共享的连接（不映射盘符）
+func addConnection(remote, username, password string) error {
+	remotePtr, err := windows.UTF16PtrFromString(remote)
+	if err != nil {
+		return err
+	}
+	var userPtr, passPtr *uint16
+	if username != "" {
+		if userPtr, err = windows.UTF16PtrFromString(username); err != nil {
+			return err
+		}
+	}
+	if password != "" {
+		if passPtr, err = windows.UTF16PtrFromString(password); err != nil {
+			return err
+		}
+	}
+	res := netResource{Type: resourceTypeDisk, RemoteName: remotePtr}
+	r, _, _ := procWNetAddConnection2W.Call(
+		uintptr(unsafe.Pointer(&res)),
+		uintptr(unsafe.Pointer(passPtr)),
+		uintptr(unsafe.Pointer(userPtr)),
+		0,
+	)
+	switch r {
+	case 0, errorAlreadyAssigned, errorSessionCredentialConflict:
+		// 已存在的连接（包括使用其他凭据的连接）可以直接使用
+		return nil
+	}
+	return fmt.Errorf("WNetAddConnection2 %s: %w", remote, windows.Errno(r))
+}
+
+// readCredential 从 Windows 凭据管理器读取普通凭据（cmdkey /generic:目标 /user:用户 /pass:密码）
+func readCredential(target string) (string, string, error) {
+	targetPtr, err := windows.UTF16PtrFromString(target)
+	if err != nil {
+		return "", "", err
+	}
+	var cred *credential
+	r, _, callErr := procCredReadW.Call(uintptr(unsafe.Pointer(targetPtr)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		return "", "", i18n.Errorf("读取凭据 %s 失败: %w", target, callErr)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	username := windows.UTF16PtrToString(cred.UserName)
+	blob := unsafe.Slice((*uint16)(unsafe.Pointer(cred.CredentialBlob)), cred.CredentialBlobSize/2)
+	return username, windows.UTF16ToString(blob), nil
+}
@@ -0,0 +1,73 @@
+package cleaner
+
+import (
+	"crypto/rand"
+	"io"
+	"os"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/spf13/afero"
+)
+
+const (
+	deleteModeUnlink = "unlink"
+	deleteModeShred  = "shred"
+
+	defaultShredPasses = 3
+)
+
+// deleteMode 返回删除方式和覆写次数：tier > 目录 > 全局
+func (c Config) deleteMode(d DirConfig, t Tier) (string, int) {
+	mode, passes := t.DeleteMode, t.ShredPasses
+	if mode == "" {
+		mode = d.DeleteMode
+	}
+	if mode == "" {
+		mode = c.DeleteMode
+	}
+	if passes <= 0 {
+		passes = d.ShredPasses
+	}
+	if passes <= 0 {
+		passes = c.ShredPasses
+	}
+	if passes <= 0 {
+		passes = defaultShredPasses
+	}
+	return mode, passes
+}
+
+// newDeleteAction 按删除方式创建删除动作
+func newDeleteAction(mode string, passes int) (deleteAction, error) {
+	switch mode {
+	case "", deleteModeUnlink:
+		return deleteAction{}, nil
+	case deleteModeShred:
+		return deleteAction{shred: passes}, nil
+	case deleteModeRename, deleteModeTrash:
+		return deleteAction{soft: mode}, nil
+	}
+	return deleteAction{}, i18n.Errorf("delete_mode %q 无效，可选 unlink、shred、rename、trash", mode)
+}
+
+// shredFile 用随机数据覆写文件内容 passes 次并截断为空，之后再删除。
+// 在 SSD、写时复制文件系统（btrfs、ZFS、ReFS）或有快照时无法保证旧数据被覆盖
+func shredFile(fsys afero.Fs, path string, size int64, passes int) error {
+	f, err := fsys.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for i := 0; i < passes; i++ {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(f, rand.Reader, size); err != nil {
+			return err
+		}
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+	return f.Truncate(0)
+}
@@ -0,0 +1,76 @@
+package cleaner
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// ByteSize 字节数，配置中可写成 "512MB"、"10GB" 或纯数字
+type ByteSize int64
+
+var byteSizeUnits = []struct {
+	suffix string
+	size   ByteSize
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"T", 1 << 40},
+	{"G", 1 << 30},
+	{"M", 1 << 20},
+	{"K", 1 << 10},
+	{"B", 1},
+}
+
+// parseByteSize 解析带单位的大小字符串，单位不区分大小写，按 1024 进位
+func parseByteSize(s string) (ByteSize, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	if str == "" {
+		return 0, nil
+	}
+	unit := ByteSize(1)
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, u.suffix))
+			unit = u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(str, 64)
+	if err != nil || n < 0 {
+		return 0, i18n.Errorf("无效的大小: %q", s)
+	}
+	return ByteSize(n * float64(unit)), nil
+}
+
+func (b ByteSize) String() string {
+	switch {
+	case b >= 1<<40:
+		return fmt.Sprintf("%.2fTB", float64(b)/(1<<40))
+	case b >= 1<<30:
+		return fmt.Sprintf("%.2fGB", float64(b)/(1<<30))
+	case b >= 1<<20:
+		return fmt.Sprintf("%.2fMB", float64(b)/(1<<20))
+	case b >= 1<<10:
+		return fmt.Sprintf("%.2fKB", float64(b)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", int64(b))
+}
+
+// MarshalYAML 输出带单位的大小，便于阅读
+func (b ByteSize) MarshalYAML() (interface{}, error) {
+	return b.String(), nil
+}
+
+// stringToByteSizeHook 将配置中的字符串解析为 ByteSize
+func stringToByteSizeHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(ByteSize(0)) {
+		return data, nil
+	}
+	return parseByteSize(data.(string))
+}
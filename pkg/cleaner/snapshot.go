@@ -0,0 +1,215 @@
+package cleaner
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"cleanlogservice/pkg/state"
+	"github.com/spf13/afero"
+)
+
+// Snapshot bench 记录的各目录的目录项（不含文件内容），供 simulate 离线预演配置修改
+type Snapshot struct {
+	Taken       time.Time     `json:"taken"`
+	Host        string        `json:"host,omitempty"`
+	Directories []SnapshotDir `json:"directories"`
+}
+
+// SnapshotDir 一个目录本身（不含子目录）中的目录项
+type SnapshotDir struct {
+	Path    string          `json:"path"`
+	Ignore  string          `json:"ignore,omitempty"` // 目录中 .cleanignore 的内容
+	Entries []SnapshotEntry `json:"entries"`
+}
+
+// SnapshotEntry 快照中的一个文件或子目录
+type SnapshotEntry struct {
+	Name    string      `json:"name"`
+	Size    int64       `json:"size"`
+	Mode    fs.FileMode `json:"mode"`
+	ModTime time.Time   `json:"mod_time"`
+}
+
+// ReadSnapshot 读取 bench 保存的快照
+func ReadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, i18n.Errorf("快照 %s 格式无效: %s", path, err)
+	}
+	return &s, nil
+}
+
+// WriteSnapshot 保存快照，写入中断时原文件保持不变
+func WriteSnapshot(path string, s *Snapshot) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return state.WriteFile(afero.NewOsFs(), path, data)
+}
+
+// snapshotFs 以快照内容构造的只读文件系统：目录结构和 .cleanignore 保存在内存中，
+// 文件的大小、权限和修改时间取自快照，文件内容为空
+type snapshotFs struct {
+	afero.Fs
+	infos map[string]os.FileInfo
+}
+
+// snapshotInfo 快照中记录的文件信息
+type snapshotInfo struct {
+	e SnapshotEntry
+}
+
+func (i snapshotInfo) Name() string       { return i.e.Name }
+func (i snapshotInfo) Size() int64        { return i.e.Size }
+func (i snapshotInfo) Mode() fs.FileMode  { return i.e.Mode }
+func (i snapshotInfo) ModTime() time.Time { return i.e.ModTime }
+func (i snapshotInfo) IsDir() bool        { return i.e.Mode.IsDir() }
+func (i snapshotInfo) Sys() interface{}   { return nil }
+
+func newSnapshotFs(s *Snapshot) (afero.Fs, error) {
+	mem := afero.NewMemMapFs()
+	infos := map[string]os.FileInfo{}
+	for _, d := range s.Directories {
+		root := filepath.Clean(d.Path)
+		if err := mem.MkdirAll(root, 0o755); err != nil {
+			return nil, err
+		}
+		for _, e := range d.Entries {
+			path := filepath.Join(root, e.Name)
+			var err error
+			switch {
+			case e.Mode.IsDir():
+				err = mem.MkdirAll(path, 0o755)
+			case e.Name == ignoreFileName:
+				err = afero.WriteFile(mem, path, []byte(d.Ignore), 0o644)
+			default:
+				err = afero.WriteFile(mem, path, nil, 0o644)
+			}
+			if err != nil {
+				return nil, err
+			}
+			infos[path] = snapshotInfo{e}
+		}
+	}
+	return &snapshotFs{Fs: afero.NewReadOnlyFs(mem), infos: infos}, nil
+}
+
+func (s *snapshotFs) info(path string, info os.FileInfo) os.FileInfo {
+	if recorded, ok := s.infos[filepath.Clean(path)]; ok {
+		return recorded
+	}
+	return info
+}
+
+func (s *snapshotFs) Stat(name string) (os.FileInfo, error) {
+	info, err := s.Fs.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.info(name, info), nil
+}
+
+func (s *snapshotFs) Open(name string) (afero.File, error) {
+	f, err := s.Fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshotFile{File: f, fs: s}, nil
+}
+
+// snapshotFile 目录列表和 Stat 返回快照中记录的文件信息
+type snapshotFile struct {
+	afero.File
+	fs *snapshotFs
+}
+
+func (f *snapshotFile) Stat() (os.FileInfo, error) {
+	info, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return f.fs.info(f.Name(), info), nil
+}
+
+func (f *snapshotFile) Readdir(n int) ([]os.FileInfo, error) {
+	infos, err := f.File.Readdir(n)
+	for i, info := range infos {
+		infos[i] = f.fs.info(filepath.Join(f.Name(), info.Name()), info)
+	}
+	return infos, err
+}
+
+// SimulatedFile 预演中到期、将被处理的文件
+type SimulatedFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Action  string    `json:"action"`
+}
+
+// SimulatedDir 一个目录的预演结果
+type SimulatedDir struct {
+	Path    string          `json:"path"`
+	Scanned int             `json:"scanned"`
+	Bytes   int64           `json:"bytes"` // 到期文件的总大小
+	Files   []SimulatedFile `json:"files"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// Simulate 以快照代替文件系统，按当前配置预演快照记录时（now 为零值时使用快照时间）的一次任务，
+// 返回各目录到期、将被处理的文件，不访问实际的目录。快照只记录各目录本身的目录项，
+// date_dirs、subtrees、归档目录以及 target_size、max_files、去重等依赖处理结果的规则不预演；
+// 远程目录和容器日志不在快照中
+func (cl *Cleaner) Simulate(ctx context.Context, s *Snapshot, now time.Time) ([]SimulatedDir, error) {
+	if cl.err != nil {
+		return nil, cl.err
+	}
+	fsys, err := newSnapshotFs(s)
+	if err != nil {
+		return nil, err
+	}
+	if now.IsZero() {
+		now = s.Taken
+	}
+	sim := *cl
+	sim.fs, sim.dates = fsys, &contentDates{}
+	var results []SimulatedDir
+	dirs, _ := sim.directories()
+	for _, dir := range dirs {
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+		res := SimulatedDir{Path: dir.Path}
+		if isRemote(dir.Path) || dir.Docker != nil {
+			res.Error = i18n.T("不支持远程目录和容器日志")
+			results = append(results, res)
+			continue
+		}
+		if info, err := fsys.Stat(dir.Path); err != nil || !info.IsDir() {
+			res.Error = i18n.T("快照中没有该目录")
+			results = append(results, res)
+			continue
+		}
+		var stats Stats
+		err := sim.expired(ctx, dir, now, &stats, func(f File, r rule) {
+			res.Files = append(res.Files, SimulatedFile{Path: f.Path, Size: f.Info.Size(), ModTime: f.Info.ModTime(), Action: r.action.Name()})
+			res.Bytes += f.Info.Size()
+		})
+		res.Scanned = stats.Scanned
+		if err != nil {
+			res.Error = err.Error()
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
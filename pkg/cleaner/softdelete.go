@@ -0,0 +1,119 @@
+package cleaner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	deleteModeRename = "rename" // 改名为 <name>.deleted-<时间>，留在原目录
+	deleteModeTrash  = "trash"  // 移入同一目录下的 .trash，改名方式相同
+
+	actionPurge            = "purge" // 永久删除软删除的文件，计入 Purged
+	trashDirName           = ".trash"
+	softDeleteLayout       = "20060102T150405"
+	defaultSoftDeleteGrace = 7 * 24 * time.Hour
+)
+
+// softDeletedName 匹配软删除后的文件名，第一个捕获组为删除时间
+var softDeletedName = regexp.MustCompile(`\.deleted-(\d{8}T\d{6})$`)
+
+// softDeleteTime 返回软删除文件的删除时间，不是软删除的文件返回 false
+func softDeleteTime(name string) (time.Time, bool) {
+	m := softDeletedName.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(softDeleteLayout, m[1], time.Local)
+	return t, err == nil
+}
+
+// softDelete 把文件改名为 <name>.deleted-<时间>（trash 时移入同目录的 .trash），同一卷内改名，不复制数据；
+// 在 soft_delete_grace 到期前去掉后缀即可恢复
+func softDelete(f *File, trash bool) (Result, error) {
+	dir := filepath.Dir(f.Path)
+	if trash {
+		dir = filepath.Join(dir, trashDirName)
+		if err := f.FS.MkdirAll(dir, 0o755); err != nil {
+			return Result{}, err
+		}
+	}
+	target := filepath.Join(dir, filepath.Base(f.Path)+".deleted-"+time.Now().Format(softDeleteLayout))
+	if _, err := f.FS.Stat(target); err == nil {
+		return Result{}, os.ErrExist
+	}
+	if err := f.FS.Rename(f.Path, target); err != nil {
+		return Result{}, err
+	}
+	// 空间在 soft_delete_grace 后永久删除时才释放
+	return Result{Removed: true}, nil
+}
+
+// softDeletes 动作是否为软删除（delete_mode: rename、trash）
+func softDeletes(a Action) bool {
+	d, ok := a.(deleteAction)
+	return ok && d.soft != ""
+}
+
+// purgeAction 永久删除软删除的文件，与删除一样记录失败、加入重试和 failure_state
+type purgeAction struct{ deleteAction }
+
+func (purgeAction) Name() string { return actionPurge }
+
+// softDeleteGrace 返回软删除的文件保留多久后永久删除，目录配置优先于全局配置
+func (c Config) softDeleteGrace(d DirConfig) time.Duration {
+	if d.SoftDeleteGrace > 0 {
+		return d.SoftDeleteGrace
+	}
+	if c.SoftDeleteGrace > 0 {
+		return c.SoftDeleteGrace
+	}
+	return defaultSoftDeleteGrace
+}
+
+// purgeSoftDeleted 永久删除 path 中（以及 trash 模式时 path/.trash 中）删除时间早于 soft_delete_grace 的软删除文件。
+// 只处理去掉后缀后的原文件名满足目录的文件名过滤条件、且不受 .cleanignore 保护的文件，即本服务可能软删除的文件，
+// 恰好同名的其他文件保留；删除前同样需要 mark_state 确认。目录配置不再使用软删除后，之前留下的文件同样按期删除
+func (cl *Cleaner) purgeSoftDeleted(ctx context.Context, dir DirConfig, path string, files []File, ignore ignoreRules, now time.Time, stats *Stats) {
+	if entries, err := afero.ReadDir(cl.fs, filepath.Join(path, trashDirName)); err == nil {
+		for _, info := range entries {
+			if !info.IsDir() {
+				files = append(files, File{FS: cl.fs, Path: filepath.Join(path, trashDirName, info.Name()), Info: info})
+			}
+		}
+	}
+	before := now.Add(-cl.config.softDeleteGrace(dir))
+	for _, f := range files {
+		if ctx.Err() != nil {
+			return
+		}
+		name := f.Info.Name()
+		t, ok := softDeleteTime(name)
+		if !ok {
+			continue
+		}
+		orig := softDeletedName.ReplaceAllString(name, "")
+		if ignore.match(name, false) || ignore.match(orig, false) || !matchNames(dir.policy.filters, orig) {
+			cl.debugf("保留 %s：原文件名不满足目录的过滤条件，不是本服务软删除的文件", f.Path)
+			continue
+		}
+		if !t.Before(before) {
+			cl.debugf("保留 %s：软删除于 %s，未超过 soft_delete_grace", f.Path, t.Format(time.DateTime))
+			continue
+		}
+		f := f
+		f.Time = t
+		action := purgeAction{}
+		if !cl.confirmMark(f, action, stats) {
+			continue
+		}
+		if _, ok := cl.apply(action, &f, stats); ok {
+			cl.debugf("永久删除 %s（软删除于 %s）", f.Path, t.Format(time.DateTime))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package cleaner
+
+import (
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// maxPIDFileSize 内容按 PID 解析的锁文件、PID 文件的最大大小
+const maxPIDFileSize = 32
+
+// StaleFiles 空文件和遗留的锁文件、PID 文件的单独保留期：不论目录的 days、tiers 配置多长，
+// 这类文件超过该保留期即删除，避免日积月累留下数万个。仍受目录的 extensions、filters、min_size 限制
+type StaleFiles struct {
+	Days     int           `yaml:"days" mapstructure:"days"` // 默认 1
+	MaxAge   time.Duration `yaml:"max_age" mapstructure:"max_age"`
+	Empty    bool          `yaml:"empty" mapstructure:"empty"`       // 大小为 0 的文件
+	Patterns []string      `yaml:"patterns" mapstructure:"patterns"` // 锁文件等的文件名模式，如 ["*.lock", "*.pid"]；内容为仍在运行的进程的 PID 时不删除
+}
+
+func (s *StaleFiles) age() time.Duration {
+	switch {
+	case s.MaxAge > 0:
+		return s.MaxAge
+	case s.Days > 0:
+		return time.Duration(s.Days) * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// staleFiles 返回目录生效的 stale_files，目录中的配置整体优先；既不处理空文件也没有模式时返回 nil
+func (c Config) staleFiles(d DirConfig) *StaleFiles {
+	s := c.StaleFiles
+	if d.StaleFiles != nil {
+		s = d.StaleFiles
+	}
+	if s == nil || (!s.Empty && len(s.Patterns) == 0) {
+		return nil
+	}
+	return s
+}
+
+func (s *StaleFiles) check() error {
+	if s.Days < 0 || s.MaxAge < 0 {
+		return i18n.Errorf("days、max_age 不能为负数")
+	}
+	for _, p := range s.Patterns {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return i18n.Errorf("模式 %q 无效: %s", p, err)
+		}
+	}
+	return nil
+}
+
+// describe 策略说明中列出的处理对象
+func (s *StaleFiles) describe() string {
+	var parts []string
+	if s.Empty {
+		parts = append(parts, i18n.T("空文件"))
+	}
+	return strings.Join(append(parts, s.Patterns...), "、")
+}
+
+// staleRule 按 stale_files 删除的规则，排在目录全部规则之后，文件同时满足其他规则时优先
+func (c Config) staleRule(d DirConfig) (*rule, error) {
+	s := c.staleFiles(d)
+	if s == nil {
+		return nil, nil
+	}
+	t := Tier{MaxAge: s.age(), Action: actionDelete, stale: s.describe()}
+	t.DeleteMode, t.ShredPasses = c.deleteMode(d, t)
+	action, err := newDeleteAction(t.DeleteMode, t.ShredPasses)
+	if err != nil {
+		return nil, err
+	}
+	fold := c.caseInsensitive(d)
+	return &rule{tier: t, filters: []Filter{ageFilter{min: t.age()}, staleFilter{empty: s.Empty, patterns: s.Patterns, fold: fold}}, action: action}, nil
+}
+
+// staleFilter 空文件，或文件名匹配 patterns 且记录的进程已不在运行的锁文件、PID 文件
+type staleFilter struct {
+	empty    bool
+	patterns []string
+	fold     bool
+}
+
+func (s staleFilter) Match(f File, now time.Time) bool {
+	if s.empty && f.Info.Size() == 0 {
+		return true
+	}
+	return matchGlob(s.patterns, f.Info.Name(), s.fold) && !f.ownerRunning()
+}
+
+// ownerRunning 文件内容为 PID 且该进程仍在运行，只检查本地文件系统上的小文件
+func (f File) ownerRunning() bool {
+	if f.FS == nil || f.Info.Size() == 0 || f.Info.Size() > maxPIDFileSize {
+		return false
+	}
+	r, err := f.FS.Open(f.Path)
+	if err != nil {
+		return false
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, maxPIDFileSize))
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return err == nil && pid > 0 && processRunning(pid)
+}
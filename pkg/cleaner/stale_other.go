@@ -0,0 +1,11 @@
+//go:build !windows
+
+package cleaner
+
+import "syscall"
+
+// processRunning 进程是否存在；无权向其发送信号（EPERM）时也说明进程存在
+func processRunning(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
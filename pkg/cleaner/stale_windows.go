@@ -0,0 +1,22 @@
+//go:build windows
+
+package cleaner
+
+import "golang.org/x/sys/windows"
+
+// stillActive GetExitCodeProcess 对仍在运行的进程返回的退出码
+const stillActive = 259
+
+// processRunning 进程是否仍在运行；无权打开时按仍在运行处理
+func processRunning(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
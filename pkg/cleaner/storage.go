@@ -0,0 +1,58 @@
+package cleaner
+
+import (
+	"sync"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// storage 的取值：目录所在卷的类型，决定分批删除的方式
+const (
+	storageAuto = "auto" // 检测卷类型，无法检测（网络共享、虚拟磁盘等）时按 batch_size、batch_pause 分批
+	storageSSD  = "ssd"  // 不分批、不暂停，TRIM 由文件系统异步处理
+	storageHDD  = "hdd"  // 大批量删除，每批结束后同步目录再暂停，避免元数据写入和寻道挤占业务 IO
+)
+
+const (
+	defaultHDDBatchSize  = 2000
+	defaultHDDBatchPause = 500 * time.Millisecond
+)
+
+// storageKinds 已检测过的卷类型，键为 volumeID 返回的卷标识；卷类型在服务运行期间不会变化
+var storageKinds sync.Map
+
+func validateStorage(s string) error {
+	switch s {
+	case "", storageAuto, storageSSD, storageHDD:
+		return nil
+	}
+	return i18n.Errorf("storage %q 无效，可选 auto、ssd、hdd", s)
+}
+
+// storageKind 返回目录所在卷的类型 ssd 或 hdd，目录配置优先于全局配置；
+// 未配置 storage 或 auto 时无法检测返回 ""，按 batch_size、batch_pause 分批
+func (cl *Cleaner) storageKind(d DirConfig, path string) string {
+	s := d.Storage
+	if s == "" {
+		s = cl.config.Storage
+	}
+	if s != storageAuto {
+		return s
+	}
+	id, ok := volumeID(path)
+	if !ok {
+		return ""
+	}
+	if kind, ok := storageKinds.Load(id); ok {
+		return kind.(string)
+	}
+	kind := volumeKind(id)
+	storageKinds.Store(id, kind)
+	if kind == "" {
+		cl.debugf("无法检测 %s 所在卷的类型，按 batch_size 分批删除", path)
+	} else {
+		cl.debugf("%s 所在的卷为 %s", path, kind)
+	}
+	return kind
+}
@@ -0,0 +1,43 @@
+package cleaner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// volumeID 返回文件所在块设备的主次设备号，如 8:1；NFS、tmpfs 等没有块设备的文件系统返回 false
+func volumeID(path string) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || unix.Major(uint64(st.Dev)) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%d:%d", unix.Major(uint64(st.Dev)), unix.Minor(uint64(st.Dev))), true
+}
+
+// volumeKind 读取 /sys/dev/block 中设备的 queue/rotational，分区的队列属性在所属磁盘上
+func volumeKind(id string) string {
+	dev, err := filepath.EvalSymlinks(filepath.Join("/sys/dev/block", id))
+	if err != nil {
+		return ""
+	}
+	for _, dir := range []string{dev, filepath.Dir(dev)} {
+		data, err := os.ReadFile(filepath.Join(dir, "queue", "rotational"))
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(string(data)) == "1" {
+			return storageHDD
+		}
+		return storageSSD
+	}
+	return ""
+}
@@ -0,0 +1,12 @@
+//go:build !windows && !linux
+
+package cleaner
+
+// volumeID 其他系统上不检测卷类型
+func volumeID(path string) (string, bool) {
+	return "", false
+}
+
+func volumeKind(id string) string {
+	return ""
+}
@@ -0,0 +1,69 @@
+package cleaner
+
+import (
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	ioctlStorageQueryProperty = 0x2D1400
+	storageDeviceSeekPenalty  = 7 // StorageDeviceSeekPenaltyProperty
+	propertyStandardQuery     = 0
+)
+
+type storagePropertyQuery struct {
+	PropertyID           uint32
+	QueryType            uint32
+	AdditionalParameters [1]byte
+}
+
+type seekPenaltyDescriptor struct {
+	Version           uint32
+	Size              uint32
+	IncursSeekPenalty byte
+}
+
+// volumeID 返回路径的盘符（如 C:），UNC 路径和网络驱动器返回 false
+func volumeID(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	vol := filepath.VolumeName(abs)
+	if len(vol) != 2 || vol[1] != ':' {
+		return "", false
+	}
+	root, err := windows.UTF16PtrFromString(vol + `\`)
+	if err != nil || windows.GetDriveType(root) != windows.DRIVE_FIXED {
+		return "", false
+	}
+	return strings.ToUpper(vol), true
+}
+
+// volumeKind 以 IOCTL_STORAGE_QUERY_PROPERTY 查询卷所在磁盘是否有寻道开销，与“优化驱动器”判断 SSD 的方式相同
+func volumeKind(id string) string {
+	name, err := windows.UTF16PtrFromString(`\\.\` + id)
+	if err != nil {
+		return ""
+	}
+	h, err := windows.CreateFile(name, 0, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(h)
+	query := storagePropertyQuery{PropertyID: storageDeviceSeekPenalty, QueryType: propertyStandardQuery}
+	var desc seekPenaltyDescriptor
+	var n uint32
+	err = windows.DeviceIoControl(h, ioctlStorageQueryProperty, (*byte)(unsafe.Pointer(&query)), uint32(unsafe.Sizeof(query)),
+		(*byte)(unsafe.Pointer(&desc)), uint32(unsafe.Sizeof(desc)), &n, nil)
+	if err != nil || n < uint32(unsafe.Offsetof(desc.IncursSeekPenalty))+1 {
+		return ""
+	}
+	if desc.IncursSeekPenalty != 0 {
+		return storageHDD
+	}
+	return storageSSD
+}
@@ -0,0 +1,98 @@
+package cleaner
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// cleanSubtrees 把每个直接子目录当作一个整体：子目录树中最新的文件也早于保留期限时删除整个子目录，
+// 否则整个保留，用于必须整体保留或删除的任务输出目录
+func (cl *Cleaner) cleanSubtrees(dir DirConfig, now time.Time, stats *Stats) {
+	age, ok := cl.config.deleteAge(dir)
+	if !ok {
+		return
+	}
+	threshold := now.Add(-age)
+	entries, err := cl.readDir(dir, dir.Path)
+	if err != nil {
+		return
+	}
+	ignore := cl.loadIgnore(dir.Path)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir.Path, entry.Name())
+		if entry.Name() == trashDirName {
+			continue // 软删除的文件按 soft_delete_grace 处理
+		}
+		if ignore.match(entry.Name(), true) {
+			cl.debugf("跳过 %s：受 %s 保护", path, ignoreFileName)
+			continue
+		}
+		if n, ok := dir.containsNested(path); ok {
+			cl.debugf("跳过 %s：其中的目录 %s 单独配置，按其自身的策略处理", path, n)
+			continue
+		}
+		newest, size, walked := treeNewest(cl.fs, path, cl.config.walkLimits(dir))
+		if walked.crossed {
+			cl.warnf("跳过 %s：其中有挂载点或联接点（cross_devices 未开启）", path)
+			continue
+		}
+		if walked.limited {
+			cl.warnf("跳过 %s：超过 %d 层（max_depth），无法确认其中的文件都已到期", path, cl.config.maxDepth(dir))
+			continue
+		}
+		if !newest.Before(threshold) {
+			cl.debugf("保留 %s：最新文件修改于 %s", path, newest.Format(time.DateTime))
+			continue
+		}
+		if !cl.allow(stats, path, size) {
+			return
+		}
+		if err := cl.fs.RemoveAll(path); err != nil {
+			cl.errorf("删除子目录 %s 失败: %s", path, err)
+			stats.fail(err)
+			stats.account(path)
+			continue
+		}
+		cl.matchf(stats, "删除子目录 %s（最新文件修改于 %s，释放 %s）", path, newest.Format(time.DateTime), ByteSize(size))
+		stats.FreedBytes += size
+		stats.DeletedDirs++
+	}
+}
+
+// treeNewest 返回目录树中最新的修改时间和文件总大小，以及因限制跳过的内容。
+// 没有文件时使用目录本身的修改时间；读取出错时返回当前时间，避免因部分内容无法读取而误删
+func treeNewest(fsys afero.Fs, root string, lim walkLimits) (time.Time, int64, walkResult) {
+	var newest, rootTime time.Time
+	var size int64
+	files, failed := 0, false
+	walked := walkTree(fsys, root, lim, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			failed = true
+			return nil
+		}
+		if info.IsDir() {
+			if path == root {
+				rootTime = info.ModTime()
+			}
+			return nil
+		}
+		if files++; info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		size += info.Size()
+		return nil
+	})
+	switch {
+	case failed:
+		return time.Now(), size, walked
+	case files == 0:
+		return rootTime, 0, walked
+	}
+	return newest, size, walked
+}
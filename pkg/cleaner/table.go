@@ -0,0 +1,171 @@
+package cleaner
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+const defaultTableBatch = 1000
+
+// TableConfig 数据库日志表的保留策略：按时间列分批删除早于保留期的行，与目录在同一次任务中执行。
+// 通过 database/sql 连接，程序中编译了 MySQL（github.com/go-sql-driver/mysql）和 PostgreSQL（github.com/lib/pq）驱动
+type TableConfig struct {
+	Driver     string        `yaml:"driver" mapstructure:"driver"`           // mysql（默认）或 postgres
+	DSN        string        `yaml:"dsn" mapstructure:"dsn"`                 // 连接串，可使用 ${ENV} 引用环境变量中的密码
+	Table      string        `yaml:"table" mapstructure:"table"`             // 表名，可带库名，如 app.audit_log
+	Column     string        `yaml:"column" mapstructure:"column"`           // 时间列
+	ColumnType string        `yaml:"column_type" mapstructure:"column_type"` // datetime（默认）、unix（秒）或 unix_ms（毫秒）
+	Where      string        `yaml:"where" mapstructure:"where"`             // 附加的条件，如 level <> 'audit'
+	Days       int           `yaml:"days" mapstructure:"days"`               // 保留天数，默认使用全局 days
+	MaxAge     time.Duration `yaml:"max_age" mapstructure:"max_age"`         // 保留时长，设置后优先于 days
+	BatchSize  int           `yaml:"batch_size" mapstructure:"batch_size"`   // 每批删除的行数，默认 1000
+	BatchPause time.Duration `yaml:"batch_pause" mapstructure:"batch_pause"` // 每批之间暂停的时间，减少对主库和复制的压力
+}
+
+// TableReport 单个数据库表的统计
+type TableReport struct {
+	Table   string `json:"table"`
+	Deleted int64  `json:"deleted_rows"`
+	Error   string `json:"error,omitempty"`
+}
+
+// identPattern 表名、列名只允许字母、数字、下划线，可用 . 分隔库名
+var identPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+func (t *TableConfig) validate() error {
+	if t.Driver == "" {
+		t.Driver = "mysql"
+	}
+	switch t.Driver {
+	case "mysql", "postgres":
+	default:
+		return i18n.Errorf("不支持的数据库驱动 %q", t.Driver)
+	}
+	registered := sql.Drivers()
+	if i := sort.SearchStrings(registered, t.Driver); i == len(registered) || registered[i] != t.Driver {
+		return i18n.Errorf("数据库驱动 %q 未编译进程序", t.Driver)
+	}
+	if t.DSN == "" {
+		return i18n.Errorf("缺少 dsn 配置")
+	}
+	if !identPattern.MatchString(t.Table) || !identPattern.MatchString(t.Column) {
+		return i18n.Errorf("表名或列名 %q.%q 无效", t.Table, t.Column)
+	}
+	switch t.ColumnType {
+	case "", "datetime", "unix", "unix_ms":
+	default:
+		return i18n.Errorf("column_type %q 无效，可选 datetime、unix、unix_ms", t.ColumnType)
+	}
+	return nil
+}
+
+// quote 按数据库的语法引用表名、列名
+func (t TableConfig) quote(ident string) string {
+	parts := strings.Split(ident, ".")
+	for i, p := range parts {
+		switch t.Driver {
+		case "mysql":
+			parts[i] = "`" + p + "`"
+		default:
+			parts[i] = `"` + p + `"`
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// deleteQuery 返回删除一批到期行的语句：MySQL 用 DELETE ... LIMIT，
+// PostgreSQL 不支持带 LIMIT 的 DELETE，按 ctid 子查询删除
+func (t TableConfig) deleteQuery(batch int) string {
+	table, column := t.quote(t.Table), t.quote(t.Column)
+	cond := column + " < ?"
+	if t.Driver == "postgres" {
+		cond = column + " < $1"
+	}
+	if t.Where != "" {
+		cond += " AND (" + t.Where + ")"
+	}
+	if t.Driver == "postgres" {
+		return fmt.Sprintf("DELETE FROM %s WHERE ctid IN (SELECT ctid FROM %s WHERE %s LIMIT %d)", table, table, cond, batch)
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %s LIMIT %d", table, cond, batch)
+}
+
+// cutoff 时间列早于该值的行到期，按列的类型返回时间或时间戳
+func (t TableConfig) cutoff(before time.Time) interface{} {
+	switch t.ColumnType {
+	case "unix":
+		return before.Unix()
+	case "unix_ms":
+		return before.UnixMilli()
+	}
+	return before
+}
+
+// tableRetention 表的保留时长：表 max_age > 表 days > 全局 max_age > 全局 days
+func (c Config) tableRetention(t TableConfig) time.Duration {
+	return c.retention(DirConfig{MaxAge: t.MaxAge, Days: t.Days})
+}
+
+// cleanTables 依次清理配置的数据库表，单个表失败计入 Failed，不影响其他表
+func (cl *Cleaner) cleanTables(ctx context.Context, now time.Time, stats *Report) {
+	for _, t := range cl.config.Tables {
+		if ctx.Err() != nil {
+			return
+		}
+		tr := TableReport{Table: t.Table}
+		n, err := cl.cleanTable(ctx, t, now.Add(-cl.config.tableRetention(t)))
+		tr.Deleted = n
+		if err != nil {
+			tr.Error = err.Error()
+			stats.fail(err)
+			cl.errorf("清理数据库表 %s 失败（已删除 %d 行）: %s", t.Table, n, err)
+		} else {
+			cl.logf("数据库表 %s: 删除 %d 行", t.Table, n)
+		}
+		stats.Tables = append(stats.Tables, tr)
+	}
+}
+
+// cleanTable 分批删除 before 之前的行，直到一批不足 batch_size，返回删除的行数
+func (cl *Cleaner) cleanTable(ctx context.Context, t TableConfig, before time.Time) (int64, error) {
+	db, err := sql.Open(t.Driver, ExpandPath(t.DSN))
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	batch := t.BatchSize
+	if batch <= 0 {
+		batch = defaultTableBatch
+	}
+	query, cutoff := t.deleteQuery(batch), t.cutoff(before)
+	cl.debugf("数据库表 %s: 删除 %s 早于 %s 的行", t.Table, t.Column, before.Format(time.RFC3339))
+	var total int64
+	for {
+		res, err := db.ExecContext(ctx, query, cutoff)
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < int64(batch) {
+			return total, nil
+		}
+		if t.BatchPause > 0 {
+			select {
+			case <-ctx.Done():
+				return total, ctx.Err()
+			case <-time.After(t.BatchPause):
+			}
+		}
+	}
+}
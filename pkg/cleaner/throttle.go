@@ -0,0 +1,108 @@
+package cleaner
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RemoteLimits 远程目标（sftp、s3、oss）的带宽、请求速率和并发限制，避免清理和上传耗尽云存储的 API 配额或占满出口带宽。
+// 限制按目录项（tier 的 upload 按 tier）分别计算
+type RemoteLimits struct {
+	Bandwidth   ByteSize `yaml:"bandwidth" mapstructure:"bandwidth"`       // 上传的速度上限（每秒），如 10MB，默认不限
+	RequestRate float64  `yaml:"request_rate" mapstructure:"request_rate"` // 每秒最多发出的请求数（sftp 为启动的会话数），默认不限
+	Concurrency int      `yaml:"concurrency" mapstructure:"concurrency"`   // 同时进行的请求数，默认 1；sftp 为每次传输的 -R，默认不设置
+}
+
+// pacer 按固定速率发放额度：take 预约 n 个单位并等待到轮到该预约为止，空闲期间不积累额度。
+// 多个并发的调用方共享同一速率，为空时不限制
+type pacer struct {
+	mu   sync.Mutex
+	per  float64 // 每个单位所需的纳秒数
+	next time.Time
+}
+
+func newPacer(perSecond float64) *pacer {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &pacer{per: float64(time.Second) / perSecond}
+}
+
+func (p *pacer) take(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	at := p.next
+	p.next = p.next.Add(time.Duration(float64(n) * p.per))
+	p.mu.Unlock()
+	time.Sleep(time.Until(at))
+}
+
+// pacedReader 按 pacer 的速率读取，用于限制上传的带宽
+type pacedReader struct {
+	io.ReadCloser
+	pacer *pacer
+}
+
+func (r pacedReader) Read(b []byte) (int, error) {
+	if len(b) > 32*1024 {
+		b = b[:32*1024]
+	}
+	n, err := r.ReadCloser.Read(b)
+	r.pacer.take(n)
+	return n, err
+}
+
+// throttle 对象存储客户端的请求限制
+type throttle struct {
+	requests  *pacer
+	bandwidth *pacer
+	slots     chan struct{}
+}
+
+func newThrottle(l RemoteLimits) *throttle {
+	n := l.Concurrency
+	if n <= 0 {
+		n = 1
+	}
+	return &throttle{
+		requests:  newPacer(l.RequestRate),
+		bandwidth: newPacer(float64(l.Bandwidth)),
+		slots:     make(chan struct{}, n),
+	}
+}
+
+// do 在并发和速率限制内发送请求，请求体按带宽限制读取
+func (t *throttle) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	t.slots <- struct{}{}
+	defer func() { <-t.slots }()
+	t.requests.take(1)
+	if req.Body != nil && req.Body != http.NoBody && t.bandwidth != nil {
+		req.Body = pacedReader{ReadCloser: req.Body, pacer: t.bandwidth}
+	}
+	return client.Do(req)
+}
+
+// inBatches 把 keys 按每批 size 个分组，各批在单独的 goroutine 中处理，同时进行的请求数由 throttle 限制
+func inBatches(keys []string, size int, fn func(batch []string)) {
+	var wg sync.WaitGroup
+	for start := 0; start < len(keys); start += size {
+		end := start + size
+		if end > len(keys) {
+			end = len(keys)
+		}
+		wg.Add(1)
+		go func(batch []string) {
+			defer wg.Done()
+			fn(batch)
+		}(keys[start:end])
+	}
+	wg.Wait()
+}
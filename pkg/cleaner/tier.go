@@ -0,0 +1,186 @@
+package cleaner
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/spf13/afero"
+)
+
+const (
+	actionCompress = "compress"
+	actionArchive  = "archive"
+	actionDelete   = "delete"
+	actionTruncate = "truncate"
+)
+
+// Tier 一级保留策略：文件超过 days/max_age 后执行 action
+type Tier struct {
+	Days        int           `yaml:"days" mapstructure:"days"`
+	MaxAge      time.Duration `yaml:"max_age" mapstructure:"max_age"`
+	Action      string        `yaml:"action" mapstructure:"action"`             // compress|archive|delete|truncate
+	ArchiveDir  string        `yaml:"archive_dir" mapstructure:"archive_dir"`   // action 为 archive 时的目标目录
+	KeepSize    ByteSize      `yaml:"keep_size" mapstructure:"keep_size"`       // action 为 truncate 时保留的末尾大小，默认清空
+	Verify      bool          `yaml:"verify" mapstructure:"verify"`             // action 为 archive 时校验归档副本的 SHA-256 后再删除原文件，并写入归档目录的 SHA256SUMS
+	DeleteMode  string        `yaml:"delete_mode" mapstructure:"delete_mode"`   // action 为 delete 时：unlink（默认）直接删除，shred 先用随机数据覆写再删除，rename、trash 软删除
+	ShredPasses int           `yaml:"shred_passes" mapstructure:"shred_passes"` // shred 的覆写次数，默认 3
+
+	// Upload action 为 archive 时先上传到 s3://、oss:// 或 sftp:// 地址，确认成功后才归档或删除本地文件（未配置 archive_dir 时删除）
+	Upload        string       `yaml:"upload" mapstructure:"upload"`
+	UploadBacklog string       `yaml:"upload_backlog" mapstructure:"upload_backlog"` // 重试后仍上传失败的文件移入该目录，之后的任务优先重新上传
+	S3            *S3Config    `yaml:"s3" mapstructure:"s3"`
+	OSS           *OSSConfig   `yaml:"oss" mapstructure:"oss"`
+	SFTP          *SFTPConfig  `yaml:"sftp" mapstructure:"sftp"`
+	Filters       []FilterSpec `yaml:"filters" mapstructure:"filters"`   // 仅对该 tier 生效的额外过滤器
+	MinSize       ByteSize     `yaml:"min_size" mapstructure:"min_size"` // 该 tier 只处理不小于该大小的文件，如超过 2GB 的转储 1 天后即删除
+	MaxSize       ByteSize     `yaml:"max_size" mapstructure:"max_size"`
+
+	// Params 自定义动作的其余参数
+	Params map[string]interface{} `yaml:",inline" mapstructure:",remain"`
+
+	stale string // 由 stale_files 生成的规则处理的文件，用于策略说明
+}
+
+// filtered 该 tier 是否只处理部分到期的文件
+func (t Tier) filtered() bool {
+	return len(t.Filters) > 0 || t.MinSize > 0 || t.MaxSize > 0
+}
+
+func (t Tier) age() time.Duration {
+	if t.MaxAge > 0 {
+		return t.MaxAge
+	}
+	return time.Duration(t.Days) * 24 * time.Hour
+}
+
+func (t Tier) String() string {
+	if t.stale != "" {
+		return i18n.Sprintf("%s 后删除（stale_files: %s）", t.age(), t.stale)
+	}
+	if t.MinSize > 0 || t.MaxSize > 0 {
+		s := t
+		s.MinSize, s.MaxSize = 0, 0
+		return i18n.Sprintf("%s（%s）", s, sizeFilter{min: t.MinSize, max: t.MaxSize})
+	}
+	if t.Action == actionArchive && t.Upload != "" {
+		target := t.Upload
+		if u, err := url.Parse(t.Upload); err == nil {
+			target = u.Redacted()
+		}
+		return i18n.Sprintf("%s 后上传到 %s", t.age(), target)
+	}
+	if t.Action == actionArchive {
+		return i18n.Sprintf("%s 后归档到 %s", t.age(), t.ArchiveDir)
+	}
+	if t.Action == actionTruncate && t.KeepSize > 0 {
+		return i18n.Sprintf("%s 后截断到 %s", t.age(), t.KeepSize)
+	}
+	if t.Action == actionDelete && t.DeleteMode == deleteModeShred {
+		return i18n.Sprintf("%s 后覆写 %d 次并删除", t.age(), t.ShredPasses)
+	}
+	if t.Action == actionDelete && (t.DeleteMode == deleteModeRename || t.DeleteMode == deleteModeTrash) {
+		return i18n.Sprintf("%s 后软删除（%s）", t.age(), t.DeleteMode)
+	}
+	if name, ok := map[string]string{actionCompress: "压缩", actionDelete: "删除", actionTruncate: "清空"}[t.Action]; ok {
+		return i18n.Sprintf("%s 后%s", t.age(), i18n.T(name))
+	}
+	return i18n.Sprintf("%s 后执行 %s", t.age(), t.Action)
+}
+
+// Tiers 返回目录生效的保留策略（按年龄从小到大）。
+// 目录未配置 tiers 时由 compress_after_days、days/max_age 和 action 组合得到
+func (c Config) Tiers(d DirConfig) []Tier {
+	if len(d.Tiers) > 0 {
+		return d.Tiers
+	}
+	var tiers []Tier
+	compressDays := d.CompressAfterDays
+	if compressDays == 0 {
+		compressDays = c.CompressAfterDays
+	}
+	retention := c.retention(d)
+	if compressDays > 0 && time.Duration(compressDays)*24*time.Hour < retention {
+		tiers = append(tiers, Tier{Days: compressDays, Action: actionCompress})
+	}
+	if d.Action == actionTruncate {
+		return append(tiers, Tier{MaxAge: retention, Action: actionTruncate, KeepSize: d.TruncateKeep})
+	}
+	return append(tiers, Tier{MaxAge: retention, Action: actionDelete})
+}
+
+// deleteAge 返回目录删除阶段的年龄，tiers 中没有 delete 时返回 false
+func (c Config) deleteAge(d DirConfig) (time.Duration, bool) {
+	for _, t := range c.Tiers(d) {
+		if t.Action == actionDelete {
+			return t.age(), true
+		}
+	}
+	return 0, false
+}
+
+// validateAction 校验目录的 action 简写配置，tiers 中的动作在构建策略时校验
+func validateAction(d *DirConfig) error {
+	switch d.Action {
+	case "", actionDelete, actionTruncate:
+		return nil
+	}
+	return i18n.Errorf("目录 %s 的 action %q 无效", d.Path, d.Action)
+}
+
+// archiveFile 将文件移动到归档目录，跨卷时复制后删除，保留修改时间
+func archiveFile(fsys afero.Fs, path, archiveDir string) (string, error) {
+	dst, err := archiveTarget(fsys, path, archiveDir)
+	if err != nil {
+		return "", err
+	}
+	if err := fsys.Rename(path, dst); err == nil {
+		return dst, nil
+	}
+	if err := copyFile(fsys, path, dst); err != nil {
+		fsys.Remove(dst)
+		return "", err
+	}
+	return dst, fsys.Remove(path)
+}
+
+// archiveTarget 创建归档目录并返回归档后的路径
+func archiveTarget(fsys afero.Fs, path, archiveDir string) (string, error) {
+	if err := fsys.MkdirAll(archiveDir, 0755); err != nil {
+		return "", err
+	}
+	dst := filepath.Join(archiveDir, filepath.Base(path))
+	if _, err := fsys.Stat(dst); err == nil {
+		// 同名文件已存在时追加时间戳避免覆盖
+		dst = dst + "." + strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+	return dst, nil
+}
+
+func copyFile(fsys afero.Fs, src, dst string) error {
+	in, err := fsys.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := fsys.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return fsys.Chtimes(dst, info.ModTime(), info.ModTime())
+}
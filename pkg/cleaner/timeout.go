@@ -0,0 +1,105 @@
+package cleaner
+
+import (
+	"context"
+	"time"
+)
+
+// abandonGrace 目录超时或任务取消后等待其正常结束的时间，仍未结束（多为卡在无响应的网络共享或挂载上的系统调用）时放弃等待
+const abandonGrace = 5 * time.Second
+
+// networked 目录是否为远程目标或网络共享
+func networked(d DirConfig) bool {
+	return isRemote(d.Path) || isUNC(d.Path) || d.Share != nil
+}
+
+// dirTimeout 返回目录的最长处理时间：目录的 timeout 优先，远程目标和网络共享默认使用 remote_timeout，0 不限制
+func (c Config) dirTimeout(d DirConfig) time.Duration {
+	if d.Timeout > 0 {
+		return d.Timeout
+	}
+	if networked(d) {
+		return c.RemoteTimeout
+	}
+	return 0
+}
+
+// dirResult 在单独的 goroutine 中处理目录的结果
+type dirResult struct {
+	report  DirReport
+	panicky interface{}
+}
+
+// runDirTimed 按 timeout、remote_timeout 处理目录：超时后取消该目录，在 abandonGrace 内结束时返回已完成部分的统计，
+// 否则放弃等待，目录标记为 timed_out 并计为一次网络失败，其他目录照常处理。远程目标和网络共享在任务取消
+// （max_run_duration、服务停止）时同样最多等待 abandonGrace，卡住的网络请求不会拖住整个任务。
+// 放弃等待的目录在 goroutine 中结束后的结果不再计入，之后也不再处理文件（max_bytes_per_run 的状态单独复制一份）
+func (cl *Cleaner) runDirTimed(ctx context.Context, dir DirConfig, now time.Time, ds Stats) DirReport {
+	timeout := cl.config.dirTimeout(dir)
+	if timeout <= 0 && !networked(dir) {
+		return cl.runDir(ctx, dir, now, ds)
+	}
+	start := time.Now()
+	shared := ds.cap
+	ds.cap = shared.fork()
+	own := ds.cap
+	dctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan dirResult, 1)
+	go func() {
+		var res dirResult
+		defer func() {
+			if r := recover(); r != nil {
+				res.panicky = r
+			}
+			done <- res
+		}()
+		res.report = cl.runDir(dctx, dir, now, ds)
+	}()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	timedOut := false
+	select {
+	case res := <-done:
+		shared.merge(own)
+		return finishDir(res)
+	case <-expired:
+		timedOut = true
+		cl.errorf("目录 %s 处理超过 %s，停止处理该目录", dir.Path, timeout)
+	case <-ctx.Done():
+	}
+	cancel()
+	grace := time.NewTimer(abandonGrace)
+	defer grace.Stop()
+	select {
+	case res := <-done:
+		shared.merge(own)
+		dr := finishDir(res)
+		if timedOut {
+			dr.TimedOut = true
+			dr.Failed++
+			dr.classify(ErrorNetwork)
+		}
+		return dr
+	case <-grace.C:
+	}
+	own.abandon()
+	cl.errorf("目录 %s 在 %s 内仍未结束，可能是网络共享或挂载无响应，放弃等待、继续处理其他目录", dir.Path, abandonGrace)
+	dr := DirReport{Path: dir.Path, Owner: dir.Owner, Start: start, Duration: time.Since(start).Seconds(), TimedOut: true}
+	dr.Failed++
+	dr.classify(ErrorNetwork)
+	dr.account(dir.Path)
+	return dr
+}
+
+// finishDir 在调用方的 goroutine 中重新抛出目录处理中的 panic
+func finishDir(res dirResult) DirReport {
+	if res.panicky != nil {
+		panic(res.panicky)
+	}
+	return res.report
+}
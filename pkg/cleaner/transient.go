@@ -0,0 +1,30 @@
+package cleaner
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultTransientRetries = 3
+	defaultTransientDelay   = time.Second
+)
+
+// isTransient 判断是否为网络文件系统（NFS、SMB）的临时错误，如句柄失效、连接被重置或共享冲突
+func isTransient(err error) bool {
+	var errno syscall.Errno
+	return errors.As(err, &errno) && transientErrno(errno)
+}
+
+// transientRetries 返回临时错误的重试次数和首次重试前的等待时间，之后每次加倍；负数表示不重试
+func (c Config) transientRetries() (int, time.Duration) {
+	retries, delay := c.TransientRetries, c.TransientDelay
+	if retries == 0 {
+		retries = defaultTransientRetries
+	}
+	if delay <= 0 {
+		delay = defaultTransientDelay
+	}
+	return retries, delay
+}
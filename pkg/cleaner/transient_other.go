@@ -0,0 +1,22 @@
+//go:build !windows
+
+package cleaner
+
+import "syscall"
+
+func transientErrno(errno syscall.Errno) bool {
+	switch errno {
+	case syscall.ESTALE, syscall.ECONNRESET, syscall.ECONNABORTED, syscall.ETIMEDOUT,
+		syscall.EHOSTDOWN, syscall.EHOSTUNREACH, syscall.ENETRESET, syscall.ENETUNREACH, syscall.EAGAIN:
+		return true
+	}
+	return false
+}
+
+func inUseErrno(errno syscall.Errno) bool {
+	return errno == syscall.EBUSY || errno == syscall.ETXTBSY
+}
+
+func pathTooLongErrno(errno syscall.Errno) bool {
+	return false
+}
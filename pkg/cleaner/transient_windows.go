@@ -0,0 +1,30 @@
+package cleaner
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+func transientErrno(errno syscall.Errno) bool {
+	switch errno {
+	case windows.ERROR_SHARING_VIOLATION, windows.ERROR_LOCK_VIOLATION, windows.ERROR_NETNAME_DELETED,
+		windows.ERROR_UNEXP_NET_ERR, windows.ERROR_NETWORK_BUSY, windows.ERROR_SEM_TIMEOUT,
+		windows.ERROR_VC_DISCONNECTED, windows.ERROR_CONNECTION_ABORTED, windows.ERROR_NETWORK_UNREACHABLE,
+		windows.ERROR_BAD_NETPATH:
+		return true
+	}
+	return false
+}
+
+func inUseErrno(errno syscall.Errno) bool {
+	switch errno {
+	case windows.ERROR_SHARING_VIOLATION, windows.ERROR_LOCK_VIOLATION, windows.ERROR_USER_MAPPED_FILE:
+		return true
+	}
+	return false
+}
+
+func pathTooLongErrno(errno syscall.Errno) bool {
+	return errno == windows.ERROR_FILENAME_EXCED_RANGE
+}
@@ -0,0 +1,48 @@
+package cleaner
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+// truncateFile 将文件截断到只保留末尾 keep 字节（从完整的一行开始），keep 为 0 时清空文件。
+// 文件不删除，正在写入该文件的进程不会出现"已删除但仍占用磁盘"的问题；
+// 写入方需以追加模式打开文件，否则截断后会在原偏移处继续写入
+func truncateFile(fsys afero.Fs, path string, size, keep int64) error {
+	if keep <= 0 {
+		f, err := fsys.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		return f.Close()
+	}
+	if size <= keep {
+		return nil
+	}
+	f, err := fsys.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	tail := make([]byte, keep)
+	if _, err := f.ReadAt(tail, size-keep); err != nil && err != io.EOF {
+		return err
+	}
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 && i+1 < len(tail) {
+		tail = tail[i+1:]
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(tail, 0); err != nil {
+		return err
+	}
+	return f.Close()
+}
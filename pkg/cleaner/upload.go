@@ -0,0 +1,224 @@
+package cleaner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/spf13/afero"
+)
+
+const (
+	uploadAttempts   = 3
+	uploadRetryDelay = 2 * time.Second
+)
+
+// uploader 支持上传的远程目标
+type uploader interface {
+	// upload 把本地文件上传为目标目录（前缀）下的 name，并确认远程文件大小与本地一致
+	upload(fsys afero.Fs, path, name string, size int64) error
+}
+
+// openUploader 根据 tier 的 upload 地址创建上传目标，连接配置取 tier 中的 s3/oss/sftp
+func openUploader(t Tier) (uploader, error) {
+	u, err := url.Parse(t.Upload)
+	if err != nil {
+		return nil, i18n.Errorf("远程地址 %s 无效: %w", t.Upload, err)
+	}
+	switch u.Scheme {
+	case "s3":
+		return newS3Target(u, t.S3)
+	case "oss":
+		return newOSSTarget(u, t.OSS)
+	case "sftp":
+		return newSFTPTarget(u, t.SFTP)
+	}
+	return nil, i18n.Errorf("不支持的上传地址类型: %s", u.Scheme)
+}
+
+// uploadFile 上传文件，失败时间隔递增地重试
+func (a archiveAction) uploadFile(f *File) error {
+	var err error
+	for i := 0; i < uploadAttempts; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * uploadRetryDelay)
+		}
+		if err = a.upload.upload(f.FS, f.Path, filepath.Base(f.Path), f.Info.Size()); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// toBacklog 上传失败时把文件移入待上传目录，之后的任务优先重新上传
+func (a archiveAction) toBacklog(f *File, err error) error {
+	if a.backlog == "" {
+		return i18n.Errorf("上传 %s 失败: %w", f.Path, err)
+	}
+	if _, moveErr := archiveFile(f.FS, f.Path, a.backlog); moveErr != nil {
+		return i18n.Errorf("上传 %s 失败: %w", f.Path, err)
+	}
+	return i18n.Errorf("上传 %s 失败，已移入待上传目录 %s: %w", f.Path, a.backlog, err)
+}
+
+// drainUploadBacklog 重新上传待上传目录中的文件，成功后按 archive_dir 归档或删除
+func (cl *Cleaner) drainUploadBacklog(ctx context.Context, dir DirConfig, stats *Stats) {
+	for _, r := range dir.policy.rules {
+		a, ok := r.action.(archiveAction)
+		if !ok || a.upload == nil || a.backlog == "" {
+			continue
+		}
+		entries, err := readDirEntries(cl.fs, a.backlog)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				cl.errorf("读取目录 %s 失败: %s", a.backlog, err)
+				stats.fail(err)
+			}
+			continue
+		}
+		for _, e := range entries {
+			if ctx.Err() != nil {
+				return
+			}
+			if e.IsDir() {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				cl.errorf("获取文件信息失败: %s", err)
+				stats.fail(err)
+				continue
+			}
+			f := File{FS: cl.fs, Path: filepath.Join(a.backlog, e.Name()), Info: info, Time: info.ModTime()}
+			if err := a.uploadFile(&f); err != nil {
+				cl.errorf("上传 %s 失败: %s", f.Path, err)
+				stats.fail(err)
+				continue
+			}
+			res, err := a.store(&f)
+			if err != nil {
+				cl.errorf("%s 文件失败: %s", actionArchive, err)
+				stats.fail(err)
+				continue
+			}
+			cl.matchf(stats, "%s %s（释放 %s）", actionArchive, f.Path, ByteSize(res.Freed))
+			stats.record(actionArchive, res)
+		}
+	}
+}
+
+func (t *s3Target) upload(fsys afero.Fs, p, name string, size int64) error {
+	hash, err := fileSHA256(fsys, p)
+	if err != nil {
+		return err
+	}
+	f, err := fsys.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	key := t.prefix + name
+	req, err := http.NewRequest(http.MethodPut, t.client.objectURL(key, nil).String(), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	t.client.sign(req, hash, time.Now().UTC())
+	if err := checkResponse(t.client.throttle.do(t.client.http, req)); err != nil {
+		return err
+	}
+
+	req, err = http.NewRequest(http.MethodHead, t.client.objectURL(key, nil).String(), nil)
+	if err != nil {
+		return err
+	}
+	t.client.sign(req, sha256Hex(nil), time.Now().UTC())
+	return confirmSize(t.client.throttle.do(t.client.http, req))(size)
+}
+
+func (t *ossTarget) upload(fsys afero.Fs, p, name string, size int64) error {
+	f, err := fsys.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	key := t.prefix + name
+	resource := "/" + t.client.bucket + "/" + key
+	req, err := http.NewRequest(http.MethodPut, t.client.objectURL(key), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	t.client.authorize(req, resource)
+	if err := checkResponse(t.client.throttle.do(t.client.http, req)); err != nil {
+		return err
+	}
+
+	req, err = http.NewRequest(http.MethodHead, t.client.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	t.client.authorize(req, resource)
+	return confirmSize(t.client.throttle.do(t.client.http, req))(size)
+}
+
+// upload 调用 sftp put 上传，只支持操作系统文件系统中的文件
+func (t *sftpTarget) upload(fsys afero.Fs, p, name string, size int64) error {
+	if !isOsFs(fsys) {
+		return errors.New(i18n.T("sftp 上传只支持本地文件"))
+	}
+	remote := path.Join(t.dir, name)
+	out, err := t.run("put " + sftpQuote(p) + " " + sftpQuote(remote) + "\nls -ln " + sftpQuote(remote) + "\n")
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "-") {
+			continue
+		}
+		f, err := parseSFTPLine(line, time.Now())
+		if err != nil {
+			return err
+		}
+		if f.Size() != size {
+			return i18n.Errorf("上传后远程文件大小 %d 与本地 %d 不一致", f.Size(), size)
+		}
+		return nil
+	}
+	return i18n.Errorf("上传后未找到远程文件 %s", remote)
+}
+
+// checkResponse 非 2xx 响应返回错误
+func checkResponse(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s: %s %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// confirmSize 检查 HEAD 响应中的对象大小
+func confirmSize(resp *http.Response, err error) func(int64) error {
+	return func(size int64) error {
+		if err := checkResponse(resp, err); err != nil {
+			return err
+		}
+		if resp.ContentLength != size {
+			return i18n.Errorf("上传后远程文件大小 %d 与本地 %d 不一致", resp.ContentLength, size)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+package cleaner
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+)
+
+// maxDiscrepancies 每个目录在任务结果中最多列出的校验失败文件数，日志中最多记录的条数相同
+const maxDiscrepancies = 100
+
+// Discrepancy 任务结束后仍然存在的到期文件或子目录：按保留策略本应删除或移走，本次任务却既没有记录跳过原因
+// 也没有报告失败，通常是权限、路径过长等被静默忽略的问题
+type Discrepancy struct {
+	Path    string    `json:"path"`
+	Dir     bool      `json:"dir,omitempty"`
+	ModTime time.Time `json:"mod_time"`
+	Policy  string    `json:"policy"`
+}
+
+// verifyRun 以任务开始时的时间重新扫描各本地目录（不含处于保留状态、canary 模式和因失败过多或超时已停止处理的目录），
+// 找出满足删除或归档规则但仍然存在的文件，以及 date_dirs、subtrees 仍未删除的子目录。
+// 跳过时说明了原因（使用中、静默期、target_size 等）或报告了失败的文件不计入；压缩、截断等保留文件的动作不校验
+func (cl *Cleaner) verifyRun(ctx context.Context, dirs []DirConfig, now time.Time, reports []DirReport, carried map[string]bool) {
+	if len(reports) != len(dirs) {
+		return
+	}
+	cl.dates.reset()
+	for i, dir := range dirs {
+		if ctx.Err() != nil {
+			return
+		}
+		r := &reports[i]
+		if r.OnHold || r.Canary || r.Aborted != "" || r.TimedOut || isRemote(dir.Path) || dir.Docker != nil {
+			continue
+		}
+		accounted := func(path string) bool {
+			return r.accounted[path] || r.accounted[filepath.Dir(path)] || carried[path]
+		}
+		add := func(d Discrepancy) {
+			r.Unverified++
+			if len(r.Discrepancies) < maxDiscrepancies {
+				r.Discrepancies = append(r.Discrepancies, d)
+				cl.errorf("校验失败: %s 按保留策略（%s）已到期，任务结束后仍然存在，且未记录跳过或失败的原因", d.Path, d.Policy)
+			}
+		}
+		var stats Stats
+		err := cl.expired(ctx, dir, now, &stats, func(f File, rl rule) {
+			if removesFile(rl.action) && !accounted(f.Path) {
+				add(Discrepancy{Path: f.Path, ModTime: f.Info.ModTime(), Policy: rl.tier.String()})
+			}
+		})
+		if err != nil {
+			continue
+		}
+		if dir.DateDirs || dir.Subtrees {
+			var res ScanDir
+			cl.scanSubdirs(dir, now, &res)
+			for _, f := range res.Files {
+				if !accounted(f.Path) {
+					add(Discrepancy{Path: f.Path, Dir: true, ModTime: f.ModTime, Policy: f.Policy})
+				}
+			}
+		}
+		if r.Unverified > len(r.Discrepancies) {
+			cl.errorf("目录 %s 另有 %d 个到期文件校验失败，未逐个记录", dir.Path, r.Unverified-len(r.Discrepancies))
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package cleaner
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// runDir 处理一个目录项：处于保留状态时跳过，canary 模式只预览，否则执行目录的钩子并清理
+func (cl *Cleaner) runDir(ctx context.Context, dir DirConfig, now time.Time, ds Stats) DirReport {
+	start := time.Now()
+	if cl.onHold(dir) {
+		cl.logf("目录 %s 处于保留（hold）状态，跳过", dir.Path)
+		return DirReport{Path: dir.Path, Start: start, OnHold: true}
+	}
+	if cl.canary(dir) {
+		dr := DirReport{Path: dir.Path, Source: dir.source, Start: start, Canary: true}
+		cl.previewDir(ctx, dir, now, &dr)
+		dr.Duration = time.Since(start).Seconds()
+		return dr
+	}
+	fs, dctx, cancel := cl.newFailureStop(ctx, dir)
+	defer cancel()
+	ds.failStop = fs
+	if err := cl.runHook(ctx, dir.Hooks, hookPreRun, dir.Path, ds, now); err != nil {
+		cl.errorf("目录 %s 的 pre_run 钩子执行失败，跳过该目录: %s", dir.Path, err)
+		ds.fail(err)
+		ds.account(dir.Path)
+	} else {
+		cl.cleanDir(dctx, dir, now, &ds)
+		cl.reportExternal(dir)
+		if err := cl.runHook(ctx, dir.Hooks, hookPostRun, dir.Path, ds, now); err != nil {
+			cl.errorf("目录 %s 的 post_run 钩子执行失败: %s", dir.Path, err)
+		}
+	}
+	dr := DirReport{Path: dir.Path, Owner: dir.Owner, Start: start, Duration: time.Since(start).Seconds(), Stats: ds}
+	if fs != nil {
+		dr.Aborted = fs.hit
+	}
+	if !isRemote(dir.Path) && dir.Docker == nil {
+		dr.Storage = cl.storageKind(dir, dir.Path)
+		if n := cl.config.TopOffenders; n > 0 && ctx.Err() == nil {
+			dr.Top = cl.offenders(ctx, dir, n)
+		}
+	}
+	return dr
+}
+
+// workers 返回本次任务同时处理的目录数。max_bytes_per_run 按目录顺序累计释放的空间，配置后按顺序处理
+func (cl *Cleaner) workers(dirs []DirConfig) int {
+	n := cl.config.Workers
+	if n <= 1 || len(dirs) <= 1 {
+		return 1
+	}
+	if cl.config.MaxBytesPerRun > 0 {
+		cl.debugf("配置了 max_bytes_per_run，按顺序处理目录")
+		return 1
+	}
+	for _, d := range dirs {
+		if d.MaxBytesPerRun > 0 {
+			cl.debugf("配置了 max_bytes_per_run，按顺序处理目录")
+			return 1
+		}
+	}
+	if n > len(dirs) {
+		n = len(dirs)
+	}
+	return n
+}
+
+// dirQueue 并行处理时待处理的目录：worker 按配置顺序领取第一个所在卷未达到 workers_per_volume 的目录，
+// 某个卷上的目录都在等待时其他卷的目录照常处理。无法识别所在卷的目录（远程目录、网络共享、Docker）只受 workers 限制。
+// 归档到同一目录的目录项会继续清理该归档目录，不同时处理
+type dirQueue struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	pending   []int      // 尚未开始的目录下标
+	volumes   []string   // 各目录所在的卷
+	archives  [][]string // 各目录的归档目录和上传失败目录
+	active    map[string]int
+	busy      map[string]bool // 正在处理的目录项的归档目录
+	perVolume int
+}
+
+func (cl *Cleaner) newDirQueue(dirs []DirConfig) *dirQueue {
+	q := &dirQueue{volumes: make([]string, len(dirs)), archives: make([][]string, len(dirs)), active: map[string]int{}, busy: map[string]bool{}, perVolume: cl.config.WorkersPerVolume}
+	if q.perVolume <= 0 {
+		q.perVolume = 1
+	}
+	q.cond = sync.NewCond(&q.mu)
+	for i, d := range dirs {
+		q.pending = append(q.pending, i)
+		for _, r := range d.policy.rules {
+			if a, ok := r.action.(archiveAction); ok {
+				for _, path := range []string{a.dir, a.backlog} {
+					if path != "" {
+						q.archives[i] = append(q.archives[i], filepath.Clean(path))
+					}
+				}
+			}
+		}
+		if !isRemote(d.Path) && d.Docker == nil {
+			if id, ok := volumeID(d.Path); ok {
+				q.volumes[i] = id
+			}
+		}
+	}
+	return q
+}
+
+// next 领取下一个目录，没有可以开始的目录时等待其他目录处理完；全部领取完或任务取消时返回 false
+func (q *dirQueue) next(ctx context.Context) (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if len(q.pending) == 0 || ctx.Err() != nil {
+			return 0, false
+		}
+		for k, i := range q.pending {
+			v := q.volumes[i]
+			if (v == "" || q.active[v] < q.perVolume) && !q.archiveBusy(i) {
+				q.pending = append(q.pending[:k], q.pending[k+1:]...)
+				if v != "" {
+					q.active[v]++
+				}
+				for _, a := range q.archives[i] {
+					q.busy[a] = true
+				}
+				return i, true
+			}
+		}
+		q.cond.Wait()
+	}
+}
+
+func (q *dirQueue) archiveBusy(i int) bool {
+	for _, a := range q.archives[i] {
+		if q.busy[a] {
+			return true
+		}
+	}
+	return false
+}
+
+// done 目录处理完，释放所在卷的名额
+func (q *dirQueue) done(i int) {
+	q.mu.Lock()
+	if v := q.volumes[i]; v != "" {
+		q.active[v]--
+	}
+	for _, a := range q.archives[i] {
+		delete(q.busy, a)
+	}
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+// runParallel 按 workers 同时处理各目录，返回已开始处理的目录的统计（按配置顺序）、任务取消时正在处理的第一个目录
+// 和未开始处理的目录数。单个目录处理中的 panic 在所有 worker 结束后在调用方的 goroutine 中重新抛出
+func (cl *Cleaner) runParallel(ctx context.Context, dirs []DirConfig, now time.Time, workers int, newStats func(DirConfig) Stats) ([]DirReport, string, int) {
+	q := cl.newDirQueue(dirs)
+	results := make([]*DirReport, len(dirs))
+	interrupted := make([]bool, len(dirs))
+	var mu sync.Mutex
+	var panicked interface{}
+	var wg sync.WaitGroup
+	cl.logf("同时处理 %d 个目录，同一卷上最多 %d 个", workers, q.perVolume)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i, ok := q.next(ctx)
+				if !ok {
+					return
+				}
+				func() {
+					defer q.done(i)
+					defer func() {
+						if r := recover(); r != nil {
+							mu.Lock()
+							panicked = r
+							mu.Unlock()
+						}
+					}()
+					dr := cl.runDirTimed(ctx, dirs[i], now, newStats(dirs[i]))
+					results[i] = &dr
+					interrupted[i] = ctx.Err() != nil
+				}()
+			}
+		}()
+	}
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+	var reports []DirReport
+	var stopped string
+	var remaining int
+	for i, r := range results {
+		if r == nil {
+			remaining++
+			continue
+		}
+		reports = append(reports, *r)
+		if interrupted[i] && stopped == "" {
+			stopped = r.Path
+		}
+	}
+	return reports, stopped, remaining
+}
@@ -0,0 +1,419 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: control.proto
+
+// 清理服务的 gRPC 控制接口，供管理程序以强类型的消息调用
+
+package controlpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Cleaner_TriggerRun_FullMethodName     = "/cleanlog.control.v1.Cleaner/TriggerRun"
+	Cleaner_GetStatus_FullMethodName      = "/cleanlog.control.v1.Cleaner/GetStatus"
+	Cleaner_ReloadConfig_FullMethodName   = "/cleanlog.control.v1.Cleaner/ReloadConfig"
+	Cleaner_ListRecentRuns_FullMethodName = "/cleanlog.control.v1.Cleaner/ListRecentRuns"
+	Cleaner_Pause_FullMethodName          = "/cleanlog.control.v1.Cleaner/Pause"
+	Cleaner_Resume_FullMethodName         = "/cleanlog.control.v1.Cleaner/Resume"
+	Cleaner_Hold_FullMethodName           = "/cleanlog.control.v1.Cleaner/Hold"
+	Cleaner_Release_FullMethodName        = "/cleanlog.control.v1.Cleaner/Release"
+	Cleaner_Confirm_FullMethodName        = "/cleanlog.control.v1.Cleaner/Confirm"
+)
+
+// CleanerClient is the client API for Cleaner service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CleanerClient interface {
+	// TriggerRun 立即执行一次任务，wait 为 true 时等待任务完成并返回结果
+	TriggerRun(ctx context.Context, in *TriggerRunRequest, opts ...grpc.CallOption) (*TriggerRunResponse, error)
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
+	// ReloadConfig 重新加载配置，校验失败时保留原配置并返回 INVALID_ARGUMENT
+	ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadConfigResponse, error)
+	// ListRecentRuns 最近完成的任务，最新的在前
+	ListRecentRuns(ctx context.Context, in *ListRecentRunsRequest, opts ...grpc.CallOption) (*ListRecentRunsResponse, error)
+	// Pause 暂停定时任务，手动触发不受影响
+	Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error)
+	Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*PauseResponse, error)
+	// Hold 暂停处理目录中的文件，Release 解除
+	Hold(ctx context.Context, in *HoldRequest, opts ...grpc.CallOption) (*HoldResponse, error)
+	Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*HoldResponse, error)
+	// Confirm 确认 canary 目录，之后的任务开始处理其中的文件
+	Confirm(ctx context.Context, in *ConfirmRequest, opts ...grpc.CallOption) (*ConfirmResponse, error)
+}
+
+type cleanerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCleanerClient(cc grpc.ClientConnInterface) CleanerClient {
+	return &cleanerClient{cc}
+}
+
+func (c *cleanerClient) TriggerRun(ctx context.Context, in *TriggerRunRequest, opts ...grpc.CallOption) (*TriggerRunResponse, error) {
+	out := new(TriggerRunResponse)
+	err := c.cc.Invoke(ctx, Cleaner_TriggerRun_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cleanerClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
+	out := new(GetStatusResponse)
+	err := c.cc.Invoke(ctx, Cleaner_GetStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cleanerClient) ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadConfigResponse, error) {
+	out := new(ReloadConfigResponse)
+	err := c.cc.Invoke(ctx, Cleaner_ReloadConfig_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cleanerClient) ListRecentRuns(ctx context.Context, in *ListRecentRunsRequest, opts ...grpc.CallOption) (*ListRecentRunsResponse, error) {
+	out := new(ListRecentRunsResponse)
+	err := c.cc.Invoke(ctx, Cleaner_ListRecentRuns_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cleanerClient) Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseResponse, error) {
+	out := new(PauseResponse)
+	err := c.cc.Invoke(ctx, Cleaner_Pause_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cleanerClient) Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*PauseResponse, error) {
+	out := new(PauseResponse)
+	err := c.cc.Invoke(ctx, Cleaner_Resume_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cleanerClient) Hold(ctx context.Context, in *HoldRequest, opts ...grpc.CallOption) (*HoldResponse, error) {
+	out := new(HoldResponse)
+	err := c.cc.Invoke(ctx, Cleaner_Hold_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cleanerClient) Release(ctx context.Context, in *ReleaseRequest, opts ...grpc.CallOption) (*HoldResponse, error) {
+	out := new(HoldResponse)
+	err := c.cc.Invoke(ctx, Cleaner_Release_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cleanerClient) Confirm(ctx context.Context, in *ConfirmRequest, opts ...grpc.CallOption) (*ConfirmResponse, error) {
+	out := new(ConfirmResponse)
+	err := c.cc.Invoke(ctx, Cleaner_Confirm_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CleanerServer is the server API for Cleaner service.
+// All implementations must embed UnimplementedCleanerServer
+// for forward compatibility
+type CleanerServer interface {
+	// TriggerRun 立即执行一次任务，wait 为 true 时等待任务完成并返回结果
+	TriggerRun(context.Context, *TriggerRunRequest) (*TriggerRunResponse, error)
+	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
+	// ReloadConfig 重新加载配置，校验失败时保留原配置并返回 INVALID_ARGUMENT
+	ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadConfigResponse, error)
+	// ListRecentRuns 最近完成的任务，最新的在前
+	ListRecentRuns(context.Context, *ListRecentRunsRequest) (*ListRecentRunsResponse, error)
+	// Pause 暂停定时任务，手动触发不受影响
+	Pause(context.Context, *PauseRequest) (*PauseResponse, error)
+	Resume(context.Context, *ResumeRequest) (*PauseResponse, error)
+	// Hold 暂停处理目录中的文件，Release 解除
+	Hold(context.Context, *HoldRequest) (*HoldResponse, error)
+	Release(context.Context, *ReleaseRequest) (*HoldResponse, error)
+	// Confirm 确认 canary 目录，之后的任务开始处理其中的文件
+	Confirm(context.Context, *ConfirmRequest) (*ConfirmResponse, error)
+	mustEmbedUnimplementedCleanerServer()
+}
+
+// UnimplementedCleanerServer must be embedded to have forward compatible implementations.
+type UnimplementedCleanerServer struct {
+}
+
+func (UnimplementedCleanerServer) TriggerRun(context.Context, *TriggerRunRequest) (*TriggerRunResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerRun not implemented")
+}
+func (UnimplementedCleanerServer) GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedCleanerServer) ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadConfig not implemented")
+}
+func (UnimplementedCleanerServer) ListRecentRuns(context.Context, *ListRecentRunsRequest) (*ListRecentRunsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRecentRuns not implemented")
+}
+func (UnimplementedCleanerServer) Pause(context.Context, *PauseRequest) (*PauseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedCleanerServer) Resume(context.Context, *ResumeRequest) (*PauseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedCleanerServer) Hold(context.Context, *HoldRequest) (*HoldResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Hold not implemented")
+}
+func (UnimplementedCleanerServer) Release(context.Context, *ReleaseRequest) (*HoldResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Release not implemented")
+}
+func (UnimplementedCleanerServer) Confirm(context.Context, *ConfirmRequest) (*ConfirmResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Confirm not implemented")
+}
+func (UnimplementedCleanerServer) mustEmbedUnimplementedCleanerServer() {}
+
+// UnsafeCleanerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CleanerServer will
+// result in compilation errors.
+type UnsafeCleanerServer interface {
+	mustEmbedUnimplementedCleanerServer()
+}
+
+func RegisterCleanerServer(s grpc.ServiceRegistrar, srv CleanerServer) {
+	s.RegisterService(&Cleaner_ServiceDesc, srv)
+}
+
+func _Cleaner_TriggerRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TriggerRunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CleanerServer).TriggerRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cleaner_TriggerRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CleanerServer).TriggerRun(ctx, req.(*TriggerRunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cleaner_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CleanerServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cleaner_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CleanerServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cleaner_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CleanerServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cleaner_ReloadConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CleanerServer).ReloadConfig(ctx, req.(*ReloadConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cleaner_ListRecentRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecentRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CleanerServer).ListRecentRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cleaner_ListRecentRuns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CleanerServer).ListRecentRuns(ctx, req.(*ListRecentRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cleaner_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CleanerServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cleaner_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CleanerServer).Pause(ctx, req.(*PauseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cleaner_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CleanerServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cleaner_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CleanerServer).Resume(ctx, req.(*ResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cleaner_Hold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HoldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CleanerServer).Hold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cleaner_Hold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CleanerServer).Hold(ctx, req.(*HoldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cleaner_Release_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CleanerServer).Release(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cleaner_Release_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CleanerServer).Release(ctx, req.(*ReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cleaner_Confirm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CleanerServer).Confirm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cleaner_Confirm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CleanerServer).Confirm(ctx, req.(*ConfirmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Cleaner_ServiceDesc is the grpc.ServiceDesc for Cleaner service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Cleaner_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cleanlog.control.v1.Cleaner",
+	HandlerType: (*CleanerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "TriggerRun",
+			Handler:    _Cleaner_TriggerRun_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _Cleaner_GetStatus_Handler,
+		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _Cleaner_ReloadConfig_Handler,
+		},
+		{
+			MethodName: "ListRecentRuns",
+			Handler:    _Cleaner_ListRecentRuns_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _Cleaner_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _Cleaner_Resume_Handler,
+		},
+		{
+			MethodName: "Hold",
+			Handler:    _Cleaner_Hold_Handler,
+		},
+		{
+			MethodName: "Release",
+			Handler:    _Cleaner_Release_Handler,
+		},
+		{
+			MethodName: "Confirm",
+			Handler:    _Cleaner_Confirm_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "control.proto",
+}
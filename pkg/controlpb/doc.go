@@ -0,0 +1,4 @@
+// Package controlpb 由 control.proto 生成的 gRPC 控制接口代码，修改 control.proto 后执行 go generate 重新生成
+package controlpb
+
+//go:generate buf generate --template {"version":"v1","plugins":[{"name":"go","out":".","opt":"paths=source_relative"},{"name":"go-grpc","out":".","opt":"paths=source_relative"}]}
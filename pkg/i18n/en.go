@@ -0,0 +1,592 @@
+package i18n
+
+// en 英文消息表，键为代码中的中文消息，格式串的参数顺序不同时使用 %[n] 指定
+var en = map[string]string{
+	// 服务与配置
+	"只执行一次清理后退出，摘要输出到标准输出；退出码 0 成功、2 有失败、3 配置错误":             "run one cleanup and exit, printing a summary to stdout; exit code 0 success, 2 failures, 3 configuration error",
+	"合计: 扫描 %d，删除 %d，失败 %d，释放 %s，耗时 %.1fs":                   "Total: scanned %d, deleted %d, failed %d, freed %s in %.1fs",
+	"前台运行，日志同时输出到控制台，Ctrl-C 退出":                              "run in the foreground and also log to the console; Ctrl-C to exit",
+	"配置文件路径，默认为程序目录下的 config.yml；与 install 一起使用时记录到服务的启动参数中": "config file path, defaults to config.yml next to the executable; with install it is recorded in the service arguments",
+	"install 时指定运行服务的账户，如 DOMAIN\\svc-clean，默认 LocalSystem":  "account to run the service as (install), e.g. DOMAIN\\svc-clean; defaults to LocalSystem",
+	"运行账户的密码，也可通过环境变量 CLEANLOG_SERVICE_PASSWORD 传入":          "password of the service account, or set CLEANLOG_SERVICE_PASSWORD",
+	"服务依赖，逗号分隔，如 LanmanWorkstation":                          "comma-separated service dependencies, e.g. LanmanWorkstation",
+	"服务名称，同一台机器上安装多个实例时使用不同的名称":                              "service name; use different names to install several instances on one host",
+	"服务显示名称，默认与服务名称相同":                                       "service display name, defaults to the service name",
+	"install 时设置服务崩溃后的操作：restart、reboot 或 none":              "action after the service crashes (install): restart, reboot or none",
+	"崩溃后多久重启服务":                                 "delay before restarting the service after a crash",
+	"多久没有再次失败后重置失败计数（仅 Windows）":                "period without failures after which the failure count resets (Windows only)",
+	"--on-failure %q 无效，可选 restart、reboot、none": "invalid --on-failure %q, expected restart, reboot or none",
+	"服务描述": "service description",
+	"目录 %s 的定时表达式 %q 无效: %s":                  "directory %s: invalid schedule %q: %s",
+	"目录 %s 单独定时: %s":                          "Directory %s has its own schedule: %s",
+	"清理任务运行时间过长":                              "Cleanup run is taking too long",
+	"任务从 %s 开始，已运行 %s，超过预期的 %s":               "The run started at %s and has been running for %s, longer than the expected %s",
+	"清理任务长时间没有成功完成":                           "No cleanup run has succeeded for a long time",
+	"自 %s 起已有 %d 个定时周期没有成功完成的任务":              "No run has succeeded in %[2]d schedule intervals since %[1]s",
+	"获取 %s 所在磁盘的剩余空间失败: %s":                   "Failed to get free space of the disk holding %s: %s",
+	"磁盘 %s 剩余 %s / %s，低于配置的下限；清理目录: %s":       "Disk %s has %s free of %s, below the configured floor; cleaned directories: %s",
+	"清理后磁盘剩余空间不足":                             "Disk space still low after cleanup",
+	"gRPC 接口启动失败: %s":                         "gRPC API failed: %s",
+	"gRPC 接口监听 %s":                            "gRPC API listening on %s",
+	"保留 %s：原文件名不满足目录的过滤条件，不是本服务软删除的文件":        "Keeping %s: the original name does not match the directory filters, so it was not soft-deleted by this service",
+	"目录 %s: 扫描 %d，到期 %d，共 %s":                 "Directory %s: scanned %d, expired %d, %s",
+	"digest.at %q 无效，应为 HH:MM，如 08:00":        "digest.at %q is invalid, expected HH:MM such as 08:00",
+	"digest.weekday %q 无效，应为 monday 至 sunday": "digest.weekday %q is invalid, expected monday through sunday",
+	"digest.period %q 无效，可选 daily、weekly":     "digest.period %q is invalid, expected daily or weekly",
+	"读取摘要记录失败: %s":                            "Failed to read digest state: %s",
+	"保存摘要记录失败: %s":                            "Failed to save digest state: %s",
+	"清理任务摘要":                                  "Cleanup digest",
+	"%s 至 %s：任务 %d 次（其中出错或有失败 %d 次），删除 %d 个文件，释放 %s，失败 %d 个": "%s to %s: %d runs (%d with errors or failures), %d files deleted, %s freed, %d failed",
+	"上一期: 任务 %d 次，删除 %d 个文件，释放 %s（本期 %s），失败 %d 个":            "Previous period: %d runs, %d files deleted, %s freed (this period %s), %d failed",
+	"释放空间最多的目录: %s":         "Directories that freed the most space: %s",
+	"%s（最近一次任务失败 %d 个）":     "%s (%d failed in the latest run)",
+	"仍未解决的失败: %s":           "Unresolved failures: %s",
+	"最近一次任务错误: %s":          "Latest run error: %s",
+	"持平":                    "unchanged",
+	"上一期为 0":                "previous was 0",
+	"%s: 不能为负数":             "%s: must not be negative",
+	"remote_timeout 不能为负数":  "remote_timeout must not be negative",
+	"目录 %s 处理超过 %s，停止处理该目录": "Directory %s took longer than %s, stopped processing it",
+	"目录 %s 在 %s 内仍未结束，可能是网络共享或挂载无响应，放弃等待、继续处理其他目录": "Directory %s did not finish within %s, the network share or mount may be unresponsive; no longer waiting for it and continuing with other directories",
+	"目录 %s: 处理超时，已停止处理":                                "Directory %s: timed out, processing stopped",
+	"  处理超时，已停止处理该目录":                                  "  timed out, processing of this directory stopped",
+	"external 过滤器需要配置 command":                         "the external filter requires command",
+	"external 过滤器的 on_error 应为 keep 或 delete":          "on_error of the external filter must be keep or delete",
+	"外部程序已退出":                                          "the external program exited",
+	"等待外部程序回复超时（%s）":                                   "timed out waiting for the external program to reply (%s)",
+	"无法解析外部程序的回复 %q: %s":                               "cannot parse the reply of the external program %q: %s",
+	"外部程序回复的文件 %s 与请求的 %s 不一致":                         "the external program replied for %s, but %s was requested",
+	"外部程序对 %s 的回复 %q 无效，应为 keep 或 delete":              "invalid reply %[2]q of the external program for %[1]s, must be keep or delete",
+	"目录 %s: external 过滤器 %s 出错 %d 次，按 on_error 处理: %s": "Directory %s: external filter %s failed %d times and on_error was applied: %s",
+	"空文件":                     "empty files",
+	"%s 后删除（stale_files: %s）": "delete after %s (stale_files: %s)",
+	"目录 %s 的 stale_files: %w": "stale_files of directory %s: %w",
+	"目录 %s %s，停止处理该目录，请检查目录的权限或挂载状态":     "Directory %s: %s, stopped processing this directory; check its permissions or mount state",
+	"连续 %d 个文件处理失败":                      "%d consecutive files failed",
+	"%d 个文件中 %d 个处理失败，超过 %d%%":           "%[2]d of %[1]d files failed, more than %[3]d%%",
+	"stop_after_failures 不能为负数":          "stop_after_failures must not be negative",
+	"stop_failure_percent 应在 0 到 100 之间": "stop_failure_percent must be between 0 and 100",
+	"目录 %s: %s，已停止处理":                    "Directory %s: %s, processing stopped",
+	"  %s，已停止处理该目录":                      "  %s, processing of this directory stopped",
+	"目录因处理失败过多已停止处理":                     "Directory processing stopped after too many failures",
+	"以下目录中的文件大量处理失败，通常是权限或挂载问题，本次已停止处理其余文件: %s": "Many files failed in these directories, usually a permissions or mount problem; the remaining files were not processed this run: %s",
+	"%s（%s；失败原因: %s）": "%s (%s; causes: %s)",
+	"警告: 无法创建日志文件，日志改为输出到 %s，请检查 logging.dir 的权限或改到可写的目录: %s": "Warning: cannot create the log file, logging to %s instead; check the permissions of logging.dir or move it to a writable directory: %s",
+	"配置了 max_bytes_per_run，按顺序处理目录":                           "max_bytes_per_run is configured, processing directories sequentially",
+	"同时处理 %d 个目录，同一卷上最多 %d 个":                                 "Processing %d directories concurrently, at most %d per volume",
+	"workers、workers_per_volume 不能为负数":                        "workers and workers_per_volume must not be negative",
+	"读取扫描缓存 %s 失败: %s":                                        "Failed to read scan cache %s: %s",
+	"保存扫描缓存 %s 失败: %s":                                        "Failed to save scan cache %s: %s",
+	"目录 %s 自 %s 扫描后没有变化，跳过":                                   "Directory %s unchanged since scan at %s, skipped",
+	"目录 %s: 自上次扫描后没有变化，跳过":                                    "Directory %s: unchanged since last scan, skipped",
+	"没有变化、跳过扫描的目录数: %d\n":                                     "Unchanged directories skipped: %d\n",
+	"发送 %s 通知失败: %s":                                          "Failed to send %s notification: %s",
+	"%s 的第 %d 个通知渠道: %w":                                      "%s channel %d: %w",
+	"%s 的第 %d 个通知渠道: 未知的事件 %q":                                "%s channel %d: unknown event %q",
+	"未知的通知渠道类型 %q，可选 %s":                                      "unknown notification channel type %q, available: %s",
+	"%s 渠道需要配置 url":                                           "%s channel requires url",
+	"email 渠道需要配置 email.smtp 和 email.to":                      "email channel requires email.smtp and email.to",
+	"exec 渠道需要配置 command":                                     "exec channel requires command",
+	"策略组 %s 的 notify":                                         "notify of group %s",
+	"清理任务完成":                                                  "Cleanup run completed",
+	"删除 %d 个文件，释放 %s，耗时 %.1f 秒":                               "Deleted %d files, freed %s in %.1f s",
+	"删除 %d 个文件，释放 %s，失败 %d 个；失败原因: %s":                        "Deleted %d files, freed %s, %d failed; failure causes: %s",
+	"任务出错: %s；":                                               "Run error: %s; ",
+	"清理任务失败":                                                  "Cleanup run failed",
+	"本次任务有 %d 个文件处理失败，超过 alert_on_failures 设置的 %d；失败原因: %s": "%d files failed in this run, more than alert_on_failures (%d); causes: %s",
+	"失败原因: %s\n": "Failure causes: %s\n",
+	"失败原因: %s":   "Failure causes: %s",
+	"权限不足":       "permission denied",
+	"被占用":        "in use",
+	"不存在":        "not found",
+	"路径过长":       "path too long",
+	"网络":         "network",
+	"其他":         "other",
+	"  校验失败 %d 个：任务结束后仍然存在、未记录原因的到期文件":             "  %d failed verification: expired files still present after the run with no recorded reason",
+	"校验失败: %s 按保留策略（%s）已到期，任务结束后仍然存在，且未记录跳过或失败的原因": "verification failed: %s is expired under %s but still exists after the run, with no skip or failure reason recorded",
+	"目录 %s 另有 %d 个到期文件校验失败，未逐个记录":                  "directory %s has %d more expired files that failed verification, not listed",
+	"校验失败（任务结束后仍然存在的到期文件）数: %d\n":                  "Verification failures (expired files still present after the run): %d\n",
+	"进程 %s": "process %s",
+	"服务 %s": "service %s",
+	"备份仍在运行（%s），已推迟 %d 次，开始执行":                                             "backup still running (%s) after deferring %d times, starting anyway",
+	"备份仍在运行（%s），已推迟 %d 次，跳过本次任务":                                           "backup still running (%s) after deferring %d times, skipping this run",
+	"备份正在运行（%s），%s 后重新检查（第 %d/%d 次推迟）":                                     "backup running (%s), checking again in %s (deferral %d/%d)",
+	"%s %s 被拒绝访问（可能被杀毒软件或索引服务占用），%s 后重试":                                   "%s %s: access denied (possibly held by antivirus or indexing), retrying in %s",
+	"%s 关闭后删除失败: %s":                                                       "Delete-on-close for %s failed: %s",
+	"%s 持续被拒绝访问，已改为关闭后删除，由系统在其他进程释放文件后删除":                                  "%s keeps being denied access; marked for delete-on-close, the system removes it once other processes release it",
+	"被拒绝访问（疑似杀毒软件或索引服务占用）文件数: %d，其中关闭后删除: %d\n":                            "Files denied access (likely antivirus or indexing): %d, deleted on close: %d\n",
+	"读取上次执行时间失败: %s":                                                       "Failed to read last run times: %s",
+	"保存上次执行时间失败: %s":                                                       "Failed to save last run times: %s",
+	"定时任务 %s 错过了 %s 的执行（上次到点于 %s）":                                         "Scheduled task %s missed its run at %s (last due at %s)",
+	"补执行定时任务 %s":                                                           "Catching up scheduled task %s",
+	"补执行全部定时任务":                                                            "Catching up all scheduled tasks",
+	"min_size %s 大于 max_size %s":                                           "min_size %s is greater than max_size %s",
+	"不小于 %s":                                                               "at least %s",
+	"不大于 %s":                                                               "at most %s",
+	"%s 到 %s":                                                              "%s to %s",
+	"  ……另有 %d 个":                                                          "  ... and %d more",
+	"  只清理这些扩展名（逗号分隔，如 .log,.txt；留空为全部文件）":                                 "  Only clean these extensions (comma separated, e.g. .log,.txt; empty for all files)",
+	"# 由 cleanlogservice init 生成；全部选项及说明见 cleanlogservice generate-config": "# Generated by cleanlogservice init; see cleanlogservice generate-config for all options",
+	"%q 不是有效的天数或时长":                                                        "%q is not a valid number of days or duration",
+	"%s 不存在或不是目录，仍然加入？":                                                    "%s does not exist or is not a directory, add it anyway?",
+	"%s 已存在，是否覆盖？":                                                         "%s already exists, overwrite?",
+	"之后可以执行 %s 安装服务":                                                       "Run %s later to install the service",
+	"依次输入要清理的目录（可以使用通配符，如 D:\\apps\\*\\logs），输入空行结束：": "Enter the directories to clean one per line (wildcards allowed, e.g. D:\\apps\\*\\logs), empty line to finish:",
+	"保留期限：天数（如 7）或时长（如 36h）":                          "Retention: days (e.g. 7) or a duration (e.g. 36h)",
+	"写入 %s？":           "Write %s?",
+	"已写入 %s，但加载失败: %s": "Wrote %s, but loading it failed: %s",
+	"已取消，未修改配置文件":      "Cancelled, configuration file not modified",
+	"执行 %s 失败: %s":     "%s failed: %s",
+	"按以上配置，当前已到期、第一次执行时将被删除的文件：": "With this configuration, these files have already expired and will be deleted on the first run:",
+	"服务已安装并启动": "Service installed and started",
+	"每天执行的时间（如 05:00），也可以输入 cron 表达式": "Daily run time (e.g. 05:00), or a cron expression",
+	"现在安装并启动服务？":                      "Install and start the service now?",
+	"目录 %d":                           "Directory %d",
+	"至少需要一个目录":                        "At least one directory is required",
+	"输入已结束，未写入配置":                     "Input ended, configuration not written",
+	"配置向导：回答以下问题生成配置文件，方括号中为默认值，直接回车使用默认值": "Setup wizard: answer the questions below to generate a configuration file; defaults are shown in brackets, press Enter to accept them",
+	"配置已写入 %s":       "Configuration written to %s",
+	"配置无效: %s":       "Invalid configuration: %s",
+	"重新填写？":          "Start over?",
+	"不是 scan 生成的报告":  "not a report generated by scan",
+	"保存扫描报告失败: %s":   "Failed to save scan report: %s",
+	"合计到期 %d 项，共 %s": "Total expired: %d items, %s",
+	"扫描失败: %s":       "Scan failed: %s",
+	"扫描报告已签名并保存到 %s": "Scan report signed and saved to %s",
+	"报告使用 HMAC 签名，校验需要配置签名时的 scan_secret":   "The report is HMAC-signed; verifying it requires the scan_secret used when signing",
+	"校验失败：报告 %s 在签名后被修改，或签名密钥不同":            "Verification failed: report %s was modified after signing, or the signing key differs",
+	"校验通过：%s 由 %s 于 %s 生成（%s），到期 %d 项，共 %s": "Verified: %s was generated by %s at %s (%s), %d expired items, %s",
+	"用法: scan verify <报告文件>":                "Usage: scan verify <report file>",
+	"目录 %s%s: 扫描 %d，到期 %d，共 %s":             "Directory %s%s: scanned %d, expired %d, %s",
+	"读取扫描报告失败: %s":                          "Failed to read scan report: %s",
+	"（canary 模式，确认前不处理）":                    " (canary mode, not processed until confirmed)",
+	"（保留状态，当前不处理）":                          " (on hold, currently not processed)",
+	"目录重叠: %s":                              "Overlapping directories: %s",
+	"%s 位于 %s 之中，父目录按子目录删除时跳过该目录，其中的文件按该目录自身的策略处理": "%s is inside %s; the parent skips it when deleting subdirectories and its files follow its own policy",
+	"%s 同时被 %s 和 %s 匹配，按 %s 处理":     "%s is matched by both %s and %s, using %[4]s",
+	"跳过 %s：其中的目录 %s 单独配置，按其自身的策略处理": "Skipping %s: it contains %s, which is configured separately and follows its own policy",
+	"%s（%s）":       "%s (%s)",
+	"%s: 只支持本地目录":  "%s: only supported for local directories",
+	"模式 %q 无效: %s": "invalid pattern %q: %s",
+	"%s: %d 天不早于保留期 %s，压缩永远不会执行":                                         "%s: %d days is not earlier than the retention period %s, files would never be compressed",
+	"%s: %q 不是扩展名，按文件名模式匹配请使用 glob 过滤器":                                  "%s: %q is not an extension; use a glob filter to match file name patterns",
+	"%s: %q 同时出现在 extensions 中，这类文件永远不会被处理":                              "%s: %q is also listed in extensions, such files would never be processed",
+	"%s: %q 是相对路径，请使用绝对路径，或配置 allow_relative_paths: true 使其相对于配置文件所在的目录": "%s: %q is a relative path; use an absolute path, or set allow_relative_paths: true to resolve it against the config file directory",
+	"%s: days、max_age 不能为负数":                                             "%s: days and max_age must not be negative",
+	"days、max_age 不能为负数":                                                 "days and max_age must not be negative",
+	"%s: 不能为空":                                                           "%s: must not be empty",
+	"%s: 与 %s 是同一目录且处理相同的文件，请合并为一项":                                      "%s: same directory and files as %s, merge them into one entry",
+	"%s: 与 days 同时配置时只有 max_age 生效，请只保留一项":                               "%s: only max_age takes effect when days is also set, keep just one",
+	"%s: 与 tiers[%d] 的年龄相同（%s），只有其中一个会执行":                                "%s: same age as tiers[%d] (%s), only one of them would run",
+	"%s: 只在 action: truncate 时生效":                                        "%s: only takes effect with action: truncate",
+	"%s: 年龄 %s 晚于 tiers[%d] 的 %s（%s），文件届时已不存在，不会执行":                      "%s: age %s is later than the %[4]s at tiers[%[3]d] (%[5]s); files are gone by then, so it would never run",
+	"%s: 配置了 tiers 时不生效，请在 tiers 中配置":                                    "%s: has no effect when tiers are configured, set it in tiers instead",
+	"directories 为空：至少需要配置一个清理目录（或 tables、elasticsearch）":                "directories is empty: configure at least one directory (or tables, elasticsearch)",
+	"%s 后软删除（%s）":                                                        "soft delete (%[2]s) after %[1]s",
+	"保留 %s：软删除于 %s，未超过 soft_delete_grace":                                "Keeping %s: soft-deleted at %s, within soft_delete_grace",
+	"永久删除 %s 失败: %s":                                                     "Failed to permanently delete %s: %s",
+	"永久删除 %s（软删除于 %s）":                                                   "Permanently deleted %s (soft-deleted at %s)",
+	"软删除到期永久删除文件数: %d\n":                                                 "Soft-deleted files permanently deleted: %d\n",
+	"服务健康状态: %s -> %s":                                                   "Service health: %s -> %s",
+	"健康状态: %s":                                                           "Health: %s",
+	"更新服务描述失败: %s":                                                       "Failed to update the service description: %s",
+	"content_date.line %q 无效，可选 first、last":                              "invalid content_date.line %q, expected first or last",
+	"content_date: %w": "content_date: %w",
+	"无法从 %s 的%s中解析时间，使用修改时间": "Cannot parse a timestamp from the %[2]s of %[1]s, using the modification time",
+	"末行":              "last line",
+	"首行":              "first line",
+	"不支持读取 .gz 文件的末行": "cannot read the last line of a .gz file",
+	"storage %q 无效，可选 auto、ssd、hdd":    "invalid storage %q, expected auto, ssd or hdd",
+	"无法检测 %s 所在卷的类型，按 batch_size 分批删除": "Cannot detect the volume type of %s, deleting in batch_size batches",
+	"%s 所在的卷为 %s":                      "%s is on a %s volume",
+	"状态文件 %s 已损坏: %s: %w":              "State file %s is corrupt: %s: %w",
+	"状态文件 %s 已损坏，已另存为 %s: %s: %w":      "State file %s is corrupt, kept as %s: %s: %w",
+	"文件为空":       "file is empty",
+	"不是有效的 JSON": "not valid JSON",
+	"格式版本 %d 高于当前程序支持的 %d": "format version %d is newer than the supported %d",
+	"校验和不符":          "checksum mismatch",
+	"目录 %s: %s":      "Directory %s: %s",
+	"快照 %s 格式无效: %s": "Invalid snapshot %s: %s",
+	"快照中没有该目录":       "directory not in the snapshot",
+	"保存快照失败: %s":     "Failed to save snapshot: %s",
+	"快照已保存到 %s":      "Snapshot saved to %s",
+	"用法: simulate <快照文件>，快照由 bench <快照文件> 生成": "Usage: simulate <snapshot file>; snapshots are recorded with bench <snapshot file>",
+	"读取快照失败: %s": "Failed to read snapshot: %s",
+	"预演失败: %s":   "Simulation failed: %s",
+	"快照 %s（%s，%s），按记录时的时间预演":                          "Snapshot %s (%s, %s), simulated as of the time it was recorded",
+	"目录 %s: 扫描 %d，将处理 %d 个文件，共 %s":                    "Directory %s: scanned %d, would process %d files, %s in total",
+	"合计将处理 %d 个文件，共 %s":                               "In total would process %d files, %s",
+	"第 %d 个策略组缺少 name":                                "Policy group %d has no name",
+	"策略组 %s 重复":                                       "Duplicate policy group %s",
+	"策略组 %s 的定时表达式 %q 无效: %s":                         "Policy group %s: invalid schedule %q: %s",
+	"策略组 %s 没有配置 directories":                         "Policy group %s has no directories",
+	"owner %q 不是有效的 webhook 地址":                       "owner %q is not a valid webhook URL",
+	"owner %q 应为邮箱或 http(s) webhook 地址":               "owner %q should be an email address or an http(s) webhook URL",
+	"owner %q 为邮箱，需要配置 notify.email 的 SMTP 服务器":       "owner %q is an email address and requires the notify.email SMTP server",
+	"%s: 删除 %d，失败 %d，释放 %s":                           "%s: deleted %d, failed %d, freed %s",
+	"日志目录已清理":                                         "Log directory cleaned",
+	"日志目录清理失败":                                        "Log directory cleanup failed",
+	"任务出错: %s":                                        "Run error: %s",
+	"通知目录负责人 %s: %s":                                  "Notifying directory owner %s: %s",
+	"max_files 和 target_files 不能为负数":                  "max_files and target_files must not be negative",
+	"配置了 target_files 但未配置 max_files":                 "target_files is set but max_files is not",
+	"target_files %d 不能大于 max_files %d":               "target_files %d must not be greater than max_files %d",
+	"目录 %s 有 %d 个文件，超过 max_files %d，从最旧的文件开始删除到 %d 个": "Directory %s has %d files, more than max_files %d; deleting the oldest down to %d",
+	"目录 %s 仍有 %d 个文件，其余文件不满足过滤条件、被跳过或删除失败":            "Directory %s still has %d files; the rest do not match the filters, were skipped or failed to delete",
+	"超过 max_files 删除文件数: %d\n":                        "Files deleted over max_files: %d\n",
+	"配置了 max_files %d，文件数超过该值时未到期的文件也可能按时间从旧到新删除": "max_files %d is set; when the directory has more files, files may be deleted oldest first even if not expired",
+	"目录释放空间将超过 %s":                                         "the directory would free more than %s",
+	"任务释放空间将超过 %s":                                         "the run would free more than %s",
+	"跳过 %s：%s（max_bytes_per_run），停止任务":                     "Skipping %s: %s (max_bytes_per_run), stopping the run",
+	"达到 max_bytes_per_run，在目录 %s 处停止，%d 个目录未处理":            "Reached max_bytes_per_run, stopped at directory %s, %d directories not processed",
+	"超过 max_bytes_per_run，任务已停止: %s":                       "Exceeded max_bytes_per_run, run stopped: %s",
+	"清理任务达到 max_bytes_per_run 已停止":                         "Cleanup run stopped at max_bytes_per_run",
+	"本次任务已释放 %s，%s，其余到期文件未处理，请检查保留期配置":                     "This run freed %s; %s, remaining expired files were not processed, please check the retention settings",
+	"目录 %s 处于 canary 模式，容器日志只跳过，不统计":                       "directory %s is in canary mode; container logs are skipped without counting",
+	"canary：%s 到期，将执行 %s（%s）":                              "canary: %s would be processed by %s (%s)",
+	"canary：%s 到期，将执行 %s":                                  "canary: %s would be processed by %s",
+	"目录 %s 处于 canary 模式，未处理任何文件：到期 %d 个，共 %s":              "directory %s is in canary mode, no files processed: %d expired, %s in total",
+	"目录 %s: canary 模式，到期 %d 个（%s），未处理":                     "Directory %s: canary mode, %d expired (%s), not processed",
+	"读取 canary 状态失败: %s":                                   "failed to read canary state: %s",
+	"保存 canary 状态失败: %s":                                   "failed to save canary state: %s",
+	"新目录 %s 进入 canary 模式：至少 %d 次任务只报告不处理，之后需确认才开始删除":       "new directory %s enters canary mode: report only for at least %d runs, then deletion starts after confirmation",
+	"首次启用 canary，现有的 %d 个目录视为已确认":                          "canary enabled for the first time; the %d existing directories are treated as confirmed",
+	"目录 %s 已完成 %d 次 canary 任务，确认无误后执行 ctl confirm %s 开始处理": "directory %s has completed %d canary runs; run ctl confirm %s once verified to start processing",
+	"未启用 canary":          "canary is not enabled",
+	"目录 %s 不在 canary 模式中": "directory %s is not in canary mode",
+	"目录 %s 只完成了 %d 次 canary 任务，至少需要 %d 次":                                              "directory %s has completed only %d canary runs, at least %d required",
+	"目录 %s 已确认，之后的任务开始处理其中的文件":                                                         "directory %s confirmed; later runs will process its files",
+	"目录 %s 处于保留（hold）状态，跳过":                                                            "directory %s is on hold, skipped",
+	"目录 %s: 处于保留状态，未处理":                                                                "Directory %s: on hold, not processed",
+	"读取目录保留状态失败: %s":                                                                   "failed to read directory holds: %s",
+	"目录 %s 处于保留状态（自 %s）":                                                               "directory %s is on hold (since %s)",
+	"目录 %q 应为绝对路径":                                                                     "directory %q must be an absolute path",
+	"目录 %s 未处于保留状态":                                                                    "directory %s is not on hold",
+	"目录 %s 已设置保留，暂停处理其中的文件: %s":                                                        "directory %s put on hold, its files will not be processed: %s",
+	"目录 %s 已解除保留":                                                                      "hold released for directory %s",
+	"未知命令 %q，可选 trigger、status、pause、resume、reload、holds、hold、release、confirm":         "unknown command %q; valid commands are trigger, status, pause, resume, reload, holds, hold, release, confirm",
+	"用法: ctl trigger|status|pause|resume|reload|holds，或 ctl hold|release|confirm <目录>": "usage: ctl trigger|status|pause|resume|reload|holds, or ctl hold|release|confirm <directory>",
+	"时间 %q 无效，格式为 2006-01-02、2006-01-02 15:04 或 RFC3339":                               "invalid time %q; use 2006-01-02, 2006-01-02 15:04 or RFC3339",
+	"需要配置 from 或 cron 之一":                                                              "exactly one of from or cron is required",
+	"cron %q 无效: %s":                                                                   "invalid cron %q: %s",
+	"cron 例外期需要配置 duration":                                                            "a cron freeze window requires duration",
+	"to %q 早于 from %q":                                                                 "to %q is before from %q",
+	"保留例外期 %d: %w":                                                                     "freeze window %d: %w",
+	"处于保留例外期 %s，本次任务不处理任何文件":                                                           "in freeze window %s; this run does not process any files",
+	"处于保留例外期 %s，未处理任何文件":                                                               "In freeze window %s; no files were processed",
+	"log_matches %q 无效，可选 none、summary、full":                                           "invalid log_matches %q; valid values are none, summary, full",
+	"合并配置片段: %s":                                                                       "Merged config fragment: %s",
+	"读取配置片段 %s 失败: %s":                                                                 "failed to read config fragment %s: %s",
+	"配置片段 %s 中不能设置 %s，只能包含 %s":                                                         "config fragment %s cannot set %s; only %s are allowed",
+	"配置片段 %s 中的 %s 应为列表":                                                               "%[2]s in config fragment %[1]s must be a list",
+	"主配置中的 %s 应为列表":                                                                    "%s in the main config must be a list",
+	"跳过 %s：文件被进程 %s（PID %d）打开":                                                         "skipped %s: file is open by process %s (PID %d)",
+	"跳过（被保护进程打开）文件数: %d\n":                                                             "Files skipped (open by protected process): %d\n",
+	"文件被进程 %s（PID %d）打开，在 protected_processes 中，本次跳过":                                  "file is open by process %s (PID %d), which is in protected_processes; skipped this run",
+	"负载 %.2f > %.2f":                                                                   "load %.2f > %.2f",
+	"CPU %.0f%% > %.0f%%":                                                              "CPU %.0f%% > %.0f%%",
+	"磁盘队列 %.1f > %.1f":                                                                 "disk queue %.1f > %.1f",
+	"获取主机负载失败，不推迟任务: %s":                                                               "failed to read host load, not deferring the run: %s",
+	"主机负载仍然过高（%s），已推迟 %d 次，开始执行":                                                       "host load still high (%s) after deferring %d times, starting anyway",
+	"主机负载过高（%s），%s 后重新检查（第 %d/%d 次推迟）":                                                 "host load high (%s), checking again in %s (deferral %d/%d)",
+	"watch 需要配置 watch_max_files 或 watch_max_size，未启用监视":                                "watch requires watch_max_files or watch_max_size, not watching",
+	"启动目录监视失败: %s":                                                                     "failed to start directory watch: %s",
+	"监视目录 %s 失败: %s":                                                                   "failed to watch directory %s: %s",
+	"监视 %d 个目录，超过阈值时立即清理":                                                              "watching %d directories, cleaning immediately when over threshold",
+	"目录监视出错: %s":                                                                       "directory watch error: %s",
+	"目录 %s 有 %d 个文件、共 %s，超过 watch 阈值，立即清理该目录":                                          "directory %s has %d files totalling %s, over the watch threshold, cleaning it now",
+	"监视触发的任务":                                                                          "watch-triggered run",
+	"读取磁盘空间记录失败: %s":                                                                   "failed to read disk space samples: %s",
+	"保存磁盘空间记录失败: %s":                                                                   "failed to save disk space samples: %s",
+	"磁盘 %s 按当前速度预计约 %.1f 天后（%s）写满：剩余 %s / %s，每天减少 %s；清理目录: %s": "disk %s is projected to fill in about %.1f days (%s) at the current rate: %s / %s free, shrinking %s per day; directories: %s",
+	"磁盘预计即将写满":                                                   "Disk projected to fill up soon",
+	"降低进程优先级失败: %s":                                              "Failed to lower process priority: %s",
+	"恢复进程优先级失败: %s":                                              "Failed to restore process priority: %s",
+	"定时表达式 %q 无效: %s":                                            "invalid schedule %q: %s",
+	"HTTP 接口监听 %s":                                               "HTTP API listening on %s",
+	"HTTP 接口启动失败: %s":                                            "HTTP API failed: %s",
+	"time %q 无效: %w":                                             "invalid time %q: %w",
+	"计划任务的重复间隔须在 1 分钟到 31 天之间: %s":                               "the scheduled task repeat interval must be between 1 minute and 31 days: %s",
+	"无法转换为计划任务的定时表达式: %s":                                        "schedule cannot be converted to a scheduled task: %s",
+	"计划任务按本机时区执行，不支持 CRON_TZ/TZ: %s":                             "scheduled tasks run in the local time zone, CRON_TZ/TZ is not supported: %s",
+	"计划任务不支持每分钟多次执行: %s":                                         "scheduled tasks cannot run more than once a minute: %s",
+	"定时表达式 %s 需要 %d 个触发器，超过计划任务的上限 %d":                           "schedule %s needs %d triggers, more than the scheduled task limit of %d",
+	"计划任务每次清理所有目录，不支持目录单独配置 time: %s":                            "a scheduled task cleans all directories at once, per-directory time is not supported: %s",
+	"已删除计划任务 %s":                                                 "deleted scheduled task %s",
+	"未知的 schedule-task 命令 %q，可选 install、uninstall":               "unknown schedule-task command %q, expected install or uninstall",
+	"已注册计划任务 %s，定时: %s":                                          "registered scheduled task %s, schedule: %s",
+	"schtasks 执行失败: %s: %s":                                      "schtasks failed: %s: %s",
+	"无法转换为 systemd 定时器的定时表达式: %s":                                "schedule cannot be converted to a systemd timer: %s",
+	"定时器模式每次清理所有目录，不支持目录单独配置 time: %s":                           "timer mode cleans all directories at once, per-directory time is not supported: %s",
+	"执行 systemctl daemon-reload && systemctl enable --now %s 启用": "run systemctl daemon-reload && systemctl enable --now %s to enable",
+	"容器模式：不经过服务管理器，日志以 JSON 输出到标准输出，收到 SIGTERM 后取消任务并退出；也可通过环境变量 CLEANLOG_CONTAINER=true 启用": "container mode: no service manager, JSON logs on stdout, cancel the run and exit on SIGTERM; also enabled by CLEANLOG_CONTAINER=true",
+	"以容器模式运行":                                    "running in container mode",
+	"收到 %s，停止服务":                                 "received %s, stopping",
+	"等待任务结束超时，直接退出":                              "timed out waiting for the run to finish, exiting",
+	"清理任务失败的文件过多":                                "too many files failed in the cleanup run",
+	"导出 OpenTelemetry 数据失败: %s":                  "failed to export OpenTelemetry data: %s",
+	"不支持的数据库驱动 %q":                               "unsupported database driver %q",
+	"数据库驱动 %q 未编译进程序":                            "database driver %q is not compiled into this binary",
+	"缺少 dsn 配置":                                  "missing dsn",
+	"表名或列名 %q.%q 无效":                             "invalid table or column name %q.%q",
+	"column_type %q 无效，可选 datetime、unix、unix_ms": "invalid column_type %q, expected datetime, unix or unix_ms",
+	"清理数据库表 %s 失败（已删除 %d 行）: %s":                 "failed to clean database table %s (%d rows deleted): %s",
+	"数据库表 %s: 删除 %d 行":                           "database table %s: deleted %d rows",
+	"数据库表 %s: 删除 %s 早于 %s 的行":                    "database table %s: deleting rows with %s before %s",
+	"数据库表 %s: %w":                                "database table %s: %w",
+	"action %q 无效，可选 delete、close":               "invalid action %q, expected delete or close",
+	"url %q 无效":                                  "invalid url %q",
+	"index %q 应包含且只包含一个表示日期的 *":                  "index %q must contain exactly one * marking the date",
+	"Elasticsearch 索引 %s: %w":                    "Elasticsearch index %s: %w",
+	"清理 Elasticsearch 索引 %s 失败（已处理 %d 个）: %s":    "failed to clean Elasticsearch index %s (%d processed): %s",
+	"Elasticsearch 索引 %s: dry_run，到期 %d 个: %s":   "Elasticsearch index %s: dry_run, %d expired: %s",
+	"Elasticsearch 索引 %s: dry_run，到期 %d 个":       "Elasticsearch index %s: dry_run, %d expired",
+	"Elasticsearch 索引 %s: %s %d 个，释放 %s":         "Elasticsearch index %s: %s %d, freed %s",
+	"Elasticsearch 索引 %s 的名称中没有 %s 格式的日期，跳过":     "Elasticsearch index %s has no %s date in its name, skipped",
+	"Elasticsearch 索引 %s: %s":                    "Elasticsearch index %s: %s",
+	"schedule-task 只支持 Windows":                  "schedule-task is only supported on Windows",
+	"RPC 接口监听 %s":                                "RPC API listening on %s",
+	"RPC 接口启动失败: %s":                             "RPC API failed: %s",
+	"RPC 接口接受连接失败: %s":                           "RPC API accept failed: %s",
+	"管理通道监听 %s":                                  "Admin channel listening on %s",
+	"管理通道启动失败: %s":                               "Admin channel failed: %s",
+	"管理通道接受连接失败: %s":                             "Admin channel accept failed: %s",
+	"定时任务已暂停":                                    "Scheduled runs paused",
+	"定时任务已恢复":                                    "Scheduled runs resumed",
+	"定时任务已暂停，跳过":                                 "Scheduled runs are paused, skipped",
+	"上一次任务仍在运行，排队等待":                             "Previous run still in progress, queued",
+	"取消正在运行的任务":                                  "Cancelling the run in progress",
+	"overlap %q 无效，可选 skip、queue、cancel":         "invalid overlap %q, expected skip, queue or cancel",
+	"上一次任务仍在运行，跳过":                               "Previous run still in progress, skipped",
+	"重新加载配置失败，继续使用原配置: %s":                       "Reloading the configuration failed, keeping the current one: %s",
+	"收到 SIGHUP，重新加载配置":                           "Received SIGHUP, reloading configuration",
+	"配置已重新加载":                                    "Configuration reloaded",
+	"读取任务历史失败: %s":                               "Failed to read run history: %s",
+	"保存任务历史失败: %s":                               "Failed to save run history: %s",
+	"清理任务历史失败: %s":                               "Failed to prune run history: %s",
+	"未启用任务历史":                                    "run history is not enabled",
+	"用法: history [条数]":                           "usage: history [count]",
+	"任务 ID\t开始时间\t耗时\t扫描\t删除\t失败\t跳过\t释放\t错误":    "Run ID\tStart\tDuration\tScanned\tDeleted\tFailed\tSkipped\tFreed\tError",
+	"任务 %s": "Run %s",
+	"读取 logging 配置失败，使用默认设置: %s": "Failed to read logging settings, using defaults: %s",
+	"pprof 监听 %s":            "pprof listening on %s",
+	"pprof 启动失败: %s":         "pprof failed: %s",
+	"下次执行时间: %s":             "Next run: %s",
+	"等待 %s 后开始执行":            "Waiting %s before the first run",
+	"开始执行":                   "Starting",
+	"服务创建！":                  "Service created",
+	"有参数：":                   "Argument: ",
+	"开始加载配置！":                "Loading configuration",
+	"配置加载完成！":                "Configuration loaded",
+	"当前文件夹路径：":               "Executable path: ",
+	"配置文件: %s，相对路径相对于 %s":    "Config file: %s, relative paths are resolved against %s",
+	"配置文件中没有名为 %q 的 profile": "no profile named %q in the config file",
+	"使用配置 profile: %s":       "Using config profile: %s",
+	"使用配置文件中 profiles 下的指定配置（如 prod），也可通过环境变量 CLEANLOG_PROFILE 指定": "use the named entry under profiles in the config file (e.g. prod); can also be set with CLEANLOG_PROFILE",
+	"配置信息读取结果如下：":                              "Configuration:",
+	"加载配置文件时发生错误: %s":                          "Failed to load configuration: %s",
+	"清理任务失败: %s":                               "Cleanup run failed: %s",
+	"log_level %q 无效，可选 debug、info、warn、error": "invalid log_level %q, expected debug, info, warn or error",
+	"不支持的语言 %q，可选 zh、en":                       "unsupported language %q, expected zh or en",
+	"Directory: %s 保留策略: %v 扩展名: %v 排除扩展名: %v": "Directory: %s policy: %v extensions: %v excluded extensions: %v",
+
+	// 配置校验
+	"目录 %s: %w":                         "directory %s: %w",
+	"目录 %s 的 action %q 无效":              "directory %s: invalid action %q",
+	"目录 %s 的第 %d 个 tier 的 action %q 无效": "directory %s: tier %d has invalid action %q",
+	"目录 %s 的第 %d 个 tier: %w":            "directory %s: tier %d: %w",
+	"未知的过滤器类型 %q":                       "unknown filter type %q",
+	"未知的预设 %q，可选 %s":                    "unknown preset %q, available: %s",
+	"预设 %s 在本系统上没有默认目录，请配置 path":        "preset %s has no default directory on this system, set path",
+	"size 过滤器的 min 大于 max":              "size filter min is greater than max",
+	"owner/group 过滤器缺少 users/groups":    "owner/group filter requires users/groups",
+	"找不到用户或组 %q: %w":                    "unknown user or group %q: %w",
+	"owner/group 过滤器仅在 Unix 上支持":        "owner/group filters are only supported on Unix",
+	"archive 缺少 archive_dir 或 upload":   "archive requires archive_dir or upload",
+	"不支持的上传地址类型: %s":                    "unsupported upload scheme: %s",
+	"docker.action %q 无效":               "invalid docker.action %q",
+	"ftp.tls %q 无效":                     "invalid ftp.tls %q",
+	"name_date 缺少 time_format":          "name_date requires time_format",
+	"name_date 正则 %q 无效: %w":            "invalid name_date regex %q: %w",
+	"无效的大小: %q":                         "invalid size: %q",
+
+	// 保留策略说明
+	"%s 后%s":         "%[2]s after %[1]s",
+	"%s 后归档到 %s":     "archive to %[2]s after %[1]s",
+	"%s 后上传到 %s":     "upload to %[2]s after %[1]s",
+	"%s 后覆写 %d 次并删除": "shred (%[2]d passes) after %[1]s",
+	"delete_mode %q 无效，可选 unlink、shred、rename、trash": "invalid delete_mode %q, expected unlink, shred, rename or trash",
+	"目录 %s: 远程目录不支持 delete_mode: %s":                 "directory %s: delete_mode %s is not supported for remote directories",
+	"%s 后截断到 %s": "truncate to %[2]s after %[1]s",
+	"%s 后执行 %s":  "%[2]s after %[1]s",
+	"压缩":         "compress",
+	"删除":         "delete",
+	"清空":         "truncate",
+
+	// 清理过程
+	"---------------   执行一次任务！ ---------------":      "---------------   Cleanup run   ---------------",
+	"任务超过最长运行时间 %s，在目录 %s 处停止，%d 个目录未处理":             "Run exceeded max_run_duration %s, stopped in directory %s, %d directories not processed",
+	"任务超过最长运行时间 %s，已停止":                              "Run exceeded max_run_duration %s, stopped",
+	"任务超过最长运行时间 %s":                                  "run exceeded max_run_duration %s",
+	"目录通配符 %s 无效: %s":                                "Invalid directory pattern %s: %s",
+	"目录通配符 %s 没有匹配的目录":                               "Directory pattern %s matched no directories",
+	"目录通配符 %s 匹配 %d 个目录":                             "Directory pattern %s matched %d directories",
+	"读取标记列表 %s 失败: %s":                               "Failed to read mark list %s: %s",
+	"保存标记列表 %s 失败: %s":                               "Failed to save mark list %s: %s",
+	"标记 %s，下次任务仍满足条件且未变化时再%s":                        "Marked %s, will %s on the next run if it still matches and is unchanged",
+	"标记待下次删除文件数: %d\n":                               "Files marked for the next run: %d\n",
+	"跳过（多个硬链接）文件数: %d\n":                             "Files skipped (multiple hard links): %d\n",
+	"仍有其他硬链接、未释放空间的文件数: %d\n":                        "Files removed with other hard links remaining (no space freed): %d\n",
+	"计算 %s 的校验和失败: %s":                               "Failed to compute checksum of %s: %s",
+	"删除重复文件 %s（与 %s 相同）":                             "Deleted duplicate %s (same as %s)",
+	"删除重复文件数: %d\n":                                  "Duplicate files deleted: %d\n",
+	"任务已取消: %s":                                      "Run cancelled: %s",
+	"获取文件信息失败: %s":                                   "Failed to stat file: %s",
+	"%s 文件失败: %s":                                    "%s failed: %s",
+	"%d 个文件删除失败，%s 后重试（第 %d 次）":                      "%d files failed to delete, retrying in %s (attempt %d)",
+	"重试 %s 失败: %s":                                   "retry %s failed: %s",
+	"重试 %s %s 成功（释放 %s）":                             "Retried %s %s (freed %s)",
+	"读取失败列表 %s 失败: %s":                               "Failed to read failure list %s: %s",
+	"保存失败列表 %s 失败: %s":                               "Failed to save failure list %s: %s",
+	"失败记录 %s 已不存在":                                   "Failed file %s no longer exists",
+	"文件 %s 已连续 %d 次删除失败，不再重试: %s":                    "File %s failed to delete %d runs in a row, giving up: %s",
+	"文件 %s 第 %d 次删除失败: %s":                           "File %s failed to delete (attempt %d): %s",
+	"归档副本 %s 校验失败：%s != %s":                          "checksum mismatch for archived copy %s: %s != %s",
+	"目录 %s 超过 %d 项（max_entries_per_dir），本次只处理前 %d 项": "directory %s has more than %d entries (max_entries_per_dir), only the first %d are processed this run",
+	"目录 %s 超过 %d 层（max_depth），统计的释放空间不完整":            "directory %s is deeper than %d levels (max_depth), freed space is incomplete",
+	"跳过 %s：超过 %d 层（max_depth），无法确认其中的文件都已到期":         "skipping %s: deeper than %d levels (max_depth), cannot confirm all files have expired",
+	"uninstall 时同时删除本实例的日志、任务历史和状态文件，删除前会列出并确认": "with uninstall, also delete this instance's logs, run history and state files after listing them and asking for confirmation",
+	"与 --purge 一起使用时不再确认": "with --purge, do not ask for confirmation",
+	"没有需要清除的文件":           "nothing to purge",
+	"将删除以下文件:":            "The following files will be deleted:",
+	"确认删除？[y/N] ":         "Delete them? [y/N] ",
+	"已取消清除":               "purge cancelled",
+	"部分文件删除失败: %s":        "some files could not be deleted: %s",
+	"已删除 %d 个文件":          "deleted %d files",
+	"文件不在任何配置的目录中（子目录中的文件不处理）": "the file is not in any configured directory (files in subdirectories are not processed)",
+	"所属目录: %s":    "directory: %s",
+	"受 %s 保护，不处理": "protected by %s, not processed",
+	"文件名不满足 extensions、exclude_extensions 或过滤器，不处理": "the file name does not match extensions, exclude_extensions or filters, not processed",
+	"是目录，只在 date_dirs、subtrees 中按整个目录处理":            "it is a directory, only handled as a whole by date_dirs and subtrees",
+	"判断年龄所用的时间: %s，大小: %s":                          "time used for age: %s, size: %s",
+	"不满足目录的过滤器，不处理":                                 "does not match the directory filters, not processed",
+	"%s: 未到期（需早于 %s）":                               "%s: not due (must be older than %s)",
+	"%s: 已到期，但不满足该 tier 的过滤器":                       "%s: due, but does not match the filters of this tier",
+	"%s: 已到期":             "%s: due",
+	"没有到期的策略，不处理":         "no policy is due, not processed",
+	"修改时间晚于静默期起点 %s，本次跳过": "modified after the quiet period start %s, skipped this run",
+	"配置了 target_size %s，目录总大小降到该值以下后剩余文件会保留": "target_size %s is set, remaining files are kept once the directory is below it",
+	"文件正被其他进程使用，本次跳过":                        "the file is in use by another process, skipped this run",
+	"开启了两阶段删除，首次满足条件时只标记，下次任务才处理":            "two-phase deletion is on: the file is only marked the first time and processed on the next run",
+	"下次任务将执行: %s":                            "the next run will apply: %s",
+	"配置文件: %s":                               "config file: %s",
+	"按当前时间 %s 计算的处理阈值":                       "thresholds computed for the current time %s",
+	"%s：修改时间早于 %s 的文件":                       "%s: files older than %s",
+	"静默期：修改时间晚于 %s 的文件不处理":                   "quiet period: files modified after %s are not processed",
+	"目录总大小降到 %s 以下后停止":                       "stops once the directory is below %s",
+	"读取 token_file 失败: %s":                   "failed to read token_file: %s",
+	"监听非本机地址 %s 时必须配置 token 或 token_file":    "token or token_file is required when listening on the non-loopback address %s",
+	"tls_cert 和 tls_key 需要同时配置":              "tls_cert and tls_key must be set together",
+	"加载 TLS 证书失败: %s":                        "failed to load TLS certificate: %s",
+	"令牌无效":                                   "invalid token",
+	"%s 已存在，不覆盖":                             "%s already exists, not overwriting",
+	"清理任务":                                   "cleanup run",
+	"调度器":                                    "scheduler",
+	"定时任务":                                   "scheduled job",
+	"%s 发生 panic: %v":                        "panic in %s: %v",
+	"清理服务发生 panic":                           "Cleanup service panicked",
+	"调度器意外退出，定时任务已停止":                        "scheduler exited unexpectedly, scheduled runs have stopped",
+	"调度器意外退出，%s 后重启":                         "scheduler exited unexpectedly, restarting in %s",
+	"版本: %s":                                 "Version: %s",
+	"不支持远程目录和容器日志":                           "remote directories and container logs are not supported",
+	"不支持容器日志":                                "container logs are not supported",
+	"目录\t目录项\t文件\t子目录\t到期\t大小\t耗时\t项/秒\tstat p50\tp90\tp99\t最大\t错误": "DIRECTORY\tENTRIES\tFILES\tDIRS\tEXPIRED\tSIZE\tDURATION\tENTRIES/S\tSTAT P50\tP90\tP99\tMAX\tERROR",
+	"目录 %s 进度: 已扫描 %d，已删除 %d，已释放 %s，用时 %s":                          "directory %s progress: scanned %d, deleted %d, freed %s, elapsed %s",
+	"跳过 %s：其中有挂载点或联接点（cross_devices 未开启）":                           "skipping %s: it contains a mount point or junction (cross_devices is off)",
+	"删除子目录 %s 失败: %s":            "failed to delete subdirectory %s: %s",
+	"保留 %s：最新文件修改于 %s":           "keeping %s: newest file modified at %s",
+	"删除子目录 %s（最新文件修改于 %s，释放 %s）": "deleted subdirectory %s (newest file modified at %s, freed %s)",
+	"删除日期目录失败: %s":               "Failed to remove date directory: %s",
+	"查找容器日志失败: %s":               "Failed to find container logs: %s",
+	"轮转容器日志失败: %s":               "Failed to rotate container log: %s",
+	"截断容器日志失败: %s":               "Failed to truncate container log: %s",
+	"连接共享目录 %s 失败: %s":           "Failed to connect share %s: %s",
+	"连接共享 %s 失败（第 %d 次）: %s":     "Failed to connect share %s (attempt %d): %s",
+	"读取共享目录 %s 失败，%s 后重试: %s":    "Failed to read share %s, retrying in %s: %s",
+	"读取凭据 %s 失败: %w":             "failed to read credential %s: %w",
+	"网络共享凭据仅在 Windows 上支持":       "share credentials are only supported on Windows",
+
+	// debug 级别的逐文件日志
+	"跳过 %s：不满足过滤条件":                "skip %s: filtered out",
+	"跳过 %s：受 %s 保护":                "skip %s: protected by %s",
+	"读取 %s 失败: %s":                 "Failed to read %s: %s",
+	"跳过 %s：未到期":                    "skip %s: not expired",
+	"跳过 %s：静默期内修改过":                "skip %s: modified within quiet period",
+	"跳过 %s：有 %d 个硬链接":              "skip %s: has %d hard links",
+	"%s 仍有其他硬链接，不计入释放空间":           "%s still has other hard links, not counted as freed",
+	"跳过 %s：文件正在使用":                 "skip %s: file in use",
+	"跳过 %s：远程目标只支持删除":              "skip %s: remote targets only support delete",
+	"跳过 %s：无需 %s":                  "skip %s: no %s needed",
+	"保留 %s：目录大小已降到 target_size 以下": "keep %s: directory is below target_size",
+	"%s %s（释放 %s）":                 "%s %s (freed %s)",
+	"删除日期目录 %s（释放 %s）":             "delete date directory %s (freed %s)",
+
+	// 目录检查
+	"missing_dir %q 无效，可选 ignore、warn、error、create": "invalid missing_dir %q, expected ignore, warn, error or create",
+	"%s 不是目录":        "%s is not a directory",
+	"无法访问目录 %s: %s":  "Cannot access directory %s: %s",
+	"目录 %s 不存在，跳过":   "Directory %s does not exist, skipped",
+	"目录 %s 不存在":      "Directory %s does not exist",
+	"创建目录 %s 失败: %s": "Failed to create directory %s: %s",
+	"目录 %s 不存在，已创建":  "Directory %s did not exist, created",
+	"读取目录 %s 失败: %s": "Failed to read directory %s: %s",
+
+	// 远程目标
+	"远程地址 %s 无效: %w":           "invalid remote address %s: %w",
+	"不支持的远程地址类型: %s":           "unsupported remote scheme: %s",
+	"打开远程目标失败: %w":             "failed to open remote target: %w",
+	"列出文件失败: %w":               "failed to list files: %w",
+	"远程目录 %s: %s":              "Remote directory %s: %s",
+	"删除远程文件失败: %s: %s":         "Failed to delete remote file %s: %s",
+	"删除后文件仍然存在":                "file still exists after delete",
+	"无法解析 sftp 输出: %q":         "cannot parse sftp output: %q",
+	"FTP 登录失败: %d":             "FTP login failed: %d",
+	"无法解析 PASV 响应: %s":         "cannot parse PASV response: %s",
+	"S3 endpoint %s 无效: %w":    "invalid S3 endpoint %s: %w",
+	"OSS 目标缺少 oss.endpoint 配置": "OSS target requires oss.endpoint",
+	"OSS endpoint %s 无效: %w":   "invalid OSS endpoint %s: %w",
+	"上传 %s 失败: %w":             "upload %s failed: %w",
+	"上传 %s 失败: %s":             "Upload %s failed: %s",
+	"上传 %s 失败，已移入待上传目录 %s: %w": "upload %s failed, moved to backlog %s: %w",
+	"sftp 上传只支持本地文件":           "sftp upload only supports local files",
+	"上传后远程文件大小 %d 与本地 %d 不一致":  "uploaded size %d does not match local size %d",
+	"上传后未找到远程文件 %s":            "uploaded file %s not found",
+	"OSS 未返回删除成功":              "OSS did not confirm the delete",
+
+	// 钩子
+	"执行 %s 钩子: %s":                     "Running %s hook: %s",
+	"%s 钩子输出: %s":                      "%s hook output: %s",
+	"执行超时（%s）":                         "timed out after %s",
+	"pre_run 钩子执行失败，跳过本次任务: %s":        "pre_run hook failed, skipping this run: %s",
+	"pre_run 钩子执行失败: %w":               "pre_run hook failed: %w",
+	"post_run 钩子执行失败: %s":              "post_run hook failed: %s",
+	"目录 %s 的 pre_run 钩子执行失败，跳过该目录: %s": "pre_run hook for %s failed, skipping directory: %s",
+	"目录 %s 的 post_run 钩子执行失败: %s":      "post_run hook for %s failed: %s",
+
+	// 统计报告
+	"目录 %s: 扫描 %d，匹配 %d，删除 %d，跳过 %d，失败 %d，释放 %s": "Directory %s: scanned %d, matched %d, deleted %d, skipped %d, failed %d, freed %s",
+	"成功删除文件数: %d\n":           "Files deleted: %d\n",
+	"临时错误未完成文件数: %d\n":        "Files not processed due to transient errors: %d\n",
+	"%s %s 遇到临时错误，%s 后重试: %s": "%s %s hit a transient error, retrying in %s: %s",
+	"%s 文件失败（临时错误）: %s":       "%s failed (transient error): %s",
+	"删除文件失败数: %d\n":           "Failures: %d\n",
+	"压缩文件数: %d\n":             "Files compressed: %d\n",
+	"归档文件数: %d\n":             "Files archived: %d\n",
+	"截断文件数: %d\n":             "Files truncated: %d\n",
+	"跳过（使用中）文件数: %d\n":        "Skipped (in use): %d\n",
+	"跳过（静默期内修改）文件数: %d\n":     "Skipped (modified within quiet period): %d\n",
+	"删除日期目录数: %d\n":           "Date directories removed: %d\n",
+	"达到目标后保留的候选文件数: %d\n":     "Candidates kept after reaching target size: %d\n",
+	"%s 文件数: %d\n":            "Files %s: %d\n",
+	"释放空间: %s\n":              "Space freed: %s\n",
+}
@@ -0,0 +1,68 @@
+// Package i18n 日志、报告和命令行输出的多语言支持。
+//
+// 代码中的消息以中文书写并直接作为键，非中文语言在消息表中查找译文，
+// 找不到时原样输出中文。
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	Chinese = "zh"
+	English = "en"
+)
+
+// languageEnv 配置文件加载前（如安装、启停服务时）使用的语言
+const languageEnv = "CLEANLOG_LANGUAGE"
+
+var catalogs = map[string]map[string]string{
+	English: en,
+}
+
+var current atomic.Value
+
+func init() {
+	current.Store(Chinese)
+	SetLanguage(os.Getenv(languageEnv))
+}
+
+// SetLanguage 设置输出语言，为空时使用中文
+func SetLanguage(lang string) error {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	switch lang {
+	case "", Chinese:
+		current.Store(Chinese)
+	case English:
+		current.Store(English)
+	default:
+		return fmt.Errorf(T("不支持的语言 %q，可选 zh、en"), lang)
+	}
+	return nil
+}
+
+// Language 返回当前输出语言
+func Language() string {
+	return current.Load().(string)
+}
+
+// T 返回消息在当前语言下的文本
+func T(msg string) string {
+	if s, ok := catalogs[Language()][msg]; ok {
+		return s
+	}
+	return msg
+}
+
+// Sprintf 以翻译后的格式串格式化
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Errorf 以翻译后的格式串创建错误，支持 %w
+func Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(T(format), args...)
+}
@@ -0,0 +1,131 @@
+// Package state 读写服务和清理任务的 JSON 状态文件：失败列表、两阶段删除的标记、目录保留状态、canary 状态、
+// 磁盘空间记录等。写入时先写同目录下的临时文件并同步到磁盘，再原子替换原文件；内容附带 SHA-256，
+// 读取时校验，断电或写入中断留下的半截文件会被识别为损坏，而不是被当作空状态或解析出错误的内容
+package state
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/spf13/afero"
+)
+
+// version 状态文件格式的版本
+const version = 1
+
+// ErrCorrupt 状态文件内容与校验和不符或无法解析
+var ErrCorrupt = errors.New("state file corrupt")
+
+// envelope 状态文件的内容：data 为实际的状态，sha256 为 data 紧凑格式的校验和
+type envelope struct {
+	Version int             `json:"version"`
+	SHA256  string          `json:"sha256"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Write 以 JSON 保存 v，写入过程中断时原文件保持不变
+func Write(fsys afero.Fs, path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(envelope{Version: version, SHA256: checksum(data), Data: data}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(fsys, path, out)
+}
+
+// WriteFile 原子替换 path 的内容：写入 path.tmp 并同步到磁盘后改名，再同步所在目录，
+// 保证断电后 path 要么是原来的内容，要么是完整的新内容
+func WriteFile(fsys afero.Fs, path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := fsys.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := fsys.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fsys.Remove(tmp)
+		return err
+	}
+	if err := fsys.Rename(tmp, path); err != nil {
+		fsys.Remove(tmp)
+		return err
+	}
+	// 改名本身也要落盘；Windows 上无法同步目录，NTFS 的元数据日志保证改名不会半途丢失
+	if d, err := fsys.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// Read 读取 Write 保存的状态到 v。文件不存在时返回的错误满足 os.IsNotExist；
+// 内容损坏时把文件改名为 path.corrupt 留待排查，返回包装了 ErrCorrupt 的错误。
+// 没有校验和的旧格式文件（直接保存的 JSON）同样可以读取，下次写入时转换为新格式
+func Read(fsys afero.Fs, path string, v interface{}) error {
+	raw, err := afero.ReadFile(fsys, path)
+	if err != nil {
+		return err
+	}
+	data, err := unwrap(raw)
+	if err == nil {
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		corrupt := path + ".corrupt"
+		if rerr := fsys.Rename(path, corrupt); rerr != nil {
+			return i18n.Errorf("状态文件 %s 已损坏: %s: %w", path, err, ErrCorrupt)
+		}
+		return i18n.Errorf("状态文件 %s 已损坏，已另存为 %s: %s: %w", path, corrupt, err, ErrCorrupt)
+	}
+	return nil
+}
+
+// unwrap 校验并取出 envelope 中的状态，旧格式的文件原样返回
+func unwrap(raw []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return nil, i18n.Errorf("文件为空")
+	}
+	var e envelope
+	if trimmed[0] != '{' || json.Unmarshal(trimmed, &e) != nil || e.SHA256 == "" || e.Data == nil {
+		if !json.Valid(trimmed) {
+			return nil, i18n.Errorf("不是有效的 JSON")
+		}
+		return trimmed, nil
+	}
+	if e.Version > version {
+		return nil, i18n.Errorf("格式版本 %d 高于当前程序支持的 %d", e.Version, version)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, e.Data); err != nil {
+		return nil, err
+	}
+	if checksum(compact.Bytes()) != e.SHA256 {
+		return nil, i18n.Errorf("校验和不符")
+	}
+	return compact.Bytes(), nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kardianos/service"
+)
+
+// macOS 上程序通常安装在 /usr/local/bin，日志和配置按系统惯例放在 /Library 下
+
+// defaultLogDir 服务日志和任务历史的默认目录
+func defaultLogDir() string {
+	return "/Library/Logs/cleanlogservice"
+}
+
+// configSearchPaths 未指定 --config 时依次查找 config.yml 的目录
+func configSearchPaths() []string {
+	return []string{getCurrentAbPathByExecutable(), "/Library/Application Support/cleanlogservice", "/usr/local/etc/cleanlogservice"}
+}
+
+// launchdOptions 设置 launchd 的 plist：崩溃（非 0 退出）后按 --restart-delay 重新拉起，
+// 标准输出和错误（如 panic 信息）写入 /Library/Logs/cleanlogservice
+func launchdOptions(opts service.KeyValue, restart bool, delay time.Duration) {
+	keepAlive := "<false/>"
+	if restart {
+		keepAlive = "<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>"
+	}
+	opts["LogDirectory"] = defaultLogDir()
+	opts["LaunchdConfig"] = strings.NewReplacer(
+		"{{keepAlive}}", keepAlive,
+		"{{throttle}}", fmt.Sprint(int(delay/time.Second)),
+	).Replace(launchdConfig)
+}
+
+// launchdConfig 与 kardianos/service 默认的模板基本相同，KeepAlive 改为只在异常退出时重启，
+// 并用 ThrottleInterval 控制重启间隔
+const launchdConfig = `<?xml version='1.0' encoding='UTF-8'?>
+<!DOCTYPE plist PUBLIC "-//Apple Computer//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd" >
+<plist version='1.0'>
+<dict>
+	<key>Label</key>
+	<string>{{html .Name}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{html .Path}}</string>
+		{{- range .Config.Arguments}}
+		<string>{{html .}}</string>
+		{{- end}}
+	</array>
+	{{- if .UserName}}
+	<key>UserName</key>
+	<string>{{html .UserName}}</string>
+	{{- end}}
+	{{- if .WorkingDirectory}}
+	<key>WorkingDirectory</key>
+	<string>{{html .WorkingDirectory}}</string>
+	{{- end}}
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	{{keepAlive}}
+	<key>ThrottleInterval</key>
+	<integer>{{throttle}}</integer>
+	<key>StandardOutPath</key>
+	<string>{{html .StandardOutPath}}</string>
+	<key>StandardErrorPath</key>
+	<string>{{html .StandardErrorPath}}</string>
+</dict>
+</plist>
+`
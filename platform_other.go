@@ -0,0 +1,22 @@
+//go:build !darwin
+
+package main
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/kardianos/service"
+)
+
+// defaultLogDir 服务日志和任务历史的默认目录：程序目录下的 logs
+func defaultLogDir() string {
+	return filepath.Join(getCurrentAbPathByExecutable(), "logs")
+}
+
+// configSearchPaths 未指定 --config 时查找 config.yml 的目录
+func configSearchPaths() []string {
+	return []string{getCurrentAbPathByExecutable()}
+}
+
+func launchdOptions(opts service.KeyValue, restart bool, delay time.Duration) {}
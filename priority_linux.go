@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	lowNice = 10
+
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+	ioprioClassBE    = 2
+	ioprioLowest     = ioprioClassBE<<ioprioClassShift | 7 // 相当于 ionice -c2 -n7
+)
+
+// lowerPriority 把进程的 nice 值调为 10，I/O 优先级调为 best-effort 最低级，返回的函数恢复原来的设置。
+// Linux 上优先级按线程生效，因此对进程的每个线程分别设置；恢复较高的优先级需要 root 或 CAP_SYS_NICE
+func lowerPriority() (func() error, error) {
+	pid := os.Getpid()
+	// 内核返回 20-nice
+	prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, pid)
+	if err != nil {
+		return nil, err
+	}
+	nice := 20 - prio
+	ioprio, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(pid), 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	if err := setThreadPriority(lowNice, ioprioLowest); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return setThreadPriority(nice, int(ioprio))
+	}, nil
+}
+
+// setThreadPriority 设置进程所有线程的 nice 值和 I/O 优先级
+func setThreadPriority(nice, ioprio int) error {
+	tasks, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		tid, err := strconv.Atoi(task.Name())
+		if err != nil {
+			continue
+		}
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, nice); err != nil {
+			return err
+		}
+		if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(ioprio)); errno != 0 {
+			return errno
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !windows && !linux
+
+package main
+
+import "syscall"
+
+// lowerPriority 把进程的 nice 值调为 10，返回的函数恢复原来的值；这些系统上不调整 I/O 优先级
+func lowerPriority() (func() error, error) {
+	nice, err := syscall.Getpriority(syscall.PRIO_PROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0, 10); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return syscall.Setpriority(syscall.PRIO_PROCESS, 0, nice)
+	}, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import "golang.org/x/sys/windows"
+
+// lowerPriority 把进程降为低于正常的 CPU 优先级，并进入后台模式（I/O 和内存优先级降为最低），
+// 返回的函数恢复原来的优先级
+func lowerPriority() (func() error, error) {
+	process := windows.CurrentProcess()
+	class, err := windows.GetPriorityClass(process)
+	if err != nil {
+		return nil, err
+	}
+	if err := windows.SetPriorityClass(process, windows.BELOW_NORMAL_PRIORITY_CLASS); err != nil {
+		return nil, err
+	}
+	if err := windows.SetPriorityClass(process, windows.PROCESS_MODE_BACKGROUND_BEGIN); err != nil {
+		windows.SetPriorityClass(process, class)
+		return nil, err
+	}
+	return func() error {
+		if err := windows.SetPriorityClass(process, windows.PROCESS_MODE_BACKGROUND_END); err != nil {
+			return err
+		}
+		return windows.SetPriorityClass(process, class)
+	}, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/spf13/viper"
+)
+
+// configProfile 当前使用的配置 profile，来自 --profile 或环境变量 CLEANLOG_PROFILE
+var configProfile string
+
+// applyProfile 用 profiles.<name> 下的配置覆盖顶层配置。嵌套的配置按项合并，
+// directories 等列表整体替换，同一份配置文件可以在开发、测试、生产环境中使用不同的目录和定时
+func applyProfile(v *viper.Viper, name string) error {
+	if name == "" {
+		return nil
+	}
+	sub := v.Sub("profiles." + name)
+	if sub == nil {
+		return i18n.Errorf("配置文件中没有名为 %q 的 profile", name)
+	}
+	return v.MergeConfigMap(sub.AllSettings())
+}
+
+// profileFromEnv 未指定 --profile 时使用环境变量 CLEANLOG_PROFILE
+func profileFromEnv(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return os.Getenv("CLEANLOG_PROFILE")
+}
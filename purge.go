@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+// purgeFiles 返回 uninstall --purge 要删除的文件：本实例的日志（含轮转的备份）、任务历史、
+// 失败列表、两阶段删除状态和管理套接字，只返回实际存在的文件
+func purgeFiles(configFilePath, name string, logging LoggingConfig) []string {
+	var files []string
+	logFile := newLogFile(logging, name).Filename
+	ext := filepath.Ext(logFile)
+	stem := strings.TrimSuffix(logFile, ext)
+	files = append(files, logFile)
+	// lumberjack 的备份文件名形如 cleanlog-2024-01-02T15-04-05.000.log，压缩后再加 .gz
+	backups, _ := filepath.Glob(stem + "-????-??-??T??-??-??.???" + ext + "*")
+	files = append(files, backups...)
+	// macOS 上 launchd 把服务的标准输出和错误写入日志目录
+	files = append(files, filepath.Join(defaultLogDir(), name+".out.log"), filepath.Join(defaultLogDir(), name+".err.log"))
+
+	history := &HistoryConfig{}
+	readConfigKey(configFilePath, "history", history)
+	files = append(files, historyPath(history, name))
+	trend := &DiskTrendConfig{}
+	readConfigKey(configFilePath, "disk_trend", trend)
+	files = append(files, diskTrendPath(trend, name))
+	digest := &DigestConfig{}
+	readConfigKey(configFilePath, "digest", digest)
+	files = append(files, digestPath(digest, name))
+	files = append(files, holdsPath(name), canaryPath(name), lastRunPath(name))
+	admin := &AdminConfig{}
+	if readConfigKey(configFilePath, "admin", admin) == nil && !strings.HasPrefix(adminPath(admin, name), `\\.\pipe\`) {
+		files = append(files, adminPath(admin, name))
+	}
+	// 状态文件的相对路径相对于配置文件所在的目录
+	base := getCurrentAbPathByExecutable()
+	if configFilePath != "" {
+		if abs, err := filepath.Abs(configFilePath); err == nil {
+			base = filepath.Dir(abs)
+		}
+	}
+	for _, key := range []string{"failure_state", "mark_state", "scan_cache"} {
+		var path string
+		if readConfigKey(configFilePath, key, &path) == nil && path != "" {
+			if path = cleaner.ExpandPath(path); !filepath.IsAbs(path) {
+				path = filepath.Join(base, path)
+			}
+			files = append(files, path)
+		}
+	}
+
+	var existing []string
+	for _, f := range files {
+		// 读取时发现损坏而另存的状态文件
+		if info, err := os.Stat(f + ".corrupt"); err == nil && !info.IsDir() {
+			existing = append(existing, f+".corrupt")
+		}
+		if info, err := os.Stat(f); err == nil && !info.IsDir() {
+			existing = append(existing, f)
+		}
+	}
+	return existing
+}
+
+// purge 列出要删除的文件，确认后删除，日志目录变空时一并删除。yes 为 true 时不再询问
+func purge(configFilePath, name string, logging LoggingConfig, yes bool) error {
+	files := purgeFiles(configFilePath, name, logging)
+	if len(files) == 0 {
+		fmt.Println(i18n.T("没有需要清除的文件"))
+		return nil
+	}
+	fmt.Println(i18n.T("将删除以下文件:"))
+	for _, f := range files {
+		fmt.Println("  " + f)
+	}
+	if !yes {
+		fmt.Print(i18n.T("确认删除？[y/N] "))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println(i18n.T("已取消清除"))
+			return nil
+		}
+	}
+	var failed []string
+	for _, f := range files {
+		if err := os.Remove(f); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	// 只删除空目录，其他实例的日志仍在时保留
+	os.Remove(filepath.Dir(newLogFile(logging, name).Filename))
+	if len(failed) > 0 {
+		return i18n.Errorf("部分文件删除失败: %s", strings.Join(failed, "; "))
+	}
+	fmt.Printf(i18n.T("已删除 %d 个文件")+"\n", len(files))
+	return nil
+}
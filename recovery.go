@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/kardianos/service"
+)
+
+// recoveryOptions 根据 --on-failure 等参数生成服务崩溃后的恢复选项：
+// Windows 上设置服务的失败操作，Linux systemd 上生成 Restart=on-failure 和对应的 RestartSec，
+// macOS 上生成只在异常退出时重启的 launchd plist
+func recoveryOptions(action string, delay, reset time.Duration) (service.KeyValue, error) {
+	opts := service.KeyValue{}
+	restart := "on-failure"
+	switch action {
+	case "restart", "reboot":
+	case "none", "noaction":
+		action, restart = "noaction", "no"
+	default:
+		return nil, i18n.Errorf("--on-failure %q 无效，可选 restart、reboot、none", action)
+	}
+	if delay < time.Second {
+		delay = time.Second
+	}
+	opts["OnFailure"] = action
+	opts["OnFailureDelayDuration"] = delay.String()
+	opts["OnFailureResetPeriod"] = int(reset / time.Second)
+
+	opts["Restart"] = restart
+	script := strings.Replace(systemdScript, "RestartSec=120", fmt.Sprintf("RestartSec=%d", int(delay/time.Second)), 1)
+	if action == "reboot" {
+		script = strings.Replace(script, "[Service]\n", "[Service]\nFailureAction=reboot\n", 1)
+	}
+	opts["SystemdScript"] = script
+	launchdOptions(opts, restart != "no", delay)
+	return opts, nil
+}
+
+// systemdScript 与 kardianos/service 默认的 unit 模板相同，只是 RestartSec 由 --restart-delay 决定
+const systemdScript = `[Unit]
+Description={{.Description}}
+ConditionFileIsExecutable={{.Path|cmdEscape}}
+{{range $i, $dep := .Dependencies}} 
+{{$dep}} {{end}}
+
+[Service]
+StartLimitInterval=5
+StartLimitBurst=10
+ExecStart={{.Path|cmdEscape}}{{range .Arguments}} {{.|cmd}}{{end}}
+{{if .ChRoot}}RootDirectory={{.ChRoot|cmd}}{{end}}
+{{if .WorkingDirectory}}WorkingDirectory={{.WorkingDirectory|cmdEscape}}{{end}}
+{{if .UserName}}User={{.UserName}}{{end}}
+{{if .ReloadSignal}}ExecReload=/bin/kill -{{.ReloadSignal}} "$MAINPID"{{end}}
+{{if .PIDFile}}PIDFile={{.PIDFile|cmd}}{{end}}
+{{if and .LogOutput .HasOutputFileSupport -}}
+StandardOutput=file:{{.LogDirectory}}/{{.Name}}.out
+StandardError=file:{{.LogDirectory}}/{{.Name}}.err
+{{- end}}
+{{if gt .LimitNOFILE -1 }}LimitNOFILE={{.LimitNOFILE}}{{end}}
+{{if .Restart}}Restart={{.Restart}}{{end}}
+{{if .SuccessExitStatus}}SuccessExitStatus={{.SuccessExitStatus}}{{end}}
+RestartSec=120
+EnvironmentFile=-/etc/sysconfig/{{.Name}}
+
+{{range $k, $v := .EnvVars -}}
+Environment={{$k}}={{$v}}
+{{end -}}
+
+[Install]
+WantedBy=multi-user.target
+`
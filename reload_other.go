@@ -0,0 +1,29 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// watchReloadSignal 收到 SIGHUP 时重新加载配置
+func (p *program) watchReloadSignal() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-p.exit:
+				return
+			case <-hup:
+				p.logger.Printf(i18n.T("收到 SIGHUP，重新加载配置"))
+				p.reload()
+			}
+		}
+	}()
+}
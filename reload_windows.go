@@ -0,0 +1,4 @@
+package main
+
+// watchReloadSignal Windows 上没有 SIGHUP，通过 ctl reload、HTTP 或 RPC 接口重新加载配置
+func (p *program) watchReloadSignal() {}
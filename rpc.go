@@ -0,0 +1,226 @@
+package main
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// RPCConfig 控制接口配置。接口使用 JSON-RPC 1.0（net/rpc/jsonrpc），每行一个请求，
+// 方法名为 Cleaner.TriggerRun、Cleaner.GetStatus、Cleaner.ReloadConfig、Cleaner.ListRecentRuns、Cleaner.Pause、Cleaner.Resume、
+// Cleaner.Hold、Cleaner.Release、Cleaner.Confirm
+type RPCConfig struct {
+	Listen     string `yaml:"listen" mapstructure:"listen"` // 如 127.0.0.1:8090，或 unix:/run/cleanlog.sock 使用本地套接字
+	AuthConfig `yaml:",inline" mapstructure:",squash"`
+}
+
+// TriggerRunArgs 立即执行一次任务，Wait 为 true 时等待任务完成并返回结果
+type TriggerRunArgs struct {
+	Wait bool `json:"wait"`
+}
+
+type TriggerRunReply struct {
+	Started bool       `json:"started"` // 已有任务在运行时为 false
+	Result  *runResult `json:"result,omitempty"`
+}
+
+type GetStatusArgs struct{}
+
+type GetStatusReply struct {
+	Healthy bool `json:"healthy"`
+	health
+}
+
+type ReloadConfigArgs struct{}
+
+type ReloadConfigReply struct {
+	Directories int `json:"directories"`
+}
+
+// ListRecentRunsArgs Limit 为 0 时返回全部保留的记录
+type ListRecentRunsArgs struct {
+	Limit int `json:"limit"`
+}
+
+type ListRecentRunsReply struct {
+	Runs []*runResult `json:"runs"` // 最新的在前
+}
+
+type PauseArgs struct{}
+
+type PauseReply struct {
+	Paused bool `json:"paused"`
+}
+
+// HoldArgs 设置或解除目录的保留状态，Path 为绝对路径
+type HoldArgs struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type HoldReply struct {
+	Holds []holdEntry `json:"holds"` // 设置或解除后全部保留的目录
+}
+
+// ConfirmArgs 确认 canary 目录，Path 与配置中的目录项相同
+type ConfirmArgs struct {
+	Path string `json:"path"`
+}
+
+type ConfirmReply struct {
+	Canaries []canaryEntry `json:"canaries"` // 仍未确认的目录
+}
+
+// Control 供管理程序调用的控制接口
+type Control struct {
+	p *program
+}
+
+func (c *Control) TriggerRun(args TriggerRunArgs, reply *TriggerRunReply) error {
+	run, ok := c.p.begin("")
+	if !ok {
+		return nil
+	}
+	reply.Started = true
+	if !args.Wait {
+		go run()
+		return nil
+	}
+	reply.Result = run()
+	return nil
+}
+
+func (c *Control) GetStatus(args GetStatusArgs, reply *GetStatusReply) error {
+	reply.health, reply.Healthy = c.p.health()
+	return nil
+}
+
+func (c *Control) ReloadConfig(args ReloadConfigArgs, reply *ReloadConfigReply) error {
+	config, err := c.p.reload()
+	if err != nil {
+		return err
+	}
+	reply.Directories = len(config.Directories)
+	return nil
+}
+
+func (c *Control) ListRecentRuns(args ListRecentRunsArgs, reply *ListRecentRunsReply) error {
+	c.p.mu.Lock()
+	defer c.p.mu.Unlock()
+	reply.Runs = []*runResult{}
+	for i := len(c.p.history) - 1; i >= 0; i-- {
+		if args.Limit > 0 && len(reply.Runs) >= args.Limit {
+			break
+		}
+		reply.Runs = append(reply.Runs, c.p.history[i])
+	}
+	return nil
+}
+
+// Pause 暂停定时任务，手动触发不受影响
+func (c *Control) Pause(args PauseArgs, reply *PauseReply) error {
+	c.p.setPaused(true)
+	reply.Paused = true
+	return nil
+}
+
+func (c *Control) Resume(args PauseArgs, reply *PauseReply) error {
+	c.p.setPaused(false)
+	return nil
+}
+
+// Hold 暂停处理目录中的文件，状态保存到文件，重启后仍然有效
+func (c *Control) Hold(args HoldArgs, reply *HoldReply) error {
+	if err := c.p.setHold(args.Path, args.Reason, true); err != nil {
+		return err
+	}
+	reply.Holds = c.p.holdList()
+	return nil
+}
+
+// Confirm 确认 canary 目录，之后的任务开始处理其中的文件
+func (c *Control) Confirm(args ConfirmArgs, reply *ConfirmReply) error {
+	if err := c.p.confirmCanary(args.Path); err != nil {
+		return err
+	}
+	reply.Canaries = c.p.pendingCanaries()
+	return nil
+}
+
+// Release 解除目录的保留状态
+func (c *Control) Release(args HoldArgs, reply *HoldReply) error {
+	if err := c.p.setHold(args.Path, "", false); err != nil {
+		return err
+	}
+	reply.Holds = c.p.holdList()
+	return nil
+}
+
+// startRPC 启动控制接口，监听失败只记录日志，不影响清理任务
+func (p *program) startRPC() {
+	cfg := p.config.RPC
+	if cfg == nil || cfg.Listen == "" {
+		return
+	}
+	token, tlsConfig, err := cfg.setup(cfg.Listen)
+	if err != nil {
+		p.logger.Printf(i18n.T("RPC 接口启动失败: %s"), err)
+		return
+	}
+	ln, err := listenControl(cfg.Listen)
+	if err != nil {
+		p.logger.Printf(i18n.T("RPC 接口启动失败: %s"), err)
+		return
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("Cleaner", &Control{p: p}); err != nil {
+		ln.Close()
+		p.logger.Printf(i18n.T("RPC 接口启动失败: %s"), err)
+		return
+	}
+	p.rpc = ln
+	p.logger.Printf(i18n.T("RPC 接口监听 %s"), cfg.Listen)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				select {
+				case <-p.exit:
+					return
+				default:
+				}
+				p.logger.Printf(i18n.T("RPC 接口接受连接失败: %s"), err)
+				time.Sleep(time.Second)
+				continue
+			}
+			var rwc io.ReadWriteCloser = conn
+			if token != "" {
+				rwc = newTokenConn(conn, token)
+			}
+			go server.ServeCodec(jsonrpc.NewServerCodec(rwc))
+		}
+	}()
+}
+
+// listenControl 监听 RPC、gRPC 接口的地址：host:port，或 unix:<路径> 使用本地套接字（与管理套接字一样只有运行服务的账户可以连接）
+func listenControl(listen string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(listen, "unix:"); ok {
+		return listenUnix(path)
+	}
+	return net.Listen("tcp", listen)
+}
+
+func (p *program) stopRPC() {
+	if p.rpc != nil {
+		p.rpc.Close()
+	}
+}
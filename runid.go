@@ -0,0 +1,37 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+)
+
+// newRunID 生成任务 ID：开始时间加随机后缀，如 20240312T050000-3fa9c2，按时间排序即按执行顺序
+func newRunID(start time.Time) string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return start.Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
+
+// setRunID 设置当前任务的 ID，任务期间的每条日志都带上 [run ID]（容器模式下为 JSON 的 run_id 字段），
+// 通知中也附带该 ID；id 为空表示任务结束
+func (p *program) setRunID(id string) {
+	p.runID.Store(id)
+	if p.container {
+		return
+	}
+	if id == "" {
+		p.logger.SetPrefix("")
+		p.logger.SetFlags(p.logger.Flags() &^ log.Lmsgprefix)
+		return
+	}
+	p.logger.SetFlags(p.logger.Flags() | log.Lmsgprefix)
+	p.logger.SetPrefix("[run " + id + "] ")
+}
+
+// currentRunID 正在执行的任务的 ID，没有任务时为空
+func (p *program) currentRunID() string {
+	id, _ := p.runID.Load().(string)
+	return id
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+	"github.com/spf13/viper"
+)
+
+const (
+	scanSHA256 = "sha256"
+	scanHMAC   = "hmac-sha256"
+)
+
+// scanReport 审计扫描的结果，签名覆盖全部字段（含生成时间），作为某一时刻保留策略执行情况的留档证据
+type scanReport struct {
+	Generated    time.Time         `json:"generated_at"`
+	Host         string            `json:"host"`
+	Service      string            `json:"service"`
+	Version      string            `json:"version"`
+	ConfigFile   string            `json:"config_file,omitempty"`
+	ConfigSHA256 string            `json:"config_sha256,omitempty"` // 扫描时配置文件的校验和，说明报告按哪份配置生成
+	Files        int               `json:"files"`
+	Bytes        int64             `json:"bytes"`
+	Directories  []cleaner.ScanDir `json:"directories"`
+}
+
+// scanSignature 报告的签名：配置了 scan_secret 时为 HMAC-SHA256，否则为 SHA-256 校验和
+type scanSignature struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+// signedScan 保存到文件的报告，report 原样保存签名时的字节，校验时不需要重新序列化
+type signedScan struct {
+	Report    json.RawMessage `json:"report"`
+	Signature scanSignature   `json:"signature"`
+}
+
+func signScan(data []byte, secret string) scanSignature {
+	if secret == "" {
+		sum := sha256.Sum256(data)
+		return scanSignature{Algorithm: scanSHA256, Value: hex.EncodeToString(sum[:])}
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(data)
+	return scanSignature{Algorithm: scanHMAC, Value: hex.EncodeToString(mac.Sum(nil))}
+}
+
+// scan 只读审计扫描：列出各目录按当前配置到期、下次任务将被处理的文件，不删除、不修改任何文件，
+// 运行账户只需要读取权限。指定文件时把签名后的报告保存到该文件；scan verify <文件> 校验报告是否被修改。返回退出码
+func (p *program) scan(configFilePath string, args []string) int {
+	if len(args) > 0 && args[0] == "verify" {
+		return p.verifyScan(configFilePath, args[1:])
+	}
+	config, err := p.loadConfig(configFilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("加载配置文件时发生错误: %s")+"\n", err)
+		return exitConfigError
+	}
+	host, _ := os.Hostname()
+	report := scanReport{Generated: time.Now(), Host: host, Service: p.name, Version: getBuildInfo().Version}
+	if used := viper.ConfigFileUsed(); used != "" {
+		report.ConfigFile, _ = filepath.Abs(used)
+		if data, err := os.ReadFile(used); err == nil {
+			sum := sha256.Sum256(data)
+			report.ConfigSHA256 = hex.EncodeToString(sum[:])
+		}
+	}
+	report.Directories, err = cleaner.New(config.Config).Scan(p.ctx, report.Generated)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("扫描失败: %s")+"\n", err)
+		return exitFailures
+	}
+	for _, d := range report.Directories {
+		status := ""
+		switch {
+		case d.Error != "":
+			fmt.Printf(i18n.T("目录 %s: %s")+"\n", d.Path, d.Error)
+			continue
+		case d.OnHold:
+			status = i18n.T("（保留状态，当前不处理）")
+		case d.Canary:
+			status = i18n.T("（canary 模式，确认前不处理）")
+		}
+		fmt.Printf(i18n.T("目录 %s%s: 扫描 %d，到期 %d，共 %s")+"\n", d.Path, status, d.Scanned, len(d.Files), cleaner.ByteSize(d.Bytes))
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range d.Files {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", f.Action, f.Path, cleaner.ByteSize(f.Size), displayTime(f.ModTime), f.Policy)
+		}
+		tw.Flush()
+		report.Files += len(d.Files)
+		report.Bytes += d.Bytes
+	}
+	fmt.Printf(i18n.T("合计到期 %d 项，共 %s")+"\n", report.Files, cleaner.ByteSize(report.Bytes))
+	if len(args) == 0 {
+		return exitOK
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("保存扫描报告失败: %s")+"\n", err)
+		return exitFailures
+	}
+	out, _ := json.MarshalIndent(signedScan{Report: data, Signature: signScan(data, cleaner.ExpandPath(config.ScanSecret))}, "", "  ")
+	if err := os.WriteFile(args[0], append(out, '\n'), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("保存扫描报告失败: %s")+"\n", err)
+		return exitFailures
+	}
+	fmt.Printf(i18n.T("扫描报告已签名并保存到 %s")+"\n", args[0])
+	return exitOK
+}
+
+// verifyScan 重新计算报告的签名并与文件中的签名比较；HMAC 签名的报告需要与签名时相同的 scan_secret
+func (p *program) verifyScan(configFilePath string, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("用法: scan verify <报告文件>"))
+		return exitConfigError
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("读取扫描报告失败: %s")+"\n", err)
+		return exitConfigError
+	}
+	var signed signedScan
+	var report scanReport
+	if err := json.Unmarshal(data, &signed); err != nil || json.Unmarshal(signed.Report, &report) != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("读取扫描报告失败: %s")+"\n", i18n.T("不是 scan 生成的报告"))
+		return exitConfigError
+	}
+	var secret string
+	if signed.Signature.Algorithm == scanHMAC {
+		config, err := p.loadConfig(configFilePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.T("加载配置文件时发生错误: %s")+"\n", err)
+			return exitConfigError
+		}
+		if secret = cleaner.ExpandPath(config.ScanSecret); secret == "" {
+			fmt.Fprintln(os.Stderr, i18n.T("报告使用 HMAC 签名，校验需要配置签名时的 scan_secret"))
+			return exitConfigError
+		}
+	}
+	// 保存时缩进的空白不参与签名，按紧凑格式校验
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, signed.Report); err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("读取扫描报告失败: %s")+"\n", err)
+		return exitConfigError
+	}
+	want := signScan(compact.Bytes(), secret)
+	if want.Algorithm != signed.Signature.Algorithm || !hmac.Equal([]byte(want.Value), []byte(signed.Signature.Value)) {
+		fmt.Printf(i18n.T("校验失败：报告 %s 在签名后被修改，或签名密钥不同")+"\n", args[0])
+		return exitFailures
+	}
+	fmt.Printf(i18n.T("校验通过：%s 由 %s 于 %s 生成（%s），到期 %d 项，共 %s")+"\n",
+		args[0], report.Host, displayTime(report.Generated), signed.Signature.Algorithm, report.Files, cleaner.ByteSize(report.Bytes))
+	return exitOK
+}
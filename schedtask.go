@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/robfig/cron/v3"
+)
+
+// Task Scheduler 最多支持 48 个触发器
+const maxTaskTriggers = 48
+
+// taskDefinition 计划任务的 XML 定义（Task Scheduler 1.2 格式），只包含用到的元素
+type taskDefinition struct {
+	XMLName     xml.Name      `xml:"Task"`
+	Version     string        `xml:"version,attr"`
+	Xmlns       string        `xml:"xmlns,attr"`
+	Description string        `xml:"RegistrationInfo>Description"`
+	Triggers    taskTriggers  `xml:"Triggers"`
+	Principal   taskPrincipal `xml:"Principals>Principal"`
+	Settings    taskSettings  `xml:"Settings"`
+	Actions     taskActions   `xml:"Actions"`
+}
+
+type taskTriggers struct {
+	Time     []taskTrigger `xml:"TimeTrigger"`
+	Calendar []taskTrigger `xml:"CalendarTrigger"`
+}
+
+type taskTrigger struct {
+	Repetition     *taskRepetition `xml:"Repetition,omitempty"`
+	StartBoundary  string          `xml:"StartBoundary"`
+	Enabled        bool            `xml:"Enabled"`
+	ByDay          *taskByDay      `xml:"ScheduleByDay,omitempty"`
+	ByWeek         *taskByWeek     `xml:"ScheduleByWeek,omitempty"`
+	ByMonth        *taskByMonth    `xml:"ScheduleByMonth,omitempty"`
+	ByMonthWeekday *taskByWeekday  `xml:"ScheduleByMonthDayOfWeek,omitempty"`
+}
+
+type taskByDay struct {
+	DaysInterval int `xml:"DaysInterval"`
+}
+
+type taskByWeek struct {
+	DaysOfWeek    taskNames `xml:"DaysOfWeek"`
+	WeeksInterval int       `xml:"WeeksInterval"`
+}
+
+type taskByMonth struct {
+	DaysOfMonth []string  `xml:"DaysOfMonth>Day"`
+	Months      taskNames `xml:"Months"`
+}
+
+// taskByWeekday 按月中的第几周和星期执行
+type taskByWeekday struct {
+	Weeks      []string  `xml:"Weeks>Week"`
+	DaysOfWeek taskNames `xml:"DaysOfWeek"`
+	Months     taskNames `xml:"Months"`
+}
+
+type taskRepetition struct {
+	Interval          string `xml:"Interval"`
+	Duration          string `xml:"Duration,omitempty"`
+	StopAtDurationEnd bool   `xml:"StopAtDurationEnd"`
+}
+
+type taskPrincipal struct {
+	ID        string `xml:"id,attr"`
+	UserID    string `xml:"UserId"`
+	LogonType string `xml:"LogonType,omitempty"`
+	RunLevel  string `xml:"RunLevel"`
+}
+
+type taskSettings struct {
+	MultipleInstancesPolicy    string `xml:"MultipleInstancesPolicy"`
+	DisallowStartIfOnBatteries bool   `xml:"DisallowStartIfOnBatteries"`
+	StopIfGoingOnBatteries     bool   `xml:"StopIfGoingOnBatteries"`
+	StartWhenAvailable         bool   `xml:"StartWhenAvailable"`
+	ExecutionTimeLimit         string `xml:"ExecutionTimeLimit"`
+	Enabled                    bool   `xml:"Enabled"`
+}
+
+type taskActions struct {
+	Context string `xml:"Context,attr"`
+	Exec    struct {
+		Command          string `xml:"Command"`
+		Arguments        string `xml:"Arguments,omitempty"`
+		WorkingDirectory string `xml:"WorkingDirectory,omitempty"`
+	} `xml:"Exec"`
+}
+
+// taskNames 以空元素列出的取值，如 <DaysOfWeek><Monday/><Friday/></DaysOfWeek>
+type taskNames []string
+
+func (n taskNames) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, name := range n {
+		if err := e.EncodeElement("", xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+var (
+	taskWeekdays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	taskMonths   = []string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+)
+
+// cronStar robfig/cron 中字段写成 * 或 ? 时设置的标记位
+const cronStar = 1 << 63
+
+// bitValues 返回 [min, max] 范围内设置了的取值
+func bitValues(bits uint64, min, max int) []int {
+	var values []int
+	for v := min; v <= max; v++ {
+		if bits&(1<<uint(v)) != 0 {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// taskDuration 按 ISO 8601 格式输出时长，如 PT1H30M
+func taskDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	s := "P"
+	if days := d / (24 * time.Hour); days > 0 {
+		s += fmt.Sprintf("%dD", days)
+		d -= days * 24 * time.Hour
+	}
+	if d == 0 {
+		return s
+	}
+	s += "T"
+	for _, u := range []struct {
+		unit time.Duration
+		name string
+	}{{time.Hour, "H"}, {time.Minute, "M"}, {time.Second, "S"}} {
+		if n := d / u.unit; n > 0 {
+			s += fmt.Sprintf("%d%s", n, u.name)
+			d -= n * u.unit
+		}
+	}
+	return s
+}
+
+// taskTriggersFor 把定时表达式转换为计划任务的触发器。Task Scheduler 只能表达按天、周、月的固定时刻
+// 和固定间隔重复，秒有多个取值等无法表达的写法返回错误，这时需要使用服务模式
+func taskTriggersFor(spec string, now time.Time) (taskTriggers, error) {
+	var triggers taskTriggers
+	sched, err := cronParser.Parse(spec)
+	if err != nil {
+		return triggers, i18n.Errorf("time %q 无效: %w", spec, err)
+	}
+	if d, ok := sched.(cron.ConstantDelaySchedule); ok {
+		if d.Delay < time.Minute || d.Delay > 31*24*time.Hour {
+			return triggers, i18n.Errorf("计划任务的重复间隔须在 1 分钟到 31 天之间: %s", spec)
+		}
+		// 间隔从注册时开始计算，与服务模式一致
+		triggers.Time = append(triggers.Time, taskTrigger{
+			Repetition:    &taskRepetition{Interval: taskDuration(d.Delay)},
+			StartBoundary: now.Add(d.Delay).Format("2006-01-02T15:04:05"),
+			Enabled:       true,
+		})
+		return triggers, nil
+	}
+	s, ok := sched.(*cron.SpecSchedule)
+	if !ok {
+		return triggers, i18n.Errorf("无法转换为计划任务的定时表达式: %s", spec)
+	}
+	if s.Location != time.Local {
+		return triggers, i18n.Errorf("计划任务按本机时区执行，不支持 CRON_TZ/TZ: %s", spec)
+	}
+	seconds := bitValues(s.Second, 0, 59)
+	if len(seconds) != 1 {
+		return triggers, i18n.Errorf("计划任务不支持每分钟多次执行: %s", spec)
+	}
+	minutes, hours := bitValues(s.Minute, 0, 59), bitValues(s.Hour, 0, 23)
+
+	// 每天的执行时刻：每小时都执行且分钟间隔均匀时用一个触发器重复执行，否则每个时刻一个触发器
+	type clock struct {
+		hour, minute int
+		repeat       time.Duration
+	}
+	var clocks []clock
+	if step, even := evenStep(minutes, 60); len(hours) == 24 && even {
+		clocks = append(clocks, clock{0, minutes[0], step})
+	} else if step, even := evenStep(hours, 24); len(minutes) == 1 && even && len(hours) > 1 {
+		clocks = append(clocks, clock{hours[0], minutes[0], step * 60})
+	} else {
+		for _, h := range hours {
+			for _, m := range minutes {
+				clocks = append(clocks, clock{h, m, 0})
+			}
+		}
+	}
+
+	// 日期：日和星期有一个是 * 时只看另一个，都限定时满足任一即可，与 cron 一致
+	days, weekdays, months := bitValues(s.Dom, 1, 31), bitValues(s.Dow, 0, 6), bitValues(s.Month, 1, 12)
+	allDays, allWeekdays := len(days) == 31, len(weekdays) == 7
+	var byDay, byWeek, byMonth bool
+	switch {
+	case s.Dom&cronStar != 0 || s.Dow&cronStar != 0:
+		byDay = allDays && allWeekdays
+		byWeek = allDays && !allWeekdays
+		byMonth = !allDays && allWeekdays
+	case allDays || allWeekdays:
+		byDay = true
+	default:
+		byWeek, byMonth = true, true
+	}
+	var monthNames, dayNames taskNames
+	for _, m := range months {
+		monthNames = append(monthNames, taskMonths[m])
+	}
+	for _, d := range weekdays {
+		dayNames = append(dayNames, taskWeekdays[d])
+	}
+	var dayOfMonth []string
+	for _, d := range days {
+		dayOfMonth = append(dayOfMonth, fmt.Sprint(d))
+	}
+	allMonths := len(months) == 12
+	if byDay && !allMonths {
+		// 只在部分月份每天执行
+		byDay, byMonth = false, true
+		dayOfMonth = nil
+		for d := 1; d <= 31; d++ {
+			dayOfMonth = append(dayOfMonth, fmt.Sprint(d))
+		}
+	}
+
+	date := now.Format("2006-01-02")
+	for _, c := range clocks {
+		base := taskTrigger{
+			StartBoundary: fmt.Sprintf("%sT%02d:%02d:%02d", date, c.hour, c.minute, seconds[0]),
+			Enabled:       true,
+		}
+		if c.repeat > 0 {
+			// 重复到当天结束，第二天由日期触发器重新开始
+			end := time.Duration(24-c.hour)*time.Hour - time.Duration(c.minute)*time.Minute
+			base.Repetition = &taskRepetition{Interval: taskDuration(c.repeat), Duration: taskDuration(end)}
+		}
+		if byDay {
+			t := base
+			t.ByDay = &taskByDay{DaysInterval: 1}
+			triggers.Calendar = append(triggers.Calendar, t)
+		}
+		if byWeek && allMonths {
+			t := base
+			t.ByWeek = &taskByWeek{DaysOfWeek: dayNames, WeeksInterval: 1}
+			triggers.Calendar = append(triggers.Calendar, t)
+		} else if byWeek {
+			t := base
+			t.ByMonthWeekday = &taskByWeekday{Weeks: []string{"1", "2", "3", "4", "Last"}, DaysOfWeek: dayNames, Months: monthNames}
+			triggers.Calendar = append(triggers.Calendar, t)
+		}
+		if byMonth {
+			t := base
+			t.ByMonth = &taskByMonth{DaysOfMonth: dayOfMonth, Months: monthNames}
+			triggers.Calendar = append(triggers.Calendar, t)
+		}
+	}
+	if len(triggers.Calendar) > maxTaskTriggers {
+		return triggers, i18n.Errorf("定时表达式 %s 需要 %d 个触发器，超过计划任务的上限 %d", spec, len(triggers.Calendar), maxTaskTriggers)
+	}
+	return triggers, nil
+}
+
+// evenStep 判断取值是否从第一个值起按固定步长覆盖整个周期（如每 15 分钟），返回步长
+func evenStep(values []int, period int) (time.Duration, bool) {
+	if len(values) < 2 || period%len(values) != 0 {
+		return 0, false
+	}
+	step := period / len(values)
+	sort.Ints(values)
+	for i, v := range values {
+		if v != values[0]+i*step {
+			return 0, false
+		}
+	}
+	return time.Duration(step) * time.Minute, true
+}
+
+// taskOptions schedule-task 的参数，来自命令行
+type taskOptions struct {
+	name, description   string
+	configPath, profile string
+	user, password      string
+}
+
+// buildTask 根据配置生成计划任务定义：按 time（或 every）触发，执行 --once 清理一次
+func buildTask(config appConfig, opts taskOptions, now time.Time) ([]byte, error) {
+	specs := config.specs()
+	if len(specs) > 1 {
+		return nil, i18n.Errorf("计划任务每次清理所有目录，不支持目录单独配置 time: %s", strings.Join(specs, ", "))
+	}
+	triggers, err := taskTriggersFor(config.spec(), now)
+	if err != nil {
+		return nil, err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	task := taskDefinition{
+		Version:     "1.2",
+		Xmlns:       "http://schemas.microsoft.com/windows/2004/02/mit/task",
+		Description: opts.description,
+		Triggers:    triggers,
+		// 默认以 SYSTEM 运行，与服务的默认账户 LocalSystem 相同
+		Principal: taskPrincipal{ID: "Author", UserID: "S-1-5-18", RunLevel: "HighestAvailable"},
+		Settings: taskSettings{
+			// 上一次仍在运行时跳过，与 overlap 的默认值一致；错过的执行在开机后补上
+			MultipleInstancesPolicy: "IgnoreNew",
+			StartWhenAvailable:      true,
+			ExecutionTimeLimit:      "PT0S",
+			Enabled:                 true,
+		},
+		Actions: taskActions{Context: "Author"},
+	}
+	if opts.user != "" {
+		task.Principal.UserID, task.Principal.LogonType = opts.user, "Password"
+	}
+	args := []string{"--once"}
+	if opts.name != defaultServiceName {
+		args = append(args, "--name", opts.name)
+	}
+	if opts.configPath != "" {
+		args = append(args, "--config", opts.configPath)
+	}
+	if opts.profile != "" {
+		args = append(args, "--profile", opts.profile)
+	}
+	for i, a := range args {
+		if strings.ContainsAny(a, " \t") {
+			args[i] = `"` + a + `"`
+		}
+	}
+	task.Actions.Exec.Command = exe
+	task.Actions.Exec.Arguments = strings.Join(args, " ")
+	task.Actions.Exec.WorkingDirectory = filepath.Dir(exe)
+
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-16"?>` + "\n")
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(task); err != nil {
+		return nil, err
+	}
+	// schtasks 要求 XML 文件为带 BOM 的 UTF-16
+	out := []byte{0xff, 0xfe}
+	for _, r := range utf16.Encode([]rune(buf.String())) {
+		out = append(out, byte(r), byte(r>>8))
+	}
+	return out, nil
+}
+
+// scheduleTask 执行 schedule-task 子命令：install（默认）注册计划任务，uninstall 删除
+func (p *program) scheduleTask(args []string, opts taskOptions) error {
+	action := "install"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	switch action {
+	case "install":
+	case "uninstall":
+		if err := deleteScheduledTask(opts.name); err != nil {
+			return err
+		}
+		p.logger.Printf(i18n.T("已删除计划任务 %s"), opts.name)
+		return nil
+	default:
+		return i18n.Errorf("未知的 schedule-task 命令 %q，可选 install、uninstall", action)
+	}
+	if opts.configPath != "" {
+		path, err := filepath.Abs(opts.configPath)
+		if err != nil {
+			return err
+		}
+		opts.configPath = path
+	}
+	config, err := p.loadConfig(opts.configPath)
+	if err != nil {
+		return i18n.Errorf("加载配置文件时发生错误: %s", err)
+	}
+	task, err := buildTask(config, opts, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := createScheduledTask(opts.name, task, opts.user, opts.password); err != nil {
+		return err
+	}
+	p.logger.Printf(i18n.T("已注册计划任务 %s，定时: %s"), opts.name, config.spec())
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import "cleanlogservice/pkg/i18n"
+
+// 计划任务模式只用于 Windows，其他系统使用服务模式或系统自带的定时任务
+func createScheduledTask(name string, task []byte, user, password string) error {
+	return i18n.Errorf("schedule-task 只支持 Windows")
+}
+
+func deleteScheduledTask(name string) error {
+	return i18n.Errorf("schedule-task 只支持 Windows")
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// createScheduledTask 通过 schtasks 注册计划任务，已存在同名任务时覆盖
+func createScheduledTask(name string, task []byte, user, password string) error {
+	f, err := os.CreateTemp("", "cleanlog-task-*.xml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(task)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	args := []string{"/Create", "/F", "/TN", name, "/XML", f.Name()}
+	if user != "" {
+		args = append(args, "/RU", user, "/RP", password)
+	}
+	return schtasks(args...)
+}
+
+// deleteScheduledTask 删除计划任务
+func deleteScheduledTask(name string) error {
+	return schtasks("/Delete", "/F", "/TN", name)
+}
+
+func schtasks(args ...string) error {
+	out, err := exec.Command("schtasks", args...).CombinedOutput()
+	if err != nil {
+		return i18n.Errorf("schtasks 执行失败: %s: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"path/filepath"
+
+	"cleanlogservice/pkg/cleaner"
+)
+
+const defaultSelfCleanupDays = 30
+
+// SelfCleanupConfig 服务自身产生的文件按同样的清理流程处理：日志目录中轮转的旧日志（含其他实例或改名前留下的）、
+// 损坏后另存的 .corrupt 状态文件和写入中断留下的临时文件，以及 reports_dir 中的报告（如定期保存的 scan 报告）。
+// 正在写入的日志和状态文件不匹配，不会被删除
+type SelfCleanupConfig struct {
+	Enabled    *bool  `yaml:"enabled" mapstructure:"enabled"`         // 默认开启
+	Days       int    `yaml:"days" mapstructure:"days"`               // 保留天数，默认 30
+	ReportsDir string `yaml:"reports_dir" mapstructure:"reports_dir"` // 报告目录，其中的文件全部按 days 清理
+}
+
+// selfPatterns 日志目录中由本服务产生、可以按年龄删除的文件
+var selfPatterns = []string{
+	// lumberjack 的备份文件名形如 cleanlog-2024-01-02T15-04-05.000.log，压缩后再加 .gz
+	"cleanlog*-????-??-??T??-??-??.???.log", "cleanlog*-????-??-??T??-??-??.???.log.gz",
+	"*.json.corrupt", "*.jsonl.corrupt", "*.json.tmp", "*.jsonl.tmp",
+}
+
+// selfDirectories 服务自身的日志目录和报告目录对应的目录项，加入清理目录后与其他目录一起处理、报告；
+// 跳过正在使用的文件，目录不存在时静默跳过，不经过 canary 阶段。容器模式下或日志文件无法创建时日志不写文件，只处理报告目录
+func (p *program) selfDirectories(config appConfig) []cleaner.DirConfig {
+	cfg := config.SelfCleanup
+	if cfg == nil {
+		cfg = &SelfCleanupConfig{}
+	}
+	if cfg.Enabled != nil && !*cfg.Enabled {
+		return nil
+	}
+	days := cfg.Days
+	if days <= 0 {
+		days = defaultSelfCleanupDays
+	}
+	canary := false
+	dir := func(path string, patterns []string) cleaner.DirConfig {
+		d := cleaner.DirConfig{Path: path, Days: days, SkipInUse: true, MissingDir: "ignore", Canary: &canary}
+		if len(patterns) > 0 {
+			d.Filters = []cleaner.FilterSpec{{Type: "glob", Params: map[string]interface{}{"patterns": patterns}}}
+		}
+		return d
+	}
+	var dirs []cleaner.DirConfig
+	if !p.container && !p.logFallback {
+		dirs = append(dirs, dir(filepath.Dir(newLogFile(config.Logging, p.name).Filename), selfPatterns))
+	}
+	if cfg.ReportsDir != "" {
+		dirs = append(dirs, dir(cleaner.ExpandPath(cfg.ReportsDir), nil))
+	}
+	return dirs
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+)
+
+// simulate 读取 bench 保存的快照，按当前配置（通常是修改后、尚未上线的配置）离线预演快照记录时的一次任务，
+// 列出各目录中将被处理的文件，不访问实际的目录。返回退出码
+func (p *program) simulate(configFilePath string, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, i18n.T("用法: simulate <快照文件>，快照由 bench <快照文件> 生成"))
+		return exitConfigError
+	}
+	snap, err := cleaner.ReadSnapshot(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("读取快照失败: %s")+"\n", err)
+		return exitConfigError
+	}
+	config, err := p.loadConfig(configFilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("加载配置文件时发生错误: %s")+"\n", err)
+		return exitConfigError
+	}
+	results, err := cleaner.New(config.Config).Simulate(p.ctx, snap, time.Time{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, i18n.T("预演失败: %s")+"\n", err)
+		return exitFailures
+	}
+	fmt.Printf(i18n.T("快照 %s（%s，%s），按记录时的时间预演")+"\n", args[0], snap.Host, displayTime(snap.Taken))
+	var files int
+	var bytes int64
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Printf(i18n.T("目录 %s: %s")+"\n", r.Path, r.Error)
+			continue
+		}
+		fmt.Printf(i18n.T("目录 %s: 扫描 %d，将处理 %d 个文件，共 %s")+"\n", r.Path, r.Scanned, len(r.Files), cleaner.ByteSize(r.Bytes))
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range r.Files {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", f.Action, f.Path, cleaner.ByteSize(f.Size), displayTime(f.ModTime))
+		}
+		tw.Flush()
+		files += len(r.Files)
+		bytes += r.Bytes
+	}
+	fmt.Printf(i18n.T("合计将处理 %d 个文件，共 %s")+"\n", files, cleaner.ByteSize(bytes))
+	return exitOK
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+	"github.com/robfig/cron/v3"
+)
+
+// unitOptions systemd 子命令的参数，来自命令行
+type unitOptions struct {
+	name, description   string
+	configPath, profile string
+	user                string
+	depends             []string
+	onFailure           string
+	restartDelay        time.Duration
+}
+
+// unitFile 生成的 unit 文件
+type unitFile struct {
+	name    string
+	content string
+}
+
+// systemdQuote 按 systemd 的规则引用 ExecStart 的参数，% 需写成 %%
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if s == "" || strings.ContainsAny(s, " \t\"'\\;") {
+		s = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+	return s
+}
+
+// unitName 按 systemd-escape 的规则转义 unit 名称，unit 名称只能包含 ASCII 字母、数字和 :_.-，
+// 默认服务名称中的中文会转义为 \xNN
+func unitName(name, suffix string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte(":_.-", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+	return b.String() + suffix
+}
+
+// execStart 返回启动本程序的命令行，extra 为附加在最前面的参数（如 --once）
+func execStart(opts unitOptions, extra ...string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	args := append([]string{exe}, extra...)
+	if opts.name != defaultServiceName {
+		args = append(args, "--name", opts.name)
+	}
+	if opts.configPath != "" {
+		args = append(args, "--config", opts.configPath)
+	}
+	if opts.profile != "" {
+		args = append(args, "--profile", opts.profile)
+	}
+	for i, a := range args {
+		args[i] = systemdQuote(a)
+	}
+	return strings.Join(args, " "), nil
+}
+
+// onCalendar 把定时表达式转换为 timer 的 OnCalendar（或 @every 对应的 OnUnitActiveSec）设置
+func onCalendar(spec string) ([]string, error) {
+	sched, err := cronParser.Parse(spec)
+	if err != nil {
+		return nil, i18n.Errorf("time %q 无效: %w", spec, err)
+	}
+	if d, ok := sched.(cron.ConstantDelaySchedule); ok {
+		sec := int(d.Delay / time.Second)
+		return []string{fmt.Sprintf("OnBootSec=%ds", sec), fmt.Sprintf("OnUnitActiveSec=%ds", sec)}, nil
+	}
+	s, ok := sched.(*cron.SpecSchedule)
+	if !ok {
+		return nil, i18n.Errorf("无法转换为 systemd 定时器的定时表达式: %s", spec)
+	}
+	field := func(bits uint64, min, max int, format func(int) string) string {
+		values := bitValues(bits, min, max)
+		if len(values) == max-min+1 {
+			return "*"
+		}
+		parts := make([]string, len(values))
+		for i, v := range values {
+			parts[i] = format(v)
+		}
+		return strings.Join(parts, ",")
+	}
+	num := func(v int) string { return fmt.Sprintf("%02d", v) }
+	weekday := func(v int) string { return taskWeekdays[v][:3] }
+	clock := field(s.Hour, 0, 23, num) + ":" + field(s.Minute, 0, 59, num) + ":" + field(s.Second, 0, 59, num)
+	months := field(s.Month, 1, 12, num)
+	days := field(s.Dom, 1, 31, num)
+	weekdays := field(s.Dow, 0, 6, weekday)
+	tz := ""
+	if s.Location != time.Local {
+		tz = " " + s.Location.String()
+	}
+	// systemd 要求日期和星期同时满足，cron 中两者都限定时满足任一即可，这时拆成两条
+	var specs []string
+	if s.Dom&cronStar == 0 && s.Dow&cronStar == 0 && days != "*" && weekdays != "*" {
+		specs = append(specs, "*-"+months+"-"+days+" "+clock+tz, weekdays+" *-"+months+"-* "+clock+tz)
+	} else if s.Dom&cronStar == 0 && s.Dow&cronStar == 0 {
+		specs = append(specs, "*-"+months+"-* "+clock+tz)
+	} else {
+		prefix := ""
+		if weekdays != "*" {
+			prefix = weekdays + " "
+		}
+		specs = append(specs, prefix+"*-"+months+"-"+days+" "+clock+tz)
+	}
+	for i, spec := range specs {
+		specs[i] = "OnCalendar=" + spec
+	}
+	return specs, nil
+}
+
+// serviceUnit 生成常驻服务的 unit，重启策略与 install 的 --on-failure、--restart-delay 一致
+func serviceUnit(opts unitOptions) (unitFile, error) {
+	cmd, err := execStart(opts)
+	if err != nil {
+		return unitFile{}, err
+	}
+	recovery, err := recoveryOptions(opts.onFailure, opts.restartDelay, 0)
+	if err != nil {
+		return unitFile{}, err
+	}
+	var b strings.Builder
+	writeUnitHeader(&b, opts)
+	b.WriteString("[Service]\nType=simple\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", cmd)
+	// 收到 SIGHUP 时重新加载配置
+	b.WriteString("ExecReload=/bin/kill -HUP $MAINPID\n")
+	writeUnitService(&b, opts)
+	fmt.Fprintf(&b, "Restart=%s\n", recovery["Restart"])
+	if recovery["Restart"] != "no" {
+		fmt.Fprintf(&b, "RestartSec=%d\n", int(opts.restartDelay.Round(time.Second)/time.Second))
+	}
+	if recovery["OnFailure"] == "reboot" {
+		b.WriteString("FailureAction=reboot\n")
+	}
+	b.WriteString("\n[Install]\nWantedBy=multi-user.target\n")
+	return unitFile{unitName(opts.name, ".service"), b.String()}, nil
+}
+
+// timerUnits 生成一次性模式的 service 和 timer：按配置的 time（或 every）触发，每次执行 --once
+func timerUnits(config appConfig, opts unitOptions) ([]unitFile, error) {
+	if specs := config.specs(); len(specs) > 1 {
+		return nil, i18n.Errorf("定时器模式每次清理所有目录，不支持目录单独配置 time: %s", strings.Join(specs, ", "))
+	}
+	calendar, err := onCalendar(config.spec())
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := execStart(opts, "--once")
+	if err != nil {
+		return nil, err
+	}
+	var svc strings.Builder
+	writeUnitHeader(&svc, opts)
+	svc.WriteString("[Service]\nType=oneshot\n")
+	fmt.Fprintf(&svc, "ExecStart=%s\n", cmd)
+	writeUnitService(&svc, opts)
+
+	var timer strings.Builder
+	fmt.Fprintf(&timer, "[Unit]\nDescription=%s\n\n[Timer]\n", opts.description)
+	for _, c := range calendar {
+		timer.WriteString(c + "\n")
+	}
+	// 关机期间错过的执行在开机后补上
+	if !strings.HasPrefix(calendar[0], "OnBootSec=") {
+		timer.WriteString("Persistent=true\n")
+	}
+	timer.WriteString("\n[Install]\nWantedBy=timers.target\n")
+	return []unitFile{{unitName(opts.name, ".service"), svc.String()}, {unitName(opts.name, ".timer"), timer.String()}}, nil
+}
+
+func writeUnitHeader(b *strings.Builder, opts unitOptions) {
+	fmt.Fprintf(b, "[Unit]\nDescription=%s\n", opts.description)
+	// 清理网络共享和远程目标需要网络就绪
+	after := append([]string{"network-online.target"}, opts.depends...)
+	fmt.Fprintf(b, "Wants=%s\nAfter=%s\n\n", strings.Join(after, " "), strings.Join(after, " "))
+}
+
+func writeUnitService(b *strings.Builder, opts unitOptions) {
+	if exe, err := os.Executable(); err == nil {
+		fmt.Fprintf(b, "WorkingDirectory=%s\n", filepath.Dir(exe))
+	}
+	if opts.user != "" {
+		fmt.Fprintf(b, "User=%s\n", opts.user)
+	}
+}
+
+// systemdUnits 执行 systemd 子命令：systemd [timer] [目录]。未指定目录时输出到标准输出，
+// 指定目录（如 /etc/systemd/system）时写入文件，已存在的文件不覆盖
+func (p *program) systemdUnits(args []string, opts unitOptions) error {
+	timer := len(args) > 0 && args[0] == "timer"
+	if timer {
+		args = args[1:]
+	}
+	if opts.configPath != "" {
+		path, err := filepath.Abs(opts.configPath)
+		if err != nil {
+			return err
+		}
+		opts.configPath = path
+	}
+	var units []unitFile
+	if timer {
+		config, err := p.loadConfig(opts.configPath)
+		if err != nil {
+			return i18n.Errorf("加载配置文件时发生错误: %s", err)
+		}
+		if units, err = timerUnits(config, opts); err != nil {
+			return err
+		}
+	} else {
+		unit, err := serviceUnit(opts)
+		if err != nil {
+			return err
+		}
+		units = append(units, unit)
+	}
+	if len(args) == 0 {
+		for i, u := range units {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("# %s\n%s", u.name, u.content)
+		}
+		return nil
+	}
+	for _, u := range units {
+		path := filepath.Join(args[0], u.name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			if os.IsExist(err) {
+				return i18n.Errorf("%s 已存在，不覆盖", path)
+			}
+			return err
+		}
+		_, err = f.WriteString(u.content)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	enable := units[len(units)-1].name
+	if strings.Contains(enable, `\`) {
+		enable = "'" + enable + "'"
+	}
+	fmt.Printf(i18n.T("执行 systemctl daemon-reload && systemctl enable --now %s 启用")+"\n", enable)
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cleanlogservice/pkg/cleaner"
+	"cleanlogservice/pkg/i18n"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	defaultWatchCooldown = 5 * time.Minute
+	watchCheckInterval   = 10 * time.Second // 有变化的目录每隔多久统计一次，避免每个写入事件都读取目录
+)
+
+// watchedDir 被监视的目录，dir 为通配符展开后的目录配置
+type watchedDir struct {
+	dir       cleaner.DirConfig
+	dirty     bool      // 上次统计后有文件变化
+	triggered time.Time // 上次触发清理的时间
+}
+
+// dirUsage 统计目录中（不含子目录）的文件数和总大小
+func dirUsage(path string) (int, int64, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	var n int
+	var size int64
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			n++
+			size += info.Size()
+		}
+	}
+	return n, size, nil
+}
+
+// watchDirectories 以文件系统通知监视各本地目录，文件数超过 watch_max_files 或总大小超过 watch_max_size 时
+// 立即只清理该目录，不等下一次定时任务；同一目录两次触发之间至少间隔 watch_cooldown。
+// 监视的目录在服务启动时确定，修改后重启服务生效
+func (p *program) watchDirectories() {
+	p.mu.Lock()
+	config := p.config
+	p.mu.Unlock()
+	if !config.Watch {
+		return
+	}
+	if config.WatchMaxFiles <= 0 && config.WatchMaxSize <= 0 {
+		p.logger.Printf(i18n.T("watch 需要配置 watch_max_files 或 watch_max_size，未启用监视"))
+		return
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		p.logger.Printf(i18n.T("启动目录监视失败: %s"), err)
+		return
+	}
+	defer w.Close()
+	dirs := map[string]*watchedDir{}
+	for _, dir := range config.Directories {
+		if strings.Contains(dir.Path, "://") || dir.Docker != nil {
+			continue
+		}
+		paths := []string{dir.Path}
+		if strings.ContainsAny(dir.Path, "*?[") {
+			paths, _ = filepath.Glob(dir.Path)
+		}
+		for _, path := range paths {
+			path = filepath.Clean(path)
+			if err := w.Add(path); err != nil {
+				p.logger.Printf(i18n.T("监视目录 %s 失败: %s"), path, err)
+				continue
+			}
+			d := dir
+			d.Path = path
+			// 启动时先统计一次，已超过阈值的目录立即清理
+			dirs[path] = &watchedDir{dir: d, dirty: true}
+		}
+	}
+	if len(dirs) == 0 {
+		return
+	}
+	p.logger.Printf(i18n.T("监视 %d 个目录，超过阈值时立即清理"), len(dirs))
+
+	ticker := time.NewTicker(watchCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.exit:
+			return
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if ev.Op&(fsnotify.Create|fsnotify.Write) != 0 {
+				if d := dirs[filepath.Dir(ev.Name)]; d != nil {
+					d.dirty = true
+				}
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			// 事件队列溢出时丢失的事件无法得知涉及哪些目录，全部重新统计
+			p.logger.Printf(i18n.T("目录监视出错: %s"), err)
+			for _, d := range dirs {
+				d.dirty = true
+			}
+		case <-ticker.C:
+			for _, d := range dirs {
+				if d.dirty {
+					p.checkWatched(d)
+				}
+			}
+		}
+	}
+}
+
+// checkWatched 统计有变化的目录，超过阈值且不在冷却期内时触发只清理该目录的任务
+func (p *program) checkWatched(d *watchedDir) {
+	p.mu.Lock()
+	config, paused := p.config, p.paused
+	p.mu.Unlock()
+	cooldown := config.WatchCooldown
+	if cooldown <= 0 {
+		cooldown = defaultWatchCooldown
+	}
+	if paused || time.Since(d.triggered) < cooldown {
+		return // 保持 dirty，冷却期过后再统计
+	}
+	d.dirty = false
+	n, size, err := dirUsage(d.dir.Path)
+	if err != nil {
+		return
+	}
+	overFiles := config.WatchMaxFiles > 0 && n > config.WatchMaxFiles
+	overSize := config.WatchMaxSize > 0 && size > int64(config.WatchMaxSize)
+	if !overFiles && !overSize {
+		return
+	}
+	d.triggered = time.Now()
+	p.logger.Printf(i18n.T("目录 %s 有 %d 个文件、共 %s，超过 watch 阈值，立即清理该目录"), d.dir.Path, n, cleaner.ByteSize(size))
+	cfg := config.Config
+	cfg.Directories = []cleaner.DirConfig{d.dir}
+	go func() {
+		defer p.recoverPanic(i18n.T("监视触发的任务"))
+		if run, ok := p.beginWith("", cleaner.New(cfg)); ok {
+			run()
+		}
+	}()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"time"
+
+	"cleanlogservice/pkg/i18n"
+)
+
+// watchdogInterval 看门狗检查的间隔
+const watchdogInterval = time.Minute
+
+// WatchdogConfig 看门狗：任务运行过久，或连续多个定时周期没有成功完成的任务时发送通知
+type WatchdogConfig struct {
+	MaxDuration time.Duration `yaml:"max_duration" mapstructure:"max_duration"` // 任务运行超过该时长时通知
+	MissedRuns  int           `yaml:"missed_runs" mapstructure:"missed_runs"`   // 连续多少个定时周期没有成功完成的任务时通知，包括调度器未能启动的情况
+}
+
+// watchdog 定期检查任务状态，每种异常只通知一次，直到恢复正常
+func (p *program) watchdog() {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+	var stuck, missed bool
+	for {
+		select {
+		case <-p.exit:
+			return
+		case now := <-ticker.C:
+			p.mu.Lock()
+			cfg := p.config.Watchdog
+			running, lastRun, since := p.running, p.lastRun, p.lastSuccess
+			if since.IsZero() {
+				since = p.started
+			}
+			specs := p.config.specs()
+			p.mu.Unlock()
+			if cfg == nil {
+				continue
+			}
+
+			if cfg.MaxDuration > 0 && running && now.Sub(lastRun) > cfg.MaxDuration {
+				if !stuck {
+					stuck = true
+					p.notify("stuck", i18n.T("清理任务运行时间过长"),
+						i18n.Sprintf("任务从 %s 开始，已运行 %s，超过预期的 %s", displayTime(lastRun), now.Sub(lastRun).Round(time.Second), cfg.MaxDuration))
+				}
+			} else {
+				stuck = false
+			}
+
+			if cfg.MissedRuns > 0 {
+				if deadline, ok := nthRun(specs, since, cfg.MissedRuns); ok && now.After(deadline) {
+					if !missed {
+						missed = true
+						p.notify("missed", i18n.T("清理任务长时间没有成功完成"),
+							i18n.Sprintf("自 %s 起已有 %d 个定时周期没有成功完成的任务", displayTime(since), cfg.MissedRuns))
+					}
+				} else {
+					missed = false
+				}
+			}
+		}
+	}
+}
+
+// nthRun 按配置的定时表达式计算 t 之后第 n 次定时任务的时间。
+// 直接解析配置而不是使用已注册的任务，调度器启动失败时同样能发现
+func nthRun(specs []string, t time.Time, n int) (time.Time, bool) {
+	var schedules []interface{ Next(time.Time) time.Time }
+	for _, spec := range specs {
+		if s, err := cronParser.Parse(spec); err == nil {
+			schedules = append(schedules, s)
+		}
+	}
+	if len(schedules) == 0 {
+		return time.Time{}, false
+	}
+	for i := 0; i < n; i++ {
+		var next time.Time
+		for _, s := range schedules {
+			if t := s.Next(t); next.IsZero() || t.Before(next) {
+				next = t
+			}
+		}
+		t = next
+	}
+	return t, true
+}